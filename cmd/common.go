@@ -15,6 +15,7 @@ import (
 func Init() {
 	bootstrap.InitConfig()
 	bootstrap.Log()
+	bootstrap.InitCache()
 	bootstrap.InitDB()
 	data.InitData()
 	bootstrap.InitIndex()
@@ -22,6 +23,7 @@ func Init() {
 
 func Release() {
 	db.Close()
+	bootstrap.CloseCache()
 }
 
 var pid = -1
@@ -0,0 +1,57 @@
+//go:build fuse
+
+package cmd
+
+import (
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/alist-org/alist/v3/internal/bootstrap"
+	"github.com/alist-org/alist/v3/internal/fuse"
+	"github.com/alist-org/alist/v3/pkg/utils"
+	"github.com/spf13/cobra"
+)
+
+var (
+	mountPath    string
+	mountOptions []string
+)
+
+// MountCmd represents the mount command
+var MountCmd = &cobra.Command{
+	Use:   "mount <mountpoint>",
+	Short: "Mount the alist file tree to a local directory",
+	Long: `Mount the alist file tree to a local directory with fuse,
+it's available only if built with the fuse tag`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		Init()
+		defer Release()
+		bootstrap.LoadStorages()
+		host := fuse.NewHost(mountPath)
+		quit := make(chan os.Signal, 1)
+		signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
+		go func() {
+			<-quit
+			utils.Log.Println("unmounting...")
+			host.Unmount()
+		}()
+		utils.Log.Infof("mount [%s] to [%s]", mountPath, args[0])
+		var opts []string
+		for _, o := range mountOptions {
+			opts = append(opts, "-o", o)
+		}
+		if !host.Mount(args[0], opts) {
+			utils.Log.Errorf("failed to mount [%s]", args[0])
+			return
+		}
+		utils.Log.Println("unmounted")
+	},
+}
+
+func init() {
+	RootCmd.AddCommand(MountCmd)
+	MountCmd.Flags().StringVar(&mountPath, "path", "/", "the alist path to mount")
+	MountCmd.Flags().StringArrayVarP(&mountOptions, "option", "o", nil, "fuse mount options, e.g. -o allow_other")
+}
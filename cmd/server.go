@@ -18,6 +18,8 @@ import (
 	"github.com/alist-org/alist/v3/internal/conf"
 	"github.com/alist-org/alist/v3/pkg/utils"
 	"github.com/alist-org/alist/v3/server"
+	"github.com/alist-org/alist/v3/server/ftp"
+	"github.com/alist-org/alist/v3/server/sftp"
 	"github.com/gin-gonic/gin"
 	log "github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
@@ -38,6 +40,9 @@ the address is defined in config file`,
 		bootstrap.InitOfflineDownloadTools()
 		bootstrap.LoadStorages()
 		bootstrap.InitTaskManager()
+		bootstrap.InitTrashCleaner()
+		bootstrap.InitIndexUpdater()
+		bootstrap.InitSyncJobs()
 		if !flags.Debug && !flags.Dev {
 			gin.SetMode(gin.ReleaseMode)
 		}
@@ -112,6 +117,34 @@ the address is defined in config file`,
 				}
 			}()
 		}
+		var ftpSrv *ftp.Server
+		if conf.Conf.FTP.Port != -1 && conf.Conf.FTP.Enable {
+			var err error
+			ftpSrv, err = ftp.NewServer()
+			if err != nil {
+				utils.Log.Fatalf("failed to create ftp server: %s", err.Error())
+			}
+			utils.Log.Infof("start ftp server @ %s", ftpSrv.Addr)
+			go func() {
+				if err := ftpSrv.ListenAndServe(); err != nil {
+					utils.Log.Fatalf("failed to start ftp server: %s", err.Error())
+				}
+			}()
+		}
+		var sftpSrv *sftp.Server
+		if conf.Conf.SFTP.Port != -1 && conf.Conf.SFTP.Enable {
+			var err error
+			sftpSrv, err = sftp.NewServer()
+			if err != nil {
+				utils.Log.Fatalf("failed to create sftp server: %s", err.Error())
+			}
+			utils.Log.Infof("start sftp server @ %s", sftpSrv.Addr)
+			go func() {
+				if err := sftpSrv.ListenAndServe(); err != nil {
+					utils.Log.Fatalf("failed to start sftp server: %s", err.Error())
+				}
+			}()
+		}
 		// Wait for interrupt signal to gracefully shutdown the server with
 		// a timeout of 1 second.
 		quit := make(chan os.Signal, 1)
@@ -152,6 +185,16 @@ the address is defined in config file`,
 				}
 			}()
 		}
+		if ftpSrv != nil {
+			if err := ftpSrv.Close(); err != nil {
+				utils.Log.Error("ftp server shutdown err: ", err)
+			}
+		}
+		if sftpSrv != nil {
+			if err := sftpSrv.Close(); err != nil {
+				utils.Log.Error("sftp server shutdown err: ", err)
+			}
+		}
 		wg.Wait()
 		utils.Log.Println("Server exit")
 	},
@@ -200,4 +200,20 @@ func (d *Pan115) DeleteOfflineTasks(ctx context.Context, hashes []string, delete
 	return d.client.DeleteOfflineTasks(hashes, deleteFiles)
 }
 
+func (d *Pan115) GetDetails(ctx context.Context) (*model.StorageDetails, error) {
+	if err := d.WaitLimit(ctx); err != nil {
+		return nil, err
+	}
+	info, err := d.getSpaceInfo()
+	if err != nil {
+		return nil, err
+	}
+	return &model.StorageDetails{
+		TotalSpace: int64(info.AllTotal.Size),
+		UsedSpace:  int64(info.AllUse.Size),
+		FreeSpace:  int64(info.AllRemain.Size),
+	}, nil
+}
+
 var _ driver.Driver = (*Pan115)(nil)
+var _ driver.WithDetails = (*Pan115)(nil)
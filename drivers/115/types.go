@@ -20,3 +20,21 @@ func (f *FileObj) CreateTime() time.Time {
 func (f *FileObj) GetHash() utils.HashInfo {
 	return utils.NewHashInfo(utils.SHA1, f.Sha1)
 }
+
+type IndexInfoResp struct {
+	driver.BasicResp
+	Data struct {
+		SpaceInfo SpaceInfo `json:"space_info"`
+	} `json:"data"`
+}
+
+type SpaceInfo struct {
+	AllTotal  SizeInfo `json:"all_total"`
+	AllRemain SizeInfo `json:"all_remain"`
+	AllUse    SizeInfo `json:"all_use"`
+}
+
+type SizeInfo struct {
+	Size       float64 `json:"size"`
+	SizeFormat string  `json:"size_format"`
+}
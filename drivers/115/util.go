@@ -389,6 +389,18 @@ func chunksProducer(ch chan oss.FileChunk, chunks []oss.FileChunk) {
 	}
 }
 
+func (d *Pan115) getSpaceInfo() (*SpaceInfo, error) {
+	result := IndexInfoResp{}
+	req := d.client.NewRequest().
+		ForceContentType("application/json;charset=UTF-8").
+		SetResult(&result)
+	resp, err := req.Get("https://webapi.115.com/files/index_info")
+	if err = driver115.CheckErr(err, &result, resp); err != nil {
+		return nil, err
+	}
+	return &result.Data.SpaceInfo, nil
+}
+
 func (d *Pan115) checkUploadStatus(dirID, sha1 string) error {
 	// 验证上传是否成功
 	req := d.client.NewRequest().ForceContentType("application/json;charset=UTF-8")
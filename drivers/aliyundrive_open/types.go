@@ -82,3 +82,10 @@ type MoveOrCopyResp struct {
 	DriveID string `json:"drive_id"`
 	FileID  string `json:"file_id"`
 }
+
+type SpaceInfo struct {
+	PersonalSpaceInfo struct {
+		UsedSize  int64 `json:"used_size"`
+		TotalSize int64 `json:"total_size"`
+	} `json:"personal_space_info"`
+}
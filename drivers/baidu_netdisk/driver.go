@@ -329,4 +329,13 @@ func (d *BaiduNetdisk) uploadSlice(ctx context.Context, params map[string]string
 	return nil
 }
 
+func (d *BaiduNetdisk) GetDetails(ctx context.Context) (*model.StorageDetails, error) {
+	quota, err := d.getQuota()
+	if err != nil {
+		return nil, err
+	}
+	return &model.StorageDetails{TotalSpace: quota.Total, UsedSpace: quota.Used, FreeSpace: quota.Free}, nil
+}
+
 var _ driver.Driver = (*BaiduNetdisk)(nil)
+var _ driver.WithDetails = (*BaiduNetdisk)(nil)
@@ -248,6 +248,14 @@ const (
 	SVipSliceSize          = 32 * utils.MB
 )
 
+func (d *BaiduNetdisk) getQuota() (*QuotaResp, error) {
+	var resp QuotaResp
+	_, err := d.request("https://pan.baidu.com/api/quota", http.MethodGet, func(req *resty.Request) {
+		req.SetQueryParam("checkfree", "1")
+	}, &resp)
+	return &resp, err
+}
+
 func (d *BaiduNetdisk) getSliceSize() int64 {
 	if d.CustomUploadPartSize != 0 {
 		return d.CustomUploadPartSize
@@ -0,0 +1,275 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	stdpath "path"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/alist-org/alist/v3/cmd/flags"
+	"github.com/alist-org/alist/v3/drivers/base"
+	"github.com/alist-org/alist/v3/internal/driver"
+	"github.com/alist-org/alist/v3/internal/errs"
+	"github.com/alist-org/alist/v3/internal/fs"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/internal/op"
+	"github.com/alist-org/alist/v3/internal/stream"
+	"github.com/alist-org/alist/v3/pkg/http_range"
+	"github.com/alist-org/alist/v3/pkg/utils"
+)
+
+type Cache struct {
+	model.Storage
+	Addition
+	remoteStorage driver.Driver
+	store         *store
+	// guards the pinned dirs
+	mu sync.Mutex
+}
+
+func (d *Cache) Config() driver.Config {
+	return config
+}
+
+func (d *Cache) GetAddition() driver.Additional {
+	return &d.Addition
+}
+
+func (d *Cache) Init(ctx context.Context) error {
+	if d.MaxSize <= 0 || d.BlockSize <= 0 {
+		return errors.New("max size and block size must be positive")
+	}
+	d.RemotePath = utils.FixAndCleanPath(d.RemotePath)
+	if utils.IsSubPath(d.RemotePath, d.MountPath) || utils.IsSubPath(d.MountPath, d.RemotePath) {
+		return errors.New("the remote path can't contain the cache itself")
+	}
+	//need remote storage exist
+	storage, err := fs.GetStorage(d.RemotePath, &fs.GetStoragesArgs{})
+	if err != nil {
+		return fmt.Errorf("can't find remote storage: %w", err)
+	}
+	d.remoteStorage = storage
+	dir := d.CacheDir
+	if dir == "" {
+		dir = filepath.Join(flags.DataDir, "block_cache", strconv.Itoa(int(d.ID)))
+	}
+	// the blocks of another size are not reusable
+	dir = filepath.Join(dir, strconv.FormatInt(d.BlockSize, 10))
+	d.store, err = newStore(dir, d.MaxSize*utils.MB, d.BlockSize*utils.KB, cleanPaths(d.Pinned))
+	if err != nil {
+		return fmt.Errorf("failed to load the cache dir: %w", err)
+	}
+	return nil
+}
+
+func (d *Cache) Drop(ctx context.Context) error {
+	return nil
+}
+
+func (d *Cache) List(ctx context.Context, dir model.Obj, args model.ListArgs) ([]model.Obj, error) {
+	remoteDir, err := d.remoteActualPath(dir.GetPath())
+	if err != nil {
+		return nil, err
+	}
+	objs, err := op.List(ctx, d.remoteStorage, remoteDir, model.ListArgs{Refresh: args.Refresh})
+	if err != nil {
+		return nil, err
+	}
+	return utils.MustSliceConvert(objs, toObj), nil
+}
+
+func (d *Cache) Get(ctx context.Context, path string) (model.Obj, error) {
+	if utils.PathEqual(path, "/") {
+		return &model.Object{
+			Name:     "Root",
+			IsFolder: true,
+			Path:     "/",
+		}, nil
+	}
+	remotePath, err := d.remoteActualPath(path)
+	if err != nil {
+		return nil, err
+	}
+	remoteObj, err := op.Get(ctx, d.remoteStorage, remotePath)
+	if err != nil {
+		return nil, err
+	}
+	return &model.Object{
+		Path:     path,
+		Name:     remoteObj.GetName(),
+		Size:     remoteObj.GetSize(),
+		Modified: remoteObj.ModTime(),
+		IsFolder: remoteObj.IsDir(),
+	}, nil
+}
+
+// Link reads the file from the cached blocks, the upstream link is got only if some blocks are missing
+func (d *Cache) Link(ctx context.Context, file model.Obj, args model.LinkArgs) (*model.Link, error) {
+	remotePath, err := d.remoteActualPath(file.GetPath())
+	if err != nil {
+		return nil, err
+	}
+	size := file.GetSize()
+	fetch := func(ctx context.Context, r http_range.Range) (io.ReadCloser, error) {
+		link, _, err := op.Link(ctx, d.remoteStorage, remotePath, args)
+		if err != nil {
+			return nil, err
+		}
+		return stream.GetRangeReaderFromLink(ctx, size, link, r)
+	}
+	key := fileKey(file.GetPath(), file)
+	return &model.Link{
+		RangeReadCloser: &model.RangeReadCloser{
+			RangeReader: func(ctx context.Context, r http_range.Range) (io.ReadCloser, error) {
+				if r.Length < 0 || r.Start+r.Length > size {
+					r.Length = size - r.Start
+				}
+				return &blockReader{
+					ctx:   ctx,
+					store: d.store,
+					key:   key,
+					path:  file.GetPath(),
+					size:  size,
+					fetch: fetch,
+					pos:   r.Start,
+					end:   r.Start + r.Length,
+				}, nil
+			},
+		},
+	}, nil
+}
+
+func (d *Cache) MakeDir(ctx context.Context, parentDir model.Obj, dirName string) error {
+	remoteDir, err := d.remoteActualPath(parentDir.GetPath())
+	if err != nil {
+		return err
+	}
+	return op.MakeDir(ctx, d.remoteStorage, stdpath.Join(remoteDir, dirName))
+}
+
+func (d *Cache) Move(ctx context.Context, srcObj, dstDir model.Obj) error {
+	src, err := d.remoteActualPath(srcObj.GetPath())
+	if err != nil {
+		return err
+	}
+	dst, err := d.remoteActualPath(dstDir.GetPath())
+	if err != nil {
+		return err
+	}
+	if err = op.Move(ctx, d.remoteStorage, src, dst); err == nil {
+		d.store.removePath(srcObj.GetPath())
+	}
+	return err
+}
+
+func (d *Cache) Rename(ctx context.Context, srcObj model.Obj, newName string) error {
+	src, err := d.remoteActualPath(srcObj.GetPath())
+	if err != nil {
+		return err
+	}
+	if err = op.Rename(ctx, d.remoteStorage, src, newName); err == nil {
+		d.store.removePath(srcObj.GetPath())
+	}
+	return err
+}
+
+func (d *Cache) Copy(ctx context.Context, srcObj, dstDir model.Obj) error {
+	src, err := d.remoteActualPath(srcObj.GetPath())
+	if err != nil {
+		return err
+	}
+	dst, err := d.remoteActualPath(dstDir.GetPath())
+	if err != nil {
+		return err
+	}
+	return op.Copy(ctx, d.remoteStorage, src, dst)
+}
+
+func (d *Cache) Remove(ctx context.Context, obj model.Obj) error {
+	path, err := d.remoteActualPath(obj.GetPath())
+	if err != nil {
+		return err
+	}
+	// the recycle bin has been handled by op.Remove of the cache
+	if err = op.RemovePermanently(ctx, d.remoteStorage, path); err == nil {
+		d.store.removePath(obj.GetPath())
+	}
+	return err
+}
+
+func (d *Cache) Put(ctx context.Context, dstDir model.Obj, s model.FileStreamer, up driver.UpdateProgress) error {
+	remoteDir, err := d.remoteActualPath(dstDir.GetPath())
+	if err != nil {
+		return err
+	}
+	if err = op.Put(ctx, d.remoteStorage, remoteDir, s, up); err == nil {
+		// the blocks of the overwritten file
+		d.store.removePath(stdpath.Join(dstDir.GetPath(), s.GetName()))
+	}
+	return err
+}
+
+// Other provides the methods to manage the cache of the obj for the admins:
+//   - stats: the stats of the cache and the size cached in the obj
+//   - evict: removes the blocks cached in the obj
+//   - pin: keeps the blocks in the dir from eviction and warms it up by a task
+//   - unpin: allows the blocks in the dir to be evicted
+func (d *Cache) Other(ctx context.Context, args model.OtherArgs) (interface{}, error) {
+	if user, ok := ctx.Value("user").(*model.User); !ok || !user.IsAdmin() {
+		return nil, errs.PermissionDenied
+	}
+	path := utils.FixAndCleanPath(args.Obj.GetPath())
+	switch args.Method {
+	case "stats":
+		return d.store.stats(path), nil
+	case "evict":
+		return base.Json{"freed": d.store.removePath(path)}, nil
+	case "pin":
+		if !args.Obj.IsDir() {
+			return nil, errors.New("only a dir can be pinned")
+		}
+		d.setPinned(func(pinned []string) []string {
+			if utils.SliceContains(pinned, path) {
+				return pinned
+			}
+			return append(pinned, path)
+		})
+		t, err := fs.WarmUp(ctx, stdpath.Join(d.MountPath, path))
+		if err != nil {
+			return nil, err
+		}
+		return base.Json{"task_id": t.GetID()}, nil
+	case "unpin":
+		d.setPinned(func(pinned []string) []string {
+			return utils.SliceFilter(pinned, func(p string) bool {
+				return p != path
+			})
+		})
+		return nil, nil
+	}
+	return nil, errs.NotSupport
+}
+
+// setPinned updates the pinned dirs by fn and saves them
+func (d *Cache) setPinned(fn func(pinned []string) []string) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	pinned := fn(cleanPaths(d.Pinned))
+	d.Pinned = strings.Join(pinned, "\n")
+	d.store.setPinned(pinned)
+	op.MustSaveDriverStorage(d)
+}
+
+var _ driver.Driver = (*Cache)(nil)
+var _ driver.Mkdir = (*Cache)(nil)
+var _ driver.Move = (*Cache)(nil)
+var _ driver.Rename = (*Cache)(nil)
+var _ driver.Copy = (*Cache)(nil)
+var _ driver.Remove = (*Cache)(nil)
+var _ driver.Put = (*Cache)(nil)
+var _ driver.Other = (*Cache)(nil)
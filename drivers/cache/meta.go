@@ -0,0 +1,28 @@
+package cache
+
+import (
+	"github.com/alist-org/alist/v3/internal/driver"
+	"github.com/alist-org/alist/v3/internal/op"
+)
+
+type Addition struct {
+	RemotePath string `json:"remote_path" required:"true" help:"the mount path of the storage to be cached"`
+	CacheDir   string `json:"cache_dir" help:"the dir on local disk to keep the blocks, default to block_cache/<storage id> in the data dir"`
+	MaxSize    int64  `json:"max_size" type:"number" required:"true" default:"10240" help:"the max size of the blocks in MB, the least recently used ones are evicted"`
+	BlockSize  int64  `json:"block_size" type:"number" required:"true" default:"1024" help:"the size of each block in KB, the blocks are discarded if it's changed"`
+	Pinned     string `json:"pinned" type:"text" help:"the dirs whose blocks are never evicted, one per line, the pin method of fs/other adds the dir and warms it up"`
+}
+
+var config = driver.Config{
+	Name:        "Cache",
+	LocalSort:   true,
+	OnlyProxy:   true,
+	NoCache:     true,
+	DefaultRoot: "/",
+}
+
+func init() {
+	op.RegisterDriver(func() driver.Driver {
+		return &Cache{}
+	})
+}
@@ -0,0 +1,350 @@
+package cache
+
+import (
+	"container/list"
+	"crypto/md5"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/pkg/utils"
+	log "github.com/sirupsen/logrus"
+)
+
+// pathName is the name of the file in the dir of blocks, which records the path of file in the storage
+const pathName = "path"
+
+type blockID struct {
+	key   string
+	index int64
+}
+
+type block struct {
+	blockID
+	size int64
+}
+
+type cachedFile struct {
+	path string
+	// the count of blocks cached
+	blocks int
+}
+
+// store keeps the blocks of files in dir, the least recently used ones are
+// evicted when the size exceeds maxSize, except the pinned ones
+type store struct {
+	dir       string
+	maxSize   int64
+	blockSize int64
+
+	mu     sync.Mutex
+	lru    *list.List // front is the most recently used
+	blocks map[blockID]*list.Element
+	files  map[string]*cachedFile
+	pinned []string
+	size   int64
+	hits   int64
+	misses int64
+}
+
+type Stats struct {
+	Size       int64 `json:"size"`
+	MaxSize    int64 `json:"max_size"`
+	PinnedSize int64 `json:"pinned_size"`
+	Blocks     int   `json:"blocks"`
+	Files      int   `json:"files"`
+	Hits       int64 `json:"hits"`
+	Misses     int64 `json:"misses"`
+	// the size cached in the requested path
+	PathSize int64 `json:"path_size"`
+}
+
+// fileKey identifies the version of file, so the blocks of the modified file are not read
+func fileKey(path string, obj model.Obj) string {
+	sum := md5.Sum([]byte(fmt.Sprintf("%s:%d:%d", path, obj.GetSize(), obj.ModTime().UnixNano())))
+	return hex.EncodeToString(sum[:])
+}
+
+func newStore(dir string, maxSize, blockSize int64, pinned []string) (*store, error) {
+	if err := os.MkdirAll(dir, 0777); err != nil {
+		return nil, err
+	}
+	s := &store{
+		dir:       dir,
+		maxSize:   maxSize,
+		blockSize: blockSize,
+		lru:       list.New(),
+		blocks:    make(map[blockID]*list.Element),
+		pinned:    pinned,
+		files:     make(map[string]*cachedFile),
+	}
+	return s, s.load()
+}
+
+// load restores the index from the blocks on disk, the last modified time is treated as the last used time
+func (s *store) load() error {
+	dirs, err := os.ReadDir(s.dir)
+	if err != nil {
+		return err
+	}
+	type loaded struct {
+		block
+		modified time.Time
+	}
+	var blocks []loaded
+	for _, d := range dirs {
+		dir := filepath.Join(s.dir, d.Name())
+		path, err := os.ReadFile(filepath.Join(dir, pathName))
+		if !d.IsDir() || err != nil {
+			_ = os.RemoveAll(dir)
+			continue
+		}
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			return err
+		}
+		var count int
+		for _, e := range entries {
+			if e.Name() == pathName {
+				continue
+			}
+			index, err := strconv.ParseInt(e.Name(), 10, 64)
+			info, infoErr := e.Info()
+			if err != nil || infoErr != nil {
+				// the block was being written
+				_ = os.Remove(filepath.Join(dir, e.Name()))
+				continue
+			}
+			blocks = append(blocks, loaded{
+				block:    block{blockID: blockID{key: d.Name(), index: index}, size: info.Size()},
+				modified: info.ModTime(),
+			})
+			count++
+		}
+		if count == 0 {
+			_ = os.RemoveAll(dir)
+			continue
+		}
+		s.files[d.Name()] = &cachedFile{path: string(path), blocks: count}
+	}
+	sort.Slice(blocks, func(i, j int) bool {
+		return blocks[i].modified.Before(blocks[j].modified)
+	})
+	for _, b := range blocks {
+		s.blocks[b.blockID] = s.lru.PushFront(b.block)
+		s.size += b.size
+	}
+	// the max size may be reduced
+	s.evict(0)
+	return nil
+}
+
+func (s *store) blockPath(b blockID) string {
+	return filepath.Join(s.dir, b.key, strconv.FormatInt(b.index, 10))
+}
+
+// get reads the block, it returns false if the block is not cached
+func (s *store) get(key string, index int64) ([]byte, bool) {
+	b := blockID{key: key, index: index}
+	s.mu.Lock()
+	e, ok := s.blocks[b]
+	if ok {
+		s.lru.MoveToFront(e)
+	}
+	s.mu.Unlock()
+	if ok {
+		data, err := os.ReadFile(s.blockPath(b))
+		if err == nil {
+			_ = os.Chtimes(s.blockPath(b), time.Now(), time.Now())
+			s.mu.Lock()
+			s.hits++
+			s.mu.Unlock()
+			return data, true
+		}
+		if !os.IsNotExist(err) {
+			log.Warnf("failed to read the cached block %s: %+v", s.blockPath(b), err)
+		}
+	}
+	s.mu.Lock()
+	s.misses++
+	// the block is missing on disk, so it can be cached again
+	if e, ok := s.blocks[b]; ok {
+		s.remove(e)
+	}
+	s.mu.Unlock()
+	return nil, false
+}
+
+// put caches the block of the file at path, the block is dropped if there isn't enough space
+func (s *store) put(key, path string, index int64, data []byte) {
+	b := block{blockID: blockID{key: key, index: index}, size: int64(len(data))}
+	s.mu.Lock()
+	if _, ok := s.blocks[b.blockID]; ok || !s.evict(b.size) {
+		s.mu.Unlock()
+		return
+	}
+	// the space is reserved before writing
+	s.size += b.size
+	s.mu.Unlock()
+	if err := s.write(b, path, data); err != nil {
+		log.Warnf("failed to cache the block %s: %+v", s.blockPath(b.blockID), err)
+		s.mu.Lock()
+		s.size -= b.size
+		s.mu.Unlock()
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.blocks[b.blockID]; ok {
+		// written by another reader at the same time
+		s.size -= b.size
+		return
+	}
+	s.blocks[b.blockID] = s.lru.PushFront(b)
+	f, ok := s.files[key]
+	if !ok {
+		f = &cachedFile{path: path}
+		s.files[key] = f
+	}
+	f.blocks++
+}
+
+func (s *store) write(b block, path string, data []byte) error {
+	dir := filepath.Join(s.dir, b.key)
+	if err := os.MkdirAll(dir, 0777); err != nil {
+		return err
+	}
+	if err := os.WriteFile(filepath.Join(dir, pathName), []byte(path), 0666); err != nil {
+		return err
+	}
+	// the block is written to a temp file first, so a partial one is never read
+	tmp, err := os.CreateTemp(dir, "*.tmp")
+	if err != nil {
+		return err
+	}
+	_, err = tmp.Write(data)
+	if e := tmp.Close(); err == nil {
+		err = e
+	}
+	if err == nil {
+		err = os.Rename(tmp.Name(), s.blockPath(b.blockID))
+	}
+	if err != nil {
+		_ = os.Remove(tmp.Name())
+	}
+	return err
+}
+
+func (s *store) isPinned(path string) bool {
+	for _, p := range s.pinned {
+		if utils.IsSubPath(p, path) {
+			return true
+		}
+	}
+	return false
+}
+
+// evict removes the least recently used blocks which aren't pinned until there
+// is space for need bytes, it reports whether there is enough space. Nothing is
+// removed if the space can't be freed, e.g. the pinned blocks take too much
+func (s *store) evict(need int64) bool {
+	if s.size+need <= s.maxSize {
+		return true
+	}
+	freeable := int64(0)
+	for e := s.lru.Front(); e != nil; e = e.Next() {
+		b := e.Value.(block)
+		if !s.isPinned(s.files[b.key].path) {
+			freeable += b.size
+		}
+	}
+	if s.size-freeable+need > s.maxSize {
+		return false
+	}
+	for e := s.lru.Back(); e != nil && s.size+need > s.maxSize; {
+		prev := e.Prev()
+		b := e.Value.(block)
+		if !s.isPinned(s.files[b.key].path) {
+			s.remove(e)
+		}
+		e = prev
+	}
+	return s.size+need <= s.maxSize
+}
+
+func (s *store) remove(e *list.Element) {
+	b := s.lru.Remove(e).(block)
+	delete(s.blocks, b.blockID)
+	s.size -= b.size
+	if err := os.Remove(s.blockPath(b.blockID)); err != nil && !os.IsNotExist(err) {
+		log.Warnf("failed to remove the cached block %s: %+v", s.blockPath(b.blockID), err)
+	}
+	f := s.files[b.key]
+	if f.blocks--; f.blocks == 0 {
+		delete(s.files, b.key)
+		_ = os.RemoveAll(filepath.Join(s.dir, b.key))
+	}
+}
+
+// removePath removes the blocks of the files in path and returns the size freed
+func (s *store) removePath(path string) int64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	size := s.size
+	for e := s.lru.Front(); e != nil; {
+		next := e.Next()
+		if utils.IsSubPath(path, s.files[e.Value.(block).key].path) {
+			s.remove(e)
+		}
+		e = next
+	}
+	return size - s.size
+}
+
+func (s *store) setPinned(pinned []string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.pinned = pinned
+}
+
+// stats returns the stats of store and the size cached in path
+func (s *store) stats(path string) Stats {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	res := Stats{
+		Size:    s.size,
+		MaxSize: s.maxSize,
+		Blocks:  len(s.blocks),
+		Files:   len(s.files),
+		Hits:    s.hits,
+		Misses:  s.misses,
+	}
+	for id, e := range s.blocks {
+		filePath := s.files[id.key].path
+		size := e.Value.(block).size
+		if s.isPinned(filePath) {
+			res.PinnedSize += size
+		}
+		if utils.IsSubPath(path, filePath) {
+			res.PathSize += size
+		}
+	}
+	return res
+}
+
+func cleanPaths(text string) []string {
+	var paths []string
+	for _, p := range strings.Split(text, "\n") {
+		if p = strings.TrimSpace(p); p != "" {
+			paths = append(paths, utils.FixAndCleanPath(p))
+		}
+	}
+	return paths
+}
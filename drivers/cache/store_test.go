@@ -0,0 +1,228 @@
+package cache
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"testing"
+
+	"github.com/alist-org/alist/v3/internal/errs"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/pkg/http_range"
+	"github.com/pkg/errors"
+)
+
+func fill(b byte) []byte {
+	return bytes.Repeat([]byte{b}, 10)
+}
+
+func TestEvict(t *testing.T) {
+	// 3 blocks of 10 bytes at most
+	s, err := newStore(t.TempDir(), 30, 10, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name string
+		do   func()
+		// the files whose block is cached
+		cached []string
+	}{
+		{name: "put", do: func() {
+			for _, key := range []string{"a", "b", "c"} {
+				s.put(key, "/"+key, 0, fill(key[0]))
+			}
+		}, cached: []string{"a", "b", "c"}},
+		{name: "evict the least recently used", do: func() {
+			s.get("a", 0)
+			s.put("d", "/d", 0, fill('d'))
+		}, cached: []string{"a", "c", "d"}},
+		{name: "too large", do: func() {
+			s.put("e", "/e", 0, make([]byte, 31))
+		}, cached: []string{"a", "c", "d"}},
+		{name: "remove path", do: func() {
+			s.removePath("/c")
+		}, cached: []string{"a", "d"}},
+	}
+	for _, tt := range tests {
+		tt.do()
+		for _, key := range []string{"a", "b", "c", "d", "e"} {
+			_, ok := s.blocks[blockID{key: key}]
+			if want := contains(tt.cached, key); ok != want {
+				t.Errorf("%s: the block of %s is cached: %v, want %v", tt.name, key, ok, want)
+			}
+		}
+		if want := int64(len(tt.cached) * 10); s.size != want {
+			t.Errorf("%s: size = %d, want %d", tt.name, s.size, want)
+		}
+	}
+	// the index is restored from disk
+	s, err = newStore(s.dir, 30, 10, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if data, ok := s.get("d", 0); !ok || !bytes.Equal(data, fill('d')) {
+		t.Errorf("the block of d isn't loaded: %q", data)
+	}
+	if st := s.stats("/"); st.Blocks != 2 || st.Files != 2 || st.Size != 20 {
+		t.Errorf("stats() = %+v, want 2 blocks of 2 files", st)
+	}
+}
+
+func TestPinnedOverflow(t *testing.T) {
+	s, err := newStore(t.TempDir(), 30, 10, []string{"/pinned"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	s.put("a", "/a", 0, fill('a'))
+	for i := int64(0); i < 3; i++ {
+		s.put("p", "/pinned/p", i, fill('p'))
+	}
+	tests := []struct {
+		key   string
+		index int64
+		ok    bool
+	}{
+		// the unpinned one is evicted for the pinned ones
+		{key: "a", index: 0, ok: false},
+		{key: "p", index: 0, ok: true},
+		{key: "p", index: 2, ok: true},
+	}
+	for _, tt := range tests {
+		if _, ok := s.get(tt.key, tt.index); ok != tt.ok {
+			t.Errorf("get(%s, %d) cached: %v, want %v", tt.key, tt.index, ok, tt.ok)
+		}
+	}
+	// the pinned blocks are kept even if they take all the space
+	s.put("b", "/b", 0, fill('b'))
+	s.put("p", "/pinned/p", 3, fill('p'))
+	if _, ok := s.get("b", 0); ok {
+		t.Errorf("the block of b is cached while the pinned blocks take all the space")
+	}
+	if _, ok := s.get("p", 3); ok {
+		t.Errorf("the pinned block is cached beyond the max size")
+	}
+	if st := s.stats("/pinned"); st.Size != 30 || st.PinnedSize != 30 || st.PathSize != 30 {
+		t.Errorf("stats() = %+v, want 30 bytes pinned", st)
+	}
+	// the blocks can be evicted after unpinned
+	s.setPinned(nil)
+	s.put("b", "/b", 0, fill('b'))
+	if _, ok := s.get("b", 0); !ok {
+		t.Errorf("the block of b isn't cached after unpinned")
+	}
+}
+
+func TestBlockReader(t *testing.T) {
+	data := make([]byte, 95)
+	for i := range data {
+		data[i] = byte(i)
+	}
+	tests := []struct {
+		name string
+		// the blocks cached before reading
+		cached []int64
+		r      http_range.Range
+		// the ranges fetched from upstream
+		fetched []http_range.Range
+	}{
+		{
+			name:    "uncached",
+			r:       http_range.Range{Start: 15, Length: 20},
+			fetched: []http_range.Range{{Start: 10, Length: 30}},
+		},
+		{
+			name:    "cached in the middle",
+			cached:  []int64{2, 3},
+			r:       http_range.Range{Start: 15, Length: 40},
+			fetched: []http_range.Range{{Start: 10, Length: 50}, {Start: 40, Length: 20}},
+		},
+		{
+			name:   "all cached",
+			cached: []int64{1, 2},
+			r:      http_range.Range{Start: 12, Length: 15},
+		},
+		{
+			name:    "the last block",
+			cached:  []int64{8},
+			r:       http_range.Range{Start: 85, Length: 10},
+			fetched: []http_range.Range{{Start: 90, Length: 5}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, err := newStore(t.TempDir(), 1000, 10, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, i := range tt.cached {
+				s.put("f", "/f", i, data[i*10:min(int64(len(data)), i*10+10)])
+			}
+			var fetched []http_range.Range
+			r := &blockReader{
+				ctx:   context.Background(),
+				store: s,
+				key:   "f",
+				path:  "/f",
+				size:  int64(len(data)),
+				fetch: func(ctx context.Context, r http_range.Range) (io.ReadCloser, error) {
+					fetched = append(fetched, r)
+					return io.NopCloser(bytes.NewReader(data[r.Start : r.Start+r.Length])), nil
+				},
+				pos: tt.r.Start,
+				end: tt.r.Start + tt.r.Length,
+			}
+			got, err := io.ReadAll(r)
+			_ = r.Close()
+			if want := data[tt.r.Start : tt.r.Start+tt.r.Length]; err != nil || !bytes.Equal(got, want) {
+				t.Errorf("read %v, %v, want %v", got, err, want)
+			}
+			if len(fetched) != len(tt.fetched) {
+				t.Fatalf("fetched %+v, want %+v", fetched, tt.fetched)
+			}
+			for i := range fetched {
+				if fetched[i] != tt.fetched[i] {
+					t.Errorf("fetched %+v, want %+v", fetched, tt.fetched)
+				}
+			}
+			// the blocks read are cached
+			for i := tt.r.Start / 10; i*10 < tt.r.Start+tt.r.Length; i++ {
+				if _, ok := s.get("f", i); !ok {
+					t.Errorf("the block %d isn't cached", i)
+				}
+			}
+		})
+	}
+}
+
+func TestStatsForAdmins(t *testing.T) {
+	s, err := newStore(t.TempDir(), 30, 10, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	d := &Cache{store: s}
+	tests := []struct {
+		name string
+		ctx  context.Context
+		err  error
+	}{
+		{name: "anonymous", ctx: context.Background(), err: errs.PermissionDenied},
+		{name: "general", ctx: context.WithValue(context.Background(), "user", &model.User{Role: model.GENERAL}), err: errs.PermissionDenied},
+		{name: "admin", ctx: context.WithValue(context.Background(), "user", &model.User{Role: model.ADMIN})},
+	}
+	for _, tt := range tests {
+		_, err := d.Other(tt.ctx, model.OtherArgs{Obj: &model.Object{Path: "/"}, Method: "stats"})
+		if !errors.Is(err, tt.err) {
+			t.Errorf("%s: stats error = %v, want %v", tt.name, err, tt.err)
+		}
+	}
+}
+
+func contains(keys []string, key string) bool {
+	for _, k := range keys {
+		if k == key {
+			return true
+		}
+	}
+	return false
+}
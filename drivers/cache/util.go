@@ -0,0 +1,111 @@
+package cache
+
+import (
+	"context"
+	"io"
+	stdpath "path"
+
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/internal/op"
+	"github.com/alist-org/alist/v3/pkg/http_range"
+)
+
+func (d *Cache) remoteActualPath(path string) (string, error) {
+	_, actualPath, err := op.GetStorageAndActualPath(stdpath.Join(d.RemotePath, path))
+	return actualPath, err
+}
+
+type fetchFunc func(ctx context.Context, r http_range.Range) (io.ReadCloser, error)
+
+// blockReader reads the range of file block by block, the missing blocks are
+// read from upstream, which is kept open while the blocks are missing in a row
+type blockReader struct {
+	ctx   context.Context
+	store *store
+	key   string
+	path  string
+	size  int64
+	fetch fetchFunc
+
+	pos, end int64
+	// the block containing pos
+	buf      []byte
+	bufStart int64
+
+	upstream io.ReadCloser
+	upPos    int64
+}
+
+func (r *blockReader) Read(p []byte) (int, error) {
+	if r.pos >= r.end {
+		return 0, io.EOF
+	}
+	if r.pos < r.bufStart || r.pos >= r.bufStart+int64(len(r.buf)) {
+		if err := r.load(r.pos / r.store.blockSize); err != nil {
+			return 0, err
+		}
+	}
+	n := copy(p, r.buf[r.pos-r.bufStart:min(int64(len(r.buf)), r.end-r.bufStart)])
+	r.pos += int64(n)
+	return n, nil
+}
+
+func (r *blockReader) load(index int64) error {
+	start := index * r.store.blockSize
+	if data, ok := r.store.get(r.key, index); ok {
+		r.buf, r.bufStart = data, start
+		return nil
+	}
+	if r.upstream == nil || r.upPos != start {
+		if err := r.closeUpstream(); err != nil {
+			return err
+		}
+		// read until the block containing the end, so the last block is complete
+		end := min(r.size, (r.end+r.store.blockSize-1)/r.store.blockSize*r.store.blockSize)
+		rc, err := r.fetch(r.ctx, http_range.Range{Start: start, Length: end - start})
+		if err != nil {
+			return err
+		}
+		r.upstream, r.upPos = rc, start
+	}
+	data := make([]byte, min(r.store.blockSize, r.size-start))
+	if _, err := io.ReadFull(r.upstream, data); err != nil {
+		return err
+	}
+	r.upPos += int64(len(data))
+	r.store.put(r.key, r.path, index, data)
+	r.buf, r.bufStart = data, start
+	return nil
+}
+
+func (r *blockReader) closeUpstream() error {
+	if r.upstream == nil {
+		return nil
+	}
+	rc := r.upstream
+	r.upstream = nil
+	return rc.Close()
+}
+
+func (r *blockReader) Close() error {
+	return r.closeUpstream()
+}
+
+func toObj(obj model.Obj) model.Obj {
+	objRes := model.Object{
+		Name:     obj.GetName(),
+		Size:     obj.GetSize(),
+		Modified: obj.ModTime(),
+		IsFolder: obj.IsDir(),
+	}
+	thumb, ok := model.GetThumb(obj)
+	if !ok {
+		return &objRes
+	}
+	return &model.ObjThumb{
+		Object: objRes,
+		Thumbnail: model.Thumbnail{
+			Thumbnail: thumb,
+		},
+	}
+}
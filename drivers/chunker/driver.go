@@ -0,0 +1,290 @@
+package chunker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	stdpath "path"
+	"strings"
+
+	"github.com/alist-org/alist/v3/internal/driver"
+	"github.com/alist-org/alist/v3/internal/errs"
+	"github.com/alist-org/alist/v3/internal/fs"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/internal/op"
+	"github.com/alist-org/alist/v3/internal/stream"
+	"github.com/alist-org/alist/v3/pkg/generic_sync"
+	"github.com/alist-org/alist/v3/pkg/http_range"
+	"github.com/alist-org/alist/v3/pkg/utils"
+	log "github.com/sirupsen/logrus"
+)
+
+type Chunker struct {
+	model.Storage
+	Addition
+	remoteStorage driver.Driver
+	// the remote paths of the dirs of parts to the infos of chunked files
+	infos generic_sync.MapOf[string, chunkedInfo]
+}
+
+func (d *Chunker) Config() driver.Config {
+	return config
+}
+
+func (d *Chunker) GetAddition() driver.Additional {
+	return &d.Addition
+}
+
+func (d *Chunker) Init(ctx context.Context) error {
+	if d.ChunkSize <= 0 {
+		return errors.New("chunk size must be positive")
+	}
+	if !strings.HasPrefix(d.ChunkSuffix, ".") || strings.Contains(d.ChunkSuffix, "/") {
+		return errors.New("chunk suffix must start with a dot and can't contain a slash")
+	}
+	d.RemotePath = utils.FixAndCleanPath(d.RemotePath)
+	if utils.IsSubPath(d.RemotePath, d.MountPath) || utils.IsSubPath(d.MountPath, d.RemotePath) {
+		return errors.New("the remote path can't contain the chunker itself")
+	}
+	//need remote storage exist
+	storage, err := fs.GetStorage(d.RemotePath, &fs.GetStoragesArgs{})
+	if err != nil {
+		return fmt.Errorf("can't find remote storage: %w", err)
+	}
+	d.remoteStorage = storage
+	return nil
+}
+
+func (d *Chunker) Drop(ctx context.Context) error {
+	d.infos.Clear()
+	return nil
+}
+
+// List shows the dirs of parts as the files, the incomplete ones are skipped
+func (d *Chunker) List(ctx context.Context, dir model.Obj, args model.ListArgs) ([]model.Obj, error) {
+	remoteDir, err := d.remoteActualPath(dir.GetPath())
+	if err != nil {
+		return nil, err
+	}
+	objs, err := op.List(ctx, d.remoteStorage, remoteDir, model.ListArgs{Refresh: args.Refresh})
+	if err != nil {
+		return nil, err
+	}
+	var result []model.Obj
+	for _, obj := range objs {
+		name, ok := strings.CutSuffix(obj.GetName(), d.ChunkSuffix)
+		if !obj.IsDir() || !ok || name == "" {
+			result = append(result, toObj(obj))
+			continue
+		}
+		info, err := d.getChunkedInfo(ctx, stdpath.Join(remoteDir, obj.GetName()), obj, args.Refresh)
+		if err != nil {
+			if !errs.IsObjectNotFound(err) {
+				log.Warnf("failed to list the parts of %s: %+v", obj.GetName(), err)
+			}
+			continue
+		}
+		result = append(result, &model.Object{
+			Name:     name,
+			Size:     info.size,
+			Modified: info.modified,
+		})
+	}
+	return result, nil
+}
+
+func (d *Chunker) Get(ctx context.Context, path string) (model.Obj, error) {
+	if utils.PathEqual(path, "/") {
+		return &model.Object{
+			Name:     "Root",
+			IsFolder: true,
+			Path:     "/",
+		}, nil
+	}
+	remotePath, err := d.remoteActualPath(path)
+	if err != nil {
+		return nil, err
+	}
+	if c, err := d.getChunked(ctx, remotePath+d.ChunkSuffix, false); err == nil {
+		return &model.Object{
+			Path:     path,
+			Name:     stdpath.Base(path),
+			Size:     c.size,
+			Modified: c.meta.ModTime(),
+		}, nil
+	}
+	remoteObj, err := op.Get(ctx, d.remoteStorage, remotePath)
+	if err != nil {
+		return nil, err
+	}
+	if remoteObj.IsDir() && strings.HasSuffix(remoteObj.GetName(), d.ChunkSuffix) {
+		// the dir of parts is hidden
+		return nil, errs.ObjectNotFound
+	}
+	return &model.Object{
+		Path:     path,
+		Name:     remoteObj.GetName(),
+		Size:     remoteObj.GetSize(),
+		Modified: remoteObj.ModTime(),
+		IsFolder: remoteObj.IsDir(),
+	}, nil
+}
+
+func (d *Chunker) Link(ctx context.Context, file model.Obj, args model.LinkArgs) (*model.Link, error) {
+	remotePath, err := d.remoteActualPath(file.GetPath())
+	if err != nil {
+		return nil, err
+	}
+	c, err := d.getChunked(ctx, remotePath+d.ChunkSuffix, false)
+	if errs.IsObjectNotFound(err) {
+		// the file is not chunked
+		link, _, err := op.Link(ctx, d.remoteStorage, remotePath, args)
+		return link, err
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err = d.check(ctx, c); err != nil {
+		return nil, err
+	}
+	return &model.Link{
+		RangeReadCloser: &model.RangeReadCloser{
+			RangeReader: func(ctx context.Context, r http_range.Range) (io.ReadCloser, error) {
+				return d.rangeRead(ctx, c, r, args)
+			},
+		},
+	}, nil
+}
+
+func (d *Chunker) MakeDir(ctx context.Context, parentDir model.Obj, dirName string) error {
+	remoteDir, err := d.remoteActualPath(parentDir.GetPath())
+	if err != nil {
+		return err
+	}
+	return op.MakeDir(ctx, d.remoteStorage, stdpath.Join(remoteDir, dirName))
+}
+
+// Move moves the dir of parts as a whole for a chunked file
+func (d *Chunker) Move(ctx context.Context, srcObj, dstDir model.Obj) error {
+	src, _, err := d.remoteObjPath(ctx, srcObj)
+	if err != nil {
+		return err
+	}
+	dst, err := d.remoteActualPath(dstDir.GetPath())
+	if err != nil {
+		return err
+	}
+	d.infos.Delete(src)
+	return op.Move(ctx, d.remoteStorage, src, dst)
+}
+
+// Rename renames the dir of parts as a whole for a chunked file
+func (d *Chunker) Rename(ctx context.Context, srcObj model.Obj, newName string) error {
+	src, isChunked, err := d.remoteObjPath(ctx, srcObj)
+	if err != nil {
+		return err
+	}
+	if isChunked {
+		newName += d.ChunkSuffix
+	}
+	d.infos.Delete(src)
+	return op.Rename(ctx, d.remoteStorage, src, newName)
+}
+
+func (d *Chunker) Copy(ctx context.Context, srcObj, dstDir model.Obj) error {
+	src, _, err := d.remoteObjPath(ctx, srcObj)
+	if err != nil {
+		return err
+	}
+	dst, err := d.remoteActualPath(dstDir.GetPath())
+	if err != nil {
+		return err
+	}
+	return op.Copy(ctx, d.remoteStorage, src, dst)
+}
+
+// Remove removes the dir of parts as a whole for a chunked file
+func (d *Chunker) Remove(ctx context.Context, obj model.Obj) error {
+	path, _, err := d.remoteObjPath(ctx, obj)
+	if err != nil {
+		return err
+	}
+	d.infos.Delete(path)
+	// the recycle bin has been handled by op.Remove of the chunker
+	return op.RemovePermanently(ctx, d.remoteStorage, path)
+}
+
+// Put stores the file as is if it's not larger than the chunk size, otherwise
+// the file is split into the parts and the meta is written at last
+func (d *Chunker) Put(ctx context.Context, dstDir model.Obj, s model.FileStreamer, up driver.UpdateProgress) error {
+	remoteDir, err := d.remoteActualPath(dstDir.GetPath())
+	if err != nil {
+		return err
+	}
+	size, chunkSize := s.GetSize(), d.ChunkSize*utils.MB
+	if size <= chunkSize {
+		return op.Put(ctx, d.remoteStorage, remoteDir, s, up)
+	}
+	partsDir := stdpath.Join(remoteDir, s.GetName()+d.ChunkSuffix)
+	defer d.infos.Delete(partsDir)
+	chunks := int((size + chunkSize - 1) / chunkSize)
+	// whether the parts need to be stored is up to the remote storage, not the web upload of the file
+	needStore := !d.remoteStorage.Config().OnlyLocal
+	err = func() error {
+		for i := 0; i < chunks; i++ {
+			offset := int64(i) * chunkSize
+			partSize := min(chunkSize, size-offset)
+			part := &stream.FileStream{
+				Obj: &model.Object{
+					Name:     partName(i),
+					Size:     partSize,
+					Modified: s.ModTime(),
+				},
+				Reader:            io.LimitReader(s, partSize),
+				Mimetype:          "application/octet-stream",
+				WebPutAsTask:      needStore,
+				ForceStreamUpload: true,
+			}
+			err := op.Put(ctx, d.remoteStorage, partsDir, part, func(p float64) {
+				up((float64(offset) + p*float64(partSize)/100) * 100 / float64(size))
+			})
+			if err != nil {
+				return fmt.Errorf("failed to upload part %d: %w", i, err)
+			}
+		}
+		metaFile, err := d.metaFile(s, chunks)
+		if err != nil {
+			return err
+		}
+		return op.Put(ctx, d.remoteStorage, partsDir, metaFile, nil)
+	}()
+	if err != nil {
+		// the incomplete parts are hidden but still take the space
+		if e := op.RemovePermanently(context.WithoutCancel(ctx), d.remoteStorage, partsDir); e != nil {
+			log.Warnf("failed to remove the incomplete parts of %s: %+v", s.GetName(), e)
+		}
+	}
+	return err
+}
+
+// remoteObjPath returns the actual path of the remote obj storing the obj,
+// which is the dir of parts for a chunked file
+func (d *Chunker) remoteObjPath(ctx context.Context, obj model.Obj) (string, bool, error) {
+	remotePath, err := d.remoteActualPath(obj.GetPath())
+	if err != nil || obj.IsDir() {
+		return remotePath, false, err
+	}
+	if remoteObj, err := op.Get(ctx, d.remoteStorage, remotePath+d.ChunkSuffix); err == nil && remoteObj.IsDir() {
+		return remotePath + d.ChunkSuffix, true, nil
+	}
+	return remotePath, false, nil
+}
+
+var _ driver.Driver = (*Chunker)(nil)
+var _ driver.Mkdir = (*Chunker)(nil)
+var _ driver.Move = (*Chunker)(nil)
+var _ driver.Rename = (*Chunker)(nil)
+var _ driver.Copy = (*Chunker)(nil)
+var _ driver.Remove = (*Chunker)(nil)
+var _ driver.Put = (*Chunker)(nil)
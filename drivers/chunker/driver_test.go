@@ -0,0 +1,168 @@
+package chunker
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	_ "github.com/alist-org/alist/v3/drivers/local"
+	"github.com/alist-org/alist/v3/internal/conf"
+	"github.com/alist-org/alist/v3/internal/db"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/internal/op"
+	"github.com/alist-org/alist/v3/internal/stream"
+	"github.com/alist-org/alist/v3/pkg/http_range"
+	"github.com/alist-org/alist/v3/pkg/utils"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func init() {
+	dB, err := gorm.Open(sqlite.Open("file::memory:?cache=shared"), &gorm.Config{})
+	if err != nil {
+		panic("failed to connect database")
+	}
+	conf.Conf = conf.DefaultConfig()
+	db.Init(dB)
+}
+
+// setup mounts a Local storage of a temp dir at /remote and a chunker of 1 MB parts on it at /chunker,
+// a file of 2.5 MB is put to the chunker
+func setup(t *testing.T) (*Chunker, string, []byte) {
+	root := t.TempDir()
+	ctx := context.Background()
+	for _, s := range []model.Storage{
+		{Driver: "Local", MountPath: "/remote", Addition: `{"root_folder_path":"` + filepath.ToSlash(root) + `"}`},
+		{Driver: "Chunker", MountPath: "/chunker", Addition: `{"remote_path":"/remote","chunk_size":1,"chunk_suffix":".alist_chunks"}`},
+	} {
+		id, err := op.CreateStorage(ctx, s)
+		if err != nil {
+			t.Fatalf("failed to create storage: %+v", err)
+		}
+		t.Cleanup(func() {
+			_ = op.DeleteStorageById(ctx, id)
+		})
+	}
+	storage, err := op.GetStorageByMountPath("/chunker")
+	if err != nil {
+		t.Fatal(err)
+	}
+	data := make([]byte, 5*utils.MB/2)
+	for i := range data {
+		data[i] = byte(i % 251)
+	}
+	err = op.Put(ctx, storage, "/", &stream.FileStream{
+		Obj:    &model.Object{Name: "big.bin", Size: int64(len(data)), Modified: time.Now()},
+		Reader: bytes.NewReader(data),
+	}, nil)
+	if err != nil {
+		t.Fatalf("failed to put: %+v", err)
+	}
+	return storage.(*Chunker), root, data
+}
+
+func TestPut(t *testing.T) {
+	_, root, _ := setup(t)
+	tests := []struct {
+		name string
+		size int64
+	}{
+		{name: "000000", size: utils.MB},
+		{name: "000001", size: utils.MB},
+		{name: "000002", size: utils.MB / 2},
+		{name: metaName},
+	}
+	for _, tt := range tests {
+		info, err := os.Stat(filepath.Join(root, "big.bin.alist_chunks", tt.name))
+		if err != nil {
+			t.Errorf("part %s: %v", tt.name, err)
+		} else if tt.size != 0 && info.Size() != tt.size {
+			t.Errorf("part %s is of %d bytes, want %d", tt.name, info.Size(), tt.size)
+		}
+	}
+}
+
+func TestListCached(t *testing.T) {
+	d, root, data := setup(t)
+	ctx := context.Background()
+	dir := &model.Object{Path: "/", IsFolder: true}
+	// the part is changed without changing the dir, so the parts are relisted only if refreshed
+	part := filepath.Join(root, "big.bin.alist_chunks", "000002")
+	tests := []struct {
+		refresh bool
+		size    int64
+	}{
+		{refresh: false, size: int64(len(data))},
+		{refresh: false, size: int64(len(data))},
+		{refresh: true, size: 2 * utils.MB},
+	}
+	for i, tt := range tests {
+		objs, err := d.List(ctx, dir, model.ListArgs{Refresh: tt.refresh})
+		if err != nil {
+			t.Fatalf("%d: List() error = %v", i, err)
+		}
+		if len(objs) != 1 || objs[0].GetName() != "big.bin" || objs[0].GetSize() != tt.size {
+			t.Errorf("%d: List() = %+v, want big.bin of %d bytes", i, objs, tt.size)
+		}
+		if i == 0 {
+			if err = os.WriteFile(part, []byte{}, 0666); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+}
+
+func TestRangeRead(t *testing.T) {
+	d, _, data := setup(t)
+	ctx := context.Background()
+	size := int64(len(data))
+	tests := []http_range.Range{
+		{Start: 0, Length: -1},
+		{Start: 100, Length: 200},
+		{Start: utils.MB - 10, Length: 20},
+		{Start: utils.MB / 2, Length: 2 * utils.MB},
+		{Start: size - 10, Length: 100},
+	}
+	link, err := d.Link(ctx, &model.Object{Path: "/big.bin"}, model.LinkArgs{})
+	if err != nil {
+		t.Fatalf("Link() error = %v", err)
+	}
+	for _, r := range tests {
+		rc, err := link.RangeReadCloser.RangeRead(ctx, r)
+		if err != nil {
+			t.Fatalf("RangeRead(%+v) error = %v", r, err)
+		}
+		got, err := io.ReadAll(rc)
+		_ = rc.Close()
+		end := size
+		if r.Length >= 0 {
+			end = min(size, r.Start+r.Length)
+		}
+		if err != nil || !bytes.Equal(got, data[r.Start:end]) {
+			t.Errorf("RangeRead(%+v) got %d bytes, %v, want %d bytes", r, len(got), err, end-r.Start)
+		}
+	}
+}
+
+func TestRemove(t *testing.T) {
+	d, root, _ := setup(t)
+	// the parts removed aren't moved to the recycle bin of remote storage
+	if err := op.SaveSettingItem(&model.SettingItem{Key: conf.RecycleBinEnabled, Value: "true", Type: conf.TypeBool}); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		_ = op.SaveSettingItem(&model.SettingItem{Key: conf.RecycleBinEnabled, Value: "false", Type: conf.TypeBool})
+	})
+	if err := d.Remove(context.Background(), &model.Object{Path: "/big.bin"}); err != nil {
+		t.Fatalf("Remove() error = %v", err)
+	}
+	for _, name := range []string{"big.bin.alist_chunks", ".alist_recycle_bin"} {
+		if _, err := os.Stat(filepath.Join(root, name)); !os.IsNotExist(err) {
+			t.Errorf("%s exists after removed: %v", name, err)
+		}
+	}
+}
@@ -0,0 +1,29 @@
+package chunker
+
+import (
+	"github.com/alist-org/alist/v3/internal/driver"
+	"github.com/alist-org/alist/v3/internal/op"
+)
+
+type Addition struct {
+	RemotePath  string `json:"remote_path" required:"true" help:"This is where the parts store"`
+	ChunkSize   int64  `json:"chunk_size" type:"number" required:"true" default:"100" help:"the max size of each part in MB, the files not larger than it are stored as is"`
+	ChunkSuffix string `json:"chunk_suffix" required:"true" default:".alist_chunks" help:"for advanced user only! the parts of a file are stored in the dir named the file with this suffix"`
+}
+
+var config = driver.Config{
+	Name:        "Chunker",
+	LocalSort:   true,
+	OnlyProxy:   true,
+	NoCache:     true,
+	DefaultRoot: "/",
+	// the old file is renamed while uploading and removed after the upload succeeds,
+	// so the parts of the old one are never mixed with the new ones
+	NoOverwriteUpload: true,
+}
+
+func init() {
+	op.RegisterDriver(func() driver.Driver {
+		return &Chunker{}
+	})
+}
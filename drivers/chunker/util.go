@@ -0,0 +1,245 @@
+package chunker
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	stdpath "path"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/alist-org/alist/v3/internal/errs"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/internal/op"
+	"github.com/alist-org/alist/v3/internal/stream"
+	"github.com/alist-org/alist/v3/pkg/http_range"
+	"github.com/alist-org/alist/v3/pkg/utils"
+)
+
+// metaName is the name of the meta object in the dir of parts, which is written
+// after all parts are uploaded, so the dir without it is an incomplete upload
+const metaName = "meta.json"
+
+type meta struct {
+	Version   int   `json:"version"`
+	Size      int64 `json:"size"`
+	ChunkSize int64 `json:"chunk_size"`
+	Chunks    int   `json:"chunks"`
+}
+
+func partName(i int) string {
+	return fmt.Sprintf("%06d", i)
+}
+
+// chunked is a file stored as the parts in the remote dir at path
+type chunked struct {
+	path  string
+	meta  model.Obj
+	parts []model.Obj
+	// the offsets of parts in the file
+	offsets []int64
+	size    int64
+}
+
+// chunkedInfo is the size and modified time of a complete chunked file, they don't change since
+// the parts are written once, a new version of the file is uploaded to a new dir of parts
+type chunkedInfo struct {
+	// the modified time of the dir of parts, the info is outdated if it's changed
+	dirModified time.Time
+	size        int64
+	modified    time.Time
+}
+
+// getChunkedInfo returns the info of the chunked file stored in the remote dir at path, the parts
+// are only listed if it's not cached, so List doesn't list the dir of each chunked file every time
+func (d *Chunker) getChunkedInfo(ctx context.Context, path string, dir model.Obj, refresh bool) (chunkedInfo, error) {
+	if info, ok := d.infos.Load(path); ok && !refresh && info.dirModified.Equal(dir.ModTime()) {
+		return info, nil
+	}
+	c, err := d.getChunked(ctx, path, refresh)
+	if err != nil {
+		d.infos.Delete(path)
+		return chunkedInfo{}, err
+	}
+	info := chunkedInfo{dirModified: dir.ModTime(), size: c.size, modified: c.meta.ModTime()}
+	d.infos.Store(path, info)
+	return info, nil
+}
+
+func (d *Chunker) remoteActualPath(path string) (string, error) {
+	_, actualPath, err := op.GetStorageAndActualPath(stdpath.Join(d.RemotePath, path))
+	return actualPath, err
+}
+
+// getChunked lists the parts in the remote dir at path, it returns errs.ObjectNotFound if the meta is missing
+func (d *Chunker) getChunked(ctx context.Context, path string, refresh bool) (*chunked, error) {
+	objs, err := op.List(ctx, d.remoteStorage, path, model.ListArgs{Refresh: refresh})
+	if err != nil {
+		return nil, err
+	}
+	c := &chunked{path: path}
+	for _, obj := range objs {
+		if obj.IsDir() {
+			continue
+		}
+		if obj.GetName() == metaName {
+			c.meta = obj
+		} else if _, err := strconv.Atoi(obj.GetName()); err == nil {
+			c.parts = append(c.parts, obj)
+		}
+	}
+	if c.meta == nil {
+		return nil, errs.ObjectNotFound
+	}
+	sort.Slice(c.parts, func(i, j int) bool {
+		return c.parts[i].GetName() < c.parts[j].GetName()
+	})
+	for _, part := range c.parts {
+		c.offsets = append(c.offsets, c.size)
+		c.size += part.GetSize()
+	}
+	return c, nil
+}
+
+// check reads the meta and reports an error if the parts don't match it
+func (d *Chunker) check(ctx context.Context, c *chunked) error {
+	link, _, err := op.Link(ctx, d.remoteStorage, stdpath.Join(c.path, metaName), model.LinkArgs{})
+	if err != nil {
+		return err
+	}
+	ss, err := stream.NewSeekableStream(stream.FileStream{Obj: c.meta, Ctx: ctx}, link)
+	if err != nil {
+		return err
+	}
+	defer ss.Close()
+	var m meta
+	if err = utils.Json.NewDecoder(io.LimitReader(ss, c.meta.GetSize())).Decode(&m); err != nil {
+		return fmt.Errorf("failed to read the meta of %s: %w", c.path, err)
+	}
+	if m.Size != c.size || m.Chunks != len(c.parts) {
+		return fmt.Errorf("the parts of %s are broken, expect %d parts of %d bytes, got %d parts of %d bytes",
+			c.path, m.Chunks, m.Size, len(c.parts), c.size)
+	}
+	return nil
+}
+
+func (d *Chunker) metaFile(s model.FileStreamer, chunks int) (model.FileStreamer, error) {
+	data, err := utils.Json.Marshal(meta{
+		Version:   1,
+		Size:      s.GetSize(),
+		ChunkSize: d.ChunkSize * utils.MB,
+		Chunks:    chunks,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &stream.FileStream{
+		Obj: &model.Object{
+			Name:     metaName,
+			Size:     int64(len(data)),
+			Modified: s.ModTime(),
+		},
+		Reader:            bytes.NewReader(data),
+		Mimetype:          "application/json",
+		ForceStreamUpload: true,
+	}, nil
+}
+
+// rangeRead reads the range of file from the parts it covers, each part is opened when it's read
+func (d *Chunker) rangeRead(ctx context.Context, c *chunked, r http_range.Range, args model.LinkArgs) (io.ReadCloser, error) {
+	if r.Length < 0 || r.Start+r.Length > c.size {
+		r.Length = c.size - r.Start
+	}
+	end := r.Start + r.Length
+	var readers []io.Reader
+	var closers utils.Closers
+	for i, part := range c.parts {
+		partStart, partEnd := c.offsets[i], c.offsets[i]+part.GetSize()
+		if partEnd <= r.Start || partStart >= end {
+			continue
+		}
+		pr := &partReader{
+			open: func() (io.ReadCloser, error) {
+				start := max(r.Start, partStart) - partStart
+				return d.openPart(ctx, c, part, http_range.Range{
+					Start:  start,
+					Length: min(end, partEnd) - partStart - start,
+				}, args)
+			},
+		}
+		readers = append(readers, pr)
+		closers.Add(pr)
+	}
+	return utils.NewReadCloser(io.MultiReader(readers...), closers.Close), nil
+}
+
+func (d *Chunker) openPart(ctx context.Context, c *chunked, part model.Obj, r http_range.Range, args model.LinkArgs) (io.ReadCloser, error) {
+	link, _, err := op.Link(ctx, d.remoteStorage, stdpath.Join(c.path, part.GetName()), args)
+	if err != nil {
+		return nil, err
+	}
+	if link.MFile != nil {
+		return utils.NewReadCloser(io.NewSectionReader(link.MFile, r.Start, r.Length), link.MFile.Close), nil
+	}
+	rrc := link.RangeReadCloser
+	if rrc == nil {
+		if rrc, err = stream.GetRangeReadCloserFromLink(part.GetSize(), link); err != nil {
+			return nil, err
+		}
+	}
+	rc, err := rrc.RangeRead(ctx, r)
+	if err != nil {
+		_ = rrc.Close()
+		return nil, err
+	}
+	return utils.NewReadCloser(rc, rrc.Close), nil
+}
+
+// partReader opens the part at the first read and closes it at the end
+type partReader struct {
+	open func() (io.ReadCloser, error)
+	rc   io.ReadCloser
+	done bool
+}
+
+func (p *partReader) Read(b []byte) (int, error) {
+	if p.done {
+		return 0, io.EOF
+	}
+	if p.rc == nil {
+		rc, err := p.open()
+		if err != nil {
+			return 0, err
+		}
+		p.rc = rc
+	}
+	n, err := p.rc.Read(b)
+	if err == io.EOF {
+		p.done = true
+		err = p.Close()
+		if err == nil {
+			err = io.EOF
+		}
+	}
+	return n, err
+}
+
+func (p *partReader) Close() error {
+	if p.rc == nil {
+		return nil
+	}
+	rc := p.rc
+	p.rc = nil
+	return rc.Close()
+}
+
+func toObj(obj model.Obj) model.Obj {
+	return &model.Object{
+		Name:     obj.GetName(),
+		Size:     obj.GetSize(),
+		Modified: obj.ModTime(),
+		IsFolder: obj.IsDir(),
+	}
+}
@@ -364,7 +364,7 @@ func (d *Crypt) Remove(ctx context.Context, obj model.Obj) error {
 	if err != nil {
 		return fmt.Errorf("failed to convert path to remote path: %w", err)
 	}
-	return op.Remove(ctx, d.remoteStorage, remoteActualPath)
+	return op.RemovePermanently(ctx, d.remoteStorage, remoteActualPath)
 }
 
 func (d *Crypt) Put(ctx context.Context, dstDir model.Obj, streamer model.FileStreamer, up driver.UpdateProgress) error {
@@ -166,4 +166,22 @@ func (d *GoogleDrive) Put(ctx context.Context, dstDir model.Obj, stream model.Fi
 	return err
 }
 
+func (d *GoogleDrive) GetDetails(ctx context.Context) (*model.StorageDetails, error) {
+	var about About
+	_, err := d.request("https://www.googleapis.com/drive/v3/about", http.MethodGet, func(req *resty.Request) {
+		req.SetQueryParam("fields", "storageQuota")
+	}, &about)
+	if err != nil {
+		return nil, err
+	}
+	// the usage of unlimited storage can't be reported as the details
+	if about.StorageQuota.Limit == "" {
+		return nil, errs.NotSupport
+	}
+	limit, _ := strconv.ParseInt(about.StorageQuota.Limit, 10, 64)
+	usage, _ := strconv.ParseInt(about.StorageQuota.Usage, 10, 64)
+	return model.NewStorageDetails(limit, usage), nil
+}
+
 var _ driver.Driver = (*GoogleDrive)(nil)
+var _ driver.WithDetails = (*GoogleDrive)(nil)
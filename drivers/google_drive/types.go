@@ -78,3 +78,11 @@ type Error struct {
 		Message string `json:"message"`
 	} `json:"error"`
 }
+
+type About struct {
+	StorageQuota struct {
+		// absent if the storage is unlimited
+		Limit string `json:"limit"`
+		Usage string `json:"usage"`
+	} `json:"storageQuota"`
+}
@@ -5,6 +5,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"io"
 	"io/fs"
 	"net/http"
 	"os"
@@ -19,9 +20,11 @@ import (
 	"github.com/alist-org/alist/v3/internal/errs"
 	"github.com/alist-org/alist/v3/internal/model"
 	"github.com/alist-org/alist/v3/internal/sign"
+	"github.com/alist-org/alist/v3/pkg/http_range"
 	"github.com/alist-org/alist/v3/pkg/utils"
 	"github.com/alist-org/alist/v3/server/common"
 	"github.com/alist-org/times"
+	"github.com/shirou/gopsutil/v3/disk"
 	log "github.com/sirupsen/logrus"
 	_ "golang.org/x/image/webp"
 )
@@ -320,4 +323,89 @@ func (d *Local) Put(ctx context.Context, dstDir model.Obj, stream model.FileStre
 	return nil
 }
 
+// the bytes written between the checkpoints of resumable upload
+const resumeChunkSize = 16 * utils.MB
+
+// PutResumable writes the stream to a partial file which is renamed after completed,
+// the partial file is appended from its size if the upload is resumed
+func (d *Local) PutResumable(ctx context.Context, dstDir model.Obj, stream model.FileStreamer, cp *driver.Checkpoint, up driver.UpdateProgress) error {
+	fullPath := filepath.Join(dstDir.GetPath(), stream.GetName())
+	partialPath := fullPath + ".alist_partial"
+	var offset int64
+	if cp.Offset > 0 {
+		if fi, err := os.Stat(partialPath); err == nil && fi.Size() <= stream.GetSize() {
+			offset = fi.Size()
+		}
+	}
+	out, err := os.OpenFile(partialPath, os.O_WRONLY|os.O_CREATE, 0666)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = out.Close()
+		if errors.Is(err, context.Canceled) {
+			_ = os.Remove(partialPath)
+			cp.Reset()
+		}
+	}()
+	if err = out.Truncate(offset); err != nil {
+		return err
+	}
+	if _, err = out.Seek(offset, io.SeekStart); err != nil {
+		return err
+	}
+	var in io.Reader = stream
+	if offset > 0 {
+		log.Debugf("[local] resume upload of %s from %d", fullPath, offset)
+		in, err = stream.RangeRead(http_range.Range{Start: offset, Length: stream.GetSize() - offset})
+		if err != nil {
+			return err
+		}
+	}
+	for offset < stream.GetSize() {
+		if utils.IsCanceled(ctx) {
+			err = ctx.Err()
+			return err
+		}
+		var n int64
+		n, err = utils.CopyWithBufferN(out, in, min(stream.GetSize()-offset, resumeChunkSize))
+		offset += n
+		if err != nil {
+			return err
+		}
+		if err = out.Sync(); err != nil {
+			return err
+		}
+		cp.Save(offset, "")
+		up(float64(offset) * 100 / float64(stream.GetSize()))
+	}
+	if err = out.Close(); err != nil {
+		return err
+	}
+	if err = os.Rename(partialPath, fullPath); err != nil {
+		return err
+	}
+	err = os.Chtimes(fullPath, stream.ModTime(), stream.ModTime())
+	if err != nil {
+		log.Errorf("[local] failed to change time of %s: %s", fullPath, err)
+	}
+	return nil
+}
+
+func (d *Local) GetDetails(ctx context.Context) (*model.StorageDetails, error) {
+	usage, err := disk.UsageWithContext(ctx, d.GetRootPath())
+	if err != nil {
+		return nil, err
+	}
+	// the free space is the one available to unprivileged users, so it may
+	// be less than the total minus the used
+	return &model.StorageDetails{
+		TotalSpace: int64(usage.Total),
+		UsedSpace:  int64(usage.Used),
+		FreeSpace:  int64(usage.Free),
+	}, nil
+}
+
 var _ driver.Driver = (*Local)(nil)
+var _ driver.PutResumable = (*Local)(nil)
+var _ driver.WithDetails = (*Local)(nil)
@@ -207,4 +207,17 @@ func (d *Onedrive) Put(ctx context.Context, dstDir model.Obj, stream model.FileS
 	return err
 }
 
+func (d *Onedrive) GetDetails(ctx context.Context) (*model.StorageDetails, error) {
+	drive, err := d.getDrive()
+	if err != nil {
+		return nil, err
+	}
+	return &model.StorageDetails{
+		TotalSpace: drive.Quota.Total,
+		UsedSpace:  drive.Quota.Used,
+		FreeSpace:  drive.Quota.Remaining,
+	}, nil
+}
+
 var _ driver.Driver = (*Onedrive)(nil)
+var _ driver.WithDetails = (*Onedrive)(nil)
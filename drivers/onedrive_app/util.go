@@ -9,6 +9,7 @@ import (
 	"net/http"
 	stdpath "path"
 	"strconv"
+	"strings"
 
 	"github.com/alist-org/alist/v3/drivers/base"
 	"github.com/alist-org/alist/v3/internal/driver"
@@ -131,6 +132,14 @@ func (d *OnedriveAPP) getFiles(path string) ([]File, error) {
 	return res, nil
 }
 
+func (d *OnedriveAPP) getDrive() (*Drive, error) {
+	// the meta url of root is the one of drive with "/root" appended
+	url := strings.TrimSuffix(d.GetMetaUrl(false, "/"), "/root")
+	var drive Drive
+	_, err := d.Request(url, http.MethodGet, nil, &drive)
+	return &drive, err
+}
+
 func (d *OnedriveAPP) GetFile(path string) (*File, error) {
 	var file File
 	u := d.GetMetaUrl(false, path)
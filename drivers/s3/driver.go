@@ -3,6 +3,7 @@ package s3
 import (
 	"bytes"
 	"context"
+	"errors"
 	"fmt"
 	"github.com/alist-org/alist/v3/server/common"
 	"io"
@@ -16,6 +17,9 @@ import (
 
 	"github.com/alist-org/alist/v3/internal/driver"
 	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/pkg/http_range"
+	"github.com/alist-org/alist/v3/pkg/utils"
+	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/aws/session"
 	"github.com/aws/aws-sdk-go/service/s3"
 	"github.com/aws/aws-sdk-go/service/s3/s3manager"
@@ -177,4 +181,91 @@ func (d *S3) Put(ctx context.Context, dstDir model.Obj, stream model.FileStreame
 	return err
 }
 
+// PutResumable uploads the stream by multipart upload, the uploaded parts are kept
+// in the checkpoint to continue the same upload if resumed
+func (d *S3) PutResumable(ctx context.Context, dstDir model.Obj, stream model.FileStreamer, cp *driver.Checkpoint, up driver.UpdateProgress) (err error) {
+	size := stream.GetSize()
+	partSize := max(s3manager.DefaultUploadPartSize, (size+s3manager.MaxUploadParts-1)/s3manager.MaxUploadParts)
+	if size <= partSize {
+		return d.Put(ctx, dstDir, stream, up)
+	}
+	key := getKey(stdpath.Join(dstDir.GetPath(), stream.GetName()), false)
+	var state multipartState
+	if cp.Data != "" {
+		if err = utils.Json.UnmarshalFromString(cp.Data, &state); err != nil || !d.uploadExists(ctx, key, state.UploadId) {
+			log.Warnf("[s3] can't resume upload of %s, restart it", key)
+			state = multipartState{}
+		}
+	}
+	if state.UploadId == "" {
+		contentType := stream.GetMimetype()
+		res, err := d.client.CreateMultipartUploadWithContext(ctx, &s3.CreateMultipartUploadInput{
+			Bucket:      &d.Bucket,
+			Key:         &key,
+			ContentType: &contentType,
+		})
+		if err != nil {
+			return err
+		}
+		state = multipartState{UploadId: *res.UploadId, PartSize: partSize}
+		cp.Save(0, mustMarshal(state))
+	}
+	defer func() {
+		if errors.Is(err, context.Canceled) {
+			_, _ = d.client.AbortMultipartUpload(&s3.AbortMultipartUploadInput{
+				Bucket:   &d.Bucket,
+				Key:      &key,
+				UploadId: &state.UploadId,
+			})
+			cp.Reset()
+		}
+	}()
+	offset := int64(len(state.Parts)) * state.PartSize
+	var in io.Reader = stream
+	if offset > 0 {
+		log.Debugf("[s3] resume upload of %s from %d", key, offset)
+		in, err = stream.RangeRead(http_range.Range{Start: offset, Length: size - offset})
+		if err != nil {
+			return err
+		}
+	}
+	buf := make([]byte, state.PartSize)
+	for offset < size {
+		if utils.IsCanceled(ctx) {
+			return ctx.Err()
+		}
+		n := min(size-offset, state.PartSize)
+		if _, err = io.ReadFull(in, buf[:n]); err != nil {
+			return err
+		}
+		partNumber := int64(len(state.Parts) + 1)
+		res, err := d.client.UploadPartWithContext(ctx, &s3.UploadPartInput{
+			Bucket:     &d.Bucket,
+			Key:        &key,
+			UploadId:   &state.UploadId,
+			PartNumber: &partNumber,
+			Body:       bytes.NewReader(buf[:n]),
+		})
+		if err != nil {
+			return err
+		}
+		state.Parts = append(state.Parts, uploadedPart{PartNumber: partNumber, ETag: *res.ETag})
+		offset += n
+		cp.Save(offset, mustMarshal(state))
+		up(float64(offset) * 100 / float64(size))
+	}
+	parts := make([]*s3.CompletedPart, 0, len(state.Parts))
+	for _, p := range state.Parts {
+		parts = append(parts, &s3.CompletedPart{PartNumber: aws.Int64(p.PartNumber), ETag: aws.String(p.ETag)})
+	}
+	_, err = d.client.CompleteMultipartUploadWithContext(ctx, &s3.CompleteMultipartUploadInput{
+		Bucket:          &d.Bucket,
+		Key:             &key,
+		UploadId:        &state.UploadId,
+		MultipartUpload: &s3.CompletedMultipartUpload{Parts: parts},
+	})
+	return err
+}
+
 var _ driver.Driver = (*S3)(nil)
+var _ driver.PutResumable = (*S3)(nil)
@@ -1 +1,13 @@
 package s3
+
+// multipartState is the state of a resumable multipart upload
+type multipartState struct {
+	UploadId string         `json:"upload_id"`
+	PartSize int64          `json:"part_size"`
+	Parts    []uploadedPart `json:"parts"`
+}
+
+type uploadedPart struct {
+	PartNumber int64  `json:"part_number"`
+	ETag       string `json:"etag"`
+}
@@ -255,3 +255,22 @@ func (d *S3) removeFile(src string) error {
 	_, err := d.client.DeleteObject(input)
 	return err
 }
+
+// uploadExists reports whether the multipart upload is not completed or aborted
+func (d *S3) uploadExists(ctx context.Context, key, uploadId string) bool {
+	if uploadId == "" {
+		return false
+	}
+	_, err := d.client.ListPartsWithContext(ctx, &s3.ListPartsInput{
+		Bucket:   &d.Bucket,
+		Key:      &key,
+		UploadId: &uploadId,
+		MaxParts: aws.Int64(1),
+	})
+	return err == nil
+}
+
+func mustMarshal(state multipartState) string {
+	data, _ := utils.Json.MarshalToString(state)
+	return data
+}
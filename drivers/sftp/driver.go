@@ -115,4 +115,21 @@ func (d *SFTP) Put(ctx context.Context, dstDir model.Obj, stream model.FileStrea
 	return err
 }
 
+func (d *SFTP) GetDetails(ctx context.Context) (*model.StorageDetails, error) {
+	if err := d.clientReconnectOnConnectionError(); err != nil {
+		return nil, err
+	}
+	// the statvfs@openssh.com extension may not be supported by the server
+	stat, err := d.client.StatVFS(d.GetRootPath())
+	if err != nil {
+		return nil, err
+	}
+	return &model.StorageDetails{
+		TotalSpace: int64(stat.TotalSpace()),
+		UsedSpace:  int64(stat.Frsize * (stat.Blocks - stat.Bfree)),
+		FreeSpace:  int64(stat.Frsize * stat.Bavail),
+	}, nil
+}
+
 var _ driver.Driver = (*SFTP)(nil)
+var _ driver.WithDetails = (*SFTP)(nil)
@@ -0,0 +1,261 @@
+package union
+
+import (
+	"context"
+	"errors"
+	"io"
+	stdpath "path"
+	"strings"
+	"sync/atomic"
+
+	"github.com/alist-org/alist/v3/internal/driver"
+	"github.com/alist-org/alist/v3/internal/errs"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/internal/op"
+	"github.com/alist-org/alist/v3/internal/stream"
+	"github.com/alist-org/alist/v3/pkg/utils"
+)
+
+type Union struct {
+	model.Storage
+	Addition
+	roots []string
+	// the count of files written by round robin
+	next atomic.Uint64
+}
+
+func (d *Union) Config() driver.Config {
+	return config
+}
+
+func (d *Union) GetAddition() driver.Additional {
+	return &d.Addition
+}
+
+func (d *Union) Init(ctx context.Context) error {
+	d.roots = nil
+	for _, path := range strings.Split(d.Paths, "\n") {
+		path = strings.TrimSpace(path)
+		if path == "" {
+			continue
+		}
+		d.roots = append(d.roots, utils.FixAndCleanPath(path))
+	}
+	if len(d.roots) == 0 {
+		return errors.New("paths is required")
+	}
+	for _, root := range d.roots {
+		if utils.IsSubPath(root, d.MountPath) || utils.IsSubPath(d.MountPath, root) {
+			return errors.New("the union can't contain itself")
+		}
+	}
+	return nil
+}
+
+func (d *Union) Drop(ctx context.Context) error {
+	d.roots = nil
+	return nil
+}
+
+func (d *Union) Get(ctx context.Context, path string) (model.Obj, error) {
+	if utils.PathEqual(path, "/") {
+		return &model.Object{
+			Name:     "Root",
+			IsFolder: true,
+			Path:     "/",
+		}, nil
+	}
+	_, objs := d.find(ctx, path)
+	if len(objs) == 0 {
+		return nil, errs.ObjectNotFound
+	}
+	obj := objs[d.pick(objs)]
+	return &model.Object{
+		Path:     path,
+		Name:     obj.GetName(),
+		Size:     obj.GetSize(),
+		Modified: obj.ModTime(),
+		IsFolder: obj.IsDir(),
+	}, nil
+}
+
+// List merges the objs in the dir of all storages, the same-name files are picked by the read policy
+func (d *Union) List(ctx context.Context, dir model.Obj, args model.ListArgs) ([]model.Obj, error) {
+	var objs []model.Obj
+	indexes := make(map[string]int)
+	var listed bool
+	var err error = errs.ObjectNotFound
+	for _, m := range d.members(dir.GetPath()) {
+		tmp, e := op.List(ctx, m.storage, m.actualPath, model.ListArgs{Refresh: args.Refresh})
+		if e != nil {
+			err = e
+			continue
+		}
+		listed = true
+		for _, obj := range tmp {
+			i, ok := indexes[obj.GetName()]
+			if !ok {
+				indexes[obj.GetName()] = len(objs)
+				objs = append(objs, obj)
+			} else if !obj.IsDir() && !objs[i].IsDir() && d.prefer(obj, objs[i]) {
+				objs[i] = obj
+			}
+		}
+	}
+	if !listed {
+		return nil, err
+	}
+	return utils.MustSliceConvert(objs, toObj), nil
+}
+
+func (d *Union) Link(ctx context.Context, file model.Obj, args model.LinkArgs) (*model.Link, error) {
+	ms, objs := d.find(ctx, file.GetPath())
+	if len(objs) == 0 {
+		return nil, errs.ObjectNotFound
+	}
+	return d.link(ctx, ms[d.pick(objs)], args)
+}
+
+// MakeDir makes the dir in all storages where the parent dir exists
+func (d *Union) MakeDir(ctx context.Context, parentDir model.Obj, dirName string) error {
+	ms, _ := d.find(ctx, parentDir.GetPath())
+	if len(ms) == 0 {
+		return errs.ObjectNotFound
+	}
+	for _, m := range ms {
+		if err := op.MakeDir(ctx, m.storage, stdpath.Join(m.actualPath, dirName)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Move moves the obj in each storage where it exists
+func (d *Union) Move(ctx context.Context, srcObj, dstDir model.Obj) error {
+	return d.each(ctx, srcObj, func(m member) error {
+		dst, err := d.sibling(m, dstDir.GetPath())
+		if err != nil {
+			return err
+		}
+		if err := op.MakeDir(ctx, m.storage, dst); err != nil {
+			return err
+		}
+		return op.Move(ctx, m.storage, m.actualPath, dst)
+	})
+}
+
+func (d *Union) Rename(ctx context.Context, srcObj model.Obj, newName string) error {
+	return d.each(ctx, srcObj, func(m member) error {
+		return op.Rename(ctx, m.storage, m.actualPath, newName)
+	})
+}
+
+// Copy copies the obj in each storage where it exists
+func (d *Union) Copy(ctx context.Context, srcObj, dstDir model.Obj) error {
+	return d.each(ctx, srcObj, func(m member) error {
+		dst, err := d.sibling(m, dstDir.GetPath())
+		if err != nil {
+			return err
+		}
+		if err := op.MakeDir(ctx, m.storage, dst); err != nil {
+			return err
+		}
+		return op.Copy(ctx, m.storage, m.actualPath, dst)
+	})
+}
+
+// Remove removes the obj from all storages where it exists or the first one by the delete policy
+func (d *Union) Remove(ctx context.Context, obj model.Obj) error {
+	ms, _ := d.find(ctx, obj.GetPath())
+	if len(ms) == 0 {
+		return errs.ObjectNotFound
+	}
+	if d.DeletePolicy == "first" {
+		ms = ms[:1]
+	}
+	for _, m := range ms {
+		// the recycle bin has been handled by op.Remove of the union
+		if err := op.RemovePermanently(ctx, m.storage, m.actualPath); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Put uploads the file to the storages chosen by the write policy, the
+// existing file is overwritten in the storages where it is instead
+func (d *Union) Put(ctx context.Context, dstDir model.Obj, s model.FileStreamer, up driver.UpdateProgress) error {
+	ms, err := d.putMembers(ctx, dstDir.GetPath(), s)
+	if err != nil {
+		return err
+	}
+	if len(ms) == 1 {
+		return d.put(ctx, ms[0], s, up)
+	}
+	// the file is read once for each storage
+	tmpFile, err := s.CacheFullInTempFile()
+	if err != nil {
+		return err
+	}
+	for i, m := range ms {
+		file := &stream.FileStream{
+			Obj:      s,
+			Reader:   io.NewSectionReader(tmpFile, 0, s.GetSize()),
+			Mimetype: s.GetMimetype(),
+		}
+		err = d.put(ctx, m, file, func(p float64) {
+			up((float64(i)*100 + p) / float64(len(ms)))
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// GetDetails sums up the space of all storages
+func (d *Union) GetDetails(ctx context.Context) (*model.StorageDetails, error) {
+	var total, used int64
+	var found bool
+	seen := make(map[driver.Driver]bool)
+	for _, m := range d.members("/") {
+		if seen[m.storage] {
+			continue
+		}
+		seen[m.storage] = true
+		details, err := op.GetStorageDetails(ctx, m.storage)
+		if err != nil {
+			continue
+		}
+		total += details.TotalSpace
+		used += details.UsedSpace
+		found = true
+	}
+	if !found {
+		return nil, errs.NotImplement
+	}
+	return model.NewStorageDetails(total, used), nil
+}
+
+// each calls fn with each member where the obj exists
+func (d *Union) each(ctx context.Context, obj model.Obj, fn func(m member) error) error {
+	ms, _ := d.find(ctx, obj.GetPath())
+	if len(ms) == 0 {
+		return errs.ObjectNotFound
+	}
+	for _, m := range ms {
+		if err := fn(m); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+var _ driver.Driver = (*Union)(nil)
+var _ driver.Mkdir = (*Union)(nil)
+var _ driver.Move = (*Union)(nil)
+var _ driver.Rename = (*Union)(nil)
+var _ driver.Copy = (*Union)(nil)
+var _ driver.Remove = (*Union)(nil)
+var _ driver.Put = (*Union)(nil)
+var _ driver.WithDetails = (*Union)(nil)
@@ -0,0 +1,172 @@
+package union
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	_ "github.com/alist-org/alist/v3/drivers/local"
+	"github.com/alist-org/alist/v3/internal/conf"
+	"github.com/alist-org/alist/v3/internal/db"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/internal/op"
+	"github.com/alist-org/alist/v3/internal/stream"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func init() {
+	dB, err := gorm.Open(sqlite.Open("file::memory:?cache=shared"), &gorm.Config{})
+	if err != nil {
+		panic("failed to connect database")
+	}
+	conf.Conf = conf.DefaultConfig()
+	db.Init(dB)
+}
+
+// mount mounts a Local storage of a temp dir with the files at mountPath
+func mount(t *testing.T, mountPath string, files map[string]string) string {
+	root := t.TempDir()
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(root, name), []byte(content), 0666); err != nil {
+			t.Fatal(err)
+		}
+	}
+	id, err := op.CreateStorage(context.Background(), model.Storage{
+		Driver:    "Local",
+		MountPath: mountPath,
+		Addition:  `{"root_folder_path":"` + filepath.ToSlash(root) + `"}`,
+	})
+	if err != nil {
+		t.Fatalf("failed to create storage: %+v", err)
+	}
+	t.Cleanup(func() {
+		_ = op.DeleteStorageById(context.Background(), id)
+	})
+	return root
+}
+
+func TestPick(t *testing.T) {
+	now := time.Now()
+	objs := []model.Obj{
+		&model.Object{Size: 1, Modified: now},
+		&model.Object{Size: 3, Modified: now.Add(-time.Hour)},
+		&model.Object{Size: 2, Modified: now.Add(time.Hour)},
+	}
+	tests := []struct {
+		policy string
+		want   int
+	}{
+		{policy: "first_found", want: 0},
+		{policy: "largest", want: 1},
+		{policy: "newest", want: 2},
+	}
+	for _, tt := range tests {
+		d := &Union{Addition: Addition{ReadPolicy: tt.policy}}
+		if got := d.pick(objs); got != tt.want {
+			t.Errorf("pick() by %s = %d, want %d", tt.policy, got, tt.want)
+		}
+	}
+}
+
+func TestRoundRobin(t *testing.T) {
+	mount(t, "/a", nil)
+	mount(t, "/b", nil)
+	d := &Union{Addition: Addition{WritePolicy: "round_robin"}, roots: []string{"/a", "/b"}}
+	for i, want := range []string{"/a", "/b", "/a"} {
+		ms, err := d.writeMembers(context.Background(), "/", 1)
+		if err != nil || len(ms) != 1 || ms[0].root != want {
+			t.Errorf("%d: writeMembers() = %+v, %v, want %s", i, ms, err, want)
+		}
+	}
+}
+
+func TestRemove(t *testing.T) {
+	// the files removed from the storages aren't moved to their recycle bins
+	if err := op.SaveSettingItem(&model.SettingItem{Key: conf.RecycleBinEnabled, Value: "true", Type: conf.TypeBool}); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		_ = op.SaveSettingItem(&model.SettingItem{Key: conf.RecycleBinEnabled, Value: "false", Type: conf.TypeBool})
+	})
+	tests := []struct {
+		policy string
+		// whether the file is left in each storage
+		left []bool
+	}{
+		{policy: "all", left: []bool{false, false}},
+		{policy: "first", left: []bool{false, true}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.policy, func(t *testing.T) {
+			roots := []string{
+				mount(t, "/a", map[string]string{"f.txt": "a"}),
+				mount(t, "/b", map[string]string{"f.txt": "b"}),
+			}
+			d := &Union{Addition: Addition{DeletePolicy: tt.policy}, roots: []string{"/a", "/b"}}
+			if err := d.Remove(context.Background(), &model.Object{Path: "/f.txt"}); err != nil {
+				t.Fatalf("Remove() error = %v", err)
+			}
+			for i, root := range roots {
+				_, err := os.Stat(filepath.Join(root, "f.txt"))
+				if left := err == nil; left != tt.left[i] {
+					t.Errorf("the file in %s is left: %v, want %v", d.roots[i], left, tt.left[i])
+				}
+				if _, err = os.Stat(filepath.Join(root, ".alist_recycle_bin")); err == nil {
+					t.Errorf("the file in %s is moved to the recycle bin", d.roots[i])
+				}
+			}
+		})
+	}
+}
+
+func TestPutOverwrite(t *testing.T) {
+	tests := []struct {
+		name   string
+		policy string
+		// the content of f.txt in each storage before and after put, empty for none
+		before []string
+		after  []string
+	}{
+		{name: "new file", policy: "round_robin", after: []string{"new", ""}},
+		// the round robin would choose the first storage
+		{name: "overwrite", policy: "round_robin", before: []string{"", "old"}, after: []string{"", "new"}},
+		{name: "overwrite mirrored", policy: "mirror_to_all", before: []string{"old", "old"}, after: []string{"new", "new"}},
+		{name: "overwrite not mirrored", policy: "mirror_to_all", before: []string{"", "old"}, after: []string{"", "new"}},
+	}
+	// the file put to several storages is cached in the temp dir
+	tempDir := conf.Conf.TempDir
+	conf.Conf.TempDir = t.TempDir()
+	t.Cleanup(func() {
+		conf.Conf.TempDir = tempDir
+	})
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var roots []string
+			for i, mountPath := range []string{"/a", "/b"} {
+				files := map[string]string{}
+				if len(tt.before) > 0 && tt.before[i] != "" {
+					files["f.txt"] = tt.before[i]
+				}
+				roots = append(roots, mount(t, mountPath, files))
+			}
+			d := &Union{Addition: Addition{WritePolicy: tt.policy}, roots: []string{"/a", "/b"}}
+			err := d.Put(context.Background(), &model.Object{Path: "/", IsFolder: true}, &stream.FileStream{
+				Obj:    &model.Object{Name: "f.txt", Size: 3, Modified: time.Now()},
+				Reader: strings.NewReader("new"),
+			}, func(float64) {})
+			if err != nil {
+				t.Fatalf("Put() error = %v", err)
+			}
+			for i, root := range roots {
+				content, _ := os.ReadFile(filepath.Join(root, "f.txt"))
+				if string(content) != tt.after[i] {
+					t.Errorf("the file in %s = %q, want %q", d.roots[i], content, tt.after[i])
+				}
+			}
+		})
+	}
+}
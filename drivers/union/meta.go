@@ -0,0 +1,34 @@
+package union
+
+import (
+	"github.com/alist-org/alist/v3/internal/driver"
+	"github.com/alist-org/alist/v3/internal/op"
+)
+
+type Addition struct {
+	Paths        string `json:"paths" required:"true" type:"text" help:"the mount paths of the storages to be combined, one per line"`
+	ReadPolicy   string `json:"read_policy" type:"select" options:"first_found,newest,largest" default:"first_found" help:"which one is read if a file exists in several storages"`
+	WritePolicy  string `json:"write_policy" type:"select" options:"first_with_free_space,most_free_space,round_robin,mirror_to_all" default:"first_with_free_space" help:"which storages a new file is uploaded to"`
+	DeletePolicy string `json:"delete_policy" type:"select" options:"all,first" default:"all" help:"remove a file from all storages where it exists or only the first one"`
+	MinFreeSpace int64  `json:"min_free_space" type:"number" default:"0" help:"the free space in MB kept in each storage while writing, it's skipped if the storage can't report it"`
+}
+
+var config = driver.Config{
+	Name:             "Union",
+	LocalSort:        true,
+	NoCache:          true,
+	DefaultRoot:      "/",
+	ProxyRangeOption: true,
+}
+
+func init() {
+	op.RegisterDriver(func() driver.Driver {
+		return &Union{
+			Addition: Addition{
+				ReadPolicy:   "first_found",
+				WritePolicy:  "first_with_free_space",
+				DeletePolicy: "all",
+			},
+		}
+	})
+}
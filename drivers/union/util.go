@@ -0,0 +1,178 @@
+package union
+
+import (
+	"context"
+	"fmt"
+	stdpath "path"
+
+	"github.com/alist-org/alist/v3/internal/driver"
+	"github.com/alist-org/alist/v3/internal/errs"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/internal/op"
+	"github.com/alist-org/alist/v3/internal/sign"
+	"github.com/alist-org/alist/v3/pkg/utils"
+	"github.com/alist-org/alist/v3/server/common"
+)
+
+// member is the path in a storage of the union
+type member struct {
+	root       string
+	storage    driver.Driver
+	actualPath string
+	// the path in alist, which is the root joined with the path in union
+	mountPath string
+}
+
+// members returns the path in each storage, the storages not found are skipped
+func (d *Union) members(path string) []member {
+	var ms []member
+	for _, root := range d.roots {
+		mountPath := stdpath.Join(root, path)
+		storage, actualPath, err := op.GetStorageAndActualPath(mountPath)
+		if err != nil {
+			continue
+		}
+		ms = append(ms, member{root: root, storage: storage, actualPath: actualPath, mountPath: mountPath})
+	}
+	return ms
+}
+
+// find returns the members where the obj at path exists and the objs
+func (d *Union) find(ctx context.Context, path string) ([]member, []model.Obj) {
+	var ms []member
+	var objs []model.Obj
+	for _, m := range d.members(path) {
+		obj, err := op.Get(ctx, m.storage, m.actualPath)
+		if err != nil {
+			continue
+		}
+		ms = append(ms, m)
+		objs = append(objs, obj)
+	}
+	return ms, objs
+}
+
+// prefer reports whether the file a is read instead of b by the read policy
+func (d *Union) prefer(a, b model.Obj) bool {
+	switch d.ReadPolicy {
+	case "newest":
+		return a.ModTime().After(b.ModTime())
+	case "largest":
+		return a.GetSize() > b.GetSize()
+	}
+	return false
+}
+
+// pick returns the index of obj to be read in objs
+func (d *Union) pick(objs []model.Obj) int {
+	i := 0
+	for j := 1; j < len(objs); j++ {
+		if d.prefer(objs[j], objs[i]) {
+			i = j
+		}
+	}
+	return i
+}
+
+// writeMembers returns the members which the file of size is written to in the dir at path
+func (d *Union) writeMembers(ctx context.Context, path string, size int64) ([]member, error) {
+	ms := d.members(path)
+	if len(ms) == 0 {
+		return nil, errs.StorageNotFound
+	}
+	switch d.WritePolicy {
+	case "mirror_to_all":
+		return ms, nil
+	case "round_robin":
+		i := d.next.Add(1) - 1
+		return ms[i%uint64(len(ms)):][:1], nil
+	case "most_free_space":
+		best, most := 0, int64(-1)
+		for i, m := range ms {
+			details, err := op.GetStorageDetails(ctx, m.storage)
+			if err == nil && details.FreeSpace > most {
+				best, most = i, details.FreeSpace
+			}
+		}
+		return ms[best : best+1], nil
+	}
+	need := size + d.MinFreeSpace*utils.MB
+	for i, m := range ms {
+		details, err := op.GetStorageDetails(ctx, m.storage)
+		if err != nil || details.FreeSpace >= need {
+			return ms[i : i+1], nil
+		}
+	}
+	return nil, fmt.Errorf("no storage has %d bytes free space", need)
+}
+
+// putMembers returns the members of the dir at path which the file is put to, the
+// file is overwritten where it exists, so no stale copy is left in other storages
+func (d *Union) putMembers(ctx context.Context, path string, file model.Obj) ([]member, error) {
+	existing, _ := d.find(ctx, stdpath.Join(path, file.GetName()))
+	if len(existing) == 0 {
+		return d.writeMembers(ctx, path, file.GetSize())
+	}
+	ms := make([]member, 0, len(existing))
+	for _, m := range existing {
+		m.actualPath, m.mountPath = stdpath.Dir(m.actualPath), stdpath.Dir(m.mountPath)
+		ms = append(ms, m)
+	}
+	return ms, nil
+}
+
+// sibling returns the actual path of the path in union in the same storage of m
+func (d *Union) sibling(m member, path string) (string, error) {
+	storage, actualPath, err := op.GetStorageAndActualPath(stdpath.Join(m.root, path))
+	if err != nil {
+		return "", err
+	}
+	if storage != m.storage {
+		return "", fmt.Errorf("%s is not in the storage of %s", path, m.mountPath)
+	}
+	return actualPath, nil
+}
+
+func toObj(obj model.Obj) model.Obj {
+	objRes := model.Object{
+		Name:     obj.GetName(),
+		Size:     obj.GetSize(),
+		Modified: obj.ModTime(),
+		IsFolder: obj.IsDir(),
+	}
+	thumb, ok := model.GetThumb(obj)
+	if !ok {
+		return &objRes
+	}
+	return &model.ObjThumb{
+		Object: objRes,
+		Thumbnail: model.Thumbnail{
+			Thumbnail: thumb,
+		},
+	}
+}
+
+func (d *Union) link(ctx context.Context, m member, args model.LinkArgs) (*model.Link, error) {
+	if common.ShouldProxy(m.storage, stdpath.Base(m.mountPath)) {
+		link := &model.Link{
+			URL: fmt.Sprintf("%s/p%s?sign=%s",
+				common.GetApiUrl(args.HttpReq),
+				utils.EncodePath(m.mountPath, true),
+				sign.Sign(m.mountPath)),
+		}
+		if args.HttpReq != nil && d.ProxyRange {
+			link.RangeReadCloser = common.NoProxyRange
+		}
+		return link, nil
+	}
+	link, _, err := op.Link(ctx, m.storage, m.actualPath, args)
+	return link, err
+}
+
+// put uploads the file to the dir of member, the dir is created if it doesn't exist
+func (d *Union) put(ctx context.Context, m member, file model.FileStreamer, up driver.UpdateProgress) error {
+	if err := op.MakeDir(ctx, m.storage, m.actualPath); err != nil {
+		return err
+	}
+	return op.Put(ctx, m.storage, m.actualPath, file, up)
+}
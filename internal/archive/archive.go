@@ -0,0 +1,212 @@
+package archive
+
+import (
+	"context"
+	"io"
+	stdpath "path"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/alist-org/alist/v3/internal/errs"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/pkg/http_range"
+	"github.com/alist-org/alist/v3/pkg/utils"
+	"github.com/pkg/errors"
+)
+
+// Entry is a file or dir in an archive
+type Entry struct {
+	// the cleaned slash separated path in the archive, without leading slash
+	Path     string
+	Size     int64
+	Modified time.Time
+	IsDir    bool
+}
+
+// Source is the archive to be read, it's read by ranges so that
+// the formats with an index like zip don't need to fetch the whole file
+type Source struct {
+	Name        string
+	Size        int64
+	RangeReader model.RangeReaderFunc
+}
+
+// Stream returns the whole content of the archive from the beginning
+func (s *Source) Stream(ctx context.Context) (io.ReadCloser, error) {
+	return s.RangeReader(ctx, http_range.Range{Length: -1})
+}
+
+// ReaderAt returns a reader of the archive at any offset, the reads are cached by blocks
+func (s *Source) ReaderAt(ctx context.Context) io.ReaderAt {
+	return &readerAt{ctx: ctx, src: s}
+}
+
+// Format reads the archives in a format, 7z, rar and so on can be added by RegisterFormat
+type Format interface {
+	// Extensions returns the lower case suffixes of the names of the archives in the format, like ".tar.gz"
+	Extensions() []string
+	// List returns all entries of the archive
+	List(ctx context.Context, src *Source) ([]Entry, error)
+	// Open returns the content of the file entry at path
+	Open(ctx context.Context, src *Source, path string) (io.ReadCloser, error)
+	// Walk calls fn for each entry in order, r is nil for dirs
+	Walk(ctx context.Context, src *Source, fn func(e Entry, r io.Reader) error) error
+}
+
+// RangeOpener is implemented by the formats which can read a range of the entries stored without
+// compression directly, the ranges of the compressed ones need to be decompressed from the beginning
+type RangeOpener interface {
+	// OpenRange returns the range of the content of the file entry at path, ErrCompressedRange if it's compressed
+	OpenRange(ctx context.Context, src *Source, path string, r http_range.Range) (io.ReadCloser, error)
+}
+
+var (
+	ErrCompressedRange = errors.New("range read of compressed entry is not supported")
+	ErrEntrySize       = errors.New("the size of entry doesn't match the declared one")
+)
+
+// OpenRange returns the range of the content of the file entry at path. The range from the beginning
+// is read by Open, others are only supported by the RangeOpener for the entries stored without compression.
+func OpenRange(ctx context.Context, f Format, src *Source, path string, r http_range.Range) (io.ReadCloser, error) {
+	if r.Start == 0 {
+		rc, err := f.Open(ctx, src, path)
+		if err != nil || r.Length < 0 {
+			return rc, err
+		}
+		return utils.NewReadCloser(io.LimitReader(rc, r.Length), rc.Close), nil
+	}
+	if ro, ok := f.(RangeOpener); ok {
+		return ro.OpenRange(ctx, src, path, r)
+	}
+	return nil, errors.WithStack(ErrCompressedRange)
+}
+
+// LimitReader returns the reader of the content of entry, it fails with ErrEntrySize if r has more or
+// less bytes than the size declared, so an entry can't take more space than the one charged for it
+func LimitReader(r io.Reader, size int64) io.Reader {
+	return &limitedReader{r: r, n: size}
+}
+
+type limitedReader struct {
+	r io.Reader
+	n int64
+}
+
+func (l *limitedReader) Read(p []byte) (int, error) {
+	if l.n <= 0 {
+		// the entry should end here
+		var b [1]byte
+		if _, err := io.ReadFull(l.r, b[:]); err == nil {
+			return 0, errors.WithStack(ErrEntrySize)
+		} else if err != io.EOF {
+			return 0, err
+		}
+		return 0, io.EOF
+	}
+	if int64(len(p)) > l.n {
+		p = p[:l.n]
+	}
+	n, err := l.r.Read(p)
+	l.n -= int64(n)
+	if err == io.EOF && l.n > 0 {
+		err = errors.WithStack(ErrEntrySize)
+	}
+	return n, err
+}
+
+var formats = map[string]Format{}
+
+func RegisterFormat(f Format) {
+	for _, ext := range f.Extensions() {
+		formats[ext] = f
+	}
+}
+
+// GetFormat returns the format of the archive by its name, the longest extension matched wins
+func GetFormat(name string) (Format, bool) {
+	name = strings.ToLower(name)
+	var matched string
+	for ext := range formats {
+		if len(ext) > len(matched) && strings.HasSuffix(name, ext) {
+			matched = ext
+		}
+	}
+	if matched == "" {
+		return nil, false
+	}
+	return formats[matched], true
+}
+
+// IsArchive reports whether the file can be browsed as an archive
+func IsArchive(name string) bool {
+	_, ok := GetFormat(name)
+	return ok
+}
+
+// Tree completes the parent dirs which are missing in entries and sorts them by path
+func Tree(entries []Entry) []Entry {
+	seen := make(map[string]bool, len(entries))
+	res := make([]Entry, 0, len(entries))
+	for _, e := range entries {
+		e.Path = CleanPath(e.Path)
+		if e.Path == "" || seen[e.Path] {
+			continue
+		}
+		seen[e.Path] = true
+		res = append(res, e)
+	}
+	for _, e := range res {
+		for dir := stdpath.Dir(e.Path); dir != "."; dir = stdpath.Dir(dir) {
+			if seen[dir] {
+				break
+			}
+			seen[dir] = true
+			res = append(res, Entry{Path: dir, Modified: e.Modified, IsDir: true})
+		}
+	}
+	sort.Slice(res, func(i, j int) bool {
+		return res[i].Path < res[j].Path
+	})
+	return res
+}
+
+// Children returns the entries directly in the dir at path, "" is the root
+func Children(entries []Entry, path string) ([]Entry, error) {
+	path = CleanPath(path)
+	if path != "" {
+		e, ok := Find(entries, path)
+		if !ok {
+			return nil, errs.ObjectNotFound
+		}
+		if !e.IsDir {
+			return nil, errs.NotFolder
+		}
+	}
+	var res []Entry
+	for _, e := range entries {
+		dir := stdpath.Dir(e.Path)
+		if dir == path || dir == "." && path == "" {
+			res = append(res, e)
+		}
+	}
+	return res, nil
+}
+
+// Find returns the entry at path
+func Find(entries []Entry, path string) (Entry, bool) {
+	path = CleanPath(path)
+	i := sort.Search(len(entries), func(i int) bool {
+		return entries[i].Path >= path
+	})
+	if i < len(entries) && entries[i].Path == path {
+		return entries[i], true
+	}
+	return Entry{}, false
+}
+
+// CleanPath returns the path in the form of Entry.Path
+func CleanPath(path string) string {
+	path = stdpath.Clean("/" + strings.ReplaceAll(path, "\\", "/"))
+	return strings.TrimPrefix(path, "/")
+}
@@ -0,0 +1,187 @@
+package archive
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"hash/crc32"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/alist-org/alist/v3/pkg/http_range"
+	"github.com/pkg/errors"
+)
+
+var content = []byte(strings.Repeat("0123456789", 100))
+
+func newSource(name string, data []byte) *Source {
+	return &Source{
+		Name: name,
+		Size: int64(len(data)),
+		RangeReader: func(ctx context.Context, r http_range.Range) (io.ReadCloser, error) {
+			length := r.Length
+			if length < 0 {
+				length = int64(len(data)) - r.Start
+			}
+			return io.NopCloser(io.NewSectionReader(bytes.NewReader(data), r.Start, length)), nil
+		},
+	}
+}
+
+func newZip(t *testing.T) *Source {
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for _, h := range []*zip.FileHeader{
+		{Name: "deflated.txt", Method: zip.Deflate},
+		{Name: "stored.txt", Method: zip.Store},
+	} {
+		w, err := zw.CreateHeader(h)
+		if err == nil {
+			_, err = w.Write(content)
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	w, err := zw.CreateRaw(&zip.FileHeader{
+		Name:               "corrupted.txt",
+		Method:             zip.Store,
+		CRC32:              crc32.ChecksumIEEE(content) + 1,
+		CompressedSize64:   uint64(len(content)),
+		UncompressedSize64: uint64(len(content)),
+	})
+	if err == nil {
+		_, err = w.Write(content)
+	}
+	if err == nil {
+		err = zw.Close()
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	return newSource("test.zip", buf.Bytes())
+}
+
+func newTar(t *testing.T, compress bool) *Source {
+	var buf bytes.Buffer
+	var w io.WriteCloser = nopWriteCloser{&buf}
+	name := "test.tar"
+	if compress {
+		w, name = gzip.NewWriter(&buf), "test.tar.gz"
+	}
+	tw := tar.NewWriter(w)
+	err := tw.WriteHeader(&tar.Header{Name: "a.txt", Mode: 0644, Size: int64(len(content))})
+	if err == nil {
+		_, err = tw.Write(content)
+	}
+	if err == nil {
+		err = tw.Close()
+	}
+	if err == nil {
+		err = w.Close()
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	return newSource(name, buf.Bytes())
+}
+
+type nopWriteCloser struct {
+	io.Writer
+}
+
+func (nopWriteCloser) Close() error {
+	return nil
+}
+
+func TestZipOpen(t *testing.T) {
+	src := newZip(t)
+	tests := []struct {
+		path string
+		err  error
+	}{
+		{path: "deflated.txt"},
+		{path: "stored.txt"},
+		{path: "corrupted.txt", err: zip.ErrChecksum},
+	}
+	for _, tt := range tests {
+		rc, err := Zip{}.Open(context.Background(), src, tt.path)
+		if err != nil {
+			t.Fatalf("Open(%s) error = %v", tt.path, err)
+		}
+		data, err := io.ReadAll(rc)
+		_ = rc.Close()
+		if !errors.Is(err, tt.err) {
+			t.Errorf("read %s error = %v, want %v", tt.path, err, tt.err)
+		}
+		if tt.err == nil && !bytes.Equal(data, content) {
+			t.Errorf("read %s got %d bytes, want the content", tt.path, len(data))
+		}
+	}
+}
+
+func TestOpenRange(t *testing.T) {
+	zipSrc, tarSrc, tgzSrc := newZip(t), newTar(t, false), newTar(t, true)
+	tests := []struct {
+		src  *Source
+		path string
+		r    http_range.Range
+		err  error
+	}{
+		{src: zipSrc, path: "stored.txt", r: http_range.Range{Start: 15, Length: 20}},
+		{src: zipSrc, path: "stored.txt", r: http_range.Range{Start: 990, Length: -1}},
+		{src: zipSrc, path: "deflated.txt", r: http_range.Range{Start: 0, Length: 20}},
+		{src: zipSrc, path: "deflated.txt", r: http_range.Range{Start: 15, Length: 20}, err: ErrCompressedRange},
+		{src: tarSrc, path: "a.txt", r: http_range.Range{Start: 15, Length: 20}},
+		{src: tgzSrc, path: "a.txt", r: http_range.Range{Start: 0, Length: -1}},
+		{src: tgzSrc, path: "a.txt", r: http_range.Range{Start: 15, Length: 20}, err: ErrCompressedRange},
+	}
+	for _, tt := range tests {
+		f, ok := GetFormat(tt.src.Name)
+		if !ok {
+			t.Fatalf("no format of %s", tt.src.Name)
+		}
+		rc, err := OpenRange(context.Background(), f, tt.src, tt.path, tt.r)
+		if !errors.Is(err, tt.err) {
+			t.Errorf("OpenRange(%s/%s, %+v) error = %v, want %v", tt.src.Name, tt.path, tt.r, err, tt.err)
+			continue
+		}
+		if err != nil {
+			continue
+		}
+		data, err := io.ReadAll(rc)
+		_ = rc.Close()
+		end := int64(len(content))
+		if tt.r.Length >= 0 {
+			end = tt.r.Start + tt.r.Length
+		}
+		if want := content[tt.r.Start:end]; err != nil || !bytes.Equal(data, want) {
+			t.Errorf("OpenRange(%s/%s, %+v) got %q, %v, want %q", tt.src.Name, tt.path, tt.r, data, err, want)
+		}
+	}
+}
+
+func TestLimitReader(t *testing.T) {
+	tests := []struct {
+		data string
+		size int64
+		err  error
+	}{
+		{data: "hello", size: 5},
+		{data: "", size: 0},
+		{data: "hello!", size: 5, err: ErrEntrySize},
+		{data: "hell", size: 5, err: ErrEntrySize},
+	}
+	for _, tt := range tests {
+		data, err := io.ReadAll(LimitReader(strings.NewReader(tt.data), tt.size))
+		if !errors.Is(err, tt.err) {
+			t.Errorf("read %q of size %d error = %v, want %v", tt.data, tt.size, err, tt.err)
+		}
+		if int64(len(data)) > tt.size {
+			t.Errorf("read %q of size %d got %d bytes", tt.data, tt.size, len(data))
+		}
+	}
+}
@@ -0,0 +1,63 @@
+package archive
+
+import (
+	"context"
+	"io"
+	"sync"
+
+	"github.com/alist-org/alist/v3/pkg/http_range"
+)
+
+// the size of block read at once, the small reads like the ones of
+// zip central directories are served by the cached block
+const blockSize = 256 * 1024
+
+type readerAt struct {
+	ctx context.Context
+	src *Source
+
+	mu    sync.Mutex
+	off   int64
+	block []byte
+}
+
+func (r *readerAt) ReadAt(p []byte, off int64) (int, error) {
+	if off < 0 {
+		return 0, io.ErrUnexpectedEOF
+	}
+	if off >= r.src.Size {
+		return 0, io.EOF
+	}
+	if len(p) >= blockSize {
+		return r.read(p, off)
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if off < r.off || off+int64(len(p)) > r.off+int64(len(r.block)) {
+		block := make([]byte, min(blockSize, r.src.Size-off))
+		n, err := r.read(block, off)
+		if err != nil && err != io.EOF {
+			return 0, err
+		}
+		r.off, r.block = off, block[:n]
+	}
+	n := copy(p, r.block[off-r.off:])
+	if n < len(p) {
+		return n, io.EOF
+	}
+	return n, nil
+}
+
+func (r *readerAt) read(p []byte, off int64) (int, error) {
+	length := min(int64(len(p)), r.src.Size-off)
+	rc, err := r.src.RangeReader(r.ctx, http_range.Range{Start: off, Length: length})
+	if err != nil {
+		return 0, err
+	}
+	defer rc.Close()
+	n, err := io.ReadFull(rc, p[:length])
+	if err == nil && int64(n) < int64(len(p)) {
+		err = io.EOF
+	}
+	return n, err
+}
@@ -0,0 +1,174 @@
+package archive
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"context"
+	"io"
+
+	"github.com/alist-org/alist/v3/internal/errs"
+	"github.com/alist-org/alist/v3/pkg/http_range"
+	"github.com/alist-org/alist/v3/pkg/utils"
+	"github.com/klauspost/compress/zstd"
+	"github.com/pkg/errors"
+)
+
+// Tar reads the tar archives, which are compressed by decompress if it's not nil
+type Tar struct {
+	extensions []string
+	decompress func(r io.Reader) (io.ReadCloser, error)
+}
+
+func (t Tar) Extensions() []string {
+	return t.extensions
+}
+
+// reader returns the tar reader and the seeker of the underlying archive, which is nil
+// if the archive is compressed. A tar without compression is read by ranges, so that the
+// contents of entries are skipped while listing.
+func (t Tar) reader(ctx context.Context, src *Source) (*tar.Reader, io.Seeker, io.Closer, error) {
+	if t.decompress == nil {
+		sr := io.NewSectionReader(src.ReaderAt(ctx), 0, src.Size)
+		return tar.NewReader(sr), sr, utils.CloseFunc(func() error { return nil }), nil
+	}
+	rc, err := src.Stream(ctx)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	dr, err := t.decompress(rc)
+	if err != nil {
+		_ = rc.Close()
+		return nil, nil, nil, errors.WithMessage(err, "failed decompress")
+	}
+	return tar.NewReader(dr), nil, utils.CloseFunc(func() error {
+		_ = dr.Close()
+		return rc.Close()
+	}), nil
+}
+
+func (t Tar) List(ctx context.Context, src *Source) ([]Entry, error) {
+	var entries []Entry
+	err := t.walk(ctx, src, func(h *tar.Header, _ *tar.Reader) error {
+		entries = append(entries, tarEntry(h))
+		return nil
+	})
+	return entries, err
+}
+
+func (t Tar) Open(ctx context.Context, src *Source, path string) (io.ReadCloser, error) {
+	return t.open(ctx, src, path, http_range.Range{Length: -1})
+}
+
+// OpenRange reads the range of the content directly if the tar isn't compressed
+func (t Tar) OpenRange(ctx context.Context, src *Source, path string, r http_range.Range) (io.ReadCloser, error) {
+	if t.decompress != nil {
+		return nil, errors.WithStack(ErrCompressedRange)
+	}
+	return t.open(ctx, src, path, r)
+}
+
+// open returns the range of the content of the entry at path, the range must start
+// from the beginning if the tar is compressed
+func (t Tar) open(ctx context.Context, src *Source, path string, r http_range.Range) (io.ReadCloser, error) {
+	path = CleanPath(path)
+	tr, seeker, closer, err := t.reader(ctx, src)
+	if err != nil {
+		return nil, err
+	}
+	for {
+		h, err := tr.Next()
+		if err == io.EOF {
+			_ = closer.Close()
+			return nil, errs.ObjectNotFound
+		}
+		if err != nil {
+			_ = closer.Close()
+			return nil, errors.WithMessage(err, "failed read tar")
+		}
+		if CleanPath(h.Name) != path || !h.FileInfo().Mode().IsRegular() {
+			continue
+		}
+		if seeker != nil {
+			// read the content by a range request directly
+			offset, err := seeker.Seek(0, io.SeekCurrent)
+			if err != nil {
+				return nil, err
+			}
+			if r.Start > h.Size {
+				return nil, errors.Errorf("the range start %d is beyond the size %d", r.Start, h.Size)
+			}
+			if r.Length < 0 || r.Start+r.Length > h.Size {
+				r.Length = h.Size - r.Start
+			}
+			return src.RangeReader(ctx, http_range.Range{Start: offset + r.Start, Length: r.Length})
+		}
+		return utils.NewReadCloser(tr, closer.Close), nil
+	}
+}
+
+func (t Tar) Walk(ctx context.Context, src *Source, fn func(e Entry, r io.Reader) error) error {
+	return t.walk(ctx, src, func(h *tar.Header, tr *tar.Reader) error {
+		e := tarEntry(h)
+		switch {
+		case e.IsDir:
+			return fn(e, nil)
+		case h.FileInfo().Mode().IsRegular():
+			return fn(e, tr)
+		}
+		// the links and the special files are skipped
+		return nil
+	})
+}
+
+// walk calls fn with each header until fn returns an error
+func (t Tar) walk(ctx context.Context, src *Source, fn func(h *tar.Header, tr *tar.Reader) error) error {
+	tr, _, closer, err := t.reader(ctx, src)
+	if err != nil {
+		return err
+	}
+	defer closer.Close()
+	for {
+		if utils.IsCanceled(ctx) {
+			return ctx.Err()
+		}
+		h, err := tr.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return errors.WithMessage(err, "failed read tar")
+		}
+		if err = fn(h, tr); err != nil {
+			return err
+		}
+	}
+}
+
+func tarEntry(h *tar.Header) Entry {
+	return Entry{
+		Path:     CleanPath(h.Name),
+		Size:     h.Size,
+		Modified: h.ModTime,
+		IsDir:    h.FileInfo().IsDir(),
+	}
+}
+
+func init() {
+	RegisterFormat(Tar{extensions: []string{".tar"}})
+	RegisterFormat(Tar{
+		extensions: []string{".tar.gz", ".tgz"},
+		decompress: func(r io.Reader) (io.ReadCloser, error) {
+			return gzip.NewReader(r)
+		},
+	})
+	RegisterFormat(Tar{
+		extensions: []string{".tar.zst", ".tzst"},
+		decompress: func(r io.Reader) (io.ReadCloser, error) {
+			zr, err := zstd.NewReader(r)
+			if err != nil {
+				return nil, err
+			}
+			return zr.IOReadCloser(), nil
+		},
+	})
+}
@@ -0,0 +1,97 @@
+package archive
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"io"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// Writer writes the files and dirs into an archive in order
+type Writer interface {
+	// Create adds the file at path and returns the writer of its content,
+	// size is needed by the formats which write it before the content
+	Create(path string, size int64, modified time.Time) (io.Writer, error)
+	// Mkdir adds the dir at path
+	Mkdir(path string, modified time.Time) error
+	// Close finishes the archive, it doesn't close the underlying writer
+	Close() error
+}
+
+// WriteFormats are the formats of archives that can be written
+var WriteFormats = []string{"zip", "tar.gz"}
+
+// NewWriter returns the writer of the format to w, format is one of WriteFormats
+func NewWriter(w io.Writer, format string) (Writer, error) {
+	switch format {
+	case "zip":
+		return &zipWriter{zw: zip.NewWriter(w)}, nil
+	case "tar.gz":
+		gw := gzip.NewWriter(w)
+		return &tarWriter{tw: tar.NewWriter(gw), gw: gw}, nil
+	}
+	return nil, errors.Errorf("unsupported archive format: %s", format)
+}
+
+type zipWriter struct {
+	zw *zip.Writer
+}
+
+func (w *zipWriter) Create(path string, _ int64, modified time.Time) (io.Writer, error) {
+	return w.zw.CreateHeader(&zip.FileHeader{
+		Name:     CleanPath(path),
+		Method:   zip.Deflate,
+		Modified: modified,
+	})
+}
+
+func (w *zipWriter) Mkdir(path string, modified time.Time) error {
+	_, err := w.zw.CreateHeader(&zip.FileHeader{
+		Name:     CleanPath(path) + "/",
+		Method:   zip.Store,
+		Modified: modified,
+	})
+	return err
+}
+
+func (w *zipWriter) Close() error {
+	return w.zw.Close()
+}
+
+type tarWriter struct {
+	tw *tar.Writer
+	gw *gzip.Writer
+}
+
+func (w *tarWriter) Create(path string, size int64, modified time.Time) (io.Writer, error) {
+	err := w.tw.WriteHeader(&tar.Header{
+		Typeflag: tar.TypeReg,
+		Name:     CleanPath(path),
+		Size:     size,
+		Mode:     0644,
+		ModTime:  modified,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return w.tw, nil
+}
+
+func (w *tarWriter) Mkdir(path string, modified time.Time) error {
+	return w.tw.WriteHeader(&tar.Header{
+		Typeflag: tar.TypeDir,
+		Name:     CleanPath(path) + "/",
+		Mode:     0755,
+		ModTime:  modified,
+	})
+}
+
+func (w *tarWriter) Close() error {
+	if err := w.tw.Close(); err != nil {
+		return err
+	}
+	return w.gw.Close()
+}
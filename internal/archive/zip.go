@@ -0,0 +1,185 @@
+package archive
+
+import (
+	"archive/zip"
+	"compress/flate"
+	"context"
+	"hash"
+	"hash/crc32"
+	"io"
+
+	"github.com/alist-org/alist/v3/internal/errs"
+	"github.com/alist-org/alist/v3/pkg/http_range"
+	"github.com/alist-org/alist/v3/pkg/utils"
+	"github.com/pkg/errors"
+)
+
+type Zip struct{}
+
+func (Zip) Extensions() []string {
+	return []string{".zip"}
+}
+
+func (Zip) reader(ctx context.Context, src *Source) (*zip.Reader, error) {
+	zr, err := zip.NewReader(src.ReaderAt(ctx), src.Size)
+	if err != nil {
+		return nil, errors.WithMessage(err, "failed read zip")
+	}
+	return zr, nil
+}
+
+func (z Zip) List(ctx context.Context, src *Source) ([]Entry, error) {
+	zr, err := z.reader(ctx, src)
+	if err != nil {
+		return nil, err
+	}
+	entries := make([]Entry, 0, len(zr.File))
+	for _, f := range zr.File {
+		entries = append(entries, zipEntry(f))
+	}
+	return entries, nil
+}
+
+func (z Zip) Open(ctx context.Context, src *Source, path string) (io.ReadCloser, error) {
+	zr, err := z.reader(ctx, src)
+	if err != nil {
+		return nil, err
+	}
+	path = CleanPath(path)
+	for _, f := range zr.File {
+		if CleanPath(f.Name) == path && !f.FileInfo().IsDir() {
+			return openZipFile(ctx, src, f)
+		}
+	}
+	return nil, errs.ObjectNotFound
+}
+
+func (z Zip) OpenRange(ctx context.Context, src *Source, path string, r http_range.Range) (io.ReadCloser, error) {
+	zr, err := z.reader(ctx, src)
+	if err != nil {
+		return nil, err
+	}
+	path = CleanPath(path)
+	for _, f := range zr.File {
+		if CleanPath(f.Name) != path || f.FileInfo().IsDir() {
+			continue
+		}
+		if f.Method != zip.Store || f.Flags&0x1 != 0 {
+			return nil, errors.WithStack(ErrCompressedRange)
+		}
+		size := int64(f.UncompressedSize64)
+		if r.Start > size {
+			return nil, errors.Errorf("the range start %d is beyond the size %d", r.Start, size)
+		}
+		if r.Length < 0 || r.Start+r.Length > size {
+			r.Length = size - r.Start
+		}
+		offset, err := f.DataOffset()
+		if err != nil {
+			return nil, err
+		}
+		return src.RangeReader(ctx, http_range.Range{Start: offset + r.Start, Length: r.Length})
+	}
+	return nil, errs.ObjectNotFound
+}
+
+func (z Zip) Walk(ctx context.Context, src *Source, fn func(e Entry, r io.Reader) error) error {
+	zr, err := z.reader(ctx, src)
+	if err != nil {
+		return err
+	}
+	for _, f := range zr.File {
+		if utils.IsCanceled(ctx) {
+			return ctx.Err()
+		}
+		e := zipEntry(f)
+		if e.IsDir {
+			err = fn(e, nil)
+		} else {
+			err = walkZipFile(ctx, src, f, e, fn)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func walkZipFile(ctx context.Context, src *Source, f *zip.File, e Entry, fn func(e Entry, r io.Reader) error) error {
+	rc, err := openZipFile(ctx, src, f)
+	if err != nil {
+		return err
+	}
+	defer rc.Close()
+	return fn(e, rc)
+}
+
+// openZipFile reads the data of the stored and deflated files by one range request,
+// instead of the small reads by zip.File.Open. The size and CRC-32 are verified at
+// the end like zip.File.Open.
+func openZipFile(ctx context.Context, src *Source, f *zip.File) (io.ReadCloser, error) {
+	if f.Flags&0x1 != 0 {
+		return nil, errors.Errorf("encrypted zip file is not supported: %s", f.Name)
+	}
+	if f.Method != zip.Store && f.Method != zip.Deflate {
+		return f.Open()
+	}
+	offset, err := f.DataOffset()
+	if err != nil {
+		return nil, err
+	}
+	rc, err := src.RangeReader(ctx, http_range.Range{Start: offset, Length: int64(f.CompressedSize64)})
+	if err != nil {
+		return nil, err
+	}
+	if f.Method == zip.Store {
+		return &checksumReader{rc: rc, f: f, hash: crc32.NewIEEE()}, nil
+	}
+	fr := flate.NewReader(rc)
+	return &checksumReader{rc: utils.NewReadCloser(fr, func() error {
+		_ = fr.Close()
+		return rc.Close()
+	}), f: f, hash: crc32.NewIEEE()}, nil
+}
+
+type checksumReader struct {
+	rc   io.ReadCloser
+	f    *zip.File
+	hash hash.Hash32
+	read uint64
+}
+
+func (r *checksumReader) Read(p []byte) (int, error) {
+	n, err := r.rc.Read(p)
+	r.hash.Write(p[:n])
+	r.read += uint64(n)
+	if r.read > r.f.UncompressedSize64 {
+		return 0, zip.ErrFormat
+	}
+	if err == io.EOF {
+		if r.read != r.f.UncompressedSize64 {
+			return n, io.ErrUnexpectedEOF
+		}
+		if r.f.CRC32 != 0 && r.hash.Sum32() != r.f.CRC32 {
+			return n, zip.ErrChecksum
+		}
+	}
+	return n, err
+}
+
+func (r *checksumReader) Close() error {
+	return r.rc.Close()
+}
+
+func zipEntry(f *zip.File) Entry {
+	return Entry{
+		Path:     CleanPath(f.Name),
+		Size:     int64(f.UncompressedSize64),
+		Modified: f.Modified,
+		IsDir:    f.FileInfo().IsDir(),
+	}
+}
+
+func init() {
+	RegisterFormat(Zip{})
+}
@@ -0,0 +1,126 @@
+package audit
+
+import (
+	"context"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/alist-org/alist/v3/internal/conf"
+	"github.com/alist-org/alist/v3/internal/db"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/internal/setting"
+	"github.com/alist-org/alist/v3/pkg/utils"
+	log "github.com/sirupsen/logrus"
+)
+
+var (
+	once  sync.Once
+	queue = make(chan model.AuditLog, 1024)
+	// the count of logs dropped since the queue is full
+	dropped atomic.Int64
+)
+
+// Record records the operation if it's requested from a frontend,
+// the internal operations such as tasks and indexing are not recorded
+func Record(ctx context.Context, operation, path, dstPath string, err error) {
+	frontend, _ := ctx.Value(conf.FrontendKey).(string)
+	if frontend == "" || !setting.GetBool(conf.AuditLogEnabled) {
+		return
+	}
+	l := model.AuditLog{
+		Frontend:  frontend,
+		Operation: operation,
+		Path:      path,
+		DstPath:   dstPath,
+		Success:   err == nil,
+		Time:      time.Now(),
+	}
+	l.IP, _ = ctx.Value(conf.ClientIPKey).(string)
+	if user, ok := ctx.Value("user").(*model.User); ok {
+		l.UserID = user.ID
+		l.Username = user.Username
+	}
+	if err != nil {
+		l.Error = err.Error()
+	}
+	once.Do(func() {
+		go worker()
+	})
+	select {
+	case queue <- l:
+	default:
+		// the operations should never wait for the db
+		if n := dropped.Add(1); n%1000 == 1 {
+			log.Warnf("audit log queue is full, %d logs dropped", n)
+		}
+	}
+}
+
+// Dropped returns the count of logs dropped since the queue is full
+func Dropped() int64 {
+	return dropped.Load()
+}
+
+// worker saves the logs in batches, so that the operations are not slowed down by the db
+func worker() {
+	var file *os.File
+	var filePath string
+	for l := range queue {
+		logs := []model.AuditLog{l}
+	batch:
+		for len(logs) < 100 {
+			select {
+			case l := <-queue:
+				logs = append(logs, l)
+			default:
+				break batch
+			}
+		}
+		if err := db.CreateAuditLogs(logs); err != nil {
+			log.Errorf("failed save %d audit logs: %+v", len(logs), err)
+		}
+		if p := setting.GetStr(conf.AuditLogFile); p != filePath {
+			if file != nil {
+				_ = file.Close()
+				file = nil
+			}
+			filePath = p
+			if p != "" {
+				var err error
+				file, err = os.OpenFile(p, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0644)
+				if err != nil {
+					// retry on the next batch
+					filePath = ""
+					log.Errorf("failed open audit log file: %+v", err)
+				}
+			}
+		}
+		if file != nil {
+			writeLines(file, logs)
+		}
+	}
+}
+
+func writeLines(file *os.File, logs []model.AuditLog) {
+	var buf []byte
+	for _, l := range logs {
+		b, err := utils.Json.Marshal(l)
+		if err != nil {
+			continue
+		}
+		buf = append(append(buf, b...), '\n')
+	}
+	if _, err := file.Write(buf); err != nil {
+		log.Errorf("failed write audit log file: %+v", err)
+	}
+}
+
+func GetLogs(filter *model.AuditLogFilter, pageIndex, pageSize int) ([]model.AuditLog, int64, error) {
+	return db.GetAuditLogs(filter, pageIndex, pageSize)
+}
+
+func DeleteLogsBefore(t time.Time) error {
+	return db.DeleteAuditLogsBefore(t)
+}
@@ -0,0 +1,47 @@
+package bootstrap
+
+import (
+	"github.com/alist-org/alist/v3/internal/cache"
+	"github.com/alist-org/alist/v3/internal/conf"
+	"github.com/alist-org/alist/v3/internal/op"
+	"github.com/redis/go-redis/v9"
+	log "github.com/sirupsen/logrus"
+)
+
+var cacheBackend cache.Backend
+
+func InitCache() {
+	c := conf.Conf.Cache
+	var err error
+	switch c.Type {
+	case "", "memory":
+		return
+	case "bolt":
+		cacheBackend, err = cache.NewBolt(c.BoltFile)
+	case "redis":
+		cacheBackend, err = cache.NewRedis(&redis.Options{
+			Addr:     c.RedisAddr,
+			Username: c.RedisUsername,
+			Password: c.RedisPassword,
+			DB:       c.RedisDB,
+		}, c.RedisKeyPrefix)
+	default:
+		log.Fatalf("not supported cache type: %s", c.Type)
+	}
+	if err != nil {
+		// e.g. the bolt file is locked by the running server when using the cli
+		log.Errorf("failed init %s cache, use memory cache instead: %+v", c.Type, err)
+		return
+	}
+	op.SetCacheBackend(cacheBackend)
+	log.Infof("use %s cache", c.Type)
+}
+
+func CloseCache() {
+	if cacheBackend == nil {
+		return
+	}
+	if err := cacheBackend.Close(); err != nil {
+		log.Errorf("failed close cache: %+v", err)
+	}
+}
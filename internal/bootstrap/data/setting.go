@@ -145,6 +145,14 @@ func InitialSettings() []model.SettingItem {
 		{Key: conf.ForwardDirectLinkParams, Value: "false", Type: conf.TypeBool, Group: model.GLOBAL},
 		{Key: conf.IgnoreDirectLinkParams, Value: "sign,alist_ts", Type: conf.TypeString, Group: model.GLOBAL},
 		{Key: conf.WebauthnLoginEnabled, Value: "false", Type: conf.TypeBool, Group: model.GLOBAL, Flag: model.PUBLIC},
+		{Key: conf.RecycleBinEnabled, Value: "false", Type: conf.TypeBool, Group: model.GLOBAL, Flag: model.PRIVATE, Help: `move the removed objects to the recycle bin of the storage if it supports move or copy`},
+		{Key: conf.RecycleBinDir, Value: ".alist_recycle_bin", Type: conf.TypeString, Group: model.GLOBAL, Flag: model.PRIVATE, Help: `the dir of recycle bin in the root of each storage`},
+		{Key: conf.RecycleBinRetention, Value: "30", Type: conf.TypeNumber, Group: model.GLOBAL, Flag: model.PRIVATE, Help: `days to keep the removed objects, 0 to keep forever`},
+		{Key: conf.AuditLogEnabled, Value: "false", Type: conf.TypeBool, Group: model.GLOBAL, Flag: model.PRIVATE, Help: `record the file operations of web, webdav, s3, ftp and sftp`},
+		{Key: conf.AuditLogFile, Value: "", Type: conf.TypeString, Group: model.GLOBAL, Flag: model.PRIVATE, Help: `also append the audit logs to the file as json lines, empty to disable`},
+		{Key: conf.CopyVerify, Value: "false", Type: conf.TypeBool, Group: model.GLOBAL, Flag: model.PRIVATE, Help: `check the size and hash of the files copied between storages, the hash is only checked if both storages provide it`},
+		{Key: conf.DownloadLimit, Value: "0", Type: conf.TypeNumber, Group: model.GLOBAL, Flag: model.PRIVATE, Help: `KB/s of all downloads and transfer tasks, 0 for no limit`},
+		{Key: conf.UploadLimit, Value: "0", Type: conf.TypeNumber, Group: model.GLOBAL, Flag: model.PRIVATE, Help: `KB/s of all uploads and transfer tasks, 0 for no limit`},
 
 		// single settings
 		{Key: conf.Token, Value: token, Type: conf.TypeString, Group: model.SINGLE, Flag: model.PRIVATE},
@@ -152,6 +160,10 @@ func InitialSettings() []model.SettingItem {
 		{Key: conf.AutoUpdateIndex, Value: "false", Type: conf.TypeBool, Group: model.INDEX},
 		{Key: conf.IgnorePaths, Value: "", Type: conf.TypeText, Group: model.INDEX, Flag: model.PRIVATE, Help: `one path per line`},
 		{Key: conf.MaxIndexDepth, Value: "20", Type: conf.TypeNumber, Group: model.INDEX, Flag: model.PRIVATE, Help: `max depth of index`},
+		{Key: conf.IndexUpdateInterval, Value: "0", Type: conf.TypeNumber, Group: model.INDEX, Flag: model.PRIVATE, Help: `minutes between the incremental updates of the changed dirs, 0 to disable, requires auto update index`},
+		{Key: conf.IndexMaxAge, Value: "24", Type: conf.TypeNumber, Group: model.INDEX, Flag: model.PRIVATE, Help: `hours before the dirs that seem unchanged are listed again by the incremental update`},
+		{Key: conf.IndexContent, Value: "false", Type: conf.TypeBool, Group: model.INDEX, Flag: model.PRIVATE, Help: `index the text of text, pdf and office files, only supported by bleve and meilisearch`},
+		{Key: conf.IndexContentMaxSize, Value: "10", Type: conf.TypeNumber, Group: model.INDEX, Flag: model.PRIVATE, Help: `MB, the content of larger files is not indexed, only the beginning of text files is read`},
 		{Key: conf.IndexProgress, Value: "{}", Type: conf.TypeText, Group: model.SINGLE, Flag: model.PRIVATE},
 
 		// SSO settings
@@ -168,6 +180,7 @@ func InitialSettings() []model.SettingItem {
 		{Key: conf.SSODefaultDir, Value: "/", Type: conf.TypeString, Group: model.SSO, Flag: model.PRIVATE},
 		{Key: conf.SSODefaultPermission, Value: "0", Type: conf.TypeNumber, Group: model.SSO, Flag: model.PRIVATE},
 		{Key: conf.SSOCompatibilityMode, Value: "false", Type: conf.TypeBool, Group: model.SSO, Flag: model.PUBLIC},
+		{Key: conf.SSOOIDCGroupsKey, Value: "groups", Type: conf.TypeString, Group: model.SSO, Flag: model.PRIVATE, Help: `the claim of groups to map to the groups with external names, empty to disable`},
 
 		// ldap settings
 		{Key: conf.LdapLoginEnabled, Value: "false", Type: conf.TypeBool, Group: model.LDAP, Flag: model.PUBLIC},
@@ -179,11 +192,13 @@ func InitialSettings() []model.SettingItem {
 		{Key: conf.LdapDefaultDir, Value: "/", Type: conf.TypeString, Group: model.LDAP, Flag: model.PRIVATE},
 		{Key: conf.LdapDefaultPermission, Value: "0", Type: conf.TypeNumber, Group: model.LDAP, Flag: model.PRIVATE},
 		{Key: conf.LdapLoginTips, Value: "login with ldap", Type: conf.TypeString, Group: model.LDAP, Flag: model.PUBLIC},
+		{Key: conf.LdapGroupAttribute, Value: "memberOf", Type: conf.TypeString, Group: model.LDAP, Flag: model.PRIVATE, Help: `the attribute of groups to map to the groups with external names, empty to disable`},
 
 		//s3 settings
 		{Key: conf.S3AccessKeyId, Value: "", Type: conf.TypeString, Group: model.S3, Flag: model.PRIVATE},
 		{Key: conf.S3SecretAccessKey, Value: "", Type: conf.TypeString, Group: model.S3, Flag: model.PRIVATE},
 		{Key: conf.S3Buckets, Value: "[]", Type: conf.TypeString, Group: model.S3, Flag: model.PRIVATE},
+		{Key: conf.S3User, Value: "", Type: conf.TypeString, Group: model.S3, Flag: model.PRIVATE, Help: `the acl rules of the user apply to s3, leave empty to allow all`},
 	}
 	initialSettingItems = append(initialSettingItems, tool.Tools.Items()...)
 	if flags.Dev {
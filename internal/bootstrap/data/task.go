@@ -22,8 +22,12 @@ func initTasks() {
 func InitialTasks() []model.TaskItem {
 	initialTaskItems = []model.TaskItem{
 		{Key: "copy", PersistData: "[]"},
+		{Key: "move", PersistData: "[]"},
 		{Key: "download", PersistData: "[]"},
 		{Key: "transfer", PersistData: "[]"},
+		{Key: "dedup", PersistData: "[]"},
+		{Key: "extract", PersistData: "[]"},
+		{Key: "warm_up", PersistData: "[]"},
 	}
 	return initialTaskItems
 }
@@ -1,7 +1,10 @@
 package bootstrap
 
 import (
+	"time"
+
 	"github.com/alist-org/alist/v3/internal/search"
+	"github.com/alist-org/alist/v3/pkg/cron"
 	log "github.com/sirupsen/logrus"
 )
 
@@ -16,3 +19,8 @@ func InitIndex() {
 		search.WriteProgress(progress)
 	}
 }
+
+// InitIndexUpdater checks every minute whether the incremental update of index should run
+func InitIndexUpdater() {
+	cron.NewCron(time.Minute).Do(search.RunIncrementalUpdate)
+}
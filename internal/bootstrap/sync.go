@@ -0,0 +1,8 @@
+package bootstrap
+
+import "github.com/alist-org/alist/v3/internal/syncjob"
+
+// InitSyncJobs schedules the sync jobs
+func InitSyncJobs() {
+	syncjob.Init()
+}
@@ -3,6 +3,7 @@ package bootstrap
 import (
 	"github.com/alist-org/alist/v3/internal/conf"
 	"github.com/alist-org/alist/v3/internal/db"
+	"github.com/alist-org/alist/v3/internal/dedup"
 	"github.com/alist-org/alist/v3/internal/fs"
 	"github.com/alist-org/alist/v3/internal/offline_download/tool"
 	"github.com/xhofe/tache"
@@ -11,8 +12,12 @@ import (
 func InitTaskManager() {
 	fs.UploadTaskManager = tache.NewManager[*fs.UploadTask](tache.WithWorks(conf.Conf.Tasks.Upload.Workers), tache.WithMaxRetry(conf.Conf.Tasks.Upload.MaxRetry)) //upload will not support persist
 	fs.CopyTaskManager = tache.NewManager[*fs.CopyTask](tache.WithWorks(conf.Conf.Tasks.Copy.Workers), tache.WithPersistFunction(db.GetTaskDataFunc("copy", conf.Conf.Tasks.Copy.TaskPersistant), db.UpdateTaskDataFunc("copy", conf.Conf.Tasks.Copy.TaskPersistant)), tache.WithMaxRetry(conf.Conf.Tasks.Copy.MaxRetry))
+	fs.MoveTaskManager = tache.NewManager[*fs.MoveTask](tache.WithWorks(conf.Conf.Tasks.Move.Workers), tache.WithPersistFunction(db.GetTaskDataFunc("move", conf.Conf.Tasks.Move.TaskPersistant), db.UpdateTaskDataFunc("move", conf.Conf.Tasks.Move.TaskPersistant)), tache.WithMaxRetry(conf.Conf.Tasks.Move.MaxRetry))
 	tool.DownloadTaskManager = tache.NewManager[*tool.DownloadTask](tache.WithWorks(conf.Conf.Tasks.Download.Workers), tache.WithPersistFunction(db.GetTaskDataFunc("download", conf.Conf.Tasks.Download.TaskPersistant), db.UpdateTaskDataFunc("download", conf.Conf.Tasks.Download.TaskPersistant)), tache.WithMaxRetry(conf.Conf.Tasks.Download.MaxRetry))
 	tool.TransferTaskManager = tache.NewManager[*tool.TransferTask](tache.WithWorks(conf.Conf.Tasks.Transfer.Workers), tache.WithPersistFunction(db.GetTaskDataFunc("transfer", conf.Conf.Tasks.Transfer.TaskPersistant), db.UpdateTaskDataFunc("transfer", conf.Conf.Tasks.Transfer.TaskPersistant)), tache.WithMaxRetry(conf.Conf.Tasks.Transfer.MaxRetry))
+	dedup.TaskManager = tache.NewManager[*dedup.Task](tache.WithWorks(conf.Conf.Tasks.Dedup.Workers), tache.WithPersistFunction(db.GetTaskDataFunc("dedup", conf.Conf.Tasks.Dedup.TaskPersistant), db.UpdateTaskDataFunc("dedup", conf.Conf.Tasks.Dedup.TaskPersistant)), tache.WithMaxRetry(conf.Conf.Tasks.Dedup.MaxRetry))
+	fs.ExtractTaskManager = tache.NewManager[*fs.ExtractTask](tache.WithWorks(conf.Conf.Tasks.Extract.Workers), tache.WithPersistFunction(db.GetTaskDataFunc("extract", conf.Conf.Tasks.Extract.TaskPersistant), db.UpdateTaskDataFunc("extract", conf.Conf.Tasks.Extract.TaskPersistant)), tache.WithMaxRetry(conf.Conf.Tasks.Extract.MaxRetry))
+	fs.WarmUpTaskManager = tache.NewManager[*fs.WarmUpTask](tache.WithWorks(conf.Conf.Tasks.WarmUp.Workers), tache.WithPersistFunction(db.GetTaskDataFunc("warm_up", conf.Conf.Tasks.WarmUp.TaskPersistant), db.UpdateTaskDataFunc("warm_up", conf.Conf.Tasks.WarmUp.TaskPersistant)), tache.WithMaxRetry(conf.Conf.Tasks.WarmUp.MaxRetry))
 	if len(tool.TransferTaskManager.GetAll()) == 0 { //prevent offline downloaded files from being deleted
 		CleanTempDir()
 	}
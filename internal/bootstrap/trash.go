@@ -0,0 +1,16 @@
+package bootstrap
+
+import (
+	"context"
+	"time"
+
+	"github.com/alist-org/alist/v3/internal/op"
+	"github.com/alist-org/alist/v3/pkg/cron"
+)
+
+// InitTrashCleaner purges the expired objects in the recycle bins hourly
+func InitTrashCleaner() {
+	cron.NewCron(time.Hour).Do(func() {
+		op.CleanExpiredTrash(context.Background())
+	})
+}
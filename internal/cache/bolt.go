@@ -0,0 +1,120 @@
+package cache
+
+import (
+	"bytes"
+	"encoding/binary"
+	"time"
+
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+	bolt "go.etcd.io/bbolt"
+)
+
+var bucketName = []byte("cache")
+
+// boltBackend stores the values in a bbolt file, the values are prefixed
+// with the unix nano expiration time and the expired ones are removed
+// periodically
+type boltBackend struct {
+	db   *bolt.DB
+	done chan struct{}
+}
+
+func NewBolt(path string) (Backend, error) {
+	db, err := bolt.Open(path, 0600, &bolt.Options{Timeout: time.Second})
+	if err != nil {
+		return nil, errors.WithMessagef(err, "failed open cache file %s", path)
+	}
+	err = db.Update(func(tx *bolt.Tx) error {
+		_, err := tx.CreateBucketIfNotExists(bucketName)
+		return err
+	})
+	if err != nil {
+		_ = db.Close()
+		return nil, errors.WithStack(err)
+	}
+	b := &boltBackend{db: db, done: make(chan struct{})}
+	go b.cleanLoop(10 * time.Minute)
+	return b, nil
+}
+
+func (b *boltBackend) Get(key string) ([]byte, bool, error) {
+	var value []byte
+	err := b.db.View(func(tx *bolt.Tx) error {
+		data := tx.Bucket(bucketName).Get([]byte(key))
+		if len(data) < 8 || expired(data) {
+			return nil
+		}
+		// the data is only valid in the transaction
+		value = bytes.Clone(data[8:])
+		return nil
+	})
+	return value, value != nil, errors.WithStack(err)
+}
+
+func (b *boltBackend) Set(key string, value []byte, ttl time.Duration) error {
+	data := make([]byte, 8+len(value))
+	binary.BigEndian.PutUint64(data, uint64(time.Now().Add(ttl).UnixNano()))
+	copy(data[8:], value)
+	return errors.WithStack(b.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(bucketName).Put([]byte(key), data)
+	}))
+}
+
+func (b *boltBackend) Del(key string) error {
+	return errors.WithStack(b.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(bucketName).Delete([]byte(key))
+	}))
+}
+
+func (b *boltBackend) DelPrefix(prefix string) error {
+	p := []byte(prefix)
+	return errors.WithStack(b.db.Update(func(tx *bolt.Tx) error {
+		c := tx.Bucket(bucketName).Cursor()
+		for k, _ := c.Seek(p); k != nil && bytes.HasPrefix(k, p); k, _ = c.Next() {
+			if err := c.Delete(); err != nil {
+				return err
+			}
+		}
+		return nil
+	}))
+}
+
+func (b *boltBackend) Close() error {
+	close(b.done)
+	return b.db.Close()
+}
+
+func (b *boltBackend) cleanLoop(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-b.done:
+			return
+		case <-ticker.C:
+			if err := b.clean(); err != nil {
+				log.Warnf("failed clean expired cache: %+v", err)
+			}
+		}
+	}
+}
+
+// clean removes the expired values
+func (b *boltBackend) clean() error {
+	return b.db.Update(func(tx *bolt.Tx) error {
+		c := tx.Bucket(bucketName).Cursor()
+		for k, v := c.First(); k != nil; k, v = c.Next() {
+			if len(v) < 8 || expired(v) {
+				if err := c.Delete(); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	})
+}
+
+func expired(data []byte) bool {
+	return int64(binary.BigEndian.Uint64(data)) < time.Now().UnixNano()
+}
@@ -0,0 +1,32 @@
+package cache
+
+import (
+	"time"
+)
+
+// Cache is a typed key-value cache with expiration,
+// op.listCache and op.linkCache are built on it
+type Cache[V any] interface {
+	Get(key string) (V, bool)
+	// Set stores the value for ttl, a non-positive ttl deletes the key
+	Set(key string, value V, ttl time.Duration)
+	Del(key string)
+	Clear()
+}
+
+// Backend is a byte-level store that the persistent caches are built on,
+// the keys of different caches are separated by prefix
+type Backend interface {
+	Get(key string) ([]byte, bool, error)
+	Set(key string, value []byte, ttl time.Duration) error
+	Del(key string) error
+	// DelPrefix deletes all the keys with the prefix
+	DelPrefix(prefix string) error
+	Close() error
+}
+
+// Codec converts the values of a cache to bytes and back
+type Codec[V any] interface {
+	Encode(value V) ([]byte, error)
+	Decode(data []byte) (V, error)
+}
@@ -0,0 +1,120 @@
+package cache_test
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/alist-org/alist/v3/internal/cache"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/pkg/utils"
+	"github.com/redis/go-redis/v9"
+)
+
+type localFile struct {
+	model.Object
+	Extra string
+	inode uint64
+}
+
+func testBackends(t *testing.T) map[string]cache.Backend {
+	bolt, err := cache.NewBolt(filepath.Join(t.TempDir(), "cache.db"))
+	if err != nil {
+		t.Fatalf("failed open bolt: %+v", err)
+	}
+	mr := miniredis.RunT(t)
+	rds, err := cache.NewRedis(&redis.Options{Addr: mr.Addr()}, "alist:")
+	if err != nil {
+		t.Fatalf("failed connect redis: %+v", err)
+	}
+	t.Cleanup(func() {
+		_ = bolt.Close()
+		_ = rds.Close()
+	})
+	return map[string]cache.Backend{"bolt": bolt, "redis": rds}
+}
+
+func TestStoreObjs(t *testing.T) {
+	modified := time.Unix(1700000000, 0)
+	for name, backend := range testBackends(t) {
+		c := cache.NewStore[[]model.Obj](backend, "list", cache.ObjsCodec{})
+		objs := []model.Obj{
+			&model.ObjWrapName{Name: "a.txt", Obj: &model.Object{
+				Name: "a.txt", Size: 10, Modified: modified,
+				HashInfo: utils.NewHashInfo(utils.MD5, "bf13fc19e5151ac57d4252e0e0f87abe"),
+			}},
+			&model.ObjThumb{Object: model.Object{Name: "b.png", IsFolder: false}, Thumbnail: model.Thumbnail{Thumbnail: "http://thumb"}},
+		}
+		c.Set("/a", objs, time.Minute)
+		got, ok := c.Get("/a")
+		if !ok || len(got) != 2 {
+			t.Fatalf("%s: expect 2 objs, got %v", name, got)
+		}
+		w, ok := got[0].(*model.ObjWrapName)
+		if !ok || w.Name != "a.txt" || w.GetSize() != 10 || !w.ModTime().Equal(modified) {
+			t.Errorf("%s: wrapped obj mismatch: %+v", name, got[0])
+		}
+		if w.GetHash().GetHash(utils.MD5) != "bf13fc19e5151ac57d4252e0e0f87abe" {
+			t.Errorf("%s: hash lost: %+v", name, w.GetHash())
+		}
+		if thumb, ok := got[1].(*model.ObjThumb); !ok || thumb.Thumbnail.Thumbnail != "http://thumb" {
+			t.Errorf("%s: thumb obj mismatch: %+v", name, got[1])
+		}
+
+		// the obj with unexported fields is kept in memory as is
+		local := &localFile{Object: model.Object{Name: "c"}, inode: 42}
+		c.Set("/b", []model.Obj{local}, time.Minute)
+		got, ok = c.Get("/b")
+		if !ok || got[0] != local {
+			t.Errorf("%s: expect the same obj from memory, got %+v", name, got)
+		}
+
+		c.Del("/a")
+		if _, ok = c.Get("/a"); ok {
+			t.Errorf("%s: expect deleted", name)
+		}
+		c.Set("/a", objs, time.Minute)
+		c.Set("/a", objs, 0)
+		if _, ok = c.Get("/a"); ok {
+			t.Errorf("%s: expect deleted by non-positive ttl", name)
+		}
+	}
+}
+
+func TestStoreClear(t *testing.T) {
+	for name, backend := range testBackends(t) {
+		list := cache.NewStore[[]model.Obj](backend, "list", cache.ObjsCodec{})
+		link := cache.NewStore[*model.Link](backend, "link", cache.LinkCodec{})
+		list.Set("/a", []model.Obj{&model.Object{Name: "a"}}, time.Minute)
+		list.Set("/b", []model.Obj{&model.Object{Name: "b"}}, time.Minute)
+		link.Set("/a", &model.Link{URL: "http://a"}, time.Minute)
+		list.Clear()
+		if _, ok := list.Get("/a"); ok {
+			t.Errorf("%s: expect cleared", name)
+		}
+		if _, ok := list.Get("/b"); ok {
+			t.Errorf("%s: expect cleared", name)
+		}
+		if l, ok := link.Get("/a"); !ok || l.URL != "http://a" {
+			t.Errorf("%s: the other cache should be kept, got %+v", name, l)
+		}
+	}
+}
+
+func TestBoltExpire(t *testing.T) {
+	backend, err := cache.NewBolt(filepath.Join(t.TempDir(), "cache.db"))
+	if err != nil {
+		t.Fatalf("failed open bolt: %+v", err)
+	}
+	defer backend.Close()
+	c := cache.NewStore[*model.Link](backend, "link", cache.LinkCodec{})
+	c.Set("/a", &model.Link{URL: "http://a"}, 50*time.Millisecond)
+	if _, ok := c.Get("/a"); !ok {
+		t.Fatalf("expect cached")
+	}
+	time.Sleep(100 * time.Millisecond)
+	if _, ok := c.Get("/a"); ok {
+		t.Errorf("expect expired")
+	}
+}
@@ -0,0 +1,219 @@
+package cache
+
+import (
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"sync"
+	"time"
+
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/pkg/utils"
+	"github.com/pkg/errors"
+)
+
+// objTypes maps the type names to the concrete types of model.Obj, the
+// drivers get their own obj types back so that type assertions still work.
+// The types are registered when they are encoded, the model types and the
+// ones registered by RegisterObjType can be decoded right after restart.
+var objTypes sync.Map
+
+func typeName(t reflect.Type) string {
+	if t.Kind() == reflect.Pointer {
+		return "*" + t.Elem().PkgPath() + "." + t.Elem().Name()
+	}
+	return t.PkgPath() + "." + t.Name()
+}
+
+// RegisterObjType registers the concrete type of obj to the codec
+func RegisterObjType(obj model.Obj) string {
+	t := reflect.TypeOf(obj)
+	name := typeName(t)
+	objTypes.LoadOrStore(name, t)
+	return name
+}
+
+func init() {
+	RegisterObjType(&model.Object{})
+	RegisterObjType(&model.ObjThumb{})
+	RegisterObjType(&model.ObjectURL{})
+	RegisterObjType(&model.ObjThumbURL{})
+}
+
+var encodableTypes sync.Map
+
+// encodable reports whether the obj of type t is the same after
+// json round trip, the unexported or ignored fields would be lost
+func encodable(t reflect.Type) bool {
+	if v, ok := encodableTypes.Load(t); ok {
+		return v.(bool)
+	}
+	ok := t.Kind() == reflect.Pointer && t.Elem().Kind() == reflect.Struct && fieldsEncodable(t.Elem())
+	encodableTypes.Store(t, ok)
+	return ok
+}
+
+func fieldsEncodable(t reflect.Type) bool {
+	if reflect.PointerTo(t).Implements(reflect.TypeOf((*json.Unmarshaler)(nil)).Elem()) {
+		return true
+	}
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		// the interfaces can't be decoded
+		if f.Tag.Get("json") == "-" || f.Type.Kind() == reflect.Interface {
+			return false
+		}
+		if f.Anonymous {
+			ft := f.Type
+			if ft.Kind() == reflect.Pointer {
+				ft = ft.Elem()
+			}
+			if ft.Kind() == reflect.Struct && !fieldsEncodable(ft) {
+				return false
+			}
+			if ft.Kind() != reflect.Struct && !f.IsExported() {
+				return false
+			}
+			continue
+		}
+		if !f.IsExported() {
+			return false
+		}
+	}
+	return true
+}
+
+type encodedObj struct {
+	Type string          `json:"t"`
+	Data json.RawMessage `json:"d"`
+	// the hashes are lost in Data since utils.HashInfo has no exported fields
+	Hash string `json:"h,omitempty"`
+	// wrapped by model.ObjWrapName
+	Wrapped  bool   `json:"w,omitempty"`
+	WrapName string `json:"n,omitempty"`
+}
+
+type ObjsCodec struct{}
+
+func (ObjsCodec) Encode(objs []model.Obj) ([]byte, error) {
+	res := make([]encodedObj, 0, len(objs))
+	for _, obj := range objs {
+		var e encodedObj
+		if w, ok := obj.(*model.ObjWrapName); ok {
+			e.Wrapped = true
+			e.WrapName = w.Name
+			obj = w.Obj
+		}
+		if _, ok := obj.(model.ObjUnwrap); ok {
+			return nil, errors.Errorf("unsupported nested obj %T", obj)
+		}
+		if !encodable(reflect.TypeOf(obj)) {
+			return nil, errors.Errorf("unsupported obj type %T", obj)
+		}
+		data, err := json.Marshal(obj)
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+		e.Type = RegisterObjType(obj)
+		e.Data = data
+		if len(obj.GetHash().Export()) > 0 {
+			e.Hash = obj.GetHash().String()
+		}
+		res = append(res, e)
+	}
+	data, err := json.Marshal(res)
+	return data, errors.WithStack(err)
+}
+
+func (ObjsCodec) Decode(data []byte) ([]model.Obj, error) {
+	var encoded []encodedObj
+	if err := json.Unmarshal(data, &encoded); err != nil {
+		return nil, errors.WithStack(err)
+	}
+	objs := make([]model.Obj, 0, len(encoded))
+	for _, e := range encoded {
+		t, ok := objTypes.Load(e.Type)
+		if !ok {
+			return nil, errors.Errorf("unknown obj type %s", e.Type)
+		}
+		v := reflect.New(t.(reflect.Type).Elem())
+		if err := json.Unmarshal(e.Data, v.Interface()); err != nil {
+			return nil, errors.WithStack(err)
+		}
+		if e.Hash != "" {
+			setHash(v.Elem(), utils.FromString(e.Hash))
+		}
+		obj := v.Interface().(model.Obj)
+		if e.Wrapped {
+			obj = &model.ObjWrapName{Name: e.WrapName, Obj: obj}
+		}
+		objs = append(objs, obj)
+	}
+	return objs, nil
+}
+
+var hashInfoType = reflect.TypeOf(utils.HashInfo{})
+
+// setHash sets the first utils.HashInfo field of struct v, the embedded structs included
+func setHash(v reflect.Value, hi utils.HashInfo) bool {
+	for i := 0; i < v.NumField(); i++ {
+		f := v.Field(i)
+		switch {
+		case f.Type() == hashInfoType && f.CanSet():
+			f.Set(reflect.ValueOf(hi))
+			return true
+		case v.Type().Field(i).Anonymous && f.Kind() == reflect.Struct:
+			if setHash(f, hi) {
+				return true
+			}
+		case v.Type().Field(i).Anonymous && f.Kind() == reflect.Pointer && !f.IsNil() && f.Elem().Kind() == reflect.Struct:
+			if setHash(f.Elem(), hi) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+type encodedLink struct {
+	URL         string         `json:"url"`
+	Header      http.Header    `json:"header"`
+	Expiration  *time.Duration `json:"expiration"`
+	IPCacheKey  bool           `json:"ip_cache_key"`
+	Concurrency int            `json:"concurrency"`
+	PartSize    int            `json:"part_size"`
+}
+
+// LinkCodec only encodes the links of url,
+// the links with readers are kept in process memory
+type LinkCodec struct{}
+
+func (LinkCodec) Encode(link *model.Link) ([]byte, error) {
+	if link.MFile != nil || link.RangeReadCloser != nil {
+		return nil, errors.New("link with reader can't be encoded")
+	}
+	data, err := json.Marshal(encodedLink{
+		URL:         link.URL,
+		Header:      link.Header,
+		Expiration:  link.Expiration,
+		IPCacheKey:  link.IPCacheKey,
+		Concurrency: link.Concurrency,
+		PartSize:    link.PartSize,
+	})
+	return data, errors.WithStack(err)
+}
+
+func (LinkCodec) Decode(data []byte) (*model.Link, error) {
+	var e encodedLink
+	if err := json.Unmarshal(data, &e); err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return &model.Link{
+		URL:         e.URL,
+		Header:      e.Header,
+		Expiration:  e.Expiration,
+		IPCacheKey:  e.IPCacheKey,
+		Concurrency: e.Concurrency,
+		PartSize:    e.PartSize,
+	}, nil
+}
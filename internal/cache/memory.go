@@ -0,0 +1,32 @@
+package cache
+
+import (
+	"time"
+
+	"github.com/Xhofe/go-cache"
+)
+
+type memoryCache[V any] struct {
+	c cache.ICache[V]
+}
+
+// NewMemory returns a process local cache which stores the values as is
+func NewMemory[V any](shards int) Cache[V] {
+	return &memoryCache[V]{c: cache.NewMemCache(cache.WithShards[V](shards))}
+}
+
+func (m *memoryCache[V]) Get(key string) (V, bool) {
+	return m.c.Get(key)
+}
+
+func (m *memoryCache[V]) Set(key string, value V, ttl time.Duration) {
+	m.c.Set(key, value, cache.WithEx[V](ttl))
+}
+
+func (m *memoryCache[V]) Del(key string) {
+	m.c.Del(key)
+}
+
+func (m *memoryCache[V]) Clear() {
+	m.c.Clear()
+}
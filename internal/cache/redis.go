@@ -0,0 +1,87 @@
+package cache
+
+import (
+	"context"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/redis/go-redis/v9"
+)
+
+// redisBackend stores the values in redis so that
+// several alist instances can share the cache
+type redisBackend struct {
+	client *redis.Client
+	prefix string
+}
+
+// NewRedis connects to the redis server, all the keys are prefixed with
+// prefix so that the redis can be shared with other applications
+func NewRedis(opts *redis.Options, prefix string) (Backend, error) {
+	client := redis.NewClient(opts)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := client.Ping(ctx).Err(); err != nil {
+		_ = client.Close()
+		return nil, errors.WithMessagef(err, "failed connect redis %s", opts.Addr)
+	}
+	return &redisBackend{client: client, prefix: prefix}, nil
+}
+
+func (r *redisBackend) Get(key string) ([]byte, bool, error) {
+	data, err := r.client.Get(context.Background(), r.prefix+key).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, errors.WithStack(err)
+	}
+	return data, true, nil
+}
+
+func (r *redisBackend) Set(key string, value []byte, ttl time.Duration) error {
+	return errors.WithStack(r.client.Set(context.Background(), r.prefix+key, value, ttl).Err())
+}
+
+func (r *redisBackend) Del(key string) error {
+	return errors.WithStack(r.client.Del(context.Background(), r.prefix+key).Err())
+}
+
+func (r *redisBackend) DelPrefix(prefix string) error {
+	ctx := context.Background()
+	iter := r.client.Scan(ctx, 0, escapePattern(r.prefix+prefix)+"*", 1000).Iterator()
+	var keys []string
+	for iter.Next(ctx) {
+		keys = append(keys, iter.Val())
+		if len(keys) >= 1000 {
+			if err := r.client.Del(ctx, keys...).Err(); err != nil {
+				return errors.WithStack(err)
+			}
+			keys = keys[:0]
+		}
+	}
+	if err := iter.Err(); err != nil {
+		return errors.WithStack(err)
+	}
+	if len(keys) > 0 {
+		return errors.WithStack(r.client.Del(ctx, keys...).Err())
+	}
+	return nil
+}
+
+func (r *redisBackend) Close() error {
+	return r.client.Close()
+}
+
+// escapePattern escapes the glob characters of redis
+func escapePattern(s string) string {
+	var b []byte
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '*', '?', '[', ']', '\\':
+			b = append(b, '\\')
+		}
+		b = append(b, s[i])
+	}
+	return string(b)
+}
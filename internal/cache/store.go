@@ -0,0 +1,81 @@
+package cache
+
+import (
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+type storeCache[V any] struct {
+	backend Backend
+	prefix  string
+	codec   Codec[V]
+	// values that can't be encoded are kept in process memory
+	fallback Cache[V]
+}
+
+// NewStore returns a cache which stores the values to backend with codec,
+// name is used as the key prefix so that caches can share a backend
+func NewStore[V any](backend Backend, name string, codec Codec[V]) Cache[V] {
+	return &storeCache[V]{
+		backend:  backend,
+		prefix:   name + ":",
+		codec:    codec,
+		fallback: NewMemory[V](16),
+	}
+}
+
+func (s *storeCache[V]) Get(key string) (V, bool) {
+	if v, ok := s.fallback.Get(key); ok {
+		return v, true
+	}
+	var zero V
+	data, ok, err := s.backend.Get(s.prefix + key)
+	if err != nil {
+		log.Warnf("failed get cache %s%s: %+v", s.prefix, key, err)
+		return zero, false
+	}
+	if !ok {
+		return zero, false
+	}
+	v, err := s.codec.Decode(data)
+	if err != nil {
+		log.Debugf("failed decode cache %s%s: %+v", s.prefix, key, err)
+		return zero, false
+	}
+	return v, true
+}
+
+func (s *storeCache[V]) Set(key string, value V, ttl time.Duration) {
+	if ttl <= 0 {
+		s.Del(key)
+		return
+	}
+	data, err := s.codec.Encode(value)
+	if err != nil {
+		log.Debugf("cache %s%s in memory since it can't be encoded: %+v", s.prefix, key, err)
+		if err = s.backend.Del(s.prefix + key); err != nil {
+			log.Warnf("failed del cache %s%s: %+v", s.prefix, key, err)
+		}
+		s.fallback.Set(key, value, ttl)
+		return
+	}
+	s.fallback.Del(key)
+	if err = s.backend.Set(s.prefix+key, data, ttl); err != nil {
+		log.Warnf("failed set cache %s%s: %+v", s.prefix, key, err)
+	}
+}
+
+func (s *storeCache[V]) Del(key string) {
+	s.fallback.Del(key)
+	if err := s.backend.Del(s.prefix + key); err != nil {
+		log.Warnf("failed del cache %s%s: %+v", s.prefix, key, err)
+	}
+}
+
+func (s *storeCache[V]) Clear() {
+	s.fallback.Clear()
+	if err := s.backend.DelPrefix(s.prefix); err != nil {
+		log.Warnf("failed clear cache %s: %+v", s.prefix, err)
+	}
+}
@@ -57,6 +57,10 @@ type TasksConfig struct {
 	Transfer TaskConfig `json:"transfer" envPrefix:"TRANSFER_"`
 	Upload   TaskConfig `json:"upload" envPrefix:"UPLOAD_"`
 	Copy     TaskConfig `json:"copy" envPrefix:"COPY_"`
+	Move     TaskConfig `json:"move" envPrefix:"MOVE_"`
+	Dedup    TaskConfig `json:"dedup" envPrefix:"DEDUP_"`
+	Extract  TaskConfig `json:"extract" envPrefix:"EXTRACT_"`
+	WarmUp   TaskConfig `json:"warm_up" envPrefix:"WARM_UP_"`
 }
 
 type Cors struct {
@@ -71,6 +75,31 @@ type S3 struct {
 	SSL    bool `json:"ssl" env:"SSL"`
 }
 
+type FTP struct {
+	Enable        bool   `json:"enable" env:"ENABLE"`
+	Port          int    `json:"port" env:"PORT"`
+	PublicHost    string `json:"public_host" env:"PUBLIC_HOST"`
+	PasvPortRange string `json:"pasv_port_range" env:"PASV_PORT_RANGE"`
+	TLS           bool   `json:"tls" env:"TLS"`
+	IdleTimeout   int    `json:"idle_timeout" env:"IDLE_TIMEOUT"`
+}
+
+type SFTP struct {
+	Enable bool `json:"enable" env:"ENABLE"`
+	Port   int  `json:"port" env:"PORT"`
+}
+
+type Cache struct {
+	// memory, bolt or redis
+	Type           string `json:"type" env:"TYPE"`
+	BoltFile       string `json:"bolt_file" env:"BOLT_FILE"`
+	RedisAddr      string `json:"redis_addr" env:"REDIS_ADDR"`
+	RedisUsername  string `json:"redis_username" env:"REDIS_USERNAME"`
+	RedisPassword  string `json:"redis_password" env:"REDIS_PASSWORD"`
+	RedisDB        int    `json:"redis_db" env:"REDIS_DB"`
+	RedisKeyPrefix string `json:"redis_key_prefix" env:"REDIS_KEY_PREFIX"`
+}
+
 type Config struct {
 	Force                 bool        `json:"force" env:"FORCE"`
 	SiteURL               string      `json:"site_url" env:"SITE_URL"`
@@ -90,6 +119,9 @@ type Config struct {
 	Tasks                 TasksConfig `json:"tasks" envPrefix:"TASKS_"`
 	Cors                  Cors        `json:"cors" envPrefix:"CORS_"`
 	S3                    S3          `json:"s3" envPrefix:"S3_"`
+	FTP                   FTP         `json:"ftp" envPrefix:"FTP_"`
+	SFTP                  SFTP        `json:"sftp" envPrefix:"SFTP_"`
+	Cache                 Cache       `json:"cache" envPrefix:"CACHE_"`
 }
 
 func DefaultConfig() *Config {
@@ -97,6 +129,7 @@ func DefaultConfig() *Config {
 	indexDir := filepath.Join(flags.DataDir, "bleve")
 	logPath := filepath.Join(flags.DataDir, "log/log.log")
 	dbPath := filepath.Join(flags.DataDir, "data.db")
+	cachePath := filepath.Join(flags.DataDir, "cache.db")
 	return &Config{
 		Scheme: Scheme{
 			Address:    "0.0.0.0",
@@ -148,6 +181,25 @@ func DefaultConfig() *Config {
 				MaxRetry:       2,
 				TaskPersistant: true,
 			},
+			Move: TaskConfig{
+				Workers:        5,
+				MaxRetry:       2,
+				TaskPersistant: true,
+			},
+			Dedup: TaskConfig{
+				Workers:        1,
+				TaskPersistant: true,
+			},
+			Extract: TaskConfig{
+				Workers:        5,
+				MaxRetry:       1,
+				TaskPersistant: true,
+			},
+			WarmUp: TaskConfig{
+				Workers:        1,
+				MaxRetry:       1,
+				TaskPersistant: true,
+			},
 		},
 		Cors: Cors{
 			AllowOrigins: []string{"*"},
@@ -159,5 +211,23 @@ func DefaultConfig() *Config {
 			Port:   5246,
 			SSL:    false,
 		},
+		FTP: FTP{
+			Enable:        false,
+			Port:          5221,
+			PublicHost:    "",
+			PasvPortRange: "",
+			TLS:           false,
+			IdleTimeout:   900,
+		},
+		SFTP: SFTP{
+			Enable: false,
+			Port:   5222,
+		},
+		Cache: Cache{
+			Type:           "memory",
+			BoltFile:       cachePath,
+			RedisAddr:      "localhost:6379",
+			RedisKeyPrefix: "alist:",
+		},
 	}
 }
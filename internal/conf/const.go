@@ -43,12 +43,28 @@ const (
 	ForwardDirectLinkParams = "forward_direct_link_params"
 	IgnoreDirectLinkParams  = "ignore_direct_link_params"
 	WebauthnLoginEnabled    = "webauthn_login_enabled"
+	RecycleBinEnabled       = "recycle_bin_enabled"
+	RecycleBinDir           = "recycle_bin_dir"
+	RecycleBinRetention     = "recycle_bin_retention"
+	AuditLogEnabled         = "audit_log_enabled"
+	AuditLogFile            = "audit_log_file"
+	CopyVerify              = "copy_verify"
+	DownloadLimit           = "download_limit"
+	UploadLimit             = "upload_limit"
 
 	// index
 	SearchIndex     = "search_index"
 	AutoUpdateIndex = "auto_update_index"
 	IgnorePaths     = "ignore_paths"
 	MaxIndexDepth   = "max_index_depth"
+	// minutes between the incremental updates, 0 to disable
+	IndexUpdateInterval = "index_update_interval"
+	// hours before the unchanged dirs are listed again
+	IndexMaxAge = "index_max_age"
+	// index the text of files, only supported by bleve and meilisearch
+	IndexContent = "index_content"
+	// MB, the larger files are not read for the content
+	IndexContentMaxSize = "index_content_max_size"
 
 	// aria2
 	Aria2Uri    = "aria2_uri"
@@ -76,6 +92,7 @@ const (
 	SSODefaultDir        = "sso_default_dir"
 	SSODefaultPermission = "sso_default_permission"
 	SSOCompatibilityMode = "sso_compatibility_mode"
+	SSOOIDCGroupsKey     = "sso_oidc_groups_key"
 
 	// ldap
 	LdapLoginEnabled      = "ldap_login_enabled"
@@ -87,11 +104,13 @@ const (
 	LdapDefaultPermission = "ldap_default_permission"
 	LdapDefaultDir        = "ldap_default_dir"
 	LdapLoginTips         = "ldap_login_tips"
+	LdapGroupAttribute    = "ldap_group_attribute"
 
 	// s3
 	S3Buckets         = "s3_buckets"
 	S3AccessKeyId     = "s3_access_key_id"
 	S3SecretAccessKey = "s3_secret_access_key"
+	S3User            = "s3_user"
 
 	// qbittorrent
 	QbittorrentUrl      = "qbittorrent_url"
@@ -111,4 +130,10 @@ const (
 // ContextKey is the type of context keys.
 const (
 	NoTaskKey = "no_task"
+	// the children of dir are copied in the copy task instead of their own tasks,
+	// so the task finishes after all of them
+	SingleTaskKey = "single_task"
+	// the frontend and the ip of the client, for audit log
+	FrontendKey = "frontend"
+	ClientIPKey = "client_ip"
 )
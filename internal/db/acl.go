@@ -0,0 +1,50 @@
+package db
+
+import (
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/pkg/errors"
+)
+
+func GetACLById(id uint) (*model.ACL, error) {
+	var a model.ACL
+	if err := db.First(&a, id).Error; err != nil {
+		return nil, errors.Wrapf(err, "failed get old acl")
+	}
+	return &a, nil
+}
+
+func GetACLsBySubject(subjectType int, subjectId uint) ([]model.ACL, error) {
+	var acls []model.ACL
+	if err := db.Where("subject_type = ? AND subject_id = ?", subjectType, subjectId).Find(&acls).Error; err != nil {
+		return nil, errors.Wrapf(err, "failed find acls")
+	}
+	return acls, nil
+}
+
+func CreateACL(a *model.ACL) error {
+	return errors.WithStack(db.Create(a).Error)
+}
+
+func UpdateACL(a *model.ACL) error {
+	return errors.WithStack(db.Save(a).Error)
+}
+
+func GetACLs(pageIndex, pageSize int) (acls []model.ACL, count int64, err error) {
+	aclDB := db.Model(&model.ACL{})
+	if err = aclDB.Count(&count).Error; err != nil {
+		return nil, 0, errors.Wrapf(err, "failed get acls count")
+	}
+	if err = aclDB.Order(columnName("id")).Offset((pageIndex - 1) * pageSize).Limit(pageSize).Find(&acls).Error; err != nil {
+		return nil, 0, errors.Wrapf(err, "failed find acls")
+	}
+	return acls, count, nil
+}
+
+func DeleteACLById(id uint) error {
+	return errors.WithStack(db.Delete(&model.ACL{}, id).Error)
+}
+
+func DeleteACLsBySubject(subjectType int, subjectId uint) error {
+	return errors.WithStack(db.Where("subject_type = ? AND subject_id = ?", subjectType, subjectId).
+		Delete(&model.ACL{}).Error)
+}
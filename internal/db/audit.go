@@ -0,0 +1,61 @@
+package db
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/pkg/errors"
+	"gorm.io/gorm"
+)
+
+func CreateAuditLogs(logs []model.AuditLog) error {
+	return errors.WithStack(db.CreateInBatches(logs, 100).Error)
+}
+
+func filterAuditLogs(filter *model.AuditLogFilter) *gorm.DB {
+	auditDB := db.Model(&model.AuditLog{})
+	if filter.Username != "" {
+		auditDB = auditDB.Where("username = ?", filter.Username)
+	}
+	if filter.IP != "" {
+		auditDB = auditDB.Where("ip = ?", filter.IP)
+	}
+	if filter.Frontend != "" {
+		auditDB = auditDB.Where("frontend = ?", filter.Frontend)
+	}
+	if filter.Operation != "" {
+		auditDB = auditDB.Where("operation = ?", filter.Operation)
+	}
+	if filter.Path != "" && filter.Path != "/" {
+		p := strings.TrimSuffix(filter.Path, "/")
+		auditDB = auditDB.Where(fmt.Sprintf("(%[1]s = ? OR %[1]s LIKE ? ESCAPE '!' OR dst_path = ? OR dst_path LIKE ? ESCAPE '!')", columnName("path")),
+			p, subPathPattern(p), p, subPathPattern(p))
+	}
+	if filter.Success != nil {
+		auditDB = auditDB.Where("success = ?", *filter.Success)
+	}
+	if filter.Start != nil {
+		auditDB = auditDB.Where(columnName("time")+" >= ?", *filter.Start)
+	}
+	if filter.End != nil {
+		auditDB = auditDB.Where(columnName("time")+" < ?", *filter.End)
+	}
+	return auditDB
+}
+
+func GetAuditLogs(filter *model.AuditLogFilter, pageIndex, pageSize int) (logs []model.AuditLog, count int64, err error) {
+	auditDB := filterAuditLogs(filter)
+	if err = auditDB.Count(&count).Error; err != nil {
+		return nil, 0, errors.Wrapf(err, "failed get audit logs count")
+	}
+	if err = auditDB.Order(columnName("id") + " DESC").Offset((pageIndex - 1) * pageSize).Limit(pageSize).Find(&logs).Error; err != nil {
+		return nil, 0, errors.Wrapf(err, "failed find audit logs")
+	}
+	return logs, count, nil
+}
+
+func DeleteAuditLogsBefore(t time.Time) error {
+	return errors.WithStack(db.Where(columnName("time")+" < ?", t).Delete(&model.AuditLog{}).Error)
+}
@@ -12,7 +12,7 @@ var db *gorm.DB
 
 func Init(d *gorm.DB) {
 	db = d
-	err := AutoMigrate(new(model.Storage), new(model.User), new(model.Meta), new(model.SettingItem), new(model.SearchNode), new(model.TaskItem))
+	err := AutoMigrate(new(model.Storage), new(model.User), new(model.Meta), new(model.SettingItem), new(model.SearchNode), new(model.TaskItem), new(model.SSHPublicKey), new(model.ACL), new(model.Group), new(model.UserGroup), new(model.GroupMeta), new(model.Share), new(model.FileRequest), new(model.FileRequestUpload), new(model.TrashItem), new(model.AuditLog), new(model.IndexedDir), new(model.SyncJob), new(model.SyncRun), new(model.Quota), new(model.QuotaUsage))
 	if err != nil {
 		log.Fatalf("failed migrate database: %s", err.Error())
 	}
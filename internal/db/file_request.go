@@ -0,0 +1,100 @@
+package db
+
+import (
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/pkg/errors"
+	"gorm.io/gorm"
+)
+
+func GetFileRequestById(id string) (*model.FileRequest, error) {
+	var r model.FileRequest
+	if err := db.Where("id = ?", id).First(&r).Error; err != nil {
+		return nil, errors.Wrapf(err, "failed get file request")
+	}
+	return &r, nil
+}
+
+func GetFileRequests(pageIndex, pageSize int) (requests []model.FileRequest, count int64, err error) {
+	requestDB := db.Model(&model.FileRequest{})
+	if err = requestDB.Count(&count).Error; err != nil {
+		return nil, 0, errors.Wrapf(err, "failed get file requests count")
+	}
+	if err = requestDB.Order(columnName("created")).Offset((pageIndex - 1) * pageSize).Limit(pageSize).Find(&requests).Error; err != nil {
+		return nil, 0, errors.Wrapf(err, "failed find file requests")
+	}
+	return requests, count, nil
+}
+
+func GetFileRequestsByUserId(userId uint, pageIndex, pageSize int) (requests []model.FileRequest, count int64, err error) {
+	requestDB := db.Model(&model.FileRequest{}).Where("user_id = ?", userId)
+	if err = requestDB.Count(&count).Error; err != nil {
+		return nil, 0, errors.Wrapf(err, "failed get user's file requests count")
+	}
+	if err = requestDB.Order(columnName("created")).Offset((pageIndex - 1) * pageSize).Limit(pageSize).Find(&requests).Error; err != nil {
+		return nil, 0, errors.Wrapf(err, "failed find user's file requests")
+	}
+	return requests, count, nil
+}
+
+func CreateFileRequest(r *model.FileRequest) error {
+	return errors.WithStack(db.Create(r).Error)
+}
+
+// ReserveFileRequestUpload adds an upload of size to the file request if the quotas allow,
+// ok is false if the quotas are exceeded
+func ReserveFileRequestUpload(id string, size int64) (ok bool, err error) {
+	res := db.Model(&model.FileRequest{}).
+		Where("id = ? AND (max_files = 0 OR uploaded_files < max_files) AND (max_size = 0 OR uploaded_size + ? <= max_size)", id, size).
+		Updates(map[string]any{
+			"uploaded_files": gorm.Expr("uploaded_files + 1"),
+			"uploaded_size":  gorm.Expr("uploaded_size + ?", size),
+		})
+	if res.Error != nil {
+		return false, errors.WithStack(res.Error)
+	}
+	return res.RowsAffected == 1, nil
+}
+
+// ReleaseFileRequestUpload reverts ReserveFileRequestUpload of a failed upload
+func ReleaseFileRequestUpload(id string, size int64) error {
+	return errors.WithStack(db.Model(&model.FileRequest{}).Where("id = ?", id).
+		Updates(map[string]any{
+			"uploaded_files": gorm.Expr("uploaded_files - 1"),
+			"uploaded_size":  gorm.Expr("uploaded_size - ?", size),
+		}).Error)
+}
+
+func DeleteFileRequestById(id string) error {
+	return errors.WithStack(db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("request_id = ?", id).Delete(&model.FileRequestUpload{}).Error; err != nil {
+			return err
+		}
+		return tx.Where("id = ?", id).Delete(&model.FileRequest{}).Error
+	}))
+}
+
+func DeleteFileRequestsByUserId(userId uint) error {
+	return errors.WithStack(db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Where("request_id IN (?)", tx.Model(&model.FileRequest{}).Select("id").Where("user_id = ?", userId)).
+			Delete(&model.FileRequestUpload{}).Error
+		if err != nil {
+			return err
+		}
+		return tx.Where("user_id = ?", userId).Delete(&model.FileRequest{}).Error
+	}))
+}
+
+func CreateFileRequestUpload(u *model.FileRequestUpload) error {
+	return errors.WithStack(db.Create(u).Error)
+}
+
+func GetFileRequestUploads(requestId string, pageIndex, pageSize int) (uploads []model.FileRequestUpload, count int64, err error) {
+	uploadDB := db.Model(&model.FileRequestUpload{}).Where("request_id = ?", requestId)
+	if err = uploadDB.Count(&count).Error; err != nil {
+		return nil, 0, errors.Wrapf(err, "failed get uploads count")
+	}
+	if err = uploadDB.Order(columnName("id")).Offset((pageIndex - 1) * pageSize).Limit(pageSize).Find(&uploads).Error; err != nil {
+		return nil, 0, errors.Wrapf(err, "failed find uploads")
+	}
+	return uploads, count, nil
+}
@@ -0,0 +1,121 @@
+package db
+
+import (
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/pkg/errors"
+	"gorm.io/gorm"
+)
+
+func GetGroupById(id uint) (*model.Group, error) {
+	var g model.Group
+	if err := db.First(&g, id).Error; err != nil {
+		return nil, errors.Wrapf(err, "failed get old group")
+	}
+	return &g, nil
+}
+
+func GetGroupByName(name string) (*model.Group, error) {
+	g := model.Group{Name: name}
+	if err := db.Where(g).First(&g).Error; err != nil {
+		return nil, errors.Wrapf(err, "failed find group")
+	}
+	return &g, nil
+}
+
+func CreateGroup(g *model.Group) error {
+	return errors.WithStack(db.Create(g).Error)
+}
+
+func UpdateGroup(g *model.Group) error {
+	return errors.WithStack(db.Save(g).Error)
+}
+
+func GetGroups(pageIndex, pageSize int) (groups []model.Group, count int64, err error) {
+	groupDB := db.Model(&model.Group{})
+	if err = groupDB.Count(&count).Error; err != nil {
+		return nil, 0, errors.Wrapf(err, "failed get groups count")
+	}
+	if err = groupDB.Order(columnName("id")).Offset((pageIndex - 1) * pageSize).Limit(pageSize).Find(&groups).Error; err != nil {
+		return nil, 0, errors.Wrapf(err, "failed find groups")
+	}
+	return groups, count, nil
+}
+
+func GetAllGroups() ([]model.Group, error) {
+	var groups []model.Group
+	if err := db.Order(columnName("id")).Find(&groups).Error; err != nil {
+		return nil, errors.Wrapf(err, "failed find groups")
+	}
+	return groups, nil
+}
+
+func DeleteGroupById(id uint) error {
+	return errors.WithStack(db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("group_id = ?", id).Delete(&model.UserGroup{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("group_id = ?", id).Delete(&model.GroupMeta{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&model.Group{}, id).Error
+	}))
+}
+
+// GetGroupsByUserId returns the groups of user ordered by id
+func GetGroupsByUserId(userId uint) ([]model.Group, error) {
+	var groups []model.Group
+	err := db.Model(&model.Group{}).
+		Where("id IN (?)", db.Model(&model.UserGroup{}).Select("group_id").Where("user_id = ?", userId)).
+		Order(columnName("id")).Find(&groups).Error
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed find groups of user")
+	}
+	return groups, nil
+}
+
+func GetUserIdsByGroupId(groupId uint) ([]uint, error) {
+	var ids []uint
+	if err := db.Model(&model.UserGroup{}).Where("group_id = ?", groupId).Pluck("user_id", &ids).Error; err != nil {
+		return nil, errors.Wrapf(err, "failed find members of group")
+	}
+	return ids, nil
+}
+
+func AddUserToGroups(userId uint, groupIds ...uint) error {
+	if len(groupIds) == 0 {
+		return nil
+	}
+	ugs := make([]model.UserGroup, 0, len(groupIds))
+	for _, id := range groupIds {
+		ugs = append(ugs, model.UserGroup{UserID: userId, GroupID: id})
+	}
+	return errors.WithStack(db.Save(&ugs).Error)
+}
+
+func RemoveUserFromGroups(userId uint, groupIds ...uint) error {
+	if len(groupIds) == 0 {
+		return nil
+	}
+	return errors.WithStack(db.Where("user_id = ? AND group_id IN ?", userId, groupIds).Delete(&model.UserGroup{}).Error)
+}
+
+// SetGroupMembers replaces the members of group with userIds
+func SetGroupMembers(groupId uint, userIds []uint) error {
+	return errors.WithStack(db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("group_id = ?", groupId).Delete(&model.UserGroup{}).Error; err != nil {
+			return err
+		}
+		if len(userIds) == 0 {
+			return nil
+		}
+		ugs := make([]model.UserGroup, 0, len(userIds))
+		for _, id := range userIds {
+			ugs = append(ugs, model.UserGroup{UserID: id, GroupID: groupId})
+		}
+		return tx.Create(&ugs).Error
+	}))
+}
+
+func DeleteUserGroupsByUserId(userId uint) error {
+	return errors.WithStack(db.Where("user_id = ?", userId).Delete(&model.UserGroup{}).Error)
+}
@@ -0,0 +1,34 @@
+package db
+
+import (
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/pkg/errors"
+)
+
+func GetGroupMetaById(id uint) (*model.GroupMeta, error) {
+	var m model.GroupMeta
+	if err := db.First(&m, id).Error; err != nil {
+		return nil, errors.Wrapf(err, "failed get old group meta")
+	}
+	return &m, nil
+}
+
+func GetGroupMetasByGroupId(groupId uint) ([]model.GroupMeta, error) {
+	var metas []model.GroupMeta
+	if err := db.Where("group_id = ?", groupId).Order(columnName("path")).Find(&metas).Error; err != nil {
+		return nil, errors.Wrapf(err, "failed find group metas")
+	}
+	return metas, nil
+}
+
+func CreateGroupMeta(m *model.GroupMeta) error {
+	return errors.WithStack(db.Create(m).Error)
+}
+
+func UpdateGroupMeta(m *model.GroupMeta) error {
+	return errors.WithStack(db.Save(m).Error)
+}
+
+func DeleteGroupMetaById(id uint) error {
+	return errors.WithStack(db.Delete(&model.GroupMeta{}, id).Error)
+}
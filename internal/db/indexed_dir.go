@@ -0,0 +1,39 @@
+package db
+
+import (
+	"fmt"
+
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/pkg/utils"
+	"github.com/pkg/errors"
+	"gorm.io/gorm/clause"
+)
+
+func GetIndexedDirs() ([]model.IndexedDir, error) {
+	var dirs []model.IndexedDir
+	if err := db.Find(&dirs).Error; err != nil {
+		return nil, errors.Wrapf(err, "failed get indexed dirs")
+	}
+	return dirs, nil
+}
+
+func SaveIndexedDir(dir *model.IndexedDir) error {
+	return errors.WithStack(db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "path"}},
+		DoUpdates: clause.AssignmentColumns([]string{"modified", "fingerprint", "indexed"}),
+	}).Create(dir).Error)
+}
+
+// DeleteIndexedDirs deletes the dir and its sub dirs
+func DeleteIndexedDirs(path string) error {
+	path = utils.FixAndCleanPath(path)
+	if path == "/" {
+		return ClearIndexedDirs()
+	}
+	return errors.WithStack(db.Where(fmt.Sprintf("%[1]s = ? OR %[1]s LIKE ? ESCAPE '!'", columnName("path")),
+		path, subPathPattern(path)).Delete(&model.IndexedDir{}).Error)
+}
+
+func ClearIndexedDirs() error {
+	return errors.WithStack(db.Where("1 = 1").Delete(&model.IndexedDir{}).Error)
+}
@@ -0,0 +1,115 @@
+package db
+
+import (
+	"fmt"
+
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/pkg/errors"
+	"gorm.io/gorm"
+)
+
+func GetQuotaById(id uint) (*model.Quota, error) {
+	var q model.Quota
+	if err := db.First(&q, id).Error; err != nil {
+		return nil, errors.Wrapf(err, "failed get quota")
+	}
+	return &q, nil
+}
+
+func GetQuotas(pageIndex, pageSize int) (quotas []model.Quota, count int64, err error) {
+	quotaDB := db.Model(&model.Quota{})
+	if err = quotaDB.Count(&count).Error; err != nil {
+		return nil, 0, errors.Wrapf(err, "failed get quotas count")
+	}
+	if err = quotaDB.Order(columnName("id")).Offset((pageIndex - 1) * pageSize).Limit(pageSize).Find(&quotas).Error; err != nil {
+		return nil, 0, errors.Wrapf(err, "failed find quotas")
+	}
+	return quotas, count, nil
+}
+
+func GetAllQuotas() ([]model.Quota, error) {
+	var quotas []model.Quota
+	if err := db.Find(&quotas).Error; err != nil {
+		return nil, errors.Wrapf(err, "failed find quotas")
+	}
+	return quotas, nil
+}
+
+func CreateQuota(q *model.Quota) error {
+	return errors.WithStack(db.Create(q).Error)
+}
+
+// UpdateQuota updates the quota except the used bytes, which is only changed by the uploads and removes
+func UpdateQuota(q *model.Quota) error {
+	return errors.WithStack(db.Model(q).Select("user_id", "base_path", "max_bytes").Updates(q).Error)
+}
+
+func DeleteQuotaById(id uint) error {
+	return errors.WithStack(db.Delete(&model.Quota{}, id).Error)
+}
+
+// AddQuotaUsedBytes adds delta to the used bytes of quota, it reports false without
+// change if the used bytes would exceed the max. The used bytes won't be less than 0.
+func AddQuotaUsedBytes(id uint, delta int64) (bool, error) {
+	quotaDB := db.Model(&model.Quota{}).Where("id = ?", id)
+	if delta > 0 {
+		res := quotaDB.Where("used_bytes + ? <= max_bytes", delta).
+			UpdateColumn("used_bytes", gorm.Expr("used_bytes + ?", delta))
+		return res.RowsAffected > 0, errors.WithStack(res.Error)
+	}
+	return true, IncQuotaUsedBytes(id, delta)
+}
+
+func ResetQuotaUsedBytes(id uint) error {
+	return errors.WithStack(db.Model(&model.Quota{}).Where("id = ?", id).UpdateColumn("used_bytes", 0).Error)
+}
+
+// IncQuotaUsedBytes adds delta to the used bytes of quota even if it would exceed the max,
+// it's used to undo or refund. The used bytes won't be less than 0.
+func IncQuotaUsedBytes(id uint, delta int64) error {
+	return errors.WithStack(db.Model(&model.Quota{}).Where("id = ?", id).
+		UpdateColumn("used_bytes", gorm.Expr("CASE WHEN used_bytes + ? > 0 THEN used_bytes + ? ELSE 0 END", delta, delta)).Error)
+}
+
+func GetQuotaUsagesByPaths(paths []string) ([]model.QuotaUsage, error) {
+	var usages []model.QuotaUsage
+	if len(paths) == 0 {
+		return usages, nil
+	}
+	if err := db.Where(columnName("path")+" IN ?", paths).Find(&usages).Error; err != nil {
+		return nil, errors.Wrapf(err, "failed find quota usages")
+	}
+	return usages, nil
+}
+
+// GetQuotaUsagesIn returns the usages of path and the files in it
+func GetQuotaUsagesIn(path string) ([]model.QuotaUsage, error) {
+	var usages []model.QuotaUsage
+	if err := db.Where(fmt.Sprintf("%[1]s = ? OR %[1]s LIKE ? ESCAPE '!'", columnName("path")),
+		path, subPathPattern(path)).Find(&usages).Error; err != nil {
+		return nil, errors.Wrapf(err, "failed find quota usages")
+	}
+	return usages, nil
+}
+
+func CreateQuotaUsages(usages []model.QuotaUsage) error {
+	if len(usages) == 0 {
+		return nil
+	}
+	return errors.WithStack(db.Create(&usages).Error)
+}
+
+func UpdateQuotaUsagePath(id uint, path string) error {
+	return errors.WithStack(db.Model(&model.QuotaUsage{}).Where("id = ?", id).UpdateColumn("path", path).Error)
+}
+
+func DeleteQuotaUsages(usages []model.QuotaUsage) error {
+	if len(usages) == 0 {
+		return nil
+	}
+	ids := make([]uint, len(usages))
+	for i, u := range usages {
+		ids[i] = u.ID
+	}
+	return errors.WithStack(db.Delete(&model.QuotaUsage{}, ids).Error)
+}
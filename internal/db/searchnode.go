@@ -35,7 +35,7 @@ func DeleteSearchNodesByParent(path string) error {
 	if err != nil {
 		return err
 	}
-	dir, name := stdpath.Split(path)
+	dir, name := stdpath.Dir(path), stdpath.Base(path)
 	return db.Where(fmt.Sprintf("%s = ? AND %s = ?",
 		columnName("parent"), columnName("name")),
 		dir, name).Delete(&model.SearchNode{}).Error
@@ -65,17 +65,49 @@ func SearchNode(req model.SearchReq, useFullText bool) ([]model.SearchNode, int6
 	} else {
 		switch conf.Conf.Database.Type {
 		case "mysql":
-			searchDB = db.Model(&model.SearchNode{}).Where(whereInParent(req.Parent)).
-				Where("MATCH (name) AGAINST (? IN BOOLEAN MODE)", "'*"+req.Keywords+"*'")
+			searchDB = db.Model(&model.SearchNode{}).Where(whereInParent(req.Parent))
+			if req.Keywords != "" {
+				searchDB = searchDB.Where("MATCH (name) AGAINST (? IN BOOLEAN MODE)", "'*"+req.Keywords+"*'")
+			}
 		case "postgres":
-			searchDB = db.Model(&model.SearchNode{}).Where(whereInParent(req.Parent)).
-				Where("to_tsvector(name) @@ to_tsquery(?)", strings.Join(strings.Fields(req.Keywords), " & "))
+			searchDB = db.Model(&model.SearchNode{}).Where(whereInParent(req.Parent))
+			if req.Keywords != "" {
+				searchDB = searchDB.Where("to_tsvector(name) @@ to_tsquery(?)", strings.Join(strings.Fields(req.Keywords), " & "))
+			}
 		}
 	}
 
 	if req.Scope != 0 {
 		isDir := req.Scope == 1
-		searchDB.Where(db.Where("is_dir = ?", isDir))
+		searchDB = searchDB.Where(db.Where("is_dir = ?", isDir))
+	}
+	if len(req.Types) != 0 {
+		searchDB = searchDB.Where("file_type IN ?", req.Types)
+	}
+	if req.Storage != "" {
+		searchDB = searchDB.Where(fmt.Sprintf("%s = ?", columnName("storage")), req.Storage)
+	}
+	if req.Hash != "" {
+		searchDB = searchDB.Where(fmt.Sprintf("%s = ?", columnName("hash")), strings.ToLower(req.Hash))
+	}
+	if req.MinSize != 0 {
+		searchDB = searchDB.Where(fmt.Sprintf("%s >= ?", columnName("size")), req.MinSize)
+	}
+	if req.MaxSize != 0 {
+		searchDB = searchDB.Where(fmt.Sprintf("%s <= ?", columnName("size")), req.MaxSize)
+	}
+	if req.ModifiedAfter != nil {
+		searchDB = searchDB.Where(fmt.Sprintf("%s >= ?", columnName("modified")), *req.ModifiedAfter)
+	}
+	if req.ModifiedBefore != nil {
+		searchDB = searchDB.Where(fmt.Sprintf("%s < ?", columnName("modified")), *req.ModifiedBefore)
+	}
+	orderBy, desc := req.Order()
+	order := columnName(orderBy)
+	if desc {
+		order += " desc"
+	} else {
+		order += " asc"
 	}
 
 	var count int64
@@ -83,7 +115,7 @@ func SearchNode(req model.SearchReq, useFullText bool) ([]model.SearchNode, int6
 		return nil, 0, errors.Wrapf(err, "failed get search items count")
 	}
 	var files []model.SearchNode
-	if err := searchDB.Order("name asc").Offset((req.Page - 1) * req.PerPage).Limit(req.PerPage).
+	if err := searchDB.Order(order).Offset((req.Page - 1) * req.PerPage).Limit(req.PerPage).
 		Find(&files).Error; err != nil {
 		return nil, 0, err
 	}
@@ -0,0 +1,65 @@
+package db
+
+import (
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/pkg/errors"
+	"gorm.io/gorm"
+)
+
+func GetShareById(id string) (*model.Share, error) {
+	var s model.Share
+	if err := db.Where("id = ?", id).First(&s).Error; err != nil {
+		return nil, errors.Wrapf(err, "failed get share")
+	}
+	return &s, nil
+}
+
+func GetShares(pageIndex, pageSize int) (shares []model.Share, count int64, err error) {
+	shareDB := db.Model(&model.Share{})
+	if err = shareDB.Count(&count).Error; err != nil {
+		return nil, 0, errors.Wrapf(err, "failed get shares count")
+	}
+	if err = shareDB.Order(columnName("created")).Offset((pageIndex - 1) * pageSize).Limit(pageSize).Find(&shares).Error; err != nil {
+		return nil, 0, errors.Wrapf(err, "failed find shares")
+	}
+	return shares, count, nil
+}
+
+func GetSharesByUserId(userId uint, pageIndex, pageSize int) (shares []model.Share, count int64, err error) {
+	shareDB := db.Model(&model.Share{}).Where("user_id = ?", userId)
+	if err = shareDB.Count(&count).Error; err != nil {
+		return nil, 0, errors.Wrapf(err, "failed get user's shares count")
+	}
+	if err = shareDB.Order(columnName("created")).Offset((pageIndex - 1) * pageSize).Limit(pageSize).Find(&shares).Error; err != nil {
+		return nil, 0, errors.Wrapf(err, "failed find user's shares")
+	}
+	return shares, count, nil
+}
+
+func CreateShare(s *model.Share) error {
+	return errors.WithStack(db.Create(s).Error)
+}
+
+func UpdateShare(s *model.Share) error {
+	return errors.WithStack(db.Save(s).Error)
+}
+
+// IncreaseShareDownloads increases the downloads of share if the limit is not reached,
+// ok is false if the limit is reached
+func IncreaseShareDownloads(id string) (ok bool, err error) {
+	res := db.Model(&model.Share{}).
+		Where("id = ? AND (max_downloads = 0 OR downloads < max_downloads)", id).
+		Update("downloads", gorm.Expr("downloads + 1"))
+	if res.Error != nil {
+		return false, errors.WithStack(res.Error)
+	}
+	return res.RowsAffected == 1, nil
+}
+
+func DeleteShareById(id string) error {
+	return errors.WithStack(db.Where("id = ?", id).Delete(&model.Share{}).Error)
+}
+
+func DeleteSharesByUserId(userId uint) error {
+	return errors.WithStack(db.Where("user_id = ?", userId).Delete(&model.Share{}).Error)
+}
@@ -0,0 +1,49 @@
+package db
+
+import (
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/pkg/errors"
+)
+
+func GetSSHPublicKeyByUserId(userId uint, pageIndex, pageSize int) (keys []model.SSHPublicKey, count int64, err error) {
+	keyDB := db.Model(&model.SSHPublicKey{}).Where("user_id = ?", userId)
+	if err := keyDB.Count(&count).Error; err != nil {
+		return nil, 0, errors.Wrapf(err, "failed get user's keys count")
+	}
+	if err := keyDB.Order(columnName("id")).Offset((pageIndex - 1) * pageSize).Limit(pageSize).Find(&keys).Error; err != nil {
+		return nil, 0, errors.Wrapf(err, "failed get find user's keys")
+	}
+	return keys, count, nil
+}
+
+func GetSSHPublicKeyById(id uint) (*model.SSHPublicKey, error) {
+	var k model.SSHPublicKey
+	if err := db.First(&k, id).Error; err != nil {
+		return nil, errors.Wrapf(err, "failed get old key")
+	}
+	return &k, nil
+}
+
+func GetSSHPublicKeyByUserIdAndFingerprint(userId uint, fingerprint string) (*model.SSHPublicKey, error) {
+	key := model.SSHPublicKey{UserId: userId, Fingerprint: fingerprint}
+	if err := db.Where(key).First(&key).Error; err != nil {
+		return nil, errors.Wrapf(err, "failed find key")
+	}
+	return &key, nil
+}
+
+func CreateSSHPublicKey(k *model.SSHPublicKey) error {
+	return errors.WithStack(db.Create(k).Error)
+}
+
+func UpdateSSHPublicKey(k *model.SSHPublicKey) error {
+	return errors.WithStack(db.Save(k).Error)
+}
+
+func DeleteSSHPublicKeyById(id uint) error {
+	return errors.WithStack(db.Delete(&model.SSHPublicKey{}, id).Error)
+}
+
+func DeleteSSHPublicKeysByUserId(userId uint) error {
+	return errors.WithStack(db.Where("user_id = ?", userId).Delete(&model.SSHPublicKey{}).Error)
+}
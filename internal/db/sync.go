@@ -0,0 +1,60 @@
+package db
+
+import (
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/pkg/errors"
+)
+
+func GetSyncJobById(id uint) (*model.SyncJob, error) {
+	var j model.SyncJob
+	if err := db.First(&j, id).Error; err != nil {
+		return nil, errors.Wrapf(err, "failed get sync job")
+	}
+	return &j, nil
+}
+
+func GetSyncJobs() ([]model.SyncJob, error) {
+	var jobs []model.SyncJob
+	if err := db.Order(columnName("id")).Find(&jobs).Error; err != nil {
+		return nil, errors.Wrapf(err, "failed find sync jobs")
+	}
+	return jobs, nil
+}
+
+func CreateSyncJob(j *model.SyncJob) error {
+	return errors.WithStack(db.Create(j).Error)
+}
+
+func UpdateSyncJob(j *model.SyncJob) error {
+	return errors.WithStack(db.Save(j).Error)
+}
+
+func DeleteSyncJobById(id uint) error {
+	if err := db.Where("job_id = ?", id).Delete(&model.SyncRun{}).Error; err != nil {
+		return errors.Wrapf(err, "failed delete sync runs")
+	}
+	return errors.WithStack(db.Delete(&model.SyncJob{}, id).Error)
+}
+
+// GetSyncRuns returns the runs of job or all the jobs if jobId is 0, the latest first
+func GetSyncRuns(jobId uint, pageIndex, pageSize int) (runs []model.SyncRun, count int64, err error) {
+	runDB := db.Model(&model.SyncRun{})
+	if jobId != 0 {
+		runDB = runDB.Where("job_id = ?", jobId)
+	}
+	if err = runDB.Count(&count).Error; err != nil {
+		return nil, 0, errors.Wrapf(err, "failed get sync runs count")
+	}
+	if err = runDB.Order(columnName("id") + " DESC").Offset((pageIndex - 1) * pageSize).Limit(pageSize).Find(&runs).Error; err != nil {
+		return nil, 0, errors.Wrapf(err, "failed find sync runs")
+	}
+	return runs, count, nil
+}
+
+func CreateSyncRun(r *model.SyncRun) error {
+	return errors.WithStack(db.Create(r).Error)
+}
+
+func UpdateSyncRun(r *model.SyncRun) error {
+	return errors.WithStack(db.Save(r).Error)
+}
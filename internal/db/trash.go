@@ -0,0 +1,59 @@
+package db
+
+import (
+	"time"
+
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/pkg/errors"
+)
+
+func GetTrashItemById(id uint) (*model.TrashItem, error) {
+	var t model.TrashItem
+	if err := db.First(&t, id).Error; err != nil {
+		return nil, errors.Wrapf(err, "failed get trash item")
+	}
+	return &t, nil
+}
+
+// GetTrashItems returns the items of storage or all the storages if storageId is 0
+func GetTrashItems(storageId uint, pageIndex, pageSize int) (items []model.TrashItem, count int64, err error) {
+	trashDB := db.Model(&model.TrashItem{})
+	if storageId != 0 {
+		trashDB = trashDB.Where("storage_id = ?", storageId)
+	}
+	if err = trashDB.Count(&count).Error; err != nil {
+		return nil, 0, errors.Wrapf(err, "failed get trash items count")
+	}
+	if err = trashDB.Order(columnName("deleted") + " DESC").Offset((pageIndex - 1) * pageSize).Limit(pageSize).Find(&items).Error; err != nil {
+		return nil, 0, errors.Wrapf(err, "failed find trash items")
+	}
+	return items, count, nil
+}
+
+func GetTrashItemsDeletedBefore(t time.Time) ([]model.TrashItem, error) {
+	var items []model.TrashItem
+	if err := db.Where(columnName("deleted")+" < ?", t).Find(&items).Error; err != nil {
+		return nil, errors.Wrapf(err, "failed find expired trash items")
+	}
+	return items, nil
+}
+
+func CreateTrashItem(t *model.TrashItem) error {
+	return errors.WithStack(db.Create(t).Error)
+}
+
+func UpdateTrashItem(t *model.TrashItem) error {
+	return errors.WithStack(db.Save(t).Error)
+}
+
+func DeleteTrashItemById(id uint) error {
+	return errors.WithStack(db.Delete(&model.TrashItem{}, id).Error)
+}
+
+func DeleteTrashItemsByStorageId(storageId uint) error {
+	return errors.WithStack(db.Where("storage_id = ?", storageId).Delete(&model.TrashItem{}).Error)
+}
+
+func DeleteTrashItemsByTrashPath(storageId uint, trashPath string) error {
+	return errors.WithStack(db.Where("storage_id = ? AND trash_path = ?", storageId, trashPath).Delete(&model.TrashItem{}).Error)
+}
@@ -2,6 +2,7 @@ package db
 
 import (
 	"fmt"
+	"strings"
 
 	"github.com/alist-org/alist/v3/internal/conf"
 	"gorm.io/gorm"
@@ -14,6 +15,12 @@ func columnName(name string) string {
 	return fmt.Sprintf("`%s`", name)
 }
 
+// subPathPattern returns the pattern matching the sub paths of path, which
+// should be used with `LIKE ? ESCAPE '!'` as the path may contain % and _
+func subPathPattern(path string) string {
+	return strings.NewReplacer("!", "!!", "%", "!%", "_", "!_").Replace(path) + "/%"
+}
+
 func addStorageOrder(db *gorm.DB) *gorm.DB {
 	return db.Order(fmt.Sprintf("%s, %s", columnName("order"), columnName("id")))
 }
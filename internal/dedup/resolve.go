@@ -0,0 +1,125 @@
+package dedup
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	stdpath "path"
+	"strings"
+	"time"
+
+	"github.com/alist-org/alist/v3/internal/fs"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/internal/stream"
+	"github.com/alist-org/alist/v3/pkg/utils"
+	"github.com/pkg/errors"
+)
+
+const (
+	// ActionDelete deletes the duplicates
+	ActionDelete = "delete"
+	// ActionLink replaces the duplicates by the .url shortcuts to the kept file
+	ActionLink = "link"
+)
+
+type ResolveArgs struct {
+	// the id of task found the duplicates
+	TaskID string
+	// the file to keep
+	Keep string
+	// the duplicates of keep to delete or replace
+	Paths  []string
+	Action string
+	// the base url of alist, required by ActionLink
+	ApiUrl string
+}
+
+// Resolve deletes or replaces the duplicates of the kept file, they must be in the same group found by the task
+func Resolve(ctx context.Context, args ResolveArgs) error {
+	if args.Action != ActionDelete && args.Action != ActionLink {
+		return errors.Errorf("invalid action: %s", args.Action)
+	}
+	groups, err := GetGroups(args.TaskID)
+	if err != nil {
+		return err
+	}
+	if utils.SliceContains(args.Paths, args.Keep) {
+		return errors.New("the kept file can't be resolved")
+	}
+	group, ok := findGroup(groups, append([]string{args.Keep}, args.Paths...)...)
+	if !ok {
+		return errors.New("the files are not in the same duplicate group")
+	}
+	// the kept file may be changed since the task found the duplicates
+	if err = checkKeep(ctx, *group, args.Keep); err != nil {
+		return err
+	}
+	for _, p := range args.Paths {
+		if err = resolve(ctx, args, p); err != nil {
+			return errors.WithMessagef(err, "failed resolve %s", p)
+		}
+		group.Files = utils.SliceFilter(group.Files, func(f File) bool {
+			return f.Path != p
+		})
+	}
+	return nil
+}
+
+// checkKeep returns an error if the kept file is no longer the same as the group
+func checkKeep(ctx context.Context, group Group, keep string) error {
+	obj, err := fs.Get(ctx, keep, &fs.GetArgs{NoLog: true})
+	if err != nil {
+		return errors.WithMessagef(err, "failed get the kept file %s", keep)
+	}
+	if obj.IsDir() || obj.GetSize() != group.Size {
+		return errors.Errorf("the kept file %s is changed", keep)
+	}
+	name, want, _ := strings.Cut(group.Hash, ":")
+	ht := hashTypeByName(name)
+	h := obj.GetHash().GetHash(ht)
+	if h == "" {
+		if h, err = hashFile(ctx, keep, ht); err != nil {
+			return errors.WithMessagef(err, "failed hash the kept file %s", keep)
+		}
+	}
+	if !strings.EqualFold(h, want) {
+		return errors.Errorf("the kept file %s is changed", keep)
+	}
+	return nil
+}
+
+func hashTypeByName(name string) *utils.HashType {
+	for _, ht := range []*utils.HashType{utils.MD5, utils.SHA1, utils.SHA256} {
+		if ht.Name == name {
+			return ht
+		}
+	}
+	return utils.MD5
+}
+
+func resolve(ctx context.Context, args ResolveArgs, path string) error {
+	if args.Action == ActionLink {
+		// the duplicate is removed only if the shortcut is put
+		err := fs.PutDirectly(ctx, stdpath.Dir(path), shortcut(stdpath.Base(path)+".url", args.ApiUrl, args.Keep))
+		if err != nil {
+			return err
+		}
+	}
+	return fs.Remove(ctx, path)
+}
+
+// shortcut returns an internet shortcut to the page of target, which doesn't
+// expire like the signed links and requires the permission of visitors
+func shortcut(name, apiUrl, target string) model.FileStreamer {
+	content := []byte(fmt.Sprintf("[InternetShortcut]\r\nURL=%s%s\r\n",
+		apiUrl, utils.EncodePath(target, true)))
+	return &stream.FileStream{
+		Obj: &model.Object{
+			Name:     name,
+			Size:     int64(len(content)),
+			Modified: time.Now(),
+		},
+		Reader:   bytes.NewReader(content),
+		Mimetype: "application/internet-shortcut",
+	}
+}
@@ -0,0 +1,145 @@
+package dedup
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	_ "github.com/alist-org/alist/v3/drivers/local"
+	"github.com/alist-org/alist/v3/internal/conf"
+	"github.com/alist-org/alist/v3/internal/db"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/internal/op"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func init() {
+	dB, err := gorm.Open(sqlite.Open("file::memory:?cache=shared"), &gorm.Config{})
+	if err != nil {
+		panic("failed to connect database")
+	}
+	conf.Conf = conf.DefaultConfig()
+	db.Init(dB)
+}
+
+// setupStorage mounts a Local storage of a temp dir at /local with the files
+func setupStorage(t *testing.T, files map[string]string) (context.Context, string) {
+	root := t.TempDir()
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(root, name), []byte(content), 0666); err != nil {
+			t.Fatal(err)
+		}
+	}
+	id, err := op.CreateStorage(context.Background(), model.Storage{
+		Driver:    "Local",
+		MountPath: "/local",
+		Addition:  `{"root_folder_path":"` + filepath.ToSlash(root) + `"}`,
+	})
+	if err != nil {
+		t.Fatalf("failed to create storage: %+v", err)
+	}
+	t.Cleanup(func() {
+		_ = op.DeleteStorageById(context.Background(), id)
+	})
+	admin := &model.User{Username: "admin", Role: model.ADMIN, BasePath: "/"}
+	return context.WithValue(context.Background(), "user", admin), root
+}
+
+func TestFindGroup(t *testing.T) {
+	groups := []Group{
+		{Size: 1, Hash: "md5:a", Files: []File{{Path: "/a"}, {Path: "/b"}}},
+		{Size: 2, Hash: "md5:b", Files: []File{{Path: "/c"}, {Path: "/d"}, {Path: "/e"}}},
+	}
+	tests := []struct {
+		paths []string
+		size  int64
+		ok    bool
+	}{
+		{paths: []string{"/a", "/b"}, size: 1, ok: true},
+		{paths: []string{"/e", "/c"}, size: 2, ok: true},
+		{paths: []string{"/a", "/c"}, ok: false},
+		{paths: []string{"/f"}, ok: false},
+	}
+	for _, tt := range tests {
+		group, ok := findGroup(groups, tt.paths...)
+		if ok != tt.ok {
+			t.Errorf("findGroup(%v) ok = %v, want %v", tt.paths, ok, tt.ok)
+			continue
+		}
+		if ok && group.Size != tt.size {
+			t.Errorf("findGroup(%v) = group of size %d, want %d", tt.paths, group.Size, tt.size)
+		}
+	}
+}
+
+func TestCheckKeep(t *testing.T) {
+	ctx, _ := setupStorage(t, map[string]string{
+		"a.txt": "hello",
+		"b.txt": "hellO",
+		"c.txt": "hello!",
+	})
+	// md5 of hello
+	group := Group{Size: 5, Hash: "md5:5d41402abc4b2a76b9719d911017c592"}
+	tests := []struct {
+		keep  string
+		isErr bool
+	}{
+		{keep: "/local/a.txt", isErr: false},
+		{keep: "/local/b.txt", isErr: true},
+		{keep: "/local/c.txt", isErr: true},
+		{keep: "/local/d.txt", isErr: true},
+		{keep: "/local", isErr: true},
+	}
+	for _, tt := range tests {
+		err := checkKeep(ctx, group, tt.keep)
+		if (err != nil) != tt.isErr {
+			t.Errorf("checkKeep(%s) error = %v, want error: %v", tt.keep, err, tt.isErr)
+		}
+	}
+}
+
+func TestResolve(t *testing.T) {
+	// the name of its shortcut is too long to be put
+	long := strings.Repeat("d", 250) + ".txt"
+	ctx, root := setupStorage(t, map[string]string{
+		"a.txt": "hello",
+		"b.txt": "hello",
+		"c.txt": "hello",
+		long:    "hello",
+	})
+	args := ResolveArgs{Keep: "/local/a.txt", ApiUrl: "http://localhost:5244"}
+	tests := []struct {
+		path     string
+		action   string
+		isErr    bool
+		removed  bool
+		shortcut bool
+	}{
+		{path: "/local/b.txt", action: ActionDelete, removed: true},
+		{path: "/local/c.txt", action: ActionLink, removed: true, shortcut: true},
+		{path: "/local/" + long, action: ActionLink, isErr: true},
+	}
+	for _, tt := range tests {
+		args.Action = tt.action
+		err := resolve(ctx, args, tt.path)
+		if (err != nil) != tt.isErr {
+			t.Errorf("resolve(%s, %s) error = %v, want error: %v", tt.path, tt.action, err, tt.isErr)
+		}
+		name := filepath.Join(root, filepath.Base(tt.path))
+		if _, err = os.Stat(name); os.IsNotExist(err) != tt.removed {
+			t.Errorf("resolve(%s, %s) removed = %v, want %v", tt.path, tt.action, os.IsNotExist(err), tt.removed)
+		}
+		if !tt.shortcut {
+			continue
+		}
+		content, err := os.ReadFile(name + ".url")
+		if err != nil {
+			t.Errorf("resolve(%s, %s) didn't put the shortcut: %v", tt.path, tt.action, err)
+		} else if !strings.Contains(string(content), "URL=http://localhost:5244/local/a.txt\r\n") {
+			t.Errorf("resolve(%s, %s) put the shortcut %q", tt.path, tt.action, content)
+		}
+	}
+}
@@ -0,0 +1,260 @@
+package dedup
+
+import (
+	"context"
+	stderrors "errors"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/alist-org/alist/v3/internal/fs"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/internal/op"
+	"github.com/alist-org/alist/v3/internal/stream"
+	"github.com/alist-org/alist/v3/pkg/http_range"
+	"github.com/alist-org/alist/v3/pkg/utils"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+	"github.com/xhofe/tache"
+)
+
+type File struct {
+	Path     string    `json:"path"`
+	Modified time.Time `json:"modified"`
+	hash     utils.HashInfo
+}
+
+// Group is the files with the same size and hash
+type Group struct {
+	Size int64 `json:"size"`
+	// in the form of type:hex, such as md5:d41d8cd98f00b204e9800998ecf8427e
+	Hash  string `json:"hash"`
+	Files []File `json:"files"`
+}
+
+type Task struct {
+	tache.Base
+	Paths    []string `json:"paths"`
+	MinSize  int64    `json:"min_size"`
+	MaxDepth int      `json:"max_depth"`
+	Groups   []Group  `json:"groups"`
+	Status   string   `json:"-"`
+}
+
+func (t *Task) GetName() string {
+	return fmt.Sprintf("find duplicates in %s", strings.Join(t.Paths, ", "))
+}
+
+func (t *Task) GetStatus() string {
+	return t.Status
+}
+
+func (t *Task) Run() error {
+	admin, err := op.GetAdmin()
+	if err != nil {
+		return err
+	}
+	ctx := context.WithValue(t.Ctx(), "user", admin)
+	t.Groups = nil
+	bySize, err := t.walk(ctx)
+	if err != nil {
+		return err
+	}
+	var candidates int
+	for _, files := range bySize {
+		candidates += len(files)
+	}
+	var hashed int
+	for size, files := range bySize {
+		groups, err := t.groupByHash(ctx, files, func() {
+			hashed++
+			t.Status = fmt.Sprintf("hashing, %d/%d files", hashed, candidates)
+			t.SetProgress(float64(hashed) * 100 / float64(candidates))
+		})
+		if err != nil {
+			return err
+		}
+		for hash, files := range groups {
+			if len(files) > 1 {
+				t.Groups = append(t.Groups, Group{Size: size, Hash: hash, Files: files})
+			}
+		}
+	}
+	// the larger duplicates waste more space
+	sort.Slice(t.Groups, func(i, j int) bool {
+		if t.Groups[i].Size != t.Groups[j].Size {
+			return t.Groups[i].Size > t.Groups[j].Size
+		}
+		return t.Groups[i].Hash < t.Groups[j].Hash
+	})
+	t.Status = fmt.Sprintf("found %d duplicate groups", len(t.Groups))
+	t.SetProgress(100)
+	return nil
+}
+
+// walk returns the files with the same size
+func (t *Task) walk(ctx context.Context) (map[int64][]File, error) {
+	bySize := make(map[int64][]File)
+	visited := make(map[string]struct{})
+	var walked int
+	for _, p := range t.Paths {
+		root, err := fs.Get(ctx, p, &fs.GetArgs{NoLog: true})
+		if err != nil {
+			return nil, errors.WithMessagef(err, "failed get %s", p)
+		}
+		err = fs.WalkFS(ctx, t.MaxDepth, p, root, func(reqPath string, obj model.Obj) error {
+			if utils.IsCanceled(ctx) {
+				return ctx.Err()
+			}
+			if obj.IsDir() || obj.GetSize() < t.MinSize || obj.GetSize() == 0 {
+				return nil
+			}
+			if _, ok := visited[reqPath]; ok {
+				return nil
+			}
+			visited[reqPath] = struct{}{}
+			walked++
+			t.Status = fmt.Sprintf("walking, %d files", walked)
+			bySize[obj.GetSize()] = append(bySize[obj.GetSize()], File{
+				Path:     reqPath,
+				Modified: obj.ModTime(),
+				hash:     obj.GetHash(),
+			})
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	for size, files := range bySize {
+		if len(files) < 2 {
+			delete(bySize, size)
+		}
+	}
+	return bySize, nil
+}
+
+// groupByHash groups the files with the same size by the hash exposed by the drivers
+// if all of them have, or by the md5 computed by streaming the missing ones
+func (t *Task) groupByHash(ctx context.Context, files []File, done func()) (map[string][]File, error) {
+	ht := commonHashType(files)
+	groups := make(map[string][]File)
+	for _, f := range files {
+		h := f.hash.GetHash(ht)
+		if h == "" {
+			var err error
+			h, err = hashFile(ctx, f.Path, ht)
+			if err != nil {
+				if utils.IsCanceled(ctx) {
+					return nil, ctx.Err()
+				}
+				// a file can't be read is not treated as a duplicate
+				log.Warnf("failed hash %s: %+v", f.Path, err)
+				done()
+				continue
+			}
+		}
+		key := ht.Name + ":" + strings.ToLower(h)
+		groups[key] = append(groups[key], f)
+		done()
+	}
+	return groups, nil
+}
+
+func commonHashType(files []File) *utils.HashType {
+	for _, ht := range []*utils.HashType{utils.MD5, utils.SHA1, utils.SHA256} {
+		all := true
+		for _, f := range files {
+			if f.hash.GetHash(ht) == "" {
+				all = false
+				break
+			}
+		}
+		if all {
+			return ht
+		}
+	}
+	return utils.MD5
+}
+
+// hashFile computes the hash of file by streaming it
+func hashFile(ctx context.Context, path string, ht *utils.HashType) (string, error) {
+	obj, err := fs.Get(ctx, path, &fs.GetArgs{NoLog: true})
+	if err != nil {
+		return "", err
+	}
+	link, _, err := fs.Link(ctx, path, model.LinkArgs{})
+	if err != nil {
+		return "", err
+	}
+	if link.MFile != nil {
+		defer link.MFile.Close()
+		return utils.HashReader(ht, link.MFile)
+	}
+	rrc := link.RangeReadCloser
+	if rrc == nil {
+		rrc, err = stream.GetRangeReadCloserFromLink(obj.GetSize(), link)
+		if err != nil {
+			return "", err
+		}
+	}
+	rc, err := rrc.RangeRead(ctx, http_range.Range{Start: 0, Length: obj.GetSize()})
+	if err != nil {
+		_ = rrc.Close()
+		return "", err
+	}
+	h, err := utils.HashReader(ht, io.LimitReader(rc, obj.GetSize()))
+	return h, stderrors.Join(err, rc.Close(), rrc.Close())
+}
+
+var TaskManager *tache.Manager[*Task]
+
+// Find adds a task to find the duplicate files in paths
+func Find(paths []string, minSize int64, maxDepth int) (tache.TaskWithInfo, error) {
+	if len(paths) == 0 {
+		return nil, errors.New("paths is empty")
+	}
+	for i := range paths {
+		paths[i] = utils.FixAndCleanPath(paths[i])
+	}
+	t := &Task{
+		Paths:    paths,
+		MinSize:  minSize,
+		MaxDepth: maxDepth,
+	}
+	TaskManager.Add(t)
+	return t, nil
+}
+
+// GetGroups returns the duplicate groups found by the task
+func GetGroups(tid string) ([]Group, error) {
+	t, ok := TaskManager.GetByID(tid)
+	if !ok {
+		return nil, errors.New("task not found")
+	}
+	if t.GetState() != tache.StateSucceeded {
+		return nil, errors.New("task is not succeeded")
+	}
+	return t.Groups, nil
+}
+
+// findGroup returns the group containing all the paths
+func findGroup(groups []Group, paths ...string) (*Group, bool) {
+	for i := range groups {
+		all := true
+		for _, p := range paths {
+			if !utils.SliceContains(utils.MustSliceConvert(groups[i].Files, func(f File) string {
+				return f.Path
+			}), p) {
+				all = false
+				break
+			}
+		}
+		if all {
+			return &groups[i], true
+		}
+	}
+	return nil, false
+}
@@ -2,6 +2,7 @@ package driver
 
 import (
 	"context"
+	"time"
 
 	"github.com/alist-org/alist/v3/internal/model"
 )
@@ -29,6 +30,11 @@ type Other interface {
 	Other(ctx context.Context, args model.OtherArgs) (interface{}, error)
 }
 
+// WithDetails is implemented by the drivers that can report the usage of storage space
+type WithDetails interface {
+	GetDetails(ctx context.Context) (*model.StorageDetails, error)
+}
+
 type Reader interface {
 	// List files in the path
 	// if identify files by path, need to set ID with path,like path.Join(dir.GetID(), obj.GetName())
@@ -109,6 +115,53 @@ type PutResult interface {
 	Put(ctx context.Context, dstDir model.Obj, stream model.FileStreamer, up UpdateProgress) (model.Obj, error)
 }
 
+// PutResumable is implemented by the drivers that can resume an interrupted upload
+type PutResumable interface {
+	// PutResumable uploads the stream from the state in cp, and saves the new state
+	// with cp.Save after each part uploaded. The stream is read with RangeRead if resumed.
+	PutResumable(ctx context.Context, dstDir model.Obj, stream model.FileStreamer, cp *Checkpoint, up UpdateProgress) error
+}
+
+// Checkpoint is the state of a resumable upload
+type Checkpoint struct {
+	// the size and modified time of the file uploaded, the checkpoint is
+	// only valid for the same file
+	Size     int64     `json:"size"`
+	Modified time.Time `json:"modified"`
+	// the bytes uploaded
+	Offset int64 `json:"offset"`
+	// the state of driver, such as the upload id and the uploaded parts
+	Data string `json:"data,omitempty"`
+	save func()
+}
+
+func NewCheckpoint(file model.Obj, save func()) *Checkpoint {
+	return &Checkpoint{Size: file.GetSize(), Modified: file.ModTime(), save: save}
+}
+
+// Valid reports whether the checkpoint can be used to resume the upload of file
+func (c *Checkpoint) Valid(file model.Obj) bool {
+	return c.Size == file.GetSize() && c.Modified.Equal(file.ModTime())
+}
+
+// SetSave sets the function to persist the checkpoint, it's lost after unmarshalled
+func (c *Checkpoint) SetSave(save func()) {
+	c.save = save
+}
+
+// Save records the state of upload and persists it
+func (c *Checkpoint) Save(offset int64, data string) {
+	c.Offset, c.Data = offset, data
+	if c.save != nil {
+		c.save()
+	}
+}
+
+// Reset discards the state, the upload restarts from the beginning
+func (c *Checkpoint) Reset() {
+	c.Save(0, "")
+}
+
 type UpdateProgress func(percentage float64)
 
 type Progress struct {
@@ -0,0 +1,8 @@
+package errs
+
+import "errors"
+
+var (
+	FileRequestExpired       = errors.New("file request is expired")
+	FileRequestQuotaExceeded = errors.New("file request quota exceeded")
+)
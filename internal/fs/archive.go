@@ -0,0 +1,333 @@
+package fs
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	stdpath "path"
+	"strings"
+	"time"
+
+	"github.com/Xhofe/go-cache"
+	"github.com/alist-org/alist/v3/internal/archive"
+	"github.com/alist-org/alist/v3/internal/driver"
+	"github.com/alist-org/alist/v3/internal/errs"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/internal/op"
+	"github.com/alist-org/alist/v3/internal/ratelimit"
+	"github.com/alist-org/alist/v3/internal/stream"
+	"github.com/alist-org/alist/v3/pkg/http_range"
+	"github.com/alist-org/alist/v3/pkg/singleflight"
+	"github.com/alist-org/alist/v3/pkg/utils"
+	"github.com/pkg/errors"
+	"github.com/xhofe/tache"
+)
+
+// the entries of archives are cached since listing a large archive needs many range requests,
+// the archive is identified by its size and modified time, so the cache is renewed after it's changed
+const archiveCacheExpiration = 30 * time.Minute
+
+var archiveCache = cache.NewMemCache(cache.WithShards[[]archive.Entry](16))
+var archiveG singleflight.Group[[]archive.Entry]
+
+// archiveFile is the archive at a mount path, which is read by the ranges of its link
+type archiveFile struct {
+	*archive.Source
+	format  archive.Format
+	storage driver.Driver
+	path    string
+	obj     model.Obj
+	// one of them is used to read the archive
+	mFile model.File
+	rrc   model.RangeReadCloserIF
+}
+
+func openArchive(ctx context.Context, path string) (*archiveFile, error) {
+	storage, actualPath, err := op.GetStorageAndActualPath(path)
+	if err != nil {
+		return nil, errors.WithMessage(err, "failed get storage")
+	}
+	if err = checkTrash(actualPath); err != nil {
+		return nil, err
+	}
+	obj, err := op.Get(ctx, storage, actualPath)
+	if err != nil {
+		return nil, errors.WithMessage(err, "failed get archive")
+	}
+	if obj.IsDir() {
+		return nil, errors.WithStack(errs.NotFile)
+	}
+	format, ok := archive.GetFormat(obj.GetName())
+	if !ok {
+		return nil, errors.Errorf("unsupported archive format: %s", obj.GetName())
+	}
+	link, _, err := op.Link(ctx, storage, actualPath, model.LinkArgs{Header: http.Header{}})
+	if err != nil {
+		return nil, errors.WithMessage(err, "failed link archive")
+	}
+	a := &archiveFile{
+		format:  format,
+		storage: storage,
+		path:    path,
+		obj:     obj,
+		mFile:   link.MFile,
+		rrc:     link.RangeReadCloser,
+	}
+	a.Source = &archive.Source{
+		Name:        obj.GetName(),
+		Size:        obj.GetSize(),
+		RangeReader: a.rangeRead,
+	}
+	if a.mFile == nil && a.rrc == nil {
+		a.rrc, err = stream.GetRangeReadCloserFromLink(obj.GetSize(), link)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return a, nil
+}
+
+func (a *archiveFile) rangeRead(ctx context.Context, r http_range.Range) (io.ReadCloser, error) {
+	if r.Length < 0 || r.Start+r.Length > a.Size {
+		r.Length = a.Size - r.Start
+	}
+	var rc io.ReadCloser
+	if a.mFile != nil {
+		rc = io.NopCloser(io.NewSectionReader(a.mFile, r.Start, r.Length))
+	} else {
+		var err error
+		rc, err = a.rrc.RangeRead(ctx, r)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return ratelimit.NewReadCloser(ctx, rc), nil
+}
+
+func (a *archiveFile) Close() error {
+	if a.mFile != nil {
+		return a.mFile.Close()
+	}
+	return a.rrc.Close()
+}
+
+// entries returns all entries of the archive, including the dirs missing in the archive
+func (a *archiveFile) entries(ctx context.Context, refresh bool) ([]archive.Entry, error) {
+	key := fmt.Sprintf("%s:%d:%d", a.path, a.obj.GetSize(), a.obj.ModTime().Unix())
+	if !refresh {
+		if entries, ok := archiveCache.Get(key); ok {
+			return entries, nil
+		}
+	}
+	entries, err, _ := archiveG.Do(key, func() ([]archive.Entry, error) {
+		entries, err := a.format.List(ctx, a.Source)
+		if err != nil {
+			return nil, err
+		}
+		entries = archive.Tree(entries)
+		archiveCache.Set(key, entries, cache.WithEx[[]archive.Entry](archiveCacheExpiration))
+		return entries, nil
+	})
+	return entries, err
+}
+
+func archiveObj(e archive.Entry) model.Obj {
+	return &model.Object{
+		Name:     stdpath.Base(e.Path),
+		Path:     e.Path,
+		Size:     e.Size,
+		Modified: e.Modified,
+		IsFolder: e.IsDir,
+	}
+}
+
+func archiveList(ctx context.Context, path, innerPath string, args *ListArgs) ([]model.Obj, error) {
+	a, err := openArchive(ctx, path)
+	if err != nil {
+		return nil, err
+	}
+	defer a.Close()
+	entries, err := a.entries(ctx, args.Refresh)
+	if err != nil {
+		return nil, errors.WithMessage(err, "failed list archive")
+	}
+	children, err := archive.Children(entries, innerPath)
+	if err != nil {
+		return nil, err
+	}
+	return utils.MustSliceConvert(children, archiveObj), nil
+}
+
+func archiveGet(ctx context.Context, path, innerPath string) (model.Obj, error) {
+	a, err := openArchive(ctx, path)
+	if err != nil {
+		return nil, err
+	}
+	defer a.Close()
+	if archive.CleanPath(innerPath) == "" {
+		// the root of archive
+		return &model.Object{Name: a.obj.GetName(), Modified: a.obj.ModTime(), IsFolder: true}, nil
+	}
+	entries, err := a.entries(ctx, false)
+	if err != nil {
+		return nil, errors.WithMessage(err, "failed list archive")
+	}
+	e, ok := archive.Find(entries, innerPath)
+	if !ok {
+		return nil, errors.WithStack(errs.ObjectNotFound)
+	}
+	return archiveObj(e), nil
+}
+
+// archiveLink returns the link of the file in the archive, it's always read by the
+// range reader of link, which opens the archive and skips the content before the range
+func archiveLink(ctx context.Context, path, innerPath string) (*model.Link, model.Obj, error) {
+	obj, err := archiveGet(ctx, path, innerPath)
+	if err != nil {
+		return nil, nil, err
+	}
+	if obj.IsDir() {
+		return nil, nil, errors.WithStack(errs.NotFile)
+	}
+	// the ranges of the compressed entries are rejected, instead of decompressing from the beginning for each
+	rangeReader := func(ctx context.Context, r http_range.Range) (io.ReadCloser, error) {
+		a, err := openArchive(ctx, path)
+		if err != nil {
+			return nil, err
+		}
+		rc, err := archive.OpenRange(ctx, a.format, a.Source, innerPath, r)
+		if err != nil {
+			_ = a.Close()
+			return nil, err
+		}
+		return utils.NewReadCloser(rc, func() error {
+			_ = rc.Close()
+			return a.Close()
+		}), nil
+	}
+	return &model.Link{RangeReadCloser: &model.RangeReadCloser{RangeReader: rangeReader}}, obj, nil
+}
+
+type ExtractTask struct {
+	tache.Base
+	Status     string `json:"-"`
+	SrcObjPath string `json:"src_path"`
+	// the path of the file or dir in the archive to be extracted, empty for all
+	InnerPath  string `json:"inner_path"`
+	DstDirPath string `json:"dst_path"`
+	// the name of user who added the task, the extracted files are charged to the quotas
+	Creator string `json:"creator"`
+}
+
+func (t *ExtractTask) GetName() string {
+	return fmt.Sprintf("extract [%s](/%s) to [%s]", t.SrcObjPath, t.InnerPath, t.DstDirPath)
+}
+
+func (t *ExtractTask) GetStatus() string {
+	return t.Status
+}
+
+func (t *ExtractTask) Run() error {
+	t.Status = "reading archive"
+	a, err := openArchive(t.Ctx(), t.SrcObjPath)
+	if err != nil {
+		return err
+	}
+	defer a.Close()
+	dstStorage, dstDirActualPath, err := op.GetStorageAndActualPath(t.DstDirPath)
+	if err != nil {
+		return errors.WithMessage(err, "failed get dst storage")
+	}
+	var user *model.User
+	if t.Creator != "" {
+		if user, err = op.GetUserByName(t.Creator); err != nil {
+			return errors.WithMessagef(err, "failed get creator %s", t.Creator)
+		}
+	}
+	entries, err := a.entries(t.Ctx(), false)
+	if err != nil {
+		return errors.WithMessage(err, "failed list archive")
+	}
+	inner := archive.CleanPath(t.InnerPath)
+	// the entries are put relative to the parent of inner path
+	parent := stdpath.Dir(inner)
+	rel := func(path string) (string, bool) {
+		if inner != "" && path != inner && !strings.HasPrefix(path, inner+"/") {
+			return "", false
+		}
+		if parent == "." {
+			return path, true
+		}
+		return strings.TrimPrefix(path, parent+"/"), true
+	}
+	var total, done int64
+	for _, e := range entries {
+		if _, ok := rel(e.Path); ok && !e.IsDir {
+			total += e.Size
+		}
+	}
+	srcCtx := ratelimit.WithLimiters(t.Ctx(), ratelimit.Download(nil, a.storage.GetStorage())...)
+	dstCtx := ratelimit.WithLimiters(t.Ctx(), ratelimit.Upload(nil, dstStorage.GetStorage())...)
+	t.Status = "extracting"
+	return a.format.Walk(srcCtx, a.Source, func(e archive.Entry, r io.Reader) error {
+		p, ok := rel(e.Path)
+		if !ok || p == "" {
+			return nil
+		}
+		dstPath := stdpath.Join(dstDirActualPath, p)
+		if e.IsDir {
+			return op.MakeDir(t.Ctx(), dstStorage, dstPath)
+		}
+		obj := &model.Object{
+			Name:     stdpath.Base(p),
+			Size:     e.Size,
+			Modified: e.Modified,
+		}
+		refund, err := op.UseQuota(user, dstStorage, stdpath.Dir(dstPath), obj)
+		if err != nil {
+			return err
+		}
+		s := &stream.FileStream{
+			Ctx:      dstCtx,
+			Obj:      obj,
+			Reader:   ratelimit.NewReader(dstCtx, archive.LimitReader(r, e.Size)),
+			Mimetype: utils.GetMimeType(obj.Name),
+		}
+		err = op.Put(t.Ctx(), dstStorage, stdpath.Dir(dstPath), s, nil)
+		if err != nil {
+			refund()
+			return errors.WithMessagef(err, "failed extract %s", e.Path)
+		}
+		done += e.Size
+		if total > 0 {
+			t.SetProgress(float64(done) / float64(total) * 100)
+		}
+		return nil
+	})
+}
+
+var ExtractTaskManager *tache.Manager[*ExtractTask]
+
+func archiveExtract(ctx context.Context, path, innerPath, dstDirPath string) (tache.TaskWithInfo, error) {
+	if _, err := archiveGet(ctx, path, innerPath); err != nil {
+		return nil, err
+	}
+	_, dstDirActualPath, err := op.GetStorageAndActualPath(dstDirPath)
+	if err != nil {
+		return nil, errors.WithMessage(err, "failed get dst storage")
+	}
+	if err = checkTrash(dstDirActualPath); err != nil {
+		return nil, err
+	}
+	t := &ExtractTask{
+		SrcObjPath: path,
+		InnerPath:  archive.CleanPath(innerPath),
+		DstDirPath: dstDirPath,
+	}
+	if user, ok := ctx.Value("user").(*model.User); ok {
+		t.Creator = user.Username
+	}
+	ExtractTaskManager.Add(t)
+	return t, nil
+}
@@ -5,14 +5,20 @@ import (
 	"fmt"
 	"net/http"
 	stdpath "path"
+	"strings"
+	"time"
 
 	"github.com/alist-org/alist/v3/internal/conf"
+	"github.com/alist-org/alist/v3/internal/db"
 	"github.com/alist-org/alist/v3/internal/driver"
 	"github.com/alist-org/alist/v3/internal/model"
 	"github.com/alist-org/alist/v3/internal/op"
+	"github.com/alist-org/alist/v3/internal/ratelimit"
+	"github.com/alist-org/alist/v3/internal/setting"
 	"github.com/alist-org/alist/v3/internal/stream"
 	"github.com/alist-org/alist/v3/pkg/utils"
 	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
 	"github.com/xhofe/tache"
 )
 
@@ -25,6 +31,23 @@ type CopyTask struct {
 	dstStorage   driver.Driver `json:"-"`
 	SrcStorageMp string        `json:"src_storage_mp"`
 	DstStorageMp string        `json:"dst_storage_mp"`
+	// the name of the copied object, the same as the src if empty
+	DstName string `json:"dst_name,omitempty"`
+	// verify the size and hash of the copied file
+	Verify bool `json:"verify"`
+	// the id of user who added the task, the copied files are charged to the quotas of the user
+	UserID uint `json:"user_id,omitempty"`
+	// the names of children whose copy tasks have been added,
+	// they are skipped if the task of dir is resumed
+	Dispatched []string `json:"dispatched,omitempty"`
+	// the state of upload if the dst storage supports resuming
+	Checkpoint      *driver.Checkpoint `json:"checkpoint,omitempty"`
+	checkpointSaved time.Time
+	// persists the tasks of the manager the task belongs to
+	persistTasks func() error
+	// runs the copy of child in the task instead of adding a copy task,
+	// it's inherited by the children
+	runChild func(child *CopyTask) error
 }
 
 func (t *CopyTask) GetName() string {
@@ -46,9 +69,39 @@ func (t *CopyTask) Run() error {
 	if err != nil {
 		return errors.WithMessage(err, "failed get storage")
 	}
+	t.persistTasks = func() error {
+		return persistTasks(CopyTaskManager, "copy", conf.Conf.Tasks.Copy.TaskPersistant)
+	}
 	return copyBetween2Storages(t, t.srcStorage, t.dstStorage, t.SrcObjPath, t.DstDirPath)
 }
 
+// the min interval between the persistence of checkpoints
+const checkpointInterval = 5 * time.Second
+
+// saveCheckpoint persists the tasks at most once per checkpointInterval. The
+// persistence of the manager is debounced, so it's delayed until no task makes progress.
+func (t *CopyTask) saveCheckpoint() {
+	if t.persistTasks == nil || time.Since(t.checkpointSaved) < checkpointInterval {
+		return
+	}
+	t.checkpointSaved = time.Now()
+	if err := t.persistTasks(); err != nil {
+		log.Warnf("failed save checkpoint of task: %+v", err)
+	}
+}
+
+// persistTasks saves the tasks of manager immediately
+func persistTasks[T tache.Task](m *tache.Manager[T], key string, enabled bool) error {
+	if !enabled {
+		return nil
+	}
+	data, err := utils.Json.Marshal(m.GetAll())
+	if err != nil {
+		return err
+	}
+	return db.UpdateTaskDataFunc(key, true)(data)
+}
+
 var CopyTaskManager *tache.Manager[*CopyTask]
 
 // Copy if in the same storage, call move method
@@ -62,9 +115,21 @@ func _copy(ctx context.Context, srcObjPath, dstDirPath string, lazyCache ...bool
 	if err != nil {
 		return nil, errors.WithMessage(err, "failed get dst storage")
 	}
+	if err = checkTrash(srcObjActualPath, stdpath.Join(dstDirActualPath, stdpath.Base(srcObjActualPath))); err != nil {
+		return nil, err
+	}
+	user, _ := ctx.Value("user").(*model.User)
 	// copy if in the same storage, just call driver.Copy
 	if srcStorage.GetStorage() == dstStorage.GetStorage() {
-		return nil, op.Copy(ctx, srcStorage, srcObjActualPath, dstDirActualPath, lazyCache...)
+		dstPath := stdpath.Join(dstDirActualPath, stdpath.Base(srcObjActualPath))
+		refund, err := op.UseQuotaOfTree(ctx, user, srcStorage, srcObjActualPath, dstPath, false)
+		if err != nil {
+			return nil, err
+		}
+		if err = op.Copy(ctx, srcStorage, srcObjActualPath, dstDirActualPath, lazyCache...); err != nil {
+			refund()
+		}
+		return nil, err
 	}
 	if ctx.Value(conf.NoTaskKey) != nil {
 		srcObj, err := op.Get(ctx, srcStorage, srcObjActualPath)
@@ -81,14 +146,24 @@ func _copy(ctx context.Context, srcObjPath, dstDirPath string, lazyCache ...bool
 			}
 			fs := stream.FileStream{
 				Obj: srcObj,
-				Ctx: ctx,
+				Ctx: ratelimit.WithLimiters(ctx, ratelimit.Transfer(srcStorage.GetStorage(), dstStorage.GetStorage())...),
 			}
 			// any link provided is seekable
 			ss, err := stream.NewSeekableStream(fs, link)
 			if err != nil {
 				return nil, errors.WithMessagef(err, "failed get [%s] stream", srcObjPath)
 			}
-			return nil, op.Put(ctx, dstStorage, dstDirActualPath, ss, nil, false)
+			refund, err := op.UseQuota(user, dstStorage, dstDirActualPath, srcObj)
+			if err != nil {
+				return nil, err
+			}
+			err = op.Put(ctx, dstStorage, dstDirActualPath, ss, nil, false)
+			if err != nil {
+				refund()
+			} else if setting.GetBool(conf.CopyVerify) {
+				err = verifyCopied(ctx, srcObj, dstStorage, stdpath.Join(dstDirActualPath, srcObj.GetName()))
+			}
+			return nil, err
 		}
 	}
 	// not in the same storage
@@ -99,6 +174,17 @@ func _copy(ctx context.Context, srcObjPath, dstDirPath string, lazyCache ...bool
 		DstDirPath:   dstDirActualPath,
 		SrcStorageMp: srcStorage.GetStorage().MountPath,
 		DstStorageMp: dstStorage.GetStorage().MountPath,
+		Verify:       setting.GetBool(conf.CopyVerify),
+	}
+	if user != nil {
+		t.UserID = user.ID
+	}
+	if ctx.Value(conf.SingleTaskKey) != nil {
+		t.runChild = func(child *CopyTask) error {
+			child.SetCtx(t.Ctx())
+			t.Status = fmt.Sprintf("copying %s", child.SrcObjPath)
+			return copyBetween2Storages(child, srcStorage, dstStorage, child.SrcObjPath, child.DstDirPath)
+		}
 	}
 	CopyTaskManager.Add(t)
 	return t, nil
@@ -110,7 +196,16 @@ func copyBetween2Storages(t *CopyTask, srcStorage, dstStorage driver.Driver, src
 	if err != nil {
 		return errors.WithMessagef(err, "failed get src [%s] file", srcObjPath)
 	}
+	dstName := srcObj.GetName()
+	if t.DstName != "" {
+		dstName = t.DstName
+	}
 	if srcObj.IsDir() {
+		// made before the children, so the empty dirs are copied too
+		t.Status = "src object is dir, making dst dir"
+		if err = op.MakeDir(t.Ctx(), dstStorage, stdpath.Join(dstDirPath, dstName)); err != nil {
+			return errors.WithMessagef(err, "failed make dst dir [%s]", stdpath.Join(dstDirPath, dstName))
+		}
 		t.Status = "src object is dir, listing objs"
 		objs, err := op.List(t.Ctx(), srcStorage, srcObjPath, model.ListArgs{})
 		if err != nil {
@@ -120,16 +215,32 @@ func copyBetween2Storages(t *CopyTask, srcStorage, dstStorage driver.Driver, src
 			if utils.IsCanceled(t.Ctx()) {
 				return nil
 			}
+			if utils.SliceContains(t.Dispatched, obj.GetName()) {
+				continue
+			}
 			srcObjPath := stdpath.Join(srcObjPath, obj.GetName())
-			dstObjPath := stdpath.Join(dstDirPath, srcObj.GetName())
-			CopyTaskManager.Add(&CopyTask{
+			dstObjPath := stdpath.Join(dstDirPath, dstName)
+			child := &CopyTask{
 				srcStorage:   srcStorage,
 				dstStorage:   dstStorage,
 				SrcObjPath:   srcObjPath,
 				DstDirPath:   dstObjPath,
 				SrcStorageMp: srcStorage.GetStorage().MountPath,
 				DstStorageMp: dstStorage.GetStorage().MountPath,
-			})
+				Verify:       t.Verify,
+				UserID:       t.UserID,
+				runChild:     t.runChild,
+			}
+			if t.runChild != nil {
+				if err = t.runChild(child); err != nil {
+					return errors.WithMessagef(err, "failed copy [%s]", srcObjPath)
+				}
+				t.Dispatched = append(t.Dispatched, obj.GetName())
+				continue
+			}
+			// recorded before added, so both are persisted by Add
+			t.Dispatched = append(t.Dispatched, obj.GetName())
+			CopyTaskManager.Add(child)
 		}
 		t.Status = "src object is dir, added all copy tasks of objs"
 		return nil
@@ -148,14 +259,73 @@ func copyFileBetween2Storages(tsk *CopyTask, srcStorage, dstStorage driver.Drive
 	if err != nil {
 		return errors.WithMessagef(err, "failed get [%s] link", srcFilePath)
 	}
+	var dstFile model.Obj = srcFile
+	if tsk.DstName != "" {
+		dstFile = &model.ObjWrapName{Name: tsk.DstName, Obj: srcFile}
+	}
 	fs := stream.FileStream{
-		Obj: srcFile,
-		Ctx: tsk.Ctx(),
+		Obj: dstFile,
+		Ctx: ratelimit.WithLimiters(tsk.Ctx(), ratelimit.Transfer(srcStorage.GetStorage(), dstStorage.GetStorage())...),
 	}
 	// any link provided is seekable
 	ss, err := stream.NewSeekableStream(fs, link)
 	if err != nil {
 		return errors.WithMessagef(err, "failed get [%s] stream", srcFilePath)
 	}
-	return op.Put(tsk.Ctx(), dstStorage, dstDirPath, ss, tsk.SetProgress, true)
+	// the checkpoint of another version of src file is discarded
+	if tsk.Checkpoint == nil || !tsk.Checkpoint.Valid(srcFile) {
+		tsk.Checkpoint = driver.NewCheckpoint(srcFile, tsk.saveCheckpoint)
+	} else {
+		tsk.Checkpoint.SetSave(tsk.saveCheckpoint)
+	}
+	refund, err := tsk.useQuota(dstStorage, dstDirPath, dstFile)
+	if err != nil {
+		return err
+	}
+	err = op.PutResumable(tsk.Ctx(), dstStorage, dstDirPath, ss, tsk.Checkpoint, tsk.SetProgress, true)
+	if err != nil {
+		refund()
+		return err
+	}
+	tsk.Checkpoint = nil
+	if tsk.Verify {
+		tsk.Status = "verifying dst file"
+		return verifyCopied(tsk.Ctx(), srcFile, dstStorage, stdpath.Join(dstDirPath, dstFile.GetName()))
+	}
+	return nil
+}
+
+// useQuota charges the copied file to the quotas of the user who added the task
+func (t *CopyTask) useQuota(dstStorage driver.Driver, dstDirPath string, file model.Obj) (refund func(), err error) {
+	var user *model.User
+	if t.UserID != 0 {
+		if user, err = op.GetUserById(t.UserID); err != nil {
+			return nil, errors.WithMessage(err, "failed get the user of task")
+		}
+	}
+	return op.UseQuota(user, dstStorage, dstDirPath, file)
+}
+
+// verifyCopied checks the size of copied file, and the hash if both storages provide the same type
+func verifyCopied(ctx context.Context, srcFile model.Obj, dstStorage driver.Driver, dstFilePath string) error {
+	// the dst dir may be cached before the file copied
+	op.ClearCache(dstStorage, stdpath.Dir(dstFilePath))
+	dstFile, err := op.Get(ctx, dstStorage, dstFilePath)
+	if err != nil {
+		return errors.WithMessagef(err, "failed get dst [%s] file", dstFilePath)
+	}
+	if dstFile.GetSize() != srcFile.GetSize() {
+		return errors.Errorf("verify failed, size of dst [%s] is %d, expect %d", dstFilePath, dstFile.GetSize(), srcFile.GetSize())
+	}
+	for _, ht := range []*utils.HashType{utils.MD5, utils.SHA1, utils.SHA256} {
+		srcHash, dstHash := srcFile.GetHash().GetHash(ht), dstFile.GetHash().GetHash(ht)
+		if srcHash == "" || dstHash == "" {
+			continue
+		}
+		if !strings.EqualFold(srcHash, dstHash) {
+			return errors.Errorf("verify failed, %s of dst [%s] is %s, expect %s", ht.Name, dstFilePath, dstHash, srcHash)
+		}
+		break
+	}
+	return nil
 }
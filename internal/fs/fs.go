@@ -2,6 +2,10 @@ package fs
 
 import (
 	"context"
+	"io"
+	stdpath "path"
+
+	"github.com/alist-org/alist/v3/internal/audit"
 	"github.com/alist-org/alist/v3/internal/driver"
 	"github.com/alist-org/alist/v3/internal/model"
 	"github.com/alist-org/alist/v3/internal/op"
@@ -20,6 +24,7 @@ type ListArgs struct {
 
 func List(ctx context.Context, path string, args *ListArgs) ([]model.Obj, error) {
 	res, err := list(ctx, path, args)
+	audit.Record(ctx, model.AuditList, path, "", err)
 	if err != nil {
 		if !args.NoLog {
 			log.Errorf("failed list %s: %+v", path, err)
@@ -44,6 +49,8 @@ func Get(ctx context.Context, path string, args *GetArgs) (model.Obj, error) {
 	return res, nil
 }
 
+// Link isn't recorded in audit log since it's used by previews and thumbnails
+// too, the downloads are recorded by the frontends
 func Link(ctx context.Context, path string, args model.LinkArgs) (*model.Link, model.Obj, error) {
 	res, file, err := link(ctx, path, args)
 	if err != nil {
@@ -55,22 +62,35 @@ func Link(ctx context.Context, path string, args model.LinkArgs) (*model.Link, m
 
 func MakeDir(ctx context.Context, path string, lazyCache ...bool) error {
 	err := makeDir(ctx, path, lazyCache...)
+	audit.Record(ctx, model.AuditMkdir, path, "", err)
 	if err != nil {
 		log.Errorf("failed make dir %s: %+v", path, err)
 	}
 	return err
 }
 
-func Move(ctx context.Context, srcPath, dstDirPath string, lazyCache ...bool) error {
-	err := move(ctx, srcPath, dstDirPath, lazyCache...)
+func Move(ctx context.Context, srcPath, dstDirPath string, lazyCache ...bool) (tache.TaskWithInfo, error) {
+	res, err := _move(ctx, srcPath, dstDirPath, "", lazyCache...)
+	audit.Record(ctx, model.AuditMove, srcPath, stdpath.Join(dstDirPath, stdpath.Base(srcPath)), err)
 	if err != nil {
 		log.Errorf("failed move %s to %s: %+v", srcPath, dstDirPath, err)
 	}
-	return err
+	return res, err
+}
+
+// MoveAs moves the obj to dstPath, which may have a different name
+func MoveAs(ctx context.Context, srcPath, dstPath string, lazyCache ...bool) (tache.TaskWithInfo, error) {
+	res, err := _move(ctx, srcPath, stdpath.Dir(dstPath), stdpath.Base(dstPath), lazyCache...)
+	audit.Record(ctx, model.AuditMove, srcPath, dstPath, err)
+	if err != nil {
+		log.Errorf("failed move %s to %s: %+v", srcPath, dstPath, err)
+	}
+	return res, err
 }
 
 func Copy(ctx context.Context, srcObjPath, dstDirPath string, lazyCache ...bool) (tache.TaskWithInfo, error) {
 	res, err := _copy(ctx, srcObjPath, dstDirPath, lazyCache...)
+	audit.Record(ctx, model.AuditCopy, srcObjPath, stdpath.Join(dstDirPath, stdpath.Base(srcObjPath)), err)
 	if err != nil {
 		log.Errorf("failed copy %s to %s: %+v", srcObjPath, dstDirPath, err)
 	}
@@ -79,6 +99,7 @@ func Copy(ctx context.Context, srcObjPath, dstDirPath string, lazyCache ...bool)
 
 func Rename(ctx context.Context, srcPath, dstName string, lazyCache ...bool) error {
 	err := rename(ctx, srcPath, dstName, lazyCache...)
+	audit.Record(ctx, model.AuditRename, srcPath, stdpath.Join(stdpath.Dir(srcPath), dstName), err)
 	if err != nil {
 		log.Errorf("failed rename %s to %s: %+v", srcPath, dstName, err)
 	}
@@ -87,6 +108,7 @@ func Rename(ctx context.Context, srcPath, dstName string, lazyCache ...bool) err
 
 func Remove(ctx context.Context, path string) error {
 	err := remove(ctx, path)
+	audit.Record(ctx, model.AuditRemove, path, "", err)
 	if err != nil {
 		log.Errorf("failed remove %s: %+v", path, err)
 	}
@@ -95,14 +117,16 @@ func Remove(ctx context.Context, path string) error {
 
 func PutDirectly(ctx context.Context, dstDirPath string, file model.FileStreamer, lazyCache ...bool) error {
 	err := putDirectly(ctx, dstDirPath, file, lazyCache...)
+	audit.Record(ctx, model.AuditUpload, stdpath.Join(dstDirPath, file.GetName()), "", err)
 	if err != nil {
 		log.Errorf("failed put %s: %+v", dstDirPath, err)
 	}
 	return err
 }
 
-func PutAsTask(dstDirPath string, file model.FileStreamer) (tache.TaskWithInfo, error) {
-	t, err := putAsTask(dstDirPath, file)
+func PutAsTask(ctx context.Context, dstDirPath string, file model.FileStreamer) (tache.TaskWithInfo, error) {
+	t, err := putAsTask(ctx, dstDirPath, file)
+	audit.Record(ctx, model.AuditUpload, stdpath.Join(dstDirPath, file.GetName()), "", err)
 	if err != nil {
 		log.Errorf("failed put %s: %+v", dstDirPath, err)
 	}
@@ -127,3 +151,73 @@ func Other(ctx context.Context, args model.FsOtherArgs) (interface{}, error) {
 	}
 	return res, err
 }
+
+// ArchiveList lists the dir at innerPath in the archive at path
+func ArchiveList(ctx context.Context, path, innerPath string, args *ListArgs) ([]model.Obj, error) {
+	res, err := archiveList(ctx, path, innerPath, args)
+	if err != nil {
+		if !args.NoLog {
+			log.Errorf("failed list archive %s(%s): %+v", path, innerPath, err)
+		}
+		return nil, err
+	}
+	return res, nil
+}
+
+func ArchiveGet(ctx context.Context, path, innerPath string) (model.Obj, error) {
+	res, err := archiveGet(ctx, path, innerPath)
+	if err != nil {
+		log.Warnf("failed get archive %s(%s): %s", path, innerPath, err)
+		return nil, err
+	}
+	return res, nil
+}
+
+func ArchiveLink(ctx context.Context, path, innerPath string) (*model.Link, model.Obj, error) {
+	res, file, err := archiveLink(ctx, path, innerPath)
+	audit.Record(ctx, model.AuditDownload, stdpath.Join(path, innerPath), "", err)
+	if err != nil {
+		log.Errorf("failed link archive %s(%s): %+v", path, innerPath, err)
+		return nil, nil, err
+	}
+	return res, file, nil
+}
+
+// ArchiveExtract adds the task to extract the file or dir at innerPath in the archive at path to dstDirPath
+func ArchiveExtract(ctx context.Context, path, innerPath, dstDirPath string) (tache.TaskWithInfo, error) {
+	res, err := archiveExtract(ctx, path, innerPath, dstDirPath)
+	audit.Record(ctx, model.AuditExtract, stdpath.Join(path, innerPath), dstDirPath, err)
+	if err != nil {
+		log.Errorf("failed extract %s(%s) to %s: %+v", path, innerPath, dstDirPath, err)
+	}
+	return res, err
+}
+
+// Pack writes the objs named names in dir into an archive in the format of args to w
+func Pack(ctx context.Context, w io.Writer, dir string, names []string, args *PackArgs) error {
+	err := pack(ctx, w, dir, names, args)
+	audit.Record(ctx, model.AuditPack, dir, "", err)
+	if err != nil {
+		log.Errorf("failed pack %v in %s: %+v", names, dir, err)
+	}
+	return err
+}
+
+// PackAsTask adds the task to pack the objs named names in dir into the archive named name in dstDirPath
+func PackAsTask(ctx context.Context, dir string, names []string, dstDirPath, name string, args *PackArgs) (tache.TaskWithInfo, error) {
+	t, err := packAsTask(ctx, dir, names, dstDirPath, name, args)
+	audit.Record(ctx, model.AuditPack, dir, stdpath.Join(dstDirPath, name), err)
+	if err != nil {
+		log.Errorf("failed pack %v in %s to %s: %+v", names, dir, dstDirPath, err)
+	}
+	return t, err
+}
+
+// WarmUp adds the task to read all files in the dir at path, which fills the caches of the storage
+func WarmUp(ctx context.Context, path string) (tache.TaskWithInfo, error) {
+	t, err := warmUp(ctx, path)
+	if err != nil {
+		log.Errorf("failed warm up %s: %+v", path, err)
+	}
+	return t, err
+}
@@ -35,5 +35,8 @@ func get(ctx context.Context, path string) (model.Obj, error) {
 		}
 		return nil, errors.WithMessage(err, "failed get storage")
 	}
+	if err = checkTrash(actualPath); err != nil {
+		return nil, err
+	}
 	return op.Get(ctx, storage, actualPath)
 }
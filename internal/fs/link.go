@@ -16,6 +16,9 @@ func link(ctx context.Context, path string, args model.LinkArgs) (*model.Link, m
 	if err != nil {
 		return nil, nil, errors.WithMessage(err, "failed get storage")
 	}
+	if err = checkTrash(actualPath); err != nil {
+		return nil, nil, err
+	}
 	l, obj, err := op.Link(ctx, storage, actualPath, args)
 	if err != nil {
 		return nil, nil, errors.WithMessage(err, "failed link")
@@ -2,7 +2,9 @@ package fs
 
 import (
 	"context"
+	"strings"
 
+	"github.com/alist-org/alist/v3/internal/errs"
 	"github.com/alist-org/alist/v3/internal/model"
 	"github.com/alist-org/alist/v3/internal/op"
 	"github.com/alist-org/alist/v3/pkg/utils"
@@ -22,6 +24,9 @@ func list(ctx context.Context, path string, args *ListArgs) ([]model.Obj, error)
 
 	var _objs []model.Obj
 	if storage != nil {
+		if err = checkTrash(actualPath); err != nil {
+			return nil, err
+		}
 		_objs, err = op.List(ctx, storage, actualPath, model.ListArgs{
 			ReqPath: path,
 			Refresh: args.Refresh,
@@ -34,6 +39,9 @@ func list(ctx context.Context, path string, args *ListArgs) ([]model.Obj, error)
 				return nil, errors.WithMessage(err, "failed get objs")
 			}
 		}
+		if actualPath == "/" {
+			_objs = hideTrashDir(_objs)
+		}
 	}
 
 	om := model.NewObjMerge()
@@ -44,6 +52,29 @@ func list(ctx context.Context, path string, args *ListArgs) ([]model.Obj, error)
 	return objs, nil
 }
 
+// hideTrashDir hides the recycle bin in the root of storage,
+// the objects in it are managed by the trash api
+func hideTrashDir(objs []model.Obj) []model.Obj {
+	name := strings.TrimPrefix(op.GetTrashDir(), "/")
+	for i, obj := range objs {
+		if obj.GetName() == name {
+			return append(objs[:i:i], objs[i+1:]...)
+		}
+	}
+	return objs
+}
+
+// checkTrash refuses the actual paths in the recycle bin as if they don't
+// exist, the objects in it are only accessed by the trash api
+func checkTrash(actualPaths ...string) error {
+	for _, p := range actualPaths {
+		if op.IsInTrash(p) {
+			return errors.WithStack(errs.ObjectNotFound)
+		}
+	}
+	return nil
+}
+
 func whetherHide(user *model.User, meta *model.Meta, path string) bool {
 	// if is admin, don't hide
 	if user == nil || user.CanSeeHides() {
@@ -0,0 +1,122 @@
+package fs
+
+import (
+	"context"
+	"fmt"
+	stdpath "path"
+
+	"github.com/alist-org/alist/v3/internal/conf"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/internal/op"
+	"github.com/alist-org/alist/v3/internal/setting"
+	"github.com/alist-org/alist/v3/pkg/utils"
+	"github.com/pkg/errors"
+	"github.com/xhofe/tache"
+)
+
+// MoveTask moves the object between storages by copying it and removing the src
+type MoveTask struct {
+	CopyTask
+}
+
+func (t *MoveTask) GetName() string {
+	return fmt.Sprintf("move [%s](%s) to [%s](%s)", t.SrcStorageMp, t.SrcObjPath, t.DstStorageMp, t.DstDirPath)
+}
+
+func (t *MoveTask) Run() error {
+	var err error
+	if t.srcStorage == nil {
+		t.srcStorage, err = op.GetStorageByMountPath(t.SrcStorageMp)
+	}
+	if t.dstStorage == nil {
+		t.dstStorage, err = op.GetStorageByMountPath(t.DstStorageMp)
+	}
+	if err != nil {
+		return errors.WithMessage(err, "failed get storage")
+	}
+	t.persistTasks = func() error {
+		return persistTasks(MoveTaskManager, "move", conf.Conf.Tasks.Move.TaskPersistant)
+	}
+	// the children are copied in the task, so the src is removed after all of them succeeded
+	t.runChild = func(child *CopyTask) error {
+		child.SetCtx(t.Ctx())
+		t.Status = fmt.Sprintf("copying %s", child.SrcObjPath)
+		return copyBetween2Storages(child, t.srcStorage, t.dstStorage, child.SrcObjPath, child.DstDirPath)
+	}
+	if err = copyBetween2Storages(&t.CopyTask, t.srcStorage, t.dstStorage, t.SrcObjPath, t.DstDirPath); err != nil {
+		return err
+	}
+	if utils.IsCanceled(t.Ctx()) {
+		return t.Ctx().Err()
+	}
+	t.Status = "removing src object"
+	// the src has been copied, so it's not moved to the recycle bin
+	if err = op.RemovePermanently(t.Ctx(), t.srcStorage, t.SrcObjPath); err != nil {
+		return errors.WithMessagef(err, "failed remove src [%s]", t.SrcObjPath)
+	}
+	t.Status = "moved"
+	t.SetProgress(100)
+	return nil
+}
+
+var MoveTaskManager *tache.Manager[*MoveTask]
+
+// _move calls the move method if in the same storage,
+// if not, adds a move task or moves directly without task.
+// The obj is renamed to dstName if it's not empty.
+func _move(ctx context.Context, srcPath, dstDirPath, dstName string, lazyCache ...bool) (tache.TaskWithInfo, error) {
+	srcStorage, srcActualPath, err := op.GetStorageAndActualPath(srcPath)
+	if err != nil {
+		return nil, errors.WithMessage(err, "failed get src storage")
+	}
+	dstStorage, dstDirActualPath, err := op.GetStorageAndActualPath(dstDirPath)
+	if err != nil {
+		return nil, errors.WithMessage(err, "failed get dst storage")
+	}
+	srcName := stdpath.Base(srcActualPath)
+	if dstName == srcName {
+		dstName = ""
+	}
+	if err = checkTrash(srcActualPath, stdpath.Join(dstDirActualPath, srcName)); err != nil {
+		return nil, err
+	}
+	if dstName != "" {
+		if err = checkTrash(stdpath.Join(dstDirActualPath, dstName)); err != nil {
+			return nil, err
+		}
+	}
+	user, _ := ctx.Value("user").(*model.User)
+	if srcStorage.GetStorage() == dstStorage.GetStorage() {
+		refund, err := op.UseQuotaOfTree(ctx, user, srcStorage, srcActualPath, stdpath.Join(dstDirActualPath, srcName), true)
+		if err != nil {
+			return nil, err
+		}
+		if err = op.Move(ctx, srcStorage, srcActualPath, dstDirActualPath, lazyCache...); err != nil {
+			refund()
+			return nil, err
+		}
+		if dstName == "" {
+			return nil, nil
+		}
+		return nil, op.Rename(ctx, dstStorage, stdpath.Join(dstDirActualPath, srcName), dstName, lazyCache...)
+	}
+	t := &MoveTask{CopyTask{
+		srcStorage:   srcStorage,
+		dstStorage:   dstStorage,
+		SrcObjPath:   srcActualPath,
+		DstDirPath:   dstDirActualPath,
+		SrcStorageMp: srcStorage.GetStorage().MountPath,
+		DstStorageMp: dstStorage.GetStorage().MountPath,
+		Verify:       setting.GetBool(conf.CopyVerify),
+		DstName:      dstName,
+	}}
+	if user != nil {
+		t.UserID = user.ID
+	}
+	if ctx.Value(conf.NoTaskKey) != nil {
+		t.SetCtx(ctx)
+		return nil, t.Run()
+	}
+	MoveTaskManager.Add(t)
+	return t, nil
+}
@@ -0,0 +1,110 @@
+package fs
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	_ "github.com/alist-org/alist/v3/drivers/local"
+	"github.com/alist-org/alist/v3/internal/conf"
+	"github.com/alist-org/alist/v3/internal/db"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/internal/op"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func init() {
+	dB, err := gorm.Open(sqlite.Open("file::memory:?cache=shared"), &gorm.Config{})
+	if err != nil {
+		panic("failed to connect database")
+	}
+	conf.Conf = conf.DefaultConfig()
+	db.Init(dB)
+}
+
+// mount mounts a Local storage of a temp dir with the files, the names of dirs end with /
+func mount(t *testing.T, mountPath string, files []string) string {
+	root := t.TempDir()
+	for _, name := range files {
+		p := filepath.Join(root, filepath.FromSlash(name))
+		if strings.HasSuffix(name, "/") {
+			if err := os.MkdirAll(p, 0777); err != nil {
+				t.Fatal(err)
+			}
+			continue
+		}
+		if err := os.MkdirAll(filepath.Dir(p), 0777); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(p, []byte(name), 0666); err != nil {
+			t.Fatal(err)
+		}
+	}
+	id, err := op.CreateStorage(context.Background(), model.Storage{
+		Driver:    "Local",
+		MountPath: mountPath,
+		Addition:  `{"root_folder_path":"` + filepath.ToSlash(root) + `"}`,
+	})
+	if err != nil {
+		t.Fatalf("failed to create storage: %+v", err)
+	}
+	t.Cleanup(func() {
+		_ = op.DeleteStorageById(context.Background(), id)
+	})
+	return root
+}
+
+func TestMoveBetweenStorages(t *testing.T) {
+	tests := []struct {
+		name    string
+		srcPath string
+		dstName string
+		files   []string
+		// the paths in dst storage after moved
+		want []string
+	}{
+		{
+			name:    "tree with empty dirs",
+			srcPath: "/src/dir",
+			files:   []string{"dir/a.txt", "dir/empty/", "dir/sub/b.txt", "dir/sub/empty/"},
+			want:    []string{"dir/a.txt", "dir/empty/", "dir/sub/b.txt", "dir/sub/empty/"},
+		},
+		{
+			name:    "empty dir",
+			srcPath: "/src/empty",
+			files:   []string{"empty/"},
+			want:    []string{"empty/"},
+		},
+		{
+			name:    "empty dir renamed",
+			srcPath: "/src/empty",
+			dstName: "renamed",
+			files:   []string{"empty/"},
+			want:    []string{"renamed/"},
+		},
+	}
+	admin := &model.User{Username: "admin", Role: model.ADMIN, BasePath: "/"}
+	ctx := context.WithValue(context.Background(), "user", admin)
+	ctx = context.WithValue(ctx, conf.NoTaskKey, struct{}{})
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			src := mount(t, "/src", tt.files)
+			dst := mount(t, "/dst", nil)
+			if _, err := _move(ctx, tt.srcPath, "/dst", tt.dstName); err != nil {
+				t.Fatalf("move error = %+v", err)
+			}
+			for _, name := range tt.want {
+				info, err := os.Stat(filepath.Join(dst, filepath.FromSlash(name)))
+				if err != nil || info.IsDir() != strings.HasSuffix(name, "/") {
+					t.Errorf("%s isn't moved: %v", name, err)
+				}
+			}
+			if _, err := os.Stat(filepath.Join(src, filepath.FromSlash(strings.TrimPrefix(tt.srcPath, "/src/")))); !os.IsNotExist(err) {
+				t.Errorf("the src is left: %v", err)
+			}
+		})
+	}
+}
@@ -2,8 +2,8 @@ package fs
 
 import (
 	"context"
+	stdpath "path"
 
-	"github.com/alist-org/alist/v3/internal/errs"
 	"github.com/alist-org/alist/v3/internal/model"
 	"github.com/alist-org/alist/v3/internal/op"
 	"github.com/pkg/errors"
@@ -14,22 +14,10 @@ func makeDir(ctx context.Context, path string, lazyCache ...bool) error {
 	if err != nil {
 		return errors.WithMessage(err, "failed get storage")
 	}
-	return op.MakeDir(ctx, storage, actualPath, lazyCache...)
-}
-
-func move(ctx context.Context, srcPath, dstDirPath string, lazyCache ...bool) error {
-	srcStorage, srcActualPath, err := op.GetStorageAndActualPath(srcPath)
-	if err != nil {
-		return errors.WithMessage(err, "failed get src storage")
-	}
-	dstStorage, dstDirActualPath, err := op.GetStorageAndActualPath(dstDirPath)
-	if err != nil {
-		return errors.WithMessage(err, "failed get dst storage")
-	}
-	if srcStorage.GetStorage() != dstStorage.GetStorage() {
-		return errors.WithStack(errs.MoveBetweenTwoStorages)
+	if err = checkTrash(actualPath); err != nil {
+		return err
 	}
-	return op.Move(ctx, srcStorage, srcActualPath, dstDirActualPath, lazyCache...)
+	return op.MakeDir(ctx, storage, actualPath, lazyCache...)
 }
 
 func rename(ctx context.Context, srcPath, dstName string, lazyCache ...bool) error {
@@ -37,6 +25,9 @@ func rename(ctx context.Context, srcPath, dstName string, lazyCache ...bool) err
 	if err != nil {
 		return errors.WithMessage(err, "failed get storage")
 	}
+	if err = checkTrash(srcActualPath, stdpath.Join(stdpath.Dir(srcActualPath), dstName)); err != nil {
+		return err
+	}
 	return op.Rename(ctx, storage, srcActualPath, dstName, lazyCache...)
 }
 
@@ -45,6 +36,9 @@ func remove(ctx context.Context, path string) error {
 	if err != nil {
 		return errors.WithMessage(err, "failed get storage")
 	}
+	if err = checkTrash(actualPath); err != nil {
+		return err
+	}
 	return op.Remove(ctx, storage, actualPath)
 }
 
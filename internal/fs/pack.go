@@ -0,0 +1,141 @@
+package fs
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"os"
+	stdpath "path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/alist-org/alist/v3/internal/archive"
+	"github.com/alist-org/alist/v3/internal/conf"
+	"github.com/alist-org/alist/v3/internal/errs"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/internal/op"
+	"github.com/alist-org/alist/v3/internal/stream"
+	"github.com/alist-org/alist/v3/pkg/utils"
+	"github.com/pkg/errors"
+	"github.com/xhofe/tache"
+)
+
+type PackArgs struct {
+	// Format is one of archive.WriteFormats
+	Format string
+	// Filter reports whether the obj at path can be packed, the dirs filtered out are skipped
+	Filter func(path string, obj model.Obj) bool
+}
+
+// pack writes the objs named names in dir into an archive, the dirs are packed recursively
+func pack(ctx context.Context, w io.Writer, dir string, names []string, args *PackArgs) error {
+	aw, err := archive.NewWriter(w, args.Format)
+	if err != nil {
+		return err
+	}
+	for _, name := range names {
+		path := stdpath.Join(dir, name)
+		obj, err := get(ctx, path)
+		if err != nil {
+			return errors.WithMessagef(err, "failed get %s", path)
+		}
+		err = WalkFS(ctx, -1, path, obj, func(reqPath string, obj model.Obj) error {
+			if utils.IsCanceled(ctx) {
+				return ctx.Err()
+			}
+			if args.Filter != nil && !args.Filter(reqPath, obj) {
+				if obj.IsDir() {
+					return filepath.SkipDir
+				}
+				return nil
+			}
+			rel := strings.TrimPrefix(reqPath, dir)
+			if obj.IsDir() {
+				return aw.Mkdir(rel, obj.ModTime())
+			}
+			return packFile(ctx, aw, reqPath, rel, obj)
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return aw.Close()
+}
+
+func packFile(ctx context.Context, aw archive.Writer, path, rel string, obj model.Obj) error {
+	storage, actualPath, err := op.GetStorageAndActualPath(path)
+	if err != nil {
+		return errors.WithMessage(err, "failed get storage")
+	}
+	link, _, err := op.Link(ctx, storage, actualPath, model.LinkArgs{
+		Header: http.Header{},
+	})
+	if err != nil {
+		return errors.WithMessagef(err, "failed get [%s] link", path)
+	}
+	ss, err := stream.NewSeekableStream(stream.FileStream{Obj: obj, Ctx: ctx}, link)
+	if err != nil {
+		return errors.WithMessagef(err, "failed get [%s] stream", path)
+	}
+	defer ss.Close()
+	fw, err := aw.Create(rel, obj.GetSize(), obj.ModTime())
+	if err != nil {
+		return err
+	}
+	_, err = utils.CopyWithBuffer(fw, ss)
+	return errors.WithMessagef(err, "failed pack %s", path)
+}
+
+// packAsTask packs the objs into a temp file as the file named name, which is
+// uploaded to dstDirPath by an upload task
+func packAsTask(ctx context.Context, dir string, names []string, dstDirPath, name string, args *PackArgs) (tache.TaskWithInfo, error) {
+	if !utils.SliceContains(archive.WriteFormats, args.Format) {
+		return nil, errors.Errorf("unsupported archive format: %s", args.Format)
+	}
+	storage, dstDirActualPath, err := op.GetStorageAndActualPath(dstDirPath)
+	if err != nil {
+		return nil, errors.WithMessage(err, "failed get storage")
+	}
+	if storage.Config().NoUpload {
+		return nil, errors.WithStack(errs.UploadNotSupported)
+	}
+	user, _ := ctx.Value("user").(*model.User)
+	file := &stream.FileStream{
+		Obj: &model.Object{
+			Name:     name,
+			Modified: time.Now(),
+		},
+		Mimetype: utils.GetMimeType(name),
+	}
+	t := &UploadTask{
+		storage:          storage,
+		dstDirActualPath: dstDirActualPath,
+		file:             file,
+		refund:           func() {},
+	}
+	t.prepare = func(ctx context.Context) error {
+		tmpFile, err := os.CreateTemp(conf.Conf.TempDir, "pack-*")
+		if err != nil {
+			return errors.Wrap(err, "failed create temp file")
+		}
+		file.Ctx = ctx
+		file.Add(tmpFile)
+		file.SetTmpFile(tmpFile)
+		// the hidden objs are listed by the user
+		err = pack(context.WithValue(ctx, "user", user), tmpFile, dir, names, args)
+		if err == nil {
+			_, err = tmpFile.Seek(0, io.SeekStart)
+		}
+		if err == nil {
+			t.refund, err = op.UseQuota(user, storage, dstDirActualPath, file)
+		}
+		if err != nil {
+			t.refund = func() {}
+			_ = file.Close()
+		}
+		return err
+	}
+	UploadTaskManager.Add(t)
+	return t, nil
+}
@@ -3,6 +3,9 @@ package fs
 import (
 	"context"
 	"fmt"
+	stdpath "path"
+	"sync"
+
 	"github.com/alist-org/alist/v3/internal/driver"
 	"github.com/alist-org/alist/v3/internal/errs"
 	"github.com/alist-org/alist/v3/internal/model"
@@ -16,6 +19,11 @@ type UploadTask struct {
 	storage          driver.Driver
 	dstDirActualPath string
 	file             model.FileStreamer
+	// writes the content of file before uploading if it's not nil, like packing files into it
+	prepare func(ctx context.Context) error
+	// refunds the quotas charged by the upload
+	refund     func()
+	refundOnce sync.Once
 }
 
 func (t *UploadTask) GetName() string {
@@ -27,23 +35,51 @@ func (t *UploadTask) GetStatus() string {
 }
 
 func (t *UploadTask) Run() error {
+	if t.prepare != nil {
+		if err := t.prepare(t.Ctx()); err != nil {
+			return err
+		}
+		t.prepare = nil
+	}
 	return op.Put(t.Ctx(), t.storage, t.dstDirActualPath, t.file, t.SetProgress, true)
 }
 
+func (t *UploadTask) OnFailed() {
+	t.refundOnce.Do(t.refund)
+}
+
+// Cancel refunds the quotas if the task hasn't run, since OnFailed isn't called then
+func (t *UploadTask) Cancel() {
+	state := t.GetState()
+	t.Base.Cancel()
+	if state == tache.StatePending || state == tache.StateWaitingRetry {
+		t.refundOnce.Do(t.refund)
+	}
+}
+
 var UploadTaskManager *tache.Manager[*UploadTask]
 
 // putAsTask add as a put task and return immediately
-func putAsTask(dstDirPath string, file model.FileStreamer) (tache.TaskWithInfo, error) {
+func putAsTask(ctx context.Context, dstDirPath string, file model.FileStreamer) (tache.TaskWithInfo, error) {
 	storage, dstDirActualPath, err := op.GetStorageAndActualPath(dstDirPath)
 	if err != nil {
 		return nil, errors.WithMessage(err, "failed get storage")
 	}
+	if err = checkTrash(stdpath.Join(dstDirActualPath, file.GetName())); err != nil {
+		return nil, err
+	}
 	if storage.Config().NoUpload {
 		return nil, errors.WithStack(errs.UploadNotSupported)
 	}
+	user, _ := ctx.Value("user").(*model.User)
+	refund, err := op.UseQuota(user, storage, dstDirActualPath, file)
+	if err != nil {
+		return nil, err
+	}
 	if file.NeedStore() {
 		_, err := file.CacheFullInTempFile()
 		if err != nil {
+			refund()
 			return nil, errors.Wrapf(err, "failed to create temp file")
 		}
 		//file.SetReader(tempFile)
@@ -53,6 +89,7 @@ func putAsTask(dstDirPath string, file model.FileStreamer) (tache.TaskWithInfo,
 		storage:          storage,
 		dstDirActualPath: dstDirActualPath,
 		file:             file,
+		refund:           refund,
 	}
 	UploadTaskManager.Add(t)
 	return t, nil
@@ -64,8 +101,20 @@ func putDirectly(ctx context.Context, dstDirPath string, file model.FileStreamer
 	if err != nil {
 		return errors.WithMessage(err, "failed get storage")
 	}
+	if err = checkTrash(stdpath.Join(dstDirActualPath, file.GetName())); err != nil {
+		return err
+	}
 	if storage.Config().NoUpload {
 		return errors.WithStack(errs.UploadNotSupported)
 	}
-	return op.Put(ctx, storage, dstDirActualPath, file, nil, lazyCache...)
+	user, _ := ctx.Value("user").(*model.User)
+	refund, err := op.UseQuota(user, storage, dstDirActualPath, file)
+	if err != nil {
+		return err
+	}
+	err = op.Put(ctx, storage, dstDirActualPath, file, nil, lazyCache...)
+	if err != nil {
+		refund()
+	}
+	return err
 }
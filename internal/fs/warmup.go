@@ -0,0 +1,110 @@
+package fs
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/internal/op"
+	"github.com/alist-org/alist/v3/internal/ratelimit"
+	"github.com/alist-org/alist/v3/internal/stream"
+	"github.com/alist-org/alist/v3/pkg/http_range"
+	"github.com/alist-org/alist/v3/pkg/utils"
+	"github.com/pkg/errors"
+	"github.com/xhofe/tache"
+)
+
+// WarmUpTask reads all files in the dir once, which fills the caches of the
+// storage, like the blocks kept by the Cache driver
+type WarmUpTask struct {
+	tache.Base
+	Status string `json:"-"`
+	Path   string `json:"path"`
+}
+
+func (t *WarmUpTask) GetName() string {
+	return fmt.Sprintf("warm up [%s]", t.Path)
+}
+
+func (t *WarmUpTask) GetStatus() string {
+	return t.Status
+}
+
+func (t *WarmUpTask) Run() error {
+	t.Status = "listing"
+	storage, _, err := op.GetStorageAndActualPath(t.Path)
+	if err != nil {
+		return errors.WithMessage(err, "failed get storage")
+	}
+	obj, err := get(t.Ctx(), t.Path)
+	if err != nil {
+		return errors.WithMessagef(err, "failed get %s", t.Path)
+	}
+	var paths []string
+	var objs []model.Obj
+	var total, done int64
+	err = WalkFS(t.Ctx(), -1, t.Path, obj, func(reqPath string, obj model.Obj) error {
+		if utils.IsCanceled(t.Ctx()) {
+			return t.Ctx().Err()
+		}
+		if !obj.IsDir() {
+			paths = append(paths, reqPath)
+			objs = append(objs, obj)
+			total += obj.GetSize()
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	ctx := ratelimit.WithLimiters(t.Ctx(), ratelimit.Download(nil, storage.GetStorage())...)
+	for i, path := range paths {
+		t.Status = fmt.Sprintf("reading %d/%d", i+1, len(paths))
+		if err = warmUpFile(ctx, path, objs[i]); err != nil {
+			return err
+		}
+		done += objs[i].GetSize()
+		if total > 0 {
+			t.SetProgress(float64(done) * 100 / float64(total))
+		}
+	}
+	t.SetProgress(100)
+	return nil
+}
+
+func warmUpFile(ctx context.Context, path string, obj model.Obj) error {
+	storage, actualPath, err := op.GetStorageAndActualPath(path)
+	if err != nil {
+		return errors.WithMessage(err, "failed get storage")
+	}
+	link, _, err := op.Link(ctx, storage, actualPath, model.LinkArgs{
+		Header: http.Header{},
+	})
+	if err != nil {
+		return errors.WithMessagef(err, "failed get [%s] link", path)
+	}
+	rc, err := stream.GetRangeReaderFromLink(ctx, obj.GetSize(), link, http_range.Range{Length: -1})
+	if err != nil {
+		return errors.WithMessagef(err, "failed read %s", path)
+	}
+	defer rc.Close()
+	_, err = utils.CopyWithBuffer(io.Discard, ratelimit.NewReader(ctx, rc))
+	return errors.WithMessagef(err, "failed read %s", path)
+}
+
+var WarmUpTaskManager *tache.Manager[*WarmUpTask]
+
+func warmUp(ctx context.Context, path string) (tache.TaskWithInfo, error) {
+	obj, err := get(ctx, path)
+	if err != nil {
+		return nil, errors.WithMessagef(err, "failed get %s", path)
+	}
+	if !obj.IsDir() {
+		return nil, errors.Errorf("%s is not a dir", path)
+	}
+	t := &WarmUpTask{Path: path}
+	WarmUpTaskManager.Add(t)
+	return t, nil
+}
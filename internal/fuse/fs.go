@@ -1,170 +1,464 @@
-package fuse
+//go:build fuse
 
-import "github.com/winfsp/cgofuse/fuse"
+package fuse
 
+import (
+	"context"
+	"fmt"
+	stdpath "path"
+	"sync"
+	"time"
+
+	"github.com/alist-org/alist/v3/internal/conf"
+	"github.com/alist-org/alist/v3/internal/errs"
+	"github.com/alist-org/alist/v3/internal/fs"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/internal/op"
+	"github.com/alist-org/alist/v3/pkg/utils"
+	log "github.com/sirupsen/logrus"
+	"github.com/winfsp/cgofuse/fuse"
+)
+
+// Fs exposes the alist virtual tree under RootFolder as a fuse filesystem,
+// every callback is translated into the corresponding internal/fs call
 type Fs struct {
 	RootFolder string
 	fuse.FileSystemBase
-}
-
-func (fs *Fs) Init() {
-	//TODO implement me
-	panic("implement me")
-}
-
-func (fs *Fs) Destroy() {
-	//TODO implement me
-	panic("implement me")
-}
-
-func (fs *Fs) Statfs(path string, stat *fuse.Statfs_t) int {
-	//TODO implement me
-	panic("implement me")
-}
-
-func (fs *Fs) Mknod(path string, mode uint32, dev uint64) int {
-	//TODO implement me
-	panic("implement me")
-}
-
-func (fs *Fs) Mkdir(path string, mode uint32) int {
-	//TODO implement me
-	panic("implement me")
-}
-
-func (fs *Fs) Unlink(path string) int {
-	//TODO implement me
-	panic("implement me")
-}
-
-func (fs *Fs) Rmdir(path string) int {
-	//TODO implement me
-	panic("implement me")
-}
-
-func (fs *Fs) Link(oldpath string, newpath string) int {
-	//TODO implement me
-	panic("implement me")
-}
-
-func (fs *Fs) Symlink(target string, newpath string) int {
-	//TODO implement me
-	panic("implement me")
-}
-
-func (fs *Fs) Readlink(path string) (int, string) {
-	//TODO implement me
-	panic("implement me")
-}
-
-func (fs *Fs) Rename(oldpath string, newpath string) int {
-	//TODO implement me
-	panic("implement me")
-}
-
-func (fs *Fs) Chmod(path string, mode uint32) int {
-	//TODO implement me
-	panic("implement me")
-}
-
-func (fs *Fs) Chown(path string, uid uint32, gid uint32) int {
-	//TODO implement me
-	panic("implement me")
-}
-
-func (fs *Fs) Utimens(path string, tmsp []fuse.Timespec) int {
-	//TODO implement me
-	panic("implement me")
-}
-
-func (fs *Fs) Access(path string, mask uint32) int {
-	//TODO implement me
-	panic("implement me")
-}
-
-func (fs *Fs) Create(path string, flags int, mode uint32) (int, uint64) {
-	//TODO implement me
-	panic("implement me")
-}
-
-func (fs *Fs) Open(path string, flags int) (int, uint64) {
-	//TODO implement me
-	panic("implement me")
-}
-
-func (fs *Fs) Getattr(path string, stat *fuse.Stat_t, fh uint64) int {
-	//TODO implement me
-	panic("implement me")
-}
-
-func (fs *Fs) Truncate(path string, size int64, fh uint64) int {
-	//TODO implement me
-	panic("implement me")
-}
-
-func (fs *Fs) Read(path string, buff []byte, ofst int64, fh uint64) int {
-	//TODO implement me
-	panic("implement me")
-}
-
-func (fs *Fs) Write(path string, buff []byte, ofst int64, fh uint64) int {
-	//TODO implement me
-	panic("implement me")
-}
-
-func (fs *Fs) Flush(path string, fh uint64) int {
-	//TODO implement me
-	panic("implement me")
-}
-
-func (fs *Fs) Release(path string, fh uint64) int {
-	//TODO implement me
-	panic("implement me")
-}
-
-func (fs *Fs) Fsync(path string, datasync bool, fh uint64) int {
-	//TODO implement me
-	panic("implement me")
-}
-
-func (fs *Fs) Opendir(path string) (int, uint64) {
-	//TODO implement me
-	panic("implement me")
-}
-
-func (fs *Fs) Readdir(path string, fill func(name string, stat *fuse.Stat_t, ofst int64) bool, ofst int64, fh uint64) int {
-	//TODO implement me
-	panic("implement me")
-}
-
-func (fs *Fs) Releasedir(path string, fh uint64) int {
-	//TODO implement me
-	panic("implement me")
-}
-
-func (fs *Fs) Fsyncdir(path string, datasync bool, fh uint64) int {
-	//TODO implement me
-	panic("implement me")
-}
-
-func (fs *Fs) Setxattr(path string, name string, value []byte, flags int) int {
-	//TODO implement me
-	panic("implement me")
-}
-
-func (fs *Fs) Getxattr(path string, name string) (int, []byte) {
-	//TODO implement me
-	panic("implement me")
-}
-
-func (fs *Fs) Removexattr(path string, name string) int {
-	//TODO implement me
-	panic("implement me")
-}
 
-func (fs *Fs) Listxattr(path string, fill func(name string) bool) int {
-	//TODO implement me
-	panic("implement me")
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	mu      sync.Mutex
+	handles map[uint64]*fileHandle
+	// handles that are opened for writing, keyed by mount path, so that
+	// files which have not been uploaded yet can still be stat'ed and listed
+	writing map[string]*fileHandle
+	nextFh  uint64
+}
+
+func NewFs(rootFolder string) *Fs {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &Fs{
+		RootFolder: utils.FixAndCleanPath(rootFolder),
+		ctx:        ctx,
+		cancel:     cancel,
+		handles:    make(map[uint64]*fileHandle),
+		writing:    make(map[string]*fileHandle),
+	}
+}
+
+func (f *Fs) fullPath(path string) string {
+	return stdpath.Join(f.RootFolder, utils.FixAndCleanPath(path))
+}
+
+func (f *Fs) Init() {
+	log.Infof("fuse: filesystem of [%s] initialized", f.RootFolder)
+}
+
+func (f *Fs) Destroy() {
+	f.mu.Lock()
+	handles := f.handles
+	f.handles = make(map[uint64]*fileHandle)
+	f.writing = make(map[string]*fileHandle)
+	f.mu.Unlock()
+	for _, h := range handles {
+		if err := h.release(f.ctx); err != nil {
+			log.Errorf("fuse: failed release [%s]: %+v", h.path, err)
+		}
+	}
+	f.cancel()
+}
+
+func (f *Fs) Statfs(path string, stat *fuse.Statfs_t) int {
+	// the storages that can't report their usage are reported
+	// as a large enough filesystem to keep tools happy
+	const blockSize = 4096
+	blocks, free := uint64(1<<40/blockSize), uint64(1<<40/blockSize)
+	if storage, err := fs.GetStorage(f.fullPath(path), &fs.GetStoragesArgs{}); err == nil {
+		details, err := op.GetStorageDetails(f.ctx, storage)
+		if err == nil {
+			blocks = uint64(details.TotalSpace) / blockSize
+			free = uint64(details.FreeSpace) / blockSize
+		} else if !errs.IsNotImplement(err) {
+			log.Warnf("fuse: failed get details of storage [%s]: %+v", storage.GetStorage().MountPath, err)
+		}
+	}
+	stat.Bsize = blockSize
+	stat.Frsize = blockSize
+	stat.Blocks = blocks
+	stat.Bfree = free
+	stat.Bavail = free
+	stat.Files = 1 << 30
+	stat.Ffree = 1 << 30
+	stat.Favail = 1 << 30
+	stat.Namemax = 255
+	return 0
+}
+
+func (f *Fs) Getattr(path string, stat *fuse.Stat_t, fh uint64) int {
+	if h := f.getHandle(fh); h != nil && h.isWritable() {
+		return h.stat(stat)
+	}
+	fullPath := f.fullPath(path)
+	if h := f.getWriting(fullPath); h != nil {
+		return h.stat(stat)
+	}
+	obj, err := fs.Get(f.ctx, fullPath, &fs.GetArgs{NoLog: true})
+	if err != nil {
+		return errno(err)
+	}
+	fillStat(stat, obj)
+	return 0
+}
+
+func (f *Fs) Mkdir(path string, mode uint32) int {
+	return errno(fs.MakeDir(f.ctx, f.fullPath(path)))
+}
+
+func (f *Fs) Unlink(path string) int {
+	return f.remove(path, false)
+}
+
+func (f *Fs) Rmdir(path string) int {
+	return f.remove(path, true)
+}
+
+func (f *Fs) remove(path string, isDir bool) int {
+	fullPath := f.fullPath(path)
+	obj, err := fs.Get(f.ctx, fullPath, &fs.GetArgs{NoLog: true})
+	if err != nil {
+		return errno(err)
+	}
+	if obj.IsDir() != isDir {
+		if isDir {
+			return -fuse.ENOTDIR
+		}
+		return -fuse.EISDIR
+	}
+	if isDir {
+		objs, err := fs.List(f.ctx, fullPath, &fs.ListArgs{NoLog: true})
+		if err != nil {
+			return errno(err)
+		}
+		if len(objs) > 0 {
+			return -fuse.ENOTEMPTY
+		}
+	}
+	return errno(fs.Remove(f.ctx, fullPath))
+}
+
+func (f *Fs) Rename(oldpath string, newpath string) int {
+	src, dst := f.fullPath(oldpath), f.fullPath(newpath)
+	if src == dst {
+		return 0
+	}
+	dstDir, dstName := stdpath.Split(dst)
+	// rename overwrites the destination as rename(2) does, the destination is
+	// renamed aside first and only removed after the source takes its place
+	var aside string
+	if obj, err := fs.Get(f.ctx, dst, &fs.GetArgs{NoLog: true}); err == nil {
+		if obj.IsDir() {
+			return -fuse.EEXIST
+		}
+		aside = tempName(dstName)
+		if err := fs.Rename(f.ctx, dst, aside); err != nil {
+			return errno(err)
+		}
+	}
+	err := f.move(src, dst)
+	if aside != "" {
+		if err != nil {
+			if e := fs.Rename(f.ctx, stdpath.Join(dstDir, aside), dstName); e != nil {
+				log.Errorf("failed restore %s after a failed rename: %+v", dst, e)
+			}
+		} else if e := fs.Remove(f.ctx, stdpath.Join(dstDir, aside)); e != nil {
+			log.Warnf("failed remove the replaced %s: %+v", dst, e)
+		}
+	}
+	return errno(err)
+}
+
+// move moves src to dst which doesn't exist, the source is renamed to a temp
+// name before moving across dirs, so no obj in the dst dir is overwritten
+func (f *Fs) move(src, dst string) error {
+	srcDir, srcName := stdpath.Split(src)
+	dstDir, dstName := stdpath.Split(dst)
+	if utils.PathEqual(srcDir, dstDir) {
+		return fs.Rename(f.ctx, src, dstName)
+	}
+	name := srcName
+	if srcName != dstName {
+		name = tempName(dstName)
+		if err := fs.Rename(f.ctx, src, name); err != nil {
+			return err
+		}
+	}
+	if _, err := fs.Move(context.WithValue(f.ctx, conf.NoTaskKey, struct{}{}), stdpath.Join(srcDir, name), dstDir); err != nil {
+		if name != srcName {
+			if e := fs.Rename(f.ctx, stdpath.Join(srcDir, name), srcName); e != nil {
+				log.Errorf("failed restore %s after a failed move: %+v", src, e)
+			}
+		}
+		return err
+	}
+	if name != dstName {
+		// the obj is kept under the temp name if it fails, nothing is lost
+		return fs.Rename(f.ctx, stdpath.Join(dstDir, name), dstName)
+	}
+	return nil
+}
+
+// tempName returns a hidden name which doesn't collide with the existing objs
+func tempName(name string) string {
+	return fmt.Sprintf(".%s.alist_fuse_%d", name, time.Now().UnixNano())
+}
+
+func (f *Fs) Chmod(path string, mode uint32) int {
+	// permissions are not supported by storages, just ignore it
+	return 0
+}
+
+func (f *Fs) Chown(path string, uid uint32, gid uint32) int {
+	return 0
+}
+
+func (f *Fs) Utimens(path string, tmsp []fuse.Timespec) int {
+	if h := f.getWriting(f.fullPath(path)); h != nil && len(tmsp) > 1 {
+		h.setModified(tmsp[1].Time())
+	}
+	return 0
+}
+
+func (f *Fs) Access(path string, mask uint32) int {
+	return 0
+}
+
+func (f *Fs) Create(path string, flags int, mode uint32) (int, uint64) {
+	fullPath := f.fullPath(path)
+	h, err := newWriteHandle(f.ctx, fullPath, nil, true)
+	if err != nil {
+		return errno(err), ^uint64(0)
+	}
+	return 0, f.addHandle(h)
+}
+
+func (f *Fs) Open(path string, flags int) (int, uint64) {
+	fullPath := f.fullPath(path)
+	obj, err := fs.Get(f.ctx, fullPath, &fs.GetArgs{NoLog: true})
+	if err != nil {
+		return errno(err), ^uint64(0)
+	}
+	if obj.IsDir() {
+		return -fuse.EISDIR, ^uint64(0)
+	}
+	var h *fileHandle
+	if flags&fuse.O_ACCMODE == fuse.O_RDONLY {
+		h = newReadHandle(fullPath, obj)
+	} else {
+		h, err = newWriteHandle(f.ctx, fullPath, obj, flags&fuse.O_TRUNC != 0)
+		if err != nil {
+			return errno(err), ^uint64(0)
+		}
+	}
+	return 0, f.addHandle(h)
+}
+
+func (f *Fs) Truncate(path string, size int64, fh uint64) int {
+	if h := f.getHandle(fh); h != nil && h.isWritable() {
+		return errno(h.truncate(size))
+	}
+	fullPath := f.fullPath(path)
+	if h := f.getWriting(fullPath); h != nil {
+		return errno(h.truncate(size))
+	}
+	obj, err := fs.Get(f.ctx, fullPath, &fs.GetArgs{NoLog: true})
+	if err != nil {
+		return errno(err)
+	}
+	if obj.GetSize() == size {
+		return 0
+	}
+	h, err := newWriteHandle(f.ctx, fullPath, obj, size == 0)
+	if err != nil {
+		return errno(err)
+	}
+	defer func() {
+		_ = h.release(f.ctx)
+	}()
+	if err = h.truncate(size); err != nil {
+		return errno(err)
+	}
+	return errno(h.flush(f.ctx))
+}
+
+func (f *Fs) Read(path string, buff []byte, ofst int64, fh uint64) int {
+	h := f.getHandle(fh)
+	if h == nil {
+		return -fuse.EBADF
+	}
+	n, err := h.readAt(f.ctx, buff, ofst)
+	if err != nil {
+		log.Errorf("fuse: failed read [%s] at %d: %+v", h.path, ofst, err)
+		return -fuse.EIO
+	}
+	return n
+}
+
+func (f *Fs) Write(path string, buff []byte, ofst int64, fh uint64) int {
+	h := f.getHandle(fh)
+	if h == nil {
+		return -fuse.EBADF
+	}
+	if !h.isWritable() {
+		return -fuse.EBADF
+	}
+	n, err := h.writeAt(buff, ofst)
+	if err != nil {
+		log.Errorf("fuse: failed write [%s] at %d: %+v", h.path, ofst, err)
+		return -fuse.EIO
+	}
+	return n
+}
+
+// Flush is called on each close of a file descriptor,
+// upload the written data here so that close(2) can report the error
+func (f *Fs) Flush(path string, fh uint64) int {
+	h := f.getHandle(fh)
+	if h == nil {
+		return 0
+	}
+	return errno(h.flush(f.ctx))
+}
+
+func (f *Fs) Release(path string, fh uint64) int {
+	h := f.delHandle(fh)
+	if h == nil {
+		return 0
+	}
+	return errno(h.release(f.ctx))
+}
+
+func (f *Fs) Fsync(path string, datasync bool, fh uint64) int {
+	h := f.getHandle(fh)
+	if h == nil {
+		return 0
+	}
+	return errno(h.flush(f.ctx))
+}
+
+func (f *Fs) Opendir(path string) (int, uint64) {
+	obj, err := fs.Get(f.ctx, f.fullPath(path), &fs.GetArgs{NoLog: true})
+	if err != nil {
+		return errno(err), ^uint64(0)
+	}
+	if !obj.IsDir() {
+		return -fuse.ENOTDIR, ^uint64(0)
+	}
+	return 0, 0
+}
+
+func (f *Fs) Readdir(path string, fill func(name string, stat *fuse.Stat_t, ofst int64) bool, ofst int64, fh uint64) int {
+	fullPath := f.fullPath(path)
+	objs, err := fs.List(f.ctx, fullPath, &fs.ListArgs{NoLog: true})
+	if err != nil {
+		return errno(err)
+	}
+	fill(".", nil, 0)
+	fill("..", nil, 0)
+	names := make(map[string]struct{}, len(objs))
+	for _, obj := range objs {
+		names[obj.GetName()] = struct{}{}
+		stat := &fuse.Stat_t{}
+		fillStat(stat, obj)
+		if !fill(obj.GetName(), stat, 0) {
+			return 0
+		}
+	}
+	// the files that are being written but haven't been uploaded yet
+	for _, h := range f.getWritingIn(fullPath) {
+		name := stdpath.Base(h.path)
+		if _, ok := names[name]; ok {
+			continue
+		}
+		stat := &fuse.Stat_t{}
+		h.stat(stat)
+		if !fill(name, stat, 0) {
+			return 0
+		}
+	}
+	return 0
+}
+
+func (f *Fs) Releasedir(path string, fh uint64) int {
+	return 0
+}
+
+func (f *Fs) addHandle(h *fileHandle) uint64 {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.nextFh++
+	f.handles[f.nextFh] = h
+	if h.isWritable() {
+		f.writing[h.path] = h
+	}
+	return f.nextFh
+}
+
+func (f *Fs) getHandle(fh uint64) *fileHandle {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.handles[fh]
+}
+
+func (f *Fs) delHandle(fh uint64) *fileHandle {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	h, ok := f.handles[fh]
+	if !ok {
+		return nil
+	}
+	delete(f.handles, fh)
+	if f.writing[h.path] == h {
+		delete(f.writing, h.path)
+	}
+	return h
+}
+
+func (f *Fs) getWriting(path string) *fileHandle {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.writing[path]
+}
+
+func (f *Fs) getWritingIn(dir string) []*fileHandle {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	var res []*fileHandle
+	for p, h := range f.writing {
+		if utils.PathEqual(stdpath.Dir(p), dir) {
+			res = append(res, h)
+		}
+	}
+	return res
+}
+
+func fillStat(stat *fuse.Stat_t, obj model.Obj) {
+	if obj.IsDir() {
+		stat.Mode = fuse.S_IFDIR | 0755
+		stat.Nlink = 2
+	} else {
+		stat.Mode = fuse.S_IFREG | 0644
+		stat.Nlink = 1
+		stat.Size = obj.GetSize()
+	}
+	fillTime(stat, obj.ModTime(), obj.CreateTime())
+}
+
+func fillTime(stat *fuse.Stat_t, modified, created time.Time) {
+	stat.Mtim = fuse.NewTimespec(modified)
+	stat.Atim = stat.Mtim
+	stat.Ctim = stat.Mtim
+	stat.Birthtim = fuse.NewTimespec(created)
 }
 
 var _ fuse.FileSystemInterface = (*Fs)(nil)
@@ -0,0 +1,246 @@
+//go:build fuse
+
+package fuse
+
+import (
+	"context"
+	"io"
+	"os"
+	stdpath "path"
+	"sync"
+	"time"
+
+	"github.com/alist-org/alist/v3/internal/conf"
+	"github.com/alist-org/alist/v3/internal/fs"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/internal/stream"
+	"github.com/alist-org/alist/v3/pkg/http_range"
+	"github.com/alist-org/alist/v3/pkg/utils"
+	"github.com/pkg/errors"
+	"github.com/winfsp/cgofuse/fuse"
+)
+
+// fileHandle is an opened file. Read only handles read from the link of
+// the file directly, writable handles write back to a local temp file
+// which is uploaded on flush.
+type fileHandle struct {
+	path string
+	obj  model.Obj
+
+	mu sync.Mutex
+
+	// read
+	link   *model.Link
+	rrc    model.RangeReadCloserIF
+	reader io.ReadCloser
+	offset int64
+
+	// write
+	tmpFile  *os.File
+	size     int64
+	modified time.Time
+	dirty    bool
+}
+
+func newReadHandle(path string, obj model.Obj) *fileHandle {
+	return &fileHandle{path: path, obj: obj}
+}
+
+// newWriteHandle creates a writable handle, the existing content of obj
+// is downloaded to the temp file unless truncate is set
+func newWriteHandle(ctx context.Context, path string, obj model.Obj, truncate bool) (*fileHandle, error) {
+	tmpFile, err := os.CreateTemp(conf.Conf.TempDir, "fuse-*")
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	h := &fileHandle{
+		path:     path,
+		obj:      obj,
+		tmpFile:  tmpFile,
+		modified: time.Now(),
+		// a new file should be uploaded even if nothing is written
+		dirty: obj == nil || truncate && obj.GetSize() > 0,
+	}
+	if obj != nil && !truncate && obj.GetSize() > 0 {
+		rc, err := h.rangeRead(ctx, http_range.Range{Start: 0, Length: -1})
+		if err == nil {
+			var n int64
+			n, err = utils.CopyWithBuffer(tmpFile, rc)
+			_ = rc.Close()
+			h.size = n
+		}
+		if err != nil {
+			_ = h.release(ctx)
+			return nil, err
+		}
+	}
+	return h, nil
+}
+
+func (h *fileHandle) isWritable() bool {
+	return h.tmpFile != nil
+}
+
+func (h *fileHandle) stat(stat *fuse.Stat_t) int {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	stat.Mode = fuse.S_IFREG | 0644
+	stat.Nlink = 1
+	stat.Size = h.size
+	created := h.modified
+	if h.obj != nil {
+		created = h.obj.CreateTime()
+	}
+	fillTime(stat, h.modified, created)
+	return 0
+}
+
+func (h *fileHandle) setModified(t time.Time) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.modified = t
+}
+
+func (h *fileHandle) rangeRead(ctx context.Context, r http_range.Range) (io.ReadCloser, error) {
+	if h.link == nil {
+		link, _, err := fs.Link(ctx, h.path, model.LinkArgs{})
+		if err != nil {
+			return nil, err
+		}
+		h.link = link
+	}
+	if h.link.MFile != nil {
+		return io.NopCloser(io.NewSectionReader(h.link.MFile, r.Start, h.obj.GetSize()-r.Start)), nil
+	}
+	if h.rrc == nil {
+		if h.link.RangeReadCloser != nil {
+			h.rrc = h.link.RangeReadCloser
+		} else {
+			rrc, err := stream.GetRangeReadCloserFromLink(h.obj.GetSize(), h.link)
+			if err != nil {
+				return nil, err
+			}
+			h.rrc = rrc
+		}
+	}
+	return h.rrc.RangeRead(ctx, r)
+}
+
+func (h *fileHandle) readAt(ctx context.Context, buff []byte, ofst int64) (int, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.isWritable() {
+		n, err := h.tmpFile.ReadAt(buff, ofst)
+		if err == io.EOF {
+			err = nil
+		}
+		return n, err
+	}
+	size := h.obj.GetSize()
+	if ofst >= size {
+		return 0, nil
+	}
+	if h.reader == nil || h.offset != ofst {
+		// reopen the stream on seek, sequential reads reuse it
+		if h.reader != nil {
+			_ = h.reader.Close()
+			h.reader = nil
+		}
+		rc, err := h.rangeRead(ctx, http_range.Range{Start: ofst, Length: size - ofst})
+		if err != nil {
+			return 0, err
+		}
+		h.reader = rc
+		h.offset = ofst
+	}
+	n, err := io.ReadFull(h.reader, buff)
+	h.offset += int64(n)
+	if err == io.EOF || err == io.ErrUnexpectedEOF {
+		err = nil
+	}
+	return n, err
+}
+
+func (h *fileHandle) writeAt(buff []byte, ofst int64) (int, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	n, err := h.tmpFile.WriteAt(buff, ofst)
+	if end := ofst + int64(n); end > h.size {
+		h.size = end
+	}
+	if n > 0 {
+		h.dirty = true
+		h.modified = time.Now()
+	}
+	return n, err
+}
+
+func (h *fileHandle) truncate(size int64) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if err := h.tmpFile.Truncate(size); err != nil {
+		return errors.WithStack(err)
+	}
+	h.size = size
+	h.dirty = true
+	h.modified = time.Now()
+	return nil
+}
+
+// flush uploads the temp file if anything has been changed
+func (h *fileHandle) flush(ctx context.Context) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if !h.isWritable() || !h.dirty {
+		return nil
+	}
+	// op.Put closes the reader of the stream, so use another file descriptor
+	file, err := os.Open(h.tmpFile.Name())
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	dir, name := stdpath.Split(h.path)
+	fileStream := &stream.FileStream{
+		Obj: &model.Object{
+			Name:     name,
+			Size:     h.size,
+			Modified: h.modified,
+		},
+		Reader:   file,
+		Mimetype: utils.GetMimeType(name),
+		Exist:    h.obj,
+	}
+	ss, err := stream.NewSeekableStream(*fileStream, nil)
+	if err != nil {
+		_ = file.Close()
+		return err
+	}
+	if err = fs.PutDirectly(ctx, dir, ss); err != nil {
+		return err
+	}
+	h.dirty = false
+	return nil
+}
+
+func (h *fileHandle) release(ctx context.Context) error {
+	err := h.flush(ctx)
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.reader != nil {
+		_ = h.reader.Close()
+		h.reader = nil
+	}
+	if h.rrc != nil {
+		_ = h.rrc.Close()
+		h.rrc = nil
+	}
+	if h.link != nil && h.link.MFile != nil {
+		_ = h.link.MFile.Close()
+	}
+	h.link = nil
+	if h.tmpFile != nil {
+		_ = h.tmpFile.Close()
+		_ = os.Remove(h.tmpFile.Name())
+	}
+	return err
+}
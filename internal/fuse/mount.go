@@ -1,9 +1,13 @@
+//go:build fuse
+
 package fuse
 
 import "github.com/winfsp/cgofuse/fuse"
 
-func Mount(mountSrc, mountDst string, opts []string) {
-	fs := &Fs{RootFolder: mountSrc}
-	host := fuse.NewFileSystemHost(fs)
-	go host.Mount(mountDst, opts)
+// NewHost creates a fuse host serving the alist path mountSrc,
+// call Mount on it to mount the filesystem and Unmount to stop serving
+func NewHost(mountSrc string) *fuse.FileSystemHost {
+	host := fuse.NewFileSystemHost(NewFs(mountSrc))
+	host.SetCapReaddirPlus(true)
+	return host
 }
@@ -0,0 +1,34 @@
+//go:build fuse
+
+package fuse
+
+import (
+	stderrors "errors"
+	"os"
+
+	"github.com/alist-org/alist/v3/internal/errs"
+	"github.com/pkg/errors"
+	"github.com/winfsp/cgofuse/fuse"
+)
+
+// errno converts the errors of internal/fs to negative fuse errno
+func errno(err error) int {
+	if err == nil {
+		return 0
+	}
+	switch {
+	case errs.IsNotFoundError(err), stderrors.Is(err, os.ErrNotExist):
+		return -fuse.ENOENT
+	case stderrors.Is(errors.Cause(err), errs.MoveBetweenTwoStorages):
+		// let mv fall back to copy and remove
+		return -fuse.EXDEV
+	case stderrors.Is(errors.Cause(err), errs.UploadNotSupported):
+		return -fuse.EROFS
+	case errs.IsNotImplement(err), errs.IsNotSupportError(err):
+		return -fuse.ENOSYS
+	case stderrors.Is(errors.Cause(err), errs.PermissionDenied), stderrors.Is(err, os.ErrPermission):
+		return -fuse.EACCES
+	default:
+		return -fuse.EIO
+	}
+}
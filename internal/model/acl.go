@@ -0,0 +1,45 @@
+package model
+
+import (
+	"github.com/alist-org/alist/v3/pkg/utils"
+)
+
+const (
+	ACLSubjectUser = iota
+	ACLSubjectGroup
+)
+
+// the operations that can be allowed by an acl rule
+const (
+	ACLRead   int32 = 1 << iota // list, get and download
+	ACLWrite                    // mkdir and upload
+	ACLRename                   // rename
+	ACLMove                     // move out of the path
+	ACLCopy                     // copy out of the path
+	ACLRemove                   // remove
+)
+
+// ACL allows the operations under Path for the subject, the nearest rule
+// of the path wins. The rules only narrow the permission bits of user, an
+// operation denied by the bits can't be allowed by a rule
+type ACL struct {
+	ID          uint   `json:"id" gorm:"primaryKey"`
+	Path        string `json:"path" gorm:"index" binding:"required"`
+	SubjectType int    `json:"subject_type" gorm:"index:idx_acl_subject"`
+	SubjectID   uint   `json:"subject_id" gorm:"index:idx_acl_subject"`
+	Operations  int32  `json:"operations"`
+	// apply to the sub paths
+	Inherit bool `json:"inherit"`
+}
+
+func (a *ACL) Allow(operation int32) bool {
+	return a.Operations&operation == operation
+}
+
+// Apply reports whether the rule applies to path
+func (a *ACL) Apply(path string) bool {
+	if utils.PathEqual(a.Path, path) {
+		return true
+	}
+	return a.Inherit && utils.IsSubPath(a.Path, path)
+}
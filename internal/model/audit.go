@@ -0,0 +1,55 @@
+package model
+
+import "time"
+
+// the frontends of the audit logs
+const (
+	FrontendWeb    = "web"
+	FrontendWebDAV = "webdav"
+	FrontendS3     = "s3"
+	FrontendFTP    = "ftp"
+	FrontendSFTP   = "sftp"
+)
+
+// the operations of the audit logs
+const (
+	AuditList            = "list"
+	AuditDownload        = "download"
+	AuditUpload          = "upload"
+	AuditRename          = "rename"
+	AuditMove            = "move"
+	AuditCopy            = "copy"
+	AuditRemove          = "remove"
+	AuditMkdir           = "mkdir"
+	AuditOfflineDownload = "offline_download"
+	AuditExtract         = "extract"
+	AuditPack            = "pack"
+)
+
+// AuditLog records a file operation of user, the paths are the full paths in alist
+type AuditLog struct {
+	ID        uint   `json:"id" gorm:"primaryKey"`
+	UserID    uint   `json:"user_id" gorm:"index"`
+	Username  string `json:"username"`
+	IP        string `json:"ip"`
+	Frontend  string `json:"frontend" gorm:"index"`
+	Operation string `json:"operation" gorm:"index"`
+	Path      string `json:"path"`
+	// the destination of rename, move, copy, offline download, extract and pack
+	DstPath string    `json:"dst_path"`
+	Success bool      `json:"success"`
+	Error   string    `json:"error"`
+	Time    time.Time `json:"time" gorm:"index"`
+}
+
+type AuditLogFilter struct {
+	Username  string `json:"username" form:"username"`
+	IP        string `json:"ip" form:"ip"`
+	Frontend  string `json:"frontend" form:"frontend"`
+	Operation string `json:"operation" form:"operation"`
+	// the logs of the path and its sub paths
+	Path    string     `json:"path" form:"path"`
+	Success *bool      `json:"success" form:"success"`
+	Start   *time.Time `json:"start" form:"start"`
+	End     *time.Time `json:"end" form:"end"`
+}
@@ -0,0 +1,38 @@
+package model
+
+import (
+	"time"
+)
+
+// FileRequest lets the anonymous users upload files to Path of the owner,
+// the contents of Path are never listed to the uploaders
+type FileRequest struct {
+	ID          string `json:"id" gorm:"primaryKey;size:32"`
+	UserID      uint   `json:"user_id" gorm:"index"`
+	Path        string `json:"path"`
+	Description string `json:"description" gorm:"type:text"`
+	Password    string `json:"password"`
+	// nil for never expires
+	Expires *time.Time `json:"expires"`
+	// the quotas of all the uploads, 0 for unlimited
+	MaxSize       int64     `json:"max_size"`
+	MaxFiles      int64     `json:"max_files"`
+	UploadedSize  int64     `json:"uploaded_size"`
+	UploadedFiles int64     `json:"uploaded_files"`
+	Created       time.Time `json:"created"`
+}
+
+func (r *FileRequest) IsExpired() bool {
+	return r.Expires != nil && time.Now().After(*r.Expires)
+}
+
+// FileRequestUpload records an upload of the file request
+type FileRequestUpload struct {
+	ID        uint      `json:"id" gorm:"primaryKey"`
+	RequestID string    `json:"request_id" gorm:"index;size:32"`
+	Name      string    `json:"name"`
+	Size      int64     `json:"size"`
+	Uploader  string    `json:"uploader"`
+	IP        string    `json:"ip"`
+	Created   time.Time `json:"created"`
+}
@@ -0,0 +1,47 @@
+package model
+
+import (
+	"strings"
+)
+
+// Group gives its permissions, base path, acl rules and meta overrides to the
+// members. The permissions of all groups are merged, for the base path and
+// the meta overrides of the same path the group with the smallest id wins
+type Group struct {
+	ID         uint   `json:"id" gorm:"primaryKey"`
+	Name       string `json:"name" gorm:"unique" binding:"required"`
+	BasePath   string `json:"base_path"`
+	Permission int32  `json:"permission"`
+	// the names of the group in ldap or the groups claim of sso, one per line,
+	// the memberships of the groups with external names are synced on login
+	ExternalNames string `json:"external_names" gorm:"type:text"`
+}
+
+type UserGroup struct {
+	UserID  uint `json:"user_id" gorm:"primaryKey"`
+	GroupID uint `json:"group_id" gorm:"primaryKey;index"`
+}
+
+func (g *Group) GetExternalNames() []string {
+	var names []string
+	for _, name := range strings.Split(g.ExternalNames, "\n") {
+		if name = strings.TrimSpace(name); name != "" {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// GroupMeta overrides the password, write and hide of the metas for the
+// members of group, the readme and header of the metas are kept
+type GroupMeta struct {
+	ID       uint   `json:"id" gorm:"primaryKey"`
+	GroupID  uint   `json:"group_id" gorm:"uniqueIndex:idx_group_meta_path" binding:"required"`
+	Path     string `json:"path" gorm:"uniqueIndex:idx_group_meta_path" binding:"required"`
+	Password string `json:"password"`
+	PSub     bool   `json:"p_sub"`
+	Write    bool   `json:"write"`
+	WSub     bool   `json:"w_sub"`
+	Hide     string `json:"hide"`
+	HSub     bool   `json:"h_sub"`
+}
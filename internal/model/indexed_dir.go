@@ -0,0 +1,14 @@
+package model
+
+import "time"
+
+// IndexedDir records the state of a dir when it's indexed, for the incremental update
+type IndexedDir struct {
+	ID   uint   `json:"id" gorm:"primaryKey"`
+	Path string `json:"path" gorm:"uniqueIndex;size:512"`
+	// the modified time of dir in the listing of its parent
+	Modified time.Time `json:"modified"`
+	// the fingerprint of the listing of dir
+	Fingerprint string    `json:"fingerprint"`
+	Indexed     time.Time `json:"indexed"`
+}
@@ -0,0 +1,44 @@
+package model
+
+import (
+	"github.com/alist-org/alist/v3/pkg/utils"
+	"github.com/pkg/errors"
+)
+
+// Quota limits the bytes uploaded under BasePath by the user of UserID,
+// it's shared by all users if UserID is 0, and applies to all paths if BasePath is empty
+type Quota struct {
+	ID        uint   `json:"id" gorm:"primaryKey"`
+	UserID    uint   `json:"user_id" gorm:"index"`
+	BasePath  string `json:"base_path"`
+	MaxBytes  int64  `json:"max_bytes"`
+	UsedBytes int64  `json:"used_bytes"`
+}
+
+func (q *Quota) Validate() error {
+	if q.MaxBytes <= 0 {
+		return errors.New("max bytes must be greater than 0")
+	}
+	if q.BasePath != "" {
+		q.BasePath = utils.FixAndCleanPath(q.BasePath)
+	}
+	return nil
+}
+
+// Match reports whether the quota applies to the uploads of user to path, the user can be nil
+func (q *Quota) Match(user *User, path string) bool {
+	if q.UserID != 0 && (user == nil || user.ID != q.UserID) {
+		return false
+	}
+	return q.BasePath == "" || utils.IsSubPath(q.BasePath, path)
+}
+
+// QuotaUsage records the bytes of a file charged to the quotas, so they are refunded to the quotas
+// of its uploader when it's replaced or removed permanently. The path is the full path of file.
+type QuotaUsage struct {
+	ID       uint   `json:"id" gorm:"primaryKey"`
+	Path     string `json:"path" gorm:"uniqueIndex;size:512"`
+	UserID   uint   `json:"user_id"`
+	Size     int64  `json:"size"`
+	QuotaIDs []uint `json:"quota_ids" gorm:"serializer:json"`
+}
@@ -17,14 +17,43 @@ type SearchReq struct {
 	Keywords string `json:"keywords"`
 	// 0 for all, 1 for dir, 2 for file
 	Scope int `json:"scope"`
+	// the types of conf, such as conf.VIDEO, empty for all
+	Types []int `json:"types"`
+	// the mount path of storage
+	Storage string `json:"storage"`
+	// the hash in the form of type:hex, see SearchNode.Hash
+	Hash    string `json:"hash"`
+	MinSize int64  `json:"min_size"`
+	// 0 for no limit
+	MaxSize        int64      `json:"max_size"`
+	ModifiedAfter  *time.Time `json:"modified_after"`
+	ModifiedBefore *time.Time `json:"modified_before"`
+	// name, size or modified, name by default
+	OrderBy string `json:"order_by"`
+	// asc or desc, asc by default
+	OrderDirection string `json:"order_direction"`
+	// match the keywords in the content of files too, if the searcher supports
+	Content bool `json:"content"`
 	PageReq
 }
 
 type SearchNode struct {
-	Parent string `json:"parent" gorm:"index"`
-	Name   string `json:"name"`
-	IsDir  bool   `json:"is_dir"`
-	Size   int64  `json:"size"`
+	Parent   string    `json:"parent" gorm:"index"`
+	Name     string    `json:"name"`
+	IsDir    bool      `json:"is_dir"`
+	Size     int64     `json:"size" gorm:"index"`
+	Modified time.Time `json:"modified" gorm:"index"`
+	// the type of conf, such as conf.VIDEO
+	FileType int `json:"file_type" gorm:"index"`
+	// the mount path of storage
+	Storage string `json:"storage" gorm:"index"`
+	// the first known hash of md5, sha1 and sha256 in the form of type:hex,
+	// such as md5:d41d8cd98f00b204e9800998ecf8427e, empty if unknown
+	Hash string `json:"hash" gorm:"index"`
+	// the text of file, only indexed by the searchers supporting content
+	Content string `json:"content,omitempty" gorm:"-"`
+	// the highlighted fragment of content matching the keywords, only in search results
+	Snippet string `json:"snippet,omitempty" gorm:"-"`
 }
 
 func (p *SearchReq) Validate() error {
@@ -34,9 +63,34 @@ func (p *SearchReq) Validate() error {
 	if p.PerPage < 1 {
 		return fmt.Errorf("per_page can't < 1")
 	}
+	if p.MinSize < 0 || p.MaxSize < 0 {
+		return fmt.Errorf("size can't < 0")
+	}
+	if p.MaxSize != 0 && p.MinSize > p.MaxSize {
+		return fmt.Errorf("min_size can't > max_size")
+	}
+	switch p.OrderBy {
+	case "", "name", "size", "modified":
+	default:
+		return fmt.Errorf("invalid order_by: %s", p.OrderBy)
+	}
+	switch p.OrderDirection {
+	case "", "asc", "desc":
+	default:
+		return fmt.Errorf("invalid order_direction: %s", p.OrderDirection)
+	}
 	return nil
 }
 
+// Order returns the field and whether in descending order
+func (p *SearchReq) Order() (string, bool) {
+	orderBy := p.OrderBy
+	if orderBy == "" {
+		orderBy = "name"
+	}
+	return orderBy, p.OrderDirection == "desc"
+}
+
 func (s *SearchNode) Type() string {
 	return "SearchNode"
 }
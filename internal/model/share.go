@@ -0,0 +1,27 @@
+package model
+
+import (
+	"time"
+)
+
+// Share gives the anonymous users read only access to Path of the owner
+type Share struct {
+	ID       string `json:"id" gorm:"primaryKey;size:32"`
+	UserID   uint   `json:"user_id" gorm:"index"`
+	Path     string `json:"path"`
+	Password string `json:"password"`
+	// nil for never expires
+	Expires *time.Time `json:"expires"`
+	// 0 for unlimited
+	MaxDownloads int64     `json:"max_downloads"`
+	Downloads    int64     `json:"downloads"`
+	Created      time.Time `json:"created"`
+}
+
+func (s *Share) IsExpired() bool {
+	return s.Expires != nil && time.Now().After(*s.Expires)
+}
+
+func (s *Share) IsDownloadsExhausted() bool {
+	return s.MaxDownloads > 0 && s.Downloads >= s.MaxDownloads
+}
@@ -14,6 +14,8 @@ type Storage struct {
 	Modified        time.Time `json:"modified"`
 	Disabled        bool      `json:"disabled"` // if disabled
 	EnableSign      bool      `json:"enable_sign"`
+	DownloadLimit   int64     `json:"download_limit"` // KB/s, 0 for no limit
+	UploadLimit     int64     `json:"upload_limit"`
 	Sort
 	Proxy
 }
@@ -31,6 +33,18 @@ type Proxy struct {
 	DownProxyUrl string `json:"down_proxy_url"`
 }
 
+// StorageDetails is the usage of storage space in bytes
+type StorageDetails struct {
+	TotalSpace int64 `json:"total_space"`
+	UsedSpace  int64 `json:"used_space"`
+	FreeSpace  int64 `json:"free_space"`
+}
+
+// NewStorageDetails returns the details of the total and used space, the rest is free
+func NewStorageDetails(total, used int64) *StorageDetails {
+	return &StorageDetails{TotalSpace: total, UsedSpace: used, FreeSpace: max(total-used, 0)}
+}
+
 func (s *Storage) GetStorage() *Storage {
 	return s
 }
@@ -0,0 +1,105 @@
+package model
+
+import (
+	"fmt"
+	"time"
+)
+
+const (
+	// SyncModeCopy copies the files not in destination
+	SyncModeCopy = "copy"
+	// SyncModeUpdate copies the files not in destination or changed
+	SyncModeUpdate = "update"
+	// SyncModeMirror updates the destination and deletes the files not in source
+	SyncModeMirror = "mirror"
+)
+
+const (
+	// SyncCompareSize treats the files with different sizes as changed
+	SyncCompareSize = "size"
+	// SyncCompareModified treats the files with different sizes or newer in source as changed
+	SyncCompareModified = "modified"
+	// SyncCompareHash treats the files with different sizes or hashes as changed,
+	// only the size is compared if the storages don't expose the same type of hash
+	SyncCompareHash = "hash"
+)
+
+// SyncJob keeps the destination dir in sync with the source dir
+type SyncJob struct {
+	ID      uint   `json:"id" gorm:"primaryKey"`
+	Name    string `json:"name" binding:"required"`
+	SrcPath string `json:"src_path" binding:"required"`
+	DstPath string `json:"dst_path" binding:"required"`
+	Mode    string `json:"mode"`
+	Compare string `json:"compare"`
+	// minutes between the scheduled runs, 0 to run manually
+	Interval int        `json:"interval"`
+	Disabled bool       `json:"disabled"`
+	LastRun  *time.Time `json:"last_run"`
+}
+
+func (j *SyncJob) Validate() error {
+	switch j.Mode {
+	case "":
+		j.Mode = SyncModeUpdate
+	case SyncModeCopy, SyncModeUpdate, SyncModeMirror:
+	default:
+		return fmt.Errorf("invalid mode: %s", j.Mode)
+	}
+	switch j.Compare {
+	case "":
+		j.Compare = SyncCompareModified
+	case SyncCompareSize, SyncCompareModified, SyncCompareHash:
+	default:
+		return fmt.Errorf("invalid compare: %s", j.Compare)
+	}
+	if j.Interval < 0 {
+		return fmt.Errorf("interval can't < 0")
+	}
+	return nil
+}
+
+const (
+	SyncActionCopy   = "copy"
+	SyncActionUpdate = "update"
+	SyncActionDelete = "delete"
+)
+
+// SyncAction is a file or dir copied, updated or deleted by a run
+type SyncAction struct {
+	Action string `json:"action"`
+	// the path relative to the source or destination dir
+	Path  string `json:"path"`
+	IsDir bool   `json:"is_dir"`
+	Size  int64  `json:"size"`
+	Error string `json:"error,omitempty"`
+}
+
+// MaxSyncActions is the max number of actions recorded by a run
+const MaxSyncActions = 1000
+
+const (
+	SyncRunRunning   = "running"
+	SyncRunSucceeded = "succeeded"
+	SyncRunFailed    = "failed"
+)
+
+// SyncRun is the history of a run of SyncJob, the copies between storages are
+// done by the copy tasks, and the run finishes after them
+type SyncRun struct {
+	ID      uint       `json:"id" gorm:"primaryKey"`
+	JobID   uint       `json:"job_id" gorm:"index"`
+	DryRun  bool       `json:"dry_run"`
+	Start   time.Time  `json:"start" gorm:"index"`
+	End     *time.Time `json:"end"`
+	State   string     `json:"state"`
+	Copied  int        `json:"copied"`
+	Updated int        `json:"updated"`
+	Deleted int        `json:"deleted"`
+	Failed  int        `json:"failed"`
+	Error   string     `json:"error"`
+	// at most MaxSyncActions are recorded
+	Actions []SyncAction `json:"actions" gorm:"serializer:json"`
+	// the ids of copy tasks
+	TaskIDs []string `json:"task_ids" gorm:"serializer:json"`
+}
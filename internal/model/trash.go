@@ -0,0 +1,26 @@
+package model
+
+import (
+	"path"
+	"time"
+)
+
+// TrashItem records an object moved to the recycle bin of a storage,
+// the paths are the actual paths in the storage
+type TrashItem struct {
+	ID        uint   `json:"id" gorm:"primaryKey"`
+	StorageID uint   `json:"storage_id" gorm:"index"`
+	Path      string `json:"path"`
+	// the dir holds the object in recycle bin
+	TrashPath string    `json:"trash_path"`
+	Name      string    `json:"name"`
+	IsDir     bool      `json:"is_dir"`
+	Size      int64     `json:"size"`
+	DeletedBy string    `json:"deleted_by"`
+	Deleted   time.Time `json:"deleted" gorm:"index"`
+}
+
+// ObjPath returns the actual path of the object in recycle bin
+func (t *TrashItem) ObjPath() string {
+	return path.Join(t.TrashPath, t.Name)
+}
@@ -42,10 +42,19 @@ type User struct {
 	//   7: can remove
 	//   8: webdav read
 	//   9: webdav write
+	//  10: ftp/sftp login and read
+	//  11: ftp/sftp write
+	//  12: create share links
+	//  13: create file requests
 	Permission int32  `json:"permission"`
 	OtpSecret  string `json:"-"`
 	SsoID      string `json:"sso_id"` // unique by sso platform
 	Authn      string `gorm:"type:text" json:"-"`
+	// KB/s, 0 for no limit
+	DownloadLimit int64 `json:"download_limit"`
+	UploadLimit   int64 `json:"upload_limit"`
+	// the groups of user, loaded by op
+	Groups []Group `json:"-" gorm:"-"`
 }
 
 func (u *User) IsGuest() bool {
@@ -77,48 +86,88 @@ func (u *User) SetPassword(pwd string) *User {
 	return u
 }
 
+// EffectivePermission merges the permission bits of user and its groups
+func (u *User) EffectivePermission() int32 {
+	permission := u.Permission
+	for _, g := range u.Groups {
+		permission |= g.Permission
+	}
+	return permission
+}
+
+// GetBasePath returns the base path of user, the users with root base path
+// use the base path of the group with the smallest id which has one, the
+// groups are loaded ordered by id. The base path of admin is never narrowed
+func (u *User) GetBasePath() string {
+	if u.IsAdmin() || (u.BasePath != "" && u.BasePath != "/") {
+		return u.BasePath
+	}
+	for _, g := range u.Groups {
+		if g.BasePath != "" && g.BasePath != "/" {
+			return g.BasePath
+		}
+	}
+	return "/"
+}
+
 func (u *User) CanSeeHides() bool {
-	return u.IsAdmin() || u.Permission&1 == 1
+	return u.IsAdmin() || u.EffectivePermission()&1 == 1
 }
 
 func (u *User) CanAccessWithoutPassword() bool {
-	return u.IsAdmin() || (u.Permission>>1)&1 == 1
+	return u.IsAdmin() || (u.EffectivePermission()>>1)&1 == 1
 }
 
 func (u *User) CanAddOfflineDownloadTasks() bool {
-	return u.IsAdmin() || (u.Permission>>2)&1 == 1
+	return u.IsAdmin() || (u.EffectivePermission()>>2)&1 == 1
 }
 
 func (u *User) CanWrite() bool {
-	return u.IsAdmin() || (u.Permission>>3)&1 == 1
+	return u.IsAdmin() || (u.EffectivePermission()>>3)&1 == 1
 }
 
 func (u *User) CanRename() bool {
-	return u.IsAdmin() || (u.Permission>>4)&1 == 1
+	return u.IsAdmin() || (u.EffectivePermission()>>4)&1 == 1
 }
 
 func (u *User) CanMove() bool {
-	return u.IsAdmin() || (u.Permission>>5)&1 == 1
+	return u.IsAdmin() || (u.EffectivePermission()>>5)&1 == 1
 }
 
 func (u *User) CanCopy() bool {
-	return u.IsAdmin() || (u.Permission>>6)&1 == 1
+	return u.IsAdmin() || (u.EffectivePermission()>>6)&1 == 1
 }
 
 func (u *User) CanRemove() bool {
-	return u.IsAdmin() || (u.Permission>>7)&1 == 1
+	return u.IsAdmin() || (u.EffectivePermission()>>7)&1 == 1
 }
 
 func (u *User) CanWebdavRead() bool {
-	return u.IsAdmin() || (u.Permission>>8)&1 == 1
+	return u.IsAdmin() || (u.EffectivePermission()>>8)&1 == 1
 }
 
 func (u *User) CanWebdavManage() bool {
-	return u.IsAdmin() || (u.Permission>>9)&1 == 1
+	return u.IsAdmin() || (u.EffectivePermission()>>9)&1 == 1
+}
+
+func (u *User) CanFTPAccess() bool {
+	return u.IsAdmin() || (u.EffectivePermission()>>10)&1 == 1
+}
+
+func (u *User) CanFTPManage() bool {
+	return u.IsAdmin() || (u.EffectivePermission()>>11)&1 == 1
+}
+
+func (u *User) CanShare() bool {
+	return u.IsAdmin() || (u.EffectivePermission()>>12)&1 == 1
+}
+
+func (u *User) CanFileRequest() bool {
+	return u.IsAdmin() || (u.EffectivePermission()>>13)&1 == 1
 }
 
 func (u *User) JoinPath(reqPath string) (string, error) {
-	return utils.JoinBasePath(u.BasePath, reqPath)
+	return utils.JoinBasePath(u.GetBasePath(), reqPath)
 }
 
 func StaticHash(password string) string {
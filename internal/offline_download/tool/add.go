@@ -4,8 +4,10 @@ import (
 	"context"
 	"path/filepath"
 
+	"github.com/alist-org/alist/v3/internal/audit"
 	"github.com/alist-org/alist/v3/internal/conf"
 	"github.com/alist-org/alist/v3/internal/errs"
+	"github.com/alist-org/alist/v3/internal/model"
 	"github.com/alist-org/alist/v3/internal/op"
 	"github.com/google/uuid"
 	"github.com/pkg/errors"
@@ -29,6 +31,12 @@ type AddURLArgs struct {
 }
 
 func AddURL(ctx context.Context, args *AddURLArgs) (tache.TaskWithInfo, error) {
+	t, err := addURL(ctx, args)
+	audit.Record(ctx, model.AuditOfflineDownload, args.URL, args.DstDirPath, err)
+	return t, err
+}
+
+func addURL(ctx context.Context, args *AddURLArgs) (tache.TaskWithInfo, error) {
 	// get tool
 	tool, err := Tools.Get(args.Tool)
 	if err != nil {
@@ -78,12 +86,17 @@ func AddURL(ctx context.Context, args *AddURLArgs) (tache.TaskWithInfo, error) {
 		deletePolicy = DeleteNever
 	}
 	
+	var creator string
+	if user, ok := ctx.Value("user").(*model.User); ok {
+		creator = user.Username
+	}
 	t := &DownloadTask{
 		Url:          args.URL,
 		DstDirPath:   args.DstDirPath,
 		TempDir:      tempDir,
 		DeletePolicy: deletePolicy,
 		Toolname:     args.Tool,
+		Creator:      creator,
 		tool:         tool,
 	}
 	DownloadTaskManager.Add(t)
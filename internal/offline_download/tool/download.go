@@ -19,6 +19,7 @@ type DownloadTask struct {
 	TempDir           string       `json:"temp_dir"`
 	DeletePolicy      DeletePolicy `json:"delete_policy"`
 	Toolname          string       `json:"toolname"`
+	Creator           string       `json:"creator"`
 	Status            string       `json:"-"`
 	Signal            chan int     `json:"-"`
 	GID               string       `json:"-"`
@@ -176,6 +177,7 @@ func (t *DownloadTask) Complete() error {
 			TempDir:      t.TempDir,
 			DeletePolicy: t.DeletePolicy,
 			FileDir:      file.Path,
+			Creator:      t.Creator,
 		})
 	}
 	return nil
@@ -7,6 +7,7 @@ import (
 
 	"github.com/alist-org/alist/v3/internal/model"
 	"github.com/alist-org/alist/v3/internal/op"
+	"github.com/alist-org/alist/v3/internal/ratelimit"
 	"github.com/alist-org/alist/v3/internal/stream"
 	"github.com/alist-org/alist/v3/pkg/utils"
 	"github.com/pkg/errors"
@@ -20,7 +21,9 @@ type TransferTask struct {
 	DstDirPath   string       `json:"dst_dir_path"`
 	TempDir      string       `json:"temp_dir"`
 	DeletePolicy DeletePolicy `json:"delete_policy"`
-	file         File
+	// the name of user who added the download, the transfer is charged to the quotas
+	Creator string `json:"creator"`
+	file    File
 }
 
 func (t *TransferTask) Run() error {
@@ -36,29 +39,45 @@ func (t *TransferTask) Run() error {
 	if err != nil {
 		return errors.WithMessage(err, "failed get storage")
 	}
+	relDir, err := filepath.Rel(t.TempDir, filepath.Dir(t.file.Path))
+	if err != nil {
+		log.Errorf("find relation directory error: %v", err)
+	}
+	newDistDir := filepath.Join(dstDirActualPath, relDir)
+	obj := &model.Object{
+		Name:     filepath.Base(t.file.Path),
+		Size:     t.file.Size,
+		Modified: t.file.Modified,
+		IsFolder: false,
+	}
+	var user *model.User
+	if t.Creator != "" {
+		if user, err = op.GetUserByName(t.Creator); err != nil {
+			return errors.WithMessagef(err, "failed get creator %s", t.Creator)
+		}
+	}
+	refund, err := op.UseQuota(user, storage, newDistDir, obj)
+	if err != nil {
+		return err
+	}
 	mimetype := utils.GetMimeType(t.file.Path)
 	rc, err := t.file.GetReadCloser()
 	if err != nil {
+		refund()
 		return errors.Wrapf(err, "failed to open file %s", t.file.Path)
 	}
+	ctx := ratelimit.WithLimiters(t.Ctx(), ratelimit.Upload(nil, storage.GetStorage())...)
 	s := &stream.FileStream{
-		Ctx: nil,
-		Obj: &model.Object{
-			Name:     filepath.Base(t.file.Path),
-			Size:     t.file.Size,
-			Modified: t.file.Modified,
-			IsFolder: false,
-		},
-		Reader:   rc,
+		Ctx:      ctx,
+		Obj:      obj,
+		Reader:   ratelimit.NewReader(ctx, rc),
 		Mimetype: mimetype,
 		Closers:  utils.NewClosers(rc),
 	}
-	relDir, err := filepath.Rel(t.TempDir, filepath.Dir(t.file.Path))
-	if err != nil {
-		log.Errorf("find relation directory error: %v", err)
+	if err = op.Put(t.Ctx(), storage, newDistDir, s, t.SetProgress); err != nil {
+		refund()
 	}
-	newDistDir := filepath.Join(dstDirActualPath, relDir)
-	return op.Put(t.Ctx(), storage, newDistDir, s, t.SetProgress)
+	return err
 }
 
 func (t *TransferTask) GetName() string {
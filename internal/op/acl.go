@@ -0,0 +1,149 @@
+package op
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/Xhofe/go-cache"
+	"github.com/alist-org/alist/v3/internal/db"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/pkg/singleflight"
+	"github.com/alist-org/alist/v3/pkg/utils"
+)
+
+var aclCache = cache.NewMemCache(cache.WithShards[[]model.ACL](2))
+var aclG singleflight.Group[[]model.ACL]
+
+func aclKey(subjectType int, subjectId uint) string {
+	return fmt.Sprintf("%d:%d", subjectType, subjectId)
+}
+
+func getACLsBySubject(subjectType int, subjectId uint) ([]model.ACL, error) {
+	key := aclKey(subjectType, subjectId)
+	if acls, ok := aclCache.Get(key); ok {
+		return acls, nil
+	}
+	acls, err, _ := aclG.Do(key, func() ([]model.ACL, error) {
+		_acls, err := db.GetACLsBySubject(subjectType, subjectId)
+		if err != nil {
+			return nil, err
+		}
+		aclCache.Set(key, _acls, cache.WithEx[[]model.ACL](time.Hour))
+		return _acls, nil
+	})
+	return acls, err
+}
+
+// GetUserACLs returns the acl rules which apply to user
+func GetUserACLs(user *model.User) ([]model.ACL, error) {
+	return getACLsBySubject(model.ACLSubjectUser, user.ID)
+}
+
+// getGroupACLs returns the acl rules of the groups of user
+func getGroupACLs(user *model.User) ([]model.ACL, error) {
+	var res []model.ACL
+	for _, g := range user.Groups {
+		acls, err := getACLsBySubject(model.ACLSubjectGroup, g.ID)
+		if err != nil {
+			return nil, err
+		}
+		res = append(res, acls...)
+	}
+	return res, nil
+}
+
+// HasACLs reports whether any acl rule is set for user or its groups
+func HasACLs(user *model.User) (bool, error) {
+	userACLs, err := GetUserACLs(user)
+	if err != nil {
+		return false, err
+	}
+	groupACLs, err := getGroupACLs(user)
+	if err != nil {
+		return false, err
+	}
+	return len(userACLs) > 0 || len(groupACLs) > 0, nil
+}
+
+// nearestOperations merges the operations of the nearest rules of path,
+// nearest is -1 if no rule applies to path
+func nearestOperations(acls []model.ACL, path string) (operations int32, nearest int) {
+	nearest = -1
+	for _, acl := range acls {
+		if !acl.Apply(path) {
+			continue
+		}
+		// the rules of the same path are merged
+		switch l := len(acl.Path); {
+		case l > nearest:
+			nearest = l
+			operations = acl.Operations
+		case l == nearest:
+			operations |= acl.Operations
+		}
+	}
+	return operations, nearest
+}
+
+// GetACLOperations returns the operations allowed by the nearest rules of path,
+// the rules of user win over the ones of groups on the same path,
+// ok is false if no rule applies to path
+func GetACLOperations(user *model.User, path string) (operations int32, ok bool, err error) {
+	userACLs, err := GetUserACLs(user)
+	if err != nil {
+		return 0, false, err
+	}
+	groupACLs, err := getGroupACLs(user)
+	if err != nil {
+		return 0, false, err
+	}
+	path = utils.FixAndCleanPath(path)
+	userOps, userNearest := nearestOperations(userACLs, path)
+	groupOps, groupNearest := nearestOperations(groupACLs, path)
+	if userNearest < 0 && groupNearest < 0 {
+		return 0, false, nil
+	}
+	if userNearest >= groupNearest {
+		return userOps, true, nil
+	}
+	return groupOps, true, nil
+}
+
+func GetACLById(id uint) (*model.ACL, error) {
+	return db.GetACLById(id)
+}
+
+func GetACLs(pageIndex, pageSize int) (acls []model.ACL, count int64, err error) {
+	return db.GetACLs(pageIndex, pageSize)
+}
+
+func CreateACL(a *model.ACL) error {
+	a.Path = utils.FixAndCleanPath(a.Path)
+	aclCache.Del(aclKey(a.SubjectType, a.SubjectID))
+	return db.CreateACL(a)
+}
+
+func UpdateACL(a *model.ACL) error {
+	old, err := db.GetACLById(a.ID)
+	if err != nil {
+		return err
+	}
+	a.Path = utils.FixAndCleanPath(a.Path)
+	aclCache.Del(aclKey(old.SubjectType, old.SubjectID))
+	aclCache.Del(aclKey(a.SubjectType, a.SubjectID))
+	return db.UpdateACL(a)
+}
+
+func DeleteACLById(id uint) error {
+	old, err := db.GetACLById(id)
+	if err != nil {
+		return err
+	}
+	aclCache.Del(aclKey(old.SubjectType, old.SubjectID))
+	return db.DeleteACLById(id)
+}
+
+func DeleteACLsBySubject(subjectType int, subjectId uint) error {
+	aclCache.Del(aclKey(subjectType, subjectId))
+	return db.DeleteACLsBySubject(subjectType, subjectId)
+}
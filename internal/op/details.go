@@ -0,0 +1,49 @@
+package op
+
+import (
+	"context"
+	"time"
+
+	"github.com/Xhofe/go-cache"
+	"github.com/alist-org/alist/v3/internal/driver"
+	"github.com/alist-org/alist/v3/internal/errs"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/pkg/singleflight"
+	"github.com/pkg/errors"
+)
+
+// the usage changes slowly, and the apis of most clouds are rate limited
+const detailsCacheExpiration = time.Minute
+
+// the details are shared by the requests, so they are got within the timeout
+// rather than canceled with the request which starts getting them
+const detailsTimeout = 30 * time.Second
+
+var detailsCache = cache.NewMemCache(cache.WithShards[*model.StorageDetails](2))
+var detailsG singleflight.Group[*model.StorageDetails]
+
+// GetStorageDetails returns the usage of storage space, errs.NotImplement if the driver can't report it
+func GetStorageDetails(ctx context.Context, storage driver.Driver) (*model.StorageDetails, error) {
+	if storage.GetStorage().Status != WORK {
+		return nil, errors.Errorf("storage not init: %s", storage.GetStorage().Status)
+	}
+	wd, ok := storage.(driver.WithDetails)
+	if !ok {
+		return nil, errs.NotImplement
+	}
+	key := storage.GetStorage().MountPath
+	if details, ok := detailsCache.Get(key); ok {
+		return details, nil
+	}
+	details, err, _ := detailsG.Do(key, func() (*model.StorageDetails, error) {
+		ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), detailsTimeout)
+		defer cancel()
+		details, err := wd.GetDetails(ctx)
+		if err != nil {
+			return nil, errors.WithMessage(err, "failed get storage details")
+		}
+		detailsCache.Set(key, details, cache.WithEx[*model.StorageDetails](detailsCacheExpiration))
+		return details, nil
+	})
+	return details, err
+}
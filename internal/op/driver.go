@@ -138,6 +138,16 @@ func getMainItems(config driver.Config) []driver.Item {
 		Type:     conf.TypeBool,
 		Default:  "false",
 		Required: true,
+	}, driver.Item{
+		Name:    "download_limit",
+		Type:    conf.TypeNumber,
+		Default: "0",
+		Help:    "KB/s, 0 for no limit",
+	}, driver.Item{
+		Name:    "upload_limit",
+		Type:    conf.TypeNumber,
+		Default: "0",
+		Help:    "KB/s, 0 for no limit",
 	})
 	return items
 }
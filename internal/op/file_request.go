@@ -0,0 +1,96 @@
+package op
+
+import (
+	"time"
+
+	"github.com/alist-org/alist/v3/internal/db"
+	"github.com/alist-org/alist/v3/internal/errs"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/pkg/utils"
+	"github.com/pkg/errors"
+)
+
+func CreateFileRequest(r *model.FileRequest) error {
+	id, err := newSecretId()
+	if err != nil {
+		return err
+	}
+	r.ID = id
+	r.Path = utils.FixAndCleanPath(r.Path)
+	r.UploadedSize = 0
+	r.UploadedFiles = 0
+	r.Created = time.Now()
+	return db.CreateFileRequest(r)
+}
+
+func GetFileRequestById(id string) (*model.FileRequest, error) {
+	return db.GetFileRequestById(id)
+}
+
+func GetFileRequestByIdAndUserId(id string, userId uint) (*model.FileRequest, error) {
+	r, err := db.GetFileRequestById(id)
+	if err != nil {
+		return nil, err
+	}
+	if r.UserID != userId {
+		return nil, errors.New("the file request does not belong to the user")
+	}
+	return r, nil
+}
+
+func GetFileRequests(pageIndex, pageSize int) (requests []model.FileRequest, count int64, err error) {
+	return db.GetFileRequests(pageIndex, pageSize)
+}
+
+func GetFileRequestsByUserId(userId uint, pageIndex, pageSize int) (requests []model.FileRequest, count int64, err error) {
+	return db.GetFileRequestsByUserId(userId, pageIndex, pageSize)
+}
+
+// GetValidFileRequest returns the file request and its owner if it can be uploaded to
+func GetValidFileRequest(id string) (*model.FileRequest, *model.User, error) {
+	r, err := db.GetFileRequestById(id)
+	if err != nil {
+		return nil, nil, err
+	}
+	if r.IsExpired() {
+		return nil, nil, errs.FileRequestExpired
+	}
+	owner, err := GetUserById(r.UserID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if owner.Disabled || !owner.CanFileRequest() {
+		return nil, nil, errs.PermissionDenied
+	}
+	return r, owner, nil
+}
+
+// ReserveFileRequestUpload takes the quotas of an upload of size,
+// ReleaseFileRequestUpload should be called if the upload fails
+func ReserveFileRequestUpload(r *model.FileRequest, size int64) error {
+	ok, err := db.ReserveFileRequestUpload(r.ID, size)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return errs.FileRequestQuotaExceeded
+	}
+	return nil
+}
+
+func ReleaseFileRequestUpload(r *model.FileRequest, size int64) error {
+	return db.ReleaseFileRequestUpload(r.ID, size)
+}
+
+func CreateFileRequestUpload(u *model.FileRequestUpload) error {
+	u.Created = time.Now()
+	return db.CreateFileRequestUpload(u)
+}
+
+func GetFileRequestUploads(requestId string, pageIndex, pageSize int) (uploads []model.FileRequestUpload, count int64, err error) {
+	return db.GetFileRequestUploads(requestId, pageIndex, pageSize)
+}
+
+func DeleteFileRequestById(id string) error {
+	return db.DeleteFileRequestById(id)
+}
@@ -5,7 +5,7 @@ import (
 	stdpath "path"
 	"time"
 
-	"github.com/Xhofe/go-cache"
+	"github.com/alist-org/alist/v3/internal/cache"
 	"github.com/alist-org/alist/v3/internal/driver"
 	"github.com/alist-org/alist/v3/internal/errs"
 	"github.com/alist-org/alist/v3/internal/model"
@@ -18,9 +18,16 @@ import (
 
 // In order to facilitate adding some other things before and after file op
 
-var listCache = cache.NewMemCache(cache.WithShards[[]model.Obj](64))
+var listCache = cache.NewMemory[[]model.Obj](64)
 var listG singleflight.Group[[]model.Obj]
 
+// SetCacheBackend moves the list and link caches to backend,
+// it should be called before the storages are loaded
+func SetCacheBackend(backend cache.Backend) {
+	listCache = cache.NewStore[[]model.Obj](backend, "list", cache.ObjsCodec{})
+	linkCache = cache.NewStore[*model.Link](backend, "link", cache.LinkCodec{})
+}
+
 func updateCacheObj(storage driver.Driver, path string, oldObj model.Obj, newObj model.Obj) {
 	key := Key(storage, path)
 	objs, ok := listCache.Get(key)
@@ -31,7 +38,7 @@ func updateCacheObj(storage driver.Driver, path string, oldObj model.Obj, newObj
 				break
 			}
 		}
-		listCache.Set(key, objs, cache.WithEx[[]model.Obj](time.Minute*time.Duration(storage.GetStorage().CacheExpiration)))
+		listCache.Set(key, objs, time.Minute*time.Duration(storage.GetStorage().CacheExpiration))
 	}
 }
 
@@ -45,7 +52,7 @@ func delCacheObj(storage driver.Driver, path string, obj model.Obj) {
 				break
 			}
 		}
-		listCache.Set(key, objs, cache.WithEx[[]model.Obj](time.Minute*time.Duration(storage.GetStorage().CacheExpiration)))
+		listCache.Set(key, objs, time.Minute*time.Duration(storage.GetStorage().CacheExpiration))
 	}
 }
 
@@ -79,7 +86,7 @@ func addCacheObj(storage driver.Driver, path string, newObj model.Obj) {
 			})
 		}
 
-		listCache.Set(key, objs, cache.WithEx[[]model.Obj](time.Minute*time.Duration(storage.GetStorage().CacheExpiration)))
+		listCache.Set(key, objs, time.Minute*time.Duration(storage.GetStorage().CacheExpiration))
 	}
 }
 
@@ -134,10 +141,8 @@ func List(ctx context.Context, storage driver.Driver, path string, args model.Li
 		}
 		// warp obj name
 		model.WrapObjsName(files)
-		// call hooks
-		go func(reqPath string, files []model.Obj) {
-			HandleObjsUpdateHook(reqPath, files)
-		}(utils.GetFullPath(storage.GetStorage().MountPath, path), files)
+		// call hooks, they are called synchronously to keep the order with the changes
+		HandleObjsUpdateHook(utils.GetFullPath(storage.GetStorage().MountPath, path), files)
 
 		// sort objs
 		if storage.Config().LocalSort {
@@ -148,7 +153,7 @@ func List(ctx context.Context, storage driver.Driver, path string, args model.Li
 		if !storage.Config().NoCache {
 			if len(files) > 0 {
 				log.Debugf("set cache: %s => %+v", key, files)
-				listCache.Set(key, files, cache.WithEx[[]model.Obj](time.Minute*time.Duration(storage.GetStorage().CacheExpiration)))
+				listCache.Set(key, files, time.Minute*time.Duration(storage.GetStorage().CacheExpiration))
 			} else {
 				log.Debugf("del cache: %s", key)
 				listCache.Del(key)
@@ -235,7 +240,7 @@ func GetUnwrap(ctx context.Context, storage driver.Driver, path string) (model.O
 	return model.UnwrapObj(obj), err
 }
 
-var linkCache = cache.NewMemCache(cache.WithShards[*model.Link](16))
+var linkCache = cache.NewMemory[*model.Link](16)
 var linkG singleflight.Group[*model.Link]
 
 // Link get link, if is an url. should have an expiry time
@@ -263,7 +268,7 @@ func Link(ctx context.Context, storage driver.Driver, path string, args model.Li
 			if link.IPCacheKey {
 				key = key + ":" + args.IP
 			}
-			linkCache.Set(key, link, cache.WithEx[*model.Link](*link.Expiration))
+			linkCache.Set(key, link, *link.Expiration)
 		}
 		return link, nil
 	}
@@ -318,9 +323,9 @@ func MakeDir(ctx context.Context, storage driver.Driver, path string, lazyCache
 					return nil, errors.WithMessagef(err, "failed to get parent dir [%s]", parentPath)
 				}
 
+				var newObj model.Obj
 				switch s := storage.(type) {
 				case driver.MkdirResult:
-					var newObj model.Obj
 					newObj, err = s.MakeDir(ctx, parentDir, dirName)
 					if err == nil {
 						if newObj != nil {
@@ -337,6 +342,12 @@ func MakeDir(ctx context.Context, storage driver.Driver, path string, lazyCache
 				default:
 					return nil, errs.NotImplement
 				}
+				if err == nil {
+					if newObj == nil {
+						newObj = &model.Object{Name: dirName, IsFolder: true, Modified: time.Now()}
+					}
+					HandleObjChangeHook(storage, ObjChange{Path: path, Obj: model.WrapObjName(newObj)})
+				}
 				return nil, errors.WithStack(err)
 			}
 			return nil, errors.WithMessage(err, "failed to check if dir exists")
@@ -368,9 +379,9 @@ func Move(ctx context.Context, storage driver.Driver, srcPath, dstDirPath string
 	}
 	srcDirPath := stdpath.Dir(srcPath)
 
+	var newObj model.Obj
 	switch s := storage.(type) {
 	case driver.MoveResult:
-		var newObj model.Obj
 		newObj, err = s.Move(ctx, srcObj, dstDir)
 		if err == nil {
 			delCacheObj(storage, srcDirPath, srcRawObj)
@@ -391,10 +402,28 @@ func Move(ctx context.Context, storage driver.Driver, srcPath, dstDirPath string
 	default:
 		return errs.NotImplement
 	}
+	if err == nil {
+		if newObj == nil {
+			newObj = srcObj
+		}
+		dstPath := stdpath.Join(dstDirPath, srcRawObj.GetName())
+		moveQuota(storage, srcPath, dstPath)
+		HandleObjChangeHook(storage, ObjChange{Path: srcPath}, ObjChange{Path: dstPath, Obj: model.WrapObjName(newObj)})
+	}
 	return errors.WithStack(err)
 }
 
 func Rename(ctx context.Context, storage driver.Driver, srcPath, dstName string, lazyCache ...bool) error {
+	err := rename(ctx, storage, srcPath, dstName, lazyCache...)
+	if err == nil {
+		srcPath = utils.FixAndCleanPath(srcPath)
+		moveQuota(storage, srcPath, stdpath.Join(stdpath.Dir(srcPath), dstName))
+	}
+	return err
+}
+
+// rename renames the object without moving its quota usage, it's used for the temp object while uploading
+func rename(ctx context.Context, storage driver.Driver, srcPath, dstName string, lazyCache ...bool) error {
 	if storage.Config().CheckStatus && storage.GetStorage().Status != WORK {
 		return errors.Errorf("storage not init: %s", storage.GetStorage().Status)
 	}
@@ -406,9 +435,9 @@ func Rename(ctx context.Context, storage driver.Driver, srcPath, dstName string,
 	srcObj := model.UnwrapObj(srcRawObj)
 	srcDirPath := stdpath.Dir(srcPath)
 
+	var newObj model.Obj
 	switch s := storage.(type) {
 	case driver.RenameResult:
-		var newObj model.Obj
 		newObj, err = s.Rename(ctx, srcObj, dstName)
 		if err == nil {
 			if newObj != nil {
@@ -425,6 +454,14 @@ func Rename(ctx context.Context, storage driver.Driver, srcPath, dstName string,
 	default:
 		return errs.NotImplement
 	}
+	if err == nil {
+		obj := &model.ObjWrapName{Name: dstName, Obj: srcObj}
+		if newObj != nil {
+			obj.Obj = newObj
+		}
+		HandleObjChangeHook(storage, ObjChange{Path: srcPath},
+			ObjChange{Path: stdpath.Join(srcDirPath, dstName), Obj: obj})
+	}
 	return errors.WithStack(err)
 }
 
@@ -444,9 +481,9 @@ func Copy(ctx context.Context, storage driver.Driver, srcPath, dstDirPath string
 		return errors.WithMessage(err, "failed to get dst dir")
 	}
 
+	var newObj model.Obj
 	switch s := storage.(type) {
 	case driver.CopyResult:
-		var newObj model.Obj
 		newObj, err = s.Copy(ctx, srcObj, dstDir)
 		if err == nil {
 			if newObj != nil {
@@ -463,10 +500,57 @@ func Copy(ctx context.Context, storage driver.Driver, srcPath, dstDirPath string
 	default:
 		return errs.NotImplement
 	}
+	if err == nil {
+		if newObj == nil {
+			newObj = srcObj
+		}
+		obj := model.WrapObjName(newObj)
+		HandleObjChangeHook(storage, ObjChange{Path: stdpath.Join(dstDirPath, obj.GetName()), Obj: obj})
+	}
 	return errors.WithStack(err)
 }
 
+// Remove moves the object to the recycle bin if it's enabled and supported by storage,
+// the objects in the recycle bin are removed permanently
 func Remove(ctx context.Context, storage driver.Driver, path string) error {
+	path = utils.FixAndCleanPath(path)
+	if !isTrashEnabled() || !canTrash(storage) || utils.PathEqual(path, "/") {
+		return RemovePermanently(ctx, storage, path)
+	}
+	if IsInTrash(path) {
+		err := RemovePermanently(ctx, storage, path)
+		if err == nil {
+			forgetTrash(storage, path)
+		}
+		return err
+	}
+	if storage.Config().CheckStatus && storage.GetStorage().Status != WORK {
+		return errors.Errorf("storage not init: %s", storage.GetStorage().Status)
+	}
+	rawObj, err := Get(ctx, storage, path)
+	if err != nil {
+		// if object not found, it's ok
+		if errs.IsObjectNotFound(err) {
+			log.Debugf("%s have been removed", path)
+			return nil
+		}
+		return errors.WithMessage(err, "failed to get object")
+	}
+	return moveToTrash(ctx, storage, path, rawObj)
+}
+
+// RemovePermanently removes the object by the driver, the quotas charged by the files in it are released
+func RemovePermanently(ctx context.Context, storage driver.Driver, path string) error {
+	err := removePermanently(ctx, storage, path)
+	if err == nil && !utils.PathEqual(path, "/") {
+		releaseQuota(storage, utils.FixAndCleanPath(path))
+	}
+	return err
+}
+
+// removePermanently removes the object without releasing the quotas, it's used for the object
+// replaced by the upload, which has been refunded
+func removePermanently(ctx context.Context, storage driver.Driver, path string) error {
 	if storage.Config().CheckStatus && storage.GetStorage().Status != WORK {
 		return errors.Errorf("storage not init: %s", storage.GetStorage().Status)
 	}
@@ -494,6 +578,7 @@ func Remove(ctx context.Context, storage driver.Driver, path string) error {
 			if rawObj.IsDir() {
 				ClearCache(storage, path)
 			}
+			HandleObjChangeHook(storage, ObjChange{Path: path})
 		}
 	default:
 		return errs.NotImplement
@@ -502,6 +587,15 @@ func Remove(ctx context.Context, storage driver.Driver, path string) error {
 }
 
 func Put(ctx context.Context, storage driver.Driver, dstDirPath string, file model.FileStreamer, up driver.UpdateProgress, lazyCache ...bool) error {
+	return put(ctx, storage, dstDirPath, file, nil, up, lazyCache...)
+}
+
+// PutResumable is Put but resumes the upload from cp if the storage supports
+func PutResumable(ctx context.Context, storage driver.Driver, dstDirPath string, file model.FileStreamer, cp *driver.Checkpoint, up driver.UpdateProgress, lazyCache ...bool) error {
+	return put(ctx, storage, dstDirPath, file, cp, up, lazyCache...)
+}
+
+func put(ctx context.Context, storage driver.Driver, dstDirPath string, file model.FileStreamer, cp *driver.Checkpoint, up driver.UpdateProgress, lazyCache ...bool) error {
 	if storage.Config().CheckStatus && storage.GetStorage().Status != WORK {
 		return errors.Errorf("storage not init: %s", storage.GetStorage().Status)
 	}
@@ -518,13 +612,13 @@ func Put(ctx context.Context, storage driver.Driver, dstDirPath string, file mod
 	fi, err := GetUnwrap(ctx, storage, dstPath)
 	if err == nil {
 		if fi.GetSize() == 0 {
-			err = Remove(ctx, storage, dstPath)
+			err = removePermanently(ctx, storage, dstPath)
 			if err != nil {
 				return errors.WithMessagef(err, "while uploading, failed remove existing file which size = 0")
 			}
 		} else if storage.Config().NoOverwriteUpload {
 			// try to rename old obj
-			err = Rename(ctx, storage, dstPath, tempName)
+			err = rename(ctx, storage, dstPath, tempName)
 			if err != nil {
 				return err
 			}
@@ -546,36 +640,55 @@ func Put(ctx context.Context, storage driver.Driver, dstDirPath string, file mod
 		up = func(p float64) {}
 	}
 
-	switch s := storage.(type) {
-	case driver.PutResult:
-		var newObj model.Obj
-		newObj, err = s.Put(ctx, parentDir, file, up)
-		if err == nil {
-			if newObj != nil {
-				addCacheObj(storage, dstDirPath, model.WrapObjName(newObj))
-			} else if !utils.IsBool(lazyCache...) {
-				ClearCache(storage, dstDirPath)
-			}
-		}
-	case driver.Put:
-		err = s.Put(ctx, parentDir, file, up)
+	var newObj model.Obj
+	if s, ok := storage.(driver.PutResumable); ok && cp != nil {
+		err = s.PutResumable(ctx, parentDir, file, cp, up)
 		if err == nil && !utils.IsBool(lazyCache...) {
 			ClearCache(storage, dstDirPath)
 		}
-	default:
-		return errs.NotImplement
+	} else {
+		switch s := storage.(type) {
+		case driver.PutResult:
+			newObj, err = s.Put(ctx, parentDir, file, up)
+			if err == nil {
+				if newObj != nil {
+					addCacheObj(storage, dstDirPath, model.WrapObjName(newObj))
+				} else if !utils.IsBool(lazyCache...) {
+					ClearCache(storage, dstDirPath)
+				}
+			}
+		case driver.Put:
+			err = s.Put(ctx, parentDir, file, up)
+			if err == nil && !utils.IsBool(lazyCache...) {
+				ClearCache(storage, dstDirPath)
+			}
+		default:
+			return errs.NotImplement
+		}
 	}
 	log.Debugf("put file [%s] done", file.GetName())
+	if err == nil {
+		if newObj == nil {
+			// the stream should not be held by the hooks
+			newObj = &model.Object{
+				Name:     file.GetName(),
+				Size:     file.GetSize(),
+				Modified: file.ModTime(),
+				HashInfo: file.GetHash(),
+			}
+		}
+		HandleObjChangeHook(storage, ObjChange{Path: dstPath, Obj: model.WrapObjName(newObj)})
+	}
 	if storage.Config().NoOverwriteUpload && fi != nil && fi.GetSize() > 0 {
 		if err != nil {
 			// upload failed, recover old obj
-			err := Rename(ctx, storage, tempPath, file.GetName())
+			err := rename(ctx, storage, tempPath, file.GetName())
 			if err != nil {
 				log.Errorf("failed recover old obj: %+v", err)
 			}
 		} else {
 			// upload success, remove old obj
-			err := Remove(ctx, storage, tempPath)
+			err := removePermanently(ctx, storage, tempPath)
 			if err != nil {
 				return err
 			} else {
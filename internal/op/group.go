@@ -0,0 +1,139 @@
+package op
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/alist-org/alist/v3/internal/db"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/pkg/utils"
+	log "github.com/sirupsen/logrus"
+)
+
+func loadUserGroups(u *model.User) error {
+	groups, err := db.GetGroupsByUserId(u.ID)
+	if err != nil {
+		return err
+	}
+	u.Groups = groups
+	return nil
+}
+
+// clearUserCache drops the cached users, the groups are loaded with them
+func clearUserCache() {
+	userCache.Clear()
+	adminUser = nil
+	guestUser = nil
+}
+
+func GetGroupById(id uint) (*model.Group, error) {
+	return db.GetGroupById(id)
+}
+
+func GetGroups(pageIndex, pageSize int) (groups []model.Group, count int64, err error) {
+	return db.GetGroups(pageIndex, pageSize)
+}
+
+func CreateGroup(g *model.Group) error {
+	g.BasePath = utils.FixAndCleanPath(g.BasePath)
+	return db.CreateGroup(g)
+}
+
+func UpdateGroup(g *model.Group) error {
+	if _, err := db.GetGroupById(g.ID); err != nil {
+		return err
+	}
+	g.BasePath = utils.FixAndCleanPath(g.BasePath)
+	defer clearUserCache()
+	return db.UpdateGroup(g)
+}
+
+func DeleteGroupById(id uint) error {
+	if _, err := db.GetGroupById(id); err != nil {
+		return err
+	}
+	defer clearUserCache()
+	if err := DeleteACLsBySubject(model.ACLSubjectGroup, id); err != nil {
+		return err
+	}
+	groupMetaCache.Del(strconv.FormatUint(uint64(id), 10))
+	return db.DeleteGroupById(id)
+}
+
+func GetGroupMemberIds(id uint) ([]uint, error) {
+	return db.GetUserIdsByGroupId(id)
+}
+
+func SetGroupMembers(id uint, userIds []uint) error {
+	if _, err := db.GetGroupById(id); err != nil {
+		return err
+	}
+	defer clearUserCache()
+	return db.SetGroupMembers(id, userIds)
+}
+
+// matchExternalName reports whether name is one of the external names,
+// the cn of a ldap dn such as cn=dev,ou=groups,dc=example,dc=com is matched too
+func matchExternalName(names []string, name string) bool {
+	cn := ""
+	if first, _, ok := strings.Cut(name, ","); ok {
+		if k, v, ok := strings.Cut(first, "="); ok && strings.EqualFold(strings.TrimSpace(k), "cn") {
+			cn = strings.TrimSpace(v)
+		}
+	}
+	for _, n := range names {
+		if strings.EqualFold(n, name) || (cn != "" && strings.EqualFold(n, cn)) {
+			return true
+		}
+	}
+	return false
+}
+
+// SyncExternalGroups syncs the memberships of user with the groups from ldap or sso,
+// only the groups with external names are managed, the others are kept as is.
+// The user may be shared by the cache, so its groups are not changed, they're
+// reloaded by the next GetUserByName after the user is deleted from the cache
+func SyncExternalGroups(user *model.User, externalNames []string) error {
+	groups, err := db.GetAllGroups()
+	if err != nil {
+		return err
+	}
+	// the user may be loaded without groups
+	current, err := db.GetGroupsByUserId(user.ID)
+	if err != nil {
+		return err
+	}
+	joined := make(map[uint]struct{}, len(current))
+	for _, g := range current {
+		joined[g.ID] = struct{}{}
+	}
+	var add, remove []uint
+	for _, g := range groups {
+		names := g.GetExternalNames()
+		if len(names) == 0 {
+			continue
+		}
+		member := false
+		for _, name := range externalNames {
+			if matchExternalName(names, name) {
+				member = true
+				break
+			}
+		}
+		_, ok := joined[g.ID]
+		if member && !ok {
+			add = append(add, g.ID)
+		} else if !member && ok {
+			remove = append(remove, g.ID)
+		}
+	}
+	if len(add) == 0 && len(remove) == 0 {
+		return nil
+	}
+	log.Debugf("sync groups of user [%s], add: %v, remove: %v", user.Username, add, remove)
+	defer userCache.Del(user.Username)
+	if err = db.AddUserToGroups(user.ID, add...); err != nil {
+		return err
+	}
+	return db.RemoveUserFromGroups(user.ID, remove...)
+}
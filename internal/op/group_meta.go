@@ -0,0 +1,127 @@
+package op
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/Xhofe/go-cache"
+	"github.com/alist-org/alist/v3/internal/db"
+	"github.com/alist-org/alist/v3/internal/errs"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/pkg/singleflight"
+	"github.com/alist-org/alist/v3/pkg/utils"
+	"github.com/pkg/errors"
+)
+
+var groupMetaCache = cache.NewMemCache(cache.WithShards[[]model.GroupMeta](2))
+var groupMetaG singleflight.Group[[]model.GroupMeta]
+
+func GetGroupMetas(groupId uint) ([]model.GroupMeta, error) {
+	key := strconv.FormatUint(uint64(groupId), 10)
+	if metas, ok := groupMetaCache.Get(key); ok {
+		return metas, nil
+	}
+	metas, err, _ := groupMetaG.Do(key, func() ([]model.GroupMeta, error) {
+		_metas, err := db.GetGroupMetasByGroupId(groupId)
+		if err != nil {
+			return nil, err
+		}
+		groupMetaCache.Set(key, _metas, cache.WithEx[[]model.GroupMeta](time.Hour))
+		return _metas, nil
+	})
+	return metas, err
+}
+
+// nearestGroupMeta returns the group meta of the nearest path, the groups
+// of user are ordered by id, so the smallest id wins on the same path
+func nearestGroupMeta(user *model.User, path string) (*model.GroupMeta, error) {
+	var nearest *model.GroupMeta
+	for _, g := range user.Groups {
+		metas, err := GetGroupMetas(g.ID)
+		if err != nil {
+			return nil, err
+		}
+		for i := range metas {
+			if utils.IsSubPath(metas[i].Path, path) && (nearest == nil || len(metas[i].Path) > len(nearest.Path)) {
+				nearest = &metas[i]
+			}
+		}
+	}
+	return nearest, nil
+}
+
+// GetNearestUserMeta returns the nearest meta of path for user, the meta
+// overrides of the groups of user replace the password, write and hide
+// if they are on the same or a deeper path
+func GetNearestUserMeta(user *model.User, path string) (*model.Meta, error) {
+	path = utils.FixAndCleanPath(path)
+	meta, err := getNearestMeta(path)
+	if err != nil && !errors.Is(errors.Cause(err), errs.MetaNotFound) {
+		return nil, err
+	}
+	if user == nil || user.IsAdmin() || len(user.Groups) == 0 {
+		return meta, err
+	}
+	gm, gErr := nearestGroupMeta(user, path)
+	if gErr != nil {
+		return nil, gErr
+	}
+	if gm == nil || (meta != nil && len(gm.Path) < len(meta.Path)) {
+		return meta, err
+	}
+	res := model.Meta{Path: gm.Path}
+	if meta != nil {
+		res = *meta
+		if meta.Path != gm.Path {
+			// the readme and header of the meta on parent path only apply to the sub paths if set
+			res.Path = gm.Path
+			res.RSub, res.HeaderSub = meta.RSub, meta.HeaderSub
+			if !meta.RSub {
+				res.Readme = ""
+			}
+			if !meta.HeaderSub {
+				res.Header = ""
+			}
+		}
+	}
+	res.Password, res.PSub = gm.Password, gm.PSub
+	res.Write, res.WSub = gm.Write, gm.WSub
+	res.Hide, res.HSub = gm.Hide, gm.HSub
+	return &res, nil
+}
+
+func GetGroupMetaById(id uint) (*model.GroupMeta, error) {
+	return db.GetGroupMetaById(id)
+}
+
+func CreateGroupMeta(m *model.GroupMeta) error {
+	if _, err := db.GetGroupById(m.GroupID); err != nil {
+		return err
+	}
+	m.Path = utils.FixAndCleanPath(m.Path)
+	groupMetaCache.Del(strconv.FormatUint(uint64(m.GroupID), 10))
+	return db.CreateGroupMeta(m)
+}
+
+func UpdateGroupMeta(m *model.GroupMeta) error {
+	old, err := db.GetGroupMetaById(m.ID)
+	if err != nil {
+		return err
+	}
+	if _, err = db.GetGroupById(m.GroupID); err != nil {
+		return err
+	}
+	m.Path = utils.FixAndCleanPath(m.Path)
+	groupMetaCache.Del(strconv.FormatUint(uint64(old.GroupID), 10))
+	groupMetaCache.Del(strconv.FormatUint(uint64(m.GroupID), 10))
+	return db.UpdateGroupMeta(m)
+}
+
+func DeleteGroupMetaById(id uint) error {
+	old, err := db.GetGroupMetaById(id)
+	if err != nil {
+		return err
+	}
+	groupMetaCache.Del(strconv.FormatUint(uint64(old.GroupID), 10))
+	return db.DeleteGroupMetaById(id)
+}
@@ -0,0 +1,72 @@
+package op_test
+
+import (
+	"testing"
+
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/internal/op"
+)
+
+func TestGetNearestUserMeta(t *testing.T) {
+	a := &model.Group{Name: "meta_a", BasePath: "/team"}
+	b := &model.Group{Name: "meta_b", BasePath: "/other"}
+	for _, g := range []*model.Group{a, b} {
+		if err := op.CreateGroup(g); err != nil {
+			t.Fatalf("failed to create group: %+v", err)
+		}
+	}
+	metas := []model.Meta{
+		{Path: "/team", Password: "secret", PSub: true, Readme: "team readme", RSub: true, Header: "team header"},
+	}
+	for i := range metas {
+		if err := op.CreateMeta(&metas[i]); err != nil {
+			t.Fatalf("failed to create meta: %+v", err)
+		}
+	}
+	groupMetas := []model.GroupMeta{
+		{GroupID: a.ID, Path: "/team", PSub: true, Write: true, WSub: true},
+		{GroupID: b.ID, Path: "/team", Password: "b", PSub: true},
+		{GroupID: b.ID, Path: "/team/docs", Hide: "draft", HSub: true},
+	}
+	for i := range groupMetas {
+		if err := op.CreateGroupMeta(&groupMetas[i]); err != nil {
+			t.Fatalf("failed to create group meta: %+v", err)
+		}
+	}
+	member := &model.User{Username: "member", Groups: []model.Group{*a, *b}}
+	outsider := &model.User{Username: "outsider"}
+	datas := []struct {
+		user     *model.User
+		path     string
+		metaPath string
+		password string
+		write    bool
+		hide     string
+		readme   string
+		header   string
+	}{
+		{user: outsider, path: "/team/docs", metaPath: "/team", password: "secret", readme: "team readme", header: "team header"},
+		// the group with the smallest id wins on the same path
+		{user: member, path: "/team", metaPath: "/team", write: true, readme: "team readme", header: "team header"},
+		// the deeper override wins, the header of parent isn't applied to sub paths
+		{user: member, path: "/team/docs/a", metaPath: "/team/docs", password: "", hide: "draft", readme: "team readme"},
+	}
+	for i, data := range datas {
+		meta, err := op.GetNearestUserMeta(data.user, data.path)
+		if err != nil {
+			t.Errorf("TestGetNearestUserMeta %d failed: %+v", i, err)
+			continue
+		}
+		if meta.Path != data.metaPath || meta.Password != data.password || meta.Write != data.write ||
+			meta.Hide != data.hide || meta.Readme != data.readme || meta.Header != data.header {
+			t.Errorf("TestGetNearestUserMeta %d failed, got: %+v", i, meta)
+		}
+	}
+	if p := member.GetBasePath(); p != "/team" {
+		t.Errorf("expected base path of the first group, got: %s", p)
+	}
+	admin := &model.User{Username: "admin", Role: model.ADMIN, BasePath: "/", Groups: []model.Group{*a}}
+	if p := admin.GetBasePath(); p != "/" {
+		t.Errorf("expected base path of admin not narrowed, got: %s", p)
+	}
+}
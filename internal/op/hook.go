@@ -2,17 +2,21 @@ package op
 
 import (
 	"regexp"
+	"strconv"
 	"strings"
 
 	"github.com/alist-org/alist/v3/internal/conf"
 	"github.com/alist-org/alist/v3/internal/driver"
 	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/internal/ratelimit"
 	"github.com/alist-org/alist/v3/pkg/utils"
 	"github.com/pkg/errors"
 	log "github.com/sirupsen/logrus"
 )
 
 // Obj
+
+// ObjsUpdateHook is called synchronously with the listing of dir, so it should not block
 type ObjsUpdateHook = func(parent string, objs []model.Obj)
 
 var (
@@ -29,6 +33,37 @@ func HandleObjsUpdateHook(parent string, objs []model.Obj) {
 	}
 }
 
+// ObjChange is an obj added to or removed from the storage by alist
+type ObjChange struct {
+	// the full path of obj in alist
+	Path string
+	// nil if the obj is removed
+	Obj model.Obj
+}
+
+// ObjChangeHook is called synchronously, so it should not block
+type ObjChangeHook = func(change ObjChange)
+
+var (
+	objChangeHooks = make([]ObjChangeHook, 0)
+)
+
+func RegisterObjChangeHook(hook ObjChangeHook) {
+	objChangeHooks = append(objChangeHooks, hook)
+}
+
+// HandleObjChangeHook calls the hooks with the changes in order, the paths of changes are
+// the actual paths in storage
+func HandleObjChangeHook(storage driver.Driver, changes ...ObjChange) {
+	mountPath := storage.GetStorage().MountPath
+	for _, change := range changes {
+		change.Path = utils.GetFullPath(mountPath, change.Path)
+		for _, hook := range objChangeHooks {
+			hook(change)
+		}
+	}
+}
+
 // Setting
 type SettingItemHook func(item *model.SettingItem) error
 
@@ -82,6 +117,22 @@ var settingItemHooks = map[string]SettingItemHook{
 		conf.SlicesMap[conf.IgnoreDirectLinkParams] = strings.Split(item.Value, ",")
 		return nil
 	},
+	conf.DownloadLimit: func(item *model.SettingItem) error {
+		kbps, err := strconv.ParseInt(item.Value, 10, 64)
+		if err != nil {
+			return errors.WithStack(err)
+		}
+		ratelimit.SetGlobalDownload(kbps)
+		return nil
+	},
+	conf.UploadLimit: func(item *model.SettingItem) error {
+		kbps, err := strconv.ParseInt(item.Value, 10, 64)
+		if err != nil {
+			return errors.WithStack(err)
+		}
+		ratelimit.SetGlobalUpload(kbps)
+		return nil
+	},
 }
 
 func RegisterSettingItemHook(key string, hook SettingItemHook) {
@@ -0,0 +1,280 @@
+package op
+
+import (
+	"context"
+	stdpath "path"
+	"strings"
+
+	"github.com/alist-org/alist/v3/internal/db"
+	"github.com/alist-org/alist/v3/internal/driver"
+	"github.com/alist-org/alist/v3/internal/errs"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/pkg/utils"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+)
+
+func GetQuotas(pageIndex, pageSize int) ([]model.Quota, int64, error) {
+	return db.GetQuotas(pageIndex, pageSize)
+}
+
+func CreateQuota(q *model.Quota) error {
+	if err := q.Validate(); err != nil {
+		return err
+	}
+	q.ID, q.UsedBytes = 0, 0
+	return db.CreateQuota(q)
+}
+
+func UpdateQuota(q *model.Quota) error {
+	if err := q.Validate(); err != nil {
+		return err
+	}
+	if _, err := db.GetQuotaById(q.ID); err != nil {
+		return err
+	}
+	return db.UpdateQuota(q)
+}
+
+func DeleteQuotaById(id uint) error {
+	return db.DeleteQuotaById(id)
+}
+
+func ResetQuotaUsedBytes(id uint) error {
+	return db.ResetQuotaUsedBytes(id)
+}
+
+// getMatchedQuotas returns the quotas applied to the uploads of user to path
+func getMatchedQuotas(user *model.User, path string) ([]model.Quota, error) {
+	quotas, err := db.GetAllQuotas()
+	if err != nil {
+		return nil, err
+	}
+	var matched []model.Quota
+	for _, q := range quotas {
+		if q.Match(user, path) {
+			matched = append(matched, q)
+		}
+	}
+	return matched, nil
+}
+
+// useQuotas adds the deltas to the used bytes of quotas, errs.QuotaExceeded if any of them would exceed.
+// The negative ones are always applied.
+func useQuotas(deltas map[uint]int64) error {
+	var used []uint
+	undo := func() {
+		for _, id := range used {
+			if err := db.IncQuotaUsedBytes(id, -deltas[id]); err != nil {
+				log.Errorf("failed undo the used bytes of quota %d: %+v", id, err)
+			}
+		}
+	}
+	for id, delta := range deltas {
+		if delta == 0 {
+			continue
+		}
+		ok, err := db.AddQuotaUsedBytes(id, delta)
+		if err == nil && ok {
+			used = append(used, id)
+			continue
+		}
+		undo()
+		if err != nil {
+			return err
+		}
+		return errors.WithStack(errs.QuotaExceeded)
+	}
+	return nil
+}
+
+// charge charges the files, which are the full paths to the sizes, to the quotas of user matched,
+// the usages replaced are refunded to the quotas they were charged to. The returned refund should
+// be called if the operation failed.
+func charge(user *model.User, quotas []model.Quota, files map[string]int64, replaced []model.QuotaUsage) (refund func(), err error) {
+	deltas := make(map[uint]int64)
+	for _, u := range replaced {
+		for _, id := range u.QuotaIDs {
+			deltas[id] -= u.Size
+		}
+	}
+	var added []model.QuotaUsage
+	for path, size := range files {
+		u := model.QuotaUsage{Path: path, Size: size}
+		if user != nil {
+			u.UserID = user.ID
+		}
+		for _, q := range quotas {
+			if q.Match(user, path) {
+				u.QuotaIDs = append(u.QuotaIDs, q.ID)
+				deltas[q.ID] += size
+			}
+		}
+		if len(u.QuotaIDs) > 0 {
+			added = append(added, u)
+		}
+	}
+	if len(added) == 0 && len(replaced) == 0 {
+		return func() {}, nil
+	}
+	if err = useQuotas(deltas); err != nil {
+		return nil, err
+	}
+	refund = func() {
+		for id, delta := range deltas {
+			if err := db.IncQuotaUsedBytes(id, -delta); err != nil {
+				log.Errorf("failed refund the used bytes of quota %d: %+v", id, err)
+			}
+		}
+		if err := db.DeleteQuotaUsages(added); err != nil {
+			log.Errorf("failed delete the quota usages: %+v", err)
+		}
+		if err := db.CreateQuotaUsages(replaced); err != nil {
+			log.Errorf("failed restore the quota usages: %+v", err)
+		}
+	}
+	err = db.DeleteQuotaUsages(replaced)
+	if err == nil {
+		err = db.CreateQuotaUsages(added)
+	}
+	if err != nil {
+		refund()
+		return nil, err
+	}
+	return refund, nil
+}
+
+// UseQuota charges the put of file to dstDirPath of storage to the quotas of user, the file
+// replaced is refunded to the quotas of its uploader. The returned refund should be called if the put failed.
+func UseQuota(user *model.User, storage driver.Driver, dstDirPath string, file model.Obj) (refund func(), err error) {
+	path := utils.GetFullPath(storage.GetStorage().MountPath, stdpath.Join(dstDirPath, file.GetName()))
+	quotas, err := db.GetAllQuotas()
+	if err != nil {
+		return nil, err
+	}
+	replaced, err := db.GetQuotaUsagesByPaths([]string{path})
+	if err != nil {
+		return nil, err
+	}
+	return charge(user, quotas, map[string]int64{path: file.GetSize()}, replaced)
+}
+
+// UseQuotaOfTree charges the files in srcPath copied or moved to dstPath of storage to the quotas of
+// user, the files replaced, and the src files if moved, are refunded to the quotas of their uploaders.
+// The returned refund should be called if the copy or move failed.
+func UseQuotaOfTree(ctx context.Context, user *model.User, storage driver.Driver, srcPath, dstPath string, move bool) (refund func(), err error) {
+	quotas, err := db.GetAllQuotas()
+	if err != nil || len(quotas) == 0 {
+		return func() {}, err
+	}
+	mountPath := storage.GetStorage().MountPath
+	srcFullPath := utils.GetFullPath(mountPath, srcPath)
+	dstFullPath := utils.GetFullPath(mountPath, dstPath)
+	obj, err := Get(ctx, storage, srcPath)
+	if err != nil {
+		return nil, errors.WithMessage(err, "failed get src object")
+	}
+	files := make(map[string]int64)
+	if obj.IsDir() {
+		err = walkFiles(ctx, storage, srcPath, func(path string, obj model.Obj) {
+			files[dstFullPath+strings.TrimPrefix(utils.GetFullPath(mountPath, path), srcFullPath)] = obj.GetSize()
+		})
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		files[dstFullPath] = obj.GetSize()
+	}
+	usages, err := db.GetQuotaUsagesIn(dstFullPath)
+	if err != nil {
+		return nil, err
+	}
+	var replaced []model.QuotaUsage
+	for _, u := range usages {
+		if _, ok := files[u.Path]; ok {
+			replaced = append(replaced, u)
+		}
+	}
+	if move {
+		usages, err = db.GetQuotaUsagesIn(srcFullPath)
+		if err != nil {
+			return nil, err
+		}
+		replaced = append(replaced, usages...)
+	}
+	return charge(user, quotas, files, replaced)
+}
+
+// releaseQuota refunds the files removed permanently in path of storage to the quotas of their uploaders
+func releaseQuota(storage driver.Driver, path string) {
+	usages, err := db.GetQuotaUsagesIn(utils.GetFullPath(storage.GetStorage().MountPath, path))
+	if err == nil {
+		err = releaseUsages(usages)
+	}
+	if err != nil {
+		log.Errorf("failed release the quotas of %s: %+v", path, err)
+	}
+}
+
+func releaseUsages(usages []model.QuotaUsage) error {
+	for _, u := range usages {
+		for _, id := range u.QuotaIDs {
+			if err := db.IncQuotaUsedBytes(id, -u.Size); err != nil {
+				log.Errorf("failed release the used bytes of quota %d: %+v", id, err)
+			}
+		}
+	}
+	return db.DeleteQuotaUsages(usages)
+}
+
+// moveQuota moves the usages of the files in srcPath to dstPath of storage, so they are still charged
+// to the quotas of their uploaders, like moving to the recycle bin. The usages replaced are released.
+func moveQuota(storage driver.Driver, srcPath, dstPath string) {
+	mountPath := storage.GetStorage().MountPath
+	srcFullPath := utils.GetFullPath(mountPath, srcPath)
+	dstFullPath := utils.GetFullPath(mountPath, dstPath)
+	usages, err := db.GetQuotaUsagesIn(srcFullPath)
+	if err != nil || len(usages) == 0 {
+		if err != nil {
+			log.Errorf("failed get the quota usages of %s: %+v", srcFullPath, err)
+		}
+		return
+	}
+	existing, err := db.GetQuotaUsagesIn(dstFullPath)
+	if err != nil {
+		log.Errorf("failed get the quota usages of %s: %+v", dstFullPath, err)
+		return
+	}
+	paths := make(map[string]model.QuotaUsage, len(existing))
+	for _, u := range existing {
+		paths[u.Path] = u
+	}
+	for _, u := range usages {
+		path := dstFullPath + strings.TrimPrefix(u.Path, srcFullPath)
+		if old, ok := paths[path]; ok {
+			if err := releaseUsages([]model.QuotaUsage{old}); err != nil {
+				log.Errorf("failed release the quota usage of %s: %+v", path, err)
+			}
+		}
+		if err := db.UpdateQuotaUsagePath(u.ID, path); err != nil {
+			log.Errorf("failed move the quota usage of %s: %+v", u.Path, err)
+		}
+	}
+}
+
+// walkFiles calls fn with the files in dir path of storage recursively
+func walkFiles(ctx context.Context, storage driver.Driver, path string, fn func(path string, obj model.Obj)) error {
+	objs, err := List(ctx, storage, path, model.ListArgs{})
+	if err != nil {
+		return errors.WithMessagef(err, "failed list [%s]", path)
+	}
+	for _, obj := range objs {
+		p := stdpath.Join(path, obj.GetName())
+		if !obj.IsDir() {
+			fn(p, obj)
+		} else if err = walkFiles(ctx, storage, p, fn); err != nil {
+			return err
+		}
+	}
+	return nil
+}
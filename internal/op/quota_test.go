@@ -0,0 +1,133 @@
+package op_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/alist-org/alist/v3/internal/conf"
+	"github.com/alist-org/alist/v3/internal/db"
+	"github.com/alist-org/alist/v3/internal/errs"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/internal/op"
+	"github.com/pkg/errors"
+)
+
+func TestQuota(t *testing.T) {
+	root := t.TempDir()
+	for name, content := range map[string]string{
+		"a/x.txt":    "hello",
+		"a/y.txt":    "1234",
+		"free/z.txt": "123456",
+		"dir/w.txt":  "ab",
+	} {
+		p := filepath.Join(root, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(p), 0777); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(p, []byte(content), 0666); err != nil {
+			t.Fatal(err)
+		}
+	}
+	ctx := context.Background()
+	id, err := op.CreateStorage(ctx, model.Storage{
+		Driver:    "Local",
+		MountPath: "/quota",
+		Addition:  `{"root_folder_path":"` + filepath.ToSlash(root) + `"}`,
+	})
+	if err != nil {
+		t.Fatalf("failed to create storage: %+v", err)
+	}
+	storage, err := op.GetStorageByMountPath("/quota")
+	if err != nil {
+		t.Fatal(err)
+	}
+	userA, userB := &model.User{ID: 1}, &model.User{ID: 2}
+	quotaA := &model.Quota{UserID: userA.ID, BasePath: "/quota", MaxBytes: 20}
+	quotaB := &model.Quota{UserID: userB.ID, BasePath: "/quota/a", MaxBytes: 20}
+	for _, q := range []*model.Quota{quotaA, quotaB} {
+		if err = op.CreateQuota(q); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err = op.SaveSettingItem(&model.SettingItem{Key: conf.RecycleBinEnabled, Value: "true", Type: conf.TypeBool}); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		_ = op.SaveSettingItem(&model.SettingItem{Key: conf.RecycleBinEnabled, Value: "false", Type: conf.TypeBool})
+		_ = op.DeleteQuotaById(quotaA.ID)
+		_ = op.DeleteQuotaById(quotaB.ID)
+		_ = op.DeleteStorageById(ctx, id)
+	})
+	upload := func(user *model.User, name string, size int64) func() error {
+		return func() error {
+			_, err := op.UseQuota(user, storage, "/a", &model.Object{Name: name, Size: size})
+			return err
+		}
+	}
+	tests := []struct {
+		name  string
+		do    func() error
+		err   error
+		usedA int64
+		usedB int64
+	}{
+		{name: "upload", do: upload(userA, "x.txt", 5), usedA: 5},
+		{name: "upload another", do: upload(userA, "y.txt", 4), usedA: 9},
+		{name: "overwrite by another user", do: upload(userB, "x.txt", 3), usedA: 4, usedB: 3},
+		{name: "exceeded", do: upload(userA, "big.txt", 17), err: errs.QuotaExceeded, usedA: 4, usedB: 3},
+		{name: "remove to recycle bin", do: func() error {
+			return op.Remove(ctx, storage, "/a/y.txt")
+		}, usedA: 4, usedB: 3},
+		{name: "purge recycle bin", do: func() error {
+			items, _, err := op.GetTrashItems(id, 1, 10)
+			if err != nil || len(items) != 1 {
+				return errors.Errorf("got %d trash items: %v", len(items), err)
+			}
+			return op.PurgeTrashItem(ctx, items[0].ID)
+		}, usedB: 3},
+		{name: "move into quota", do: func() error {
+			if _, err := op.UseQuotaOfTree(ctx, userA, storage, "/free/z.txt", "/a/z.txt", true); err != nil {
+				return err
+			}
+			return op.Move(ctx, storage, "/free/z.txt", "/a")
+		}, usedA: 6, usedB: 3},
+		{name: "copy dir", do: func() error {
+			if _, err := op.UseQuotaOfTree(ctx, userA, storage, "/dir", "/a/dir", false); err != nil {
+				return err
+			}
+			return op.Copy(ctx, storage, "/dir", "/a")
+		}, usedA: 8, usedB: 3},
+		{name: "remove renamed", do: func() error {
+			if err := op.Rename(ctx, storage, "/a/z.txt", "z2.txt"); err != nil {
+				return err
+			}
+			return op.RemovePermanently(ctx, storage, "/a/z2.txt")
+		}, usedA: 2, usedB: 3},
+		{name: "remove overwritten", do: func() error {
+			return op.RemovePermanently(ctx, storage, "/a/x.txt")
+		}, usedA: 2},
+		{name: "remove dir", do: func() error {
+			return op.RemovePermanently(ctx, storage, "/a/dir")
+		}},
+	}
+	for _, tt := range tests {
+		err := tt.do()
+		if !errors.Is(err, tt.err) {
+			t.Fatalf("%s: error = %+v, want %v", tt.name, err, tt.err)
+		}
+		for _, want := range []struct {
+			q    *model.Quota
+			used int64
+		}{{quotaA, tt.usedA}, {quotaB, tt.usedB}} {
+			q, err := db.GetQuotaById(want.q.ID)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if q.UsedBytes != want.used {
+				t.Errorf("%s: used bytes of quota %d = %d, want %d", tt.name, q.ID, q.UsedBytes, want.used)
+			}
+		}
+	}
+}
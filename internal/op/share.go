@@ -0,0 +1,94 @@
+package op
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"time"
+
+	"github.com/alist-org/alist/v3/internal/db"
+	"github.com/alist-org/alist/v3/internal/errs"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/pkg/utils"
+	"github.com/pkg/errors"
+)
+
+// newSecretId generates an id which can't be guessed, as the id is the only
+// secret of the shares and file requests without password
+func newSecretId() (string, error) {
+	b := make([]byte, 9)
+	if _, err := rand.Read(b); err != nil {
+		return "", errors.WithStack(err)
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+func CreateShare(s *model.Share) error {
+	id, err := newSecretId()
+	if err != nil {
+		return err
+	}
+	s.ID = id
+	s.Path = utils.FixAndCleanPath(s.Path)
+	s.Downloads = 0
+	s.Created = time.Now()
+	return db.CreateShare(s)
+}
+
+func GetShareById(id string) (*model.Share, error) {
+	return db.GetShareById(id)
+}
+
+func GetShareByIdAndUserId(id string, userId uint) (*model.Share, error) {
+	s, err := db.GetShareById(id)
+	if err != nil {
+		return nil, err
+	}
+	if s.UserID != userId {
+		return nil, errors.New("the share does not belong to the user")
+	}
+	return s, nil
+}
+
+func GetShares(pageIndex, pageSize int) (shares []model.Share, count int64, err error) {
+	return db.GetShares(pageIndex, pageSize)
+}
+
+func GetSharesByUserId(userId uint, pageIndex, pageSize int) (shares []model.Share, count int64, err error) {
+	return db.GetSharesByUserId(userId, pageIndex, pageSize)
+}
+
+// GetValidShare returns the share and its owner if the share can be accessed
+func GetValidShare(id string) (*model.Share, *model.User, error) {
+	s, err := db.GetShareById(id)
+	if err != nil {
+		return nil, nil, err
+	}
+	if s.IsExpired() {
+		return nil, nil, errs.ShareExpired
+	}
+	owner, err := GetUserById(s.UserID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if owner.Disabled || !owner.CanShare() {
+		return nil, nil, errs.PermissionDenied
+	}
+	return s, owner, nil
+}
+
+// ConsumeShareDownload counts a download of the share
+func ConsumeShareDownload(s *model.Share) error {
+	ok, err := db.IncreaseShareDownloads(s.ID)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return errs.ShareDownloadsExhausted
+	}
+	s.Downloads++
+	return nil
+}
+
+func DeleteShareById(id string) error {
+	return db.DeleteShareById(id)
+}
@@ -0,0 +1,62 @@
+package op
+
+import (
+	"strings"
+	"time"
+
+	"github.com/alist-org/alist/v3/internal/db"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/pkg/errors"
+	"golang.org/x/crypto/ssh"
+)
+
+func CreateSSHPublicKey(k *model.SSHPublicKey) error {
+	if err := parseSSHPublicKey(k); err != nil {
+		return err
+	}
+	_, err := db.GetSSHPublicKeyByUserIdAndFingerprint(k.UserId, k.Fingerprint)
+	if err == nil {
+		return errors.New("the key already exists")
+	}
+	k.AddedTime = time.Now()
+	k.LastUsedTime = k.AddedTime
+	return db.CreateSSHPublicKey(k)
+}
+
+// parseSSHPublicKey parses a key in the authorized_keys format,
+// fills the normalized key and fingerprint to k
+func parseSSHPublicKey(k *model.SSHPublicKey) error {
+	pubKey, comment, _, _, err := ssh.ParseAuthorizedKey([]byte(strings.TrimSpace(k.KeyStr)))
+	if err != nil {
+		return errors.WithMessage(err, "invalid ssh public key")
+	}
+	k.KeyStr = strings.TrimSpace(string(ssh.MarshalAuthorizedKey(pubKey)))
+	k.Fingerprint = ssh.FingerprintSHA256(pubKey)
+	if k.Title == "" {
+		k.Title = comment
+	}
+	return nil
+}
+
+func GetSSHPublicKeyByUserId(userId uint, pageIndex, pageSize int) (keys []model.SSHPublicKey, count int64, err error) {
+	return db.GetSSHPublicKeyByUserId(userId, pageIndex, pageSize)
+}
+
+func GetSSHPublicKeyByIdAndUserId(id uint, userId uint) (*model.SSHPublicKey, error) {
+	key, err := db.GetSSHPublicKeyById(id)
+	if err != nil {
+		return nil, err
+	}
+	if key.UserId != userId {
+		return nil, errors.New("the key does not belong to the user")
+	}
+	return key, nil
+}
+
+func UpdateSSHPublicKey(k *model.SSHPublicKey) error {
+	return db.UpdateSSHPublicKey(k)
+}
+
+func DeleteSSHPublicKeyById(keyId uint) error {
+	return db.DeleteSSHPublicKeyById(keyId)
+}
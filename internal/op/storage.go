@@ -196,6 +196,8 @@ func UpdateStorage(ctx context.Context, storage model.Storage) error {
 	if err != nil {
 		return errors.WithMessage(err, "failed get storage driver")
 	}
+	// the cache may be persistent, drop the listings of the old storage
+	ClearCache(storageDriver, "/")
 	err = storageDriver.Drop(ctx)
 	if err != nil {
 		return errors.Wrapf(err, "failed drop storage")
@@ -217,6 +219,7 @@ func DeleteStorageById(ctx context.Context, id uint) error {
 		if err != nil {
 			return errors.WithMessage(err, "failed get storage driver")
 		}
+		ClearCache(storageDriver, "/")
 		// drop the storage in the driver
 		if err := storageDriver.Drop(ctx); err != nil {
 			return errors.Wrapf(err, "failed drop storage")
@@ -225,6 +228,9 @@ func DeleteStorageById(ctx context.Context, id uint) error {
 		storagesMap.Delete(storage.MountPath)
 		go callStorageHooks("del", storageDriver)
 	}
+	if err := db.DeleteTrashItemsByStorageId(id); err != nil {
+		return errors.WithMessage(err, "failed delete trash items of storage")
+	}
 	// delete the storage in the database
 	if err := db.DeleteStorageById(id); err != nil {
 		return errors.WithMessage(err, "failed delete storage in database")
@@ -0,0 +1,205 @@
+package op
+
+import (
+	"context"
+	stdpath "path"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/alist-org/alist/v3/internal/conf"
+	"github.com/alist-org/alist/v3/internal/db"
+	"github.com/alist-org/alist/v3/internal/driver"
+	"github.com/alist-org/alist/v3/internal/errs"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/pkg/utils"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+)
+
+func isTrashEnabled() bool {
+	item, err := GetSettingItemByKey(conf.RecycleBinEnabled)
+	return err == nil && item.Value == "true"
+}
+
+// GetTrashDir returns the actual path of the recycle bin in each storage
+func GetTrashDir() string {
+	dir := ".alist_recycle_bin"
+	if item, err := GetSettingItemByKey(conf.RecycleBinDir); err == nil && strings.Trim(item.Value, "/ ") != "" {
+		dir = strings.Trim(item.Value, "/ ")
+	}
+	return "/" + dir
+}
+
+// IsInTrash reports whether the actual path is in the recycle bin
+func IsInTrash(path string) bool {
+	return utils.IsSubPath(GetTrashDir(), path)
+}
+
+func canTrash(storage driver.Driver) bool {
+	switch storage.(type) {
+	case driver.Move, driver.MoveResult, driver.Copy, driver.CopyResult:
+	default:
+		return false
+	}
+	switch storage.(type) {
+	case driver.Mkdir, driver.MkdirResult:
+		return true
+	}
+	return false
+}
+
+// moveOrCopy moves the object to dstDirPath, or copies then removes it
+// if the storage doesn't support move
+func moveOrCopy(ctx context.Context, storage driver.Driver, srcPath, dstDirPath string) error {
+	err := Move(ctx, storage, srcPath, dstDirPath)
+	if !errors.Is(err, errs.NotImplement) {
+		return err
+	}
+	if err = Copy(ctx, storage, srcPath, dstDirPath); err != nil {
+		return err
+	}
+	// the files are still stored, so the quotas charged by them aren't released
+	moveQuota(storage, srcPath, stdpath.Join(dstDirPath, stdpath.Base(srcPath)))
+	return RemovePermanently(ctx, storage, srcPath)
+}
+
+// moveToTrash moves the object to a dir of its own in the recycle bin, so that
+// the objects with the same name don't conflict
+func moveToTrash(ctx context.Context, storage driver.Driver, path string, obj model.Obj) error {
+	item := &model.TrashItem{
+		StorageID: storage.GetStorage().ID,
+		Path:      path,
+		Name:      obj.GetName(),
+		IsDir:     obj.IsDir(),
+		Size:      obj.GetSize(),
+		Deleted:   time.Now(),
+	}
+	if user, ok := ctx.Value("user").(*model.User); ok {
+		item.DeletedBy = user.Username
+	}
+	if err := db.CreateTrashItem(item); err != nil {
+		return err
+	}
+	item.TrashPath = stdpath.Join(GetTrashDir(), strconv.FormatUint(uint64(item.ID), 10))
+	err := MakeDir(ctx, storage, item.TrashPath)
+	if err == nil {
+		err = moveOrCopy(ctx, storage, path, item.TrashPath)
+		if err != nil {
+			_ = RemovePermanently(ctx, storage, item.TrashPath)
+		}
+	}
+	if err == nil {
+		err = db.UpdateTrashItem(item)
+	}
+	if err != nil {
+		if err := db.DeleteTrashItemById(item.ID); err != nil {
+			log.Errorf("failed delete trash item %d: %+v", item.ID, err)
+		}
+		return errors.WithMessage(err, "failed move to recycle bin")
+	}
+	return nil
+}
+
+// forgetTrash deletes the records of the objects removed from the recycle bin directly
+func forgetTrash(storage driver.Driver, path string) {
+	trashDir := GetTrashDir()
+	var err error
+	if utils.PathEqual(path, trashDir) {
+		err = db.DeleteTrashItemsByStorageId(storage.GetStorage().ID)
+	} else if rel := strings.TrimPrefix(path, trashDir+"/"); !strings.Contains(rel, "/") {
+		err = db.DeleteTrashItemsByTrashPath(storage.GetStorage().ID, path)
+	}
+	if err != nil {
+		log.Errorf("failed delete trash items of %s: %+v", path, err)
+	}
+}
+
+func getTrashItemStorage(item *model.TrashItem) (driver.Driver, error) {
+	s, err := db.GetStorageById(item.StorageID)
+	if err != nil {
+		return nil, errors.WithMessage(err, "failed get storage")
+	}
+	return GetStorageByMountPath(s.MountPath)
+}
+
+func GetTrashItems(storageId uint, pageIndex, pageSize int) (items []model.TrashItem, count int64, err error) {
+	return db.GetTrashItems(storageId, pageIndex, pageSize)
+}
+
+func GetTrashItemById(id uint) (*model.TrashItem, error) {
+	return db.GetTrashItemById(id)
+}
+
+// RestoreTrashItem moves the object back to its original path
+func RestoreTrashItem(ctx context.Context, id uint) error {
+	item, err := db.GetTrashItemById(id)
+	if err != nil {
+		return err
+	}
+	storage, err := getTrashItemStorage(item)
+	if err != nil {
+		return err
+	}
+	if _, err = Get(ctx, storage, item.Path); err == nil {
+		return errors.Errorf("%s already exists", item.Path)
+	} else if !errs.IsObjectNotFound(err) {
+		return err
+	}
+	dstDirPath := stdpath.Dir(item.Path)
+	if err = MakeDir(ctx, storage, dstDirPath); err != nil {
+		return errors.WithMessagef(err, "failed make dir [%s]", dstDirPath)
+	}
+	if err = moveOrCopy(ctx, storage, item.ObjPath(), dstDirPath); err != nil {
+		return errors.WithMessage(err, "failed restore")
+	}
+	if err = RemovePermanently(ctx, storage, item.TrashPath); err != nil {
+		log.Warnf("failed remove the trash dir %s: %+v", item.TrashPath, err)
+	}
+	return db.DeleteTrashItemById(item.ID)
+}
+
+// PurgeTrashItem removes the object in recycle bin permanently
+func PurgeTrashItem(ctx context.Context, id uint) error {
+	item, err := db.GetTrashItemById(id)
+	if err != nil {
+		return err
+	}
+	return purgeTrashItem(ctx, item)
+}
+
+func purgeTrashItem(ctx context.Context, item *model.TrashItem) error {
+	// the records of the deleted storages are dropped
+	if s, err := db.GetStorageById(item.StorageID); err == nil && item.TrashPath != "" {
+		storage, err := GetStorageByMountPath(s.MountPath)
+		if err != nil {
+			return err
+		}
+		if err = RemovePermanently(ctx, storage, item.TrashPath); err != nil {
+			return err
+		}
+	}
+	return db.DeleteTrashItemById(item.ID)
+}
+
+// CleanExpiredTrash purges the objects removed before the retention days
+func CleanExpiredTrash(ctx context.Context) {
+	item, err := GetSettingItemByKey(conf.RecycleBinRetention)
+	if err != nil {
+		return
+	}
+	days, err := strconv.Atoi(item.Value)
+	if err != nil || days <= 0 {
+		return
+	}
+	items, err := db.GetTrashItemsDeletedBefore(time.Now().AddDate(0, 0, -days))
+	if err != nil {
+		log.Errorf("failed get expired trash items: %+v", err)
+		return
+	}
+	for i := range items {
+		if err = purgeTrashItem(ctx, &items[i]); err != nil {
+			log.Errorf("failed purge trash item %d: %+v", items[i].ID, err)
+		}
+	}
+}
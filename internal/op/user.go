@@ -22,6 +22,9 @@ func GetAdmin() (*model.User, error) {
 		if err != nil {
 			return nil, err
 		}
+		if err = loadUserGroups(user); err != nil {
+			return nil, err
+		}
 		adminUser = user
 	}
 	return adminUser, nil
@@ -33,6 +36,9 @@ func GetGuest() (*model.User, error) {
 		if err != nil {
 			return nil, err
 		}
+		if err = loadUserGroups(user); err != nil {
+			return nil, err
+		}
 		guestUser = user
 	}
 	return guestUser, nil
@@ -54,6 +60,9 @@ func GetUserByName(username string) (*model.User, error) {
 		if err != nil {
 			return nil, err
 		}
+		if err = loadUserGroups(_user); err != nil {
+			return nil, err
+		}
 		userCache.Set(username, _user, cache.WithEx[*model.User](time.Hour))
 		return _user, nil
 	})
@@ -61,7 +70,14 @@ func GetUserByName(username string) (*model.User, error) {
 }
 
 func GetUserById(id uint) (*model.User, error) {
-	return db.GetUserById(id)
+	user, err := db.GetUserById(id)
+	if err != nil {
+		return nil, err
+	}
+	if err = loadUserGroups(user); err != nil {
+		return nil, err
+	}
+	return user, nil
 }
 
 func GetUsers(pageIndex, pageSize int) (users []model.User, count int64, err error) {
@@ -82,6 +98,21 @@ func DeleteUserById(id uint) error {
 		return errs.DeleteAdminOrGuest
 	}
 	userCache.Del(old.Username)
+	if err = db.DeleteSSHPublicKeysByUserId(id); err != nil {
+		return err
+	}
+	if err = DeleteACLsBySubject(model.ACLSubjectUser, id); err != nil {
+		return err
+	}
+	if err = db.DeleteUserGroupsByUserId(id); err != nil {
+		return err
+	}
+	if err = db.DeleteSharesByUserId(id); err != nil {
+		return err
+	}
+	if err = db.DeleteFileRequestsByUserId(id); err != nil {
+		return err
+	}
 	return db.DeleteUserById(id)
 }
 
@@ -0,0 +1,184 @@
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"golang.org/x/time/rate"
+)
+
+type ctxKey struct{}
+
+// WithLimiters returns a copy of ctx with the limiters added, the nil ones are ignored
+func WithLimiters(ctx context.Context, ls ...*rate.Limiter) context.Context {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	all := append([]*rate.Limiter{}, FromContext(ctx)...)
+	for _, l := range ls {
+		if l != nil {
+			all = append(all, l)
+		}
+	}
+	return context.WithValue(ctx, ctxKey{}, all)
+}
+
+func FromContext(ctx context.Context) []*rate.Limiter {
+	if ctx == nil {
+		return nil
+	}
+	ls, _ := ctx.Value(ctxKey{}).([]*rate.Limiter)
+	return ls
+}
+
+// WaitN blocks until n bytes are allowed by all limiters in ctx
+func WaitN(ctx context.Context, n int) error {
+	ls := FromContext(ctx)
+	if len(ls) == 0 || n <= 0 {
+		return nil
+	}
+	for n > 0 {
+		c := min(n, chunkSize)
+		if err := waitAll(ctx, ls, c); err != nil {
+			return err
+		}
+		n -= c
+	}
+	return nil
+}
+
+// waitAll reserves n tokens on all limiters and waits for the longest delay,
+// so the limiters are waited at the same time rather than in turn
+func waitAll(ctx context.Context, ls []*rate.Limiter, n int) error {
+	now := time.Now()
+	var (
+		rs    []*rate.Reservation
+		delay time.Duration
+	)
+	// the tokens are fully restored only if the later reservations are canceled first
+	cancel := func() {
+		for i := len(rs) - 1; i >= 0; i-- {
+			rs[i].CancelAt(now)
+		}
+	}
+	for _, l := range ls {
+		if l.Limit() == rate.Inf {
+			continue
+		}
+		// the burst may be changed to be smaller than n, so it's reserved in steps of burst
+		for m := n; m > 0; {
+			c := min(m, l.Burst())
+			var r *rate.Reservation
+			if c > 0 {
+				r = l.ReserveN(now, c)
+			}
+			if r == nil || !r.OK() {
+				cancel()
+				return fmt.Errorf("rate: wait(n=%d) exceeds the burst %d of limiter", n, l.Burst())
+			}
+			rs = append(rs, r)
+			delay = max(delay, r.DelayFrom(now))
+			m -= c
+		}
+	}
+	if delay == 0 {
+		return nil
+	}
+	if deadline, ok := ctx.Deadline(); ok && now.Add(delay).After(deadline) {
+		cancel()
+		return fmt.Errorf("rate: wait(n=%d) would exceed context deadline", n)
+	}
+	t := time.NewTimer(delay)
+	defer t.Stop()
+	select {
+	case <-t.C:
+		return nil
+	case <-ctx.Done():
+		cancel()
+		return ctx.Err()
+	}
+}
+
+type reader struct {
+	ctx context.Context
+	io.Reader
+}
+
+func (r *reader) Read(p []byte) (int, error) {
+	n, err := r.Reader.Read(p[:min(len(p), chunkSize)])
+	if werr := WaitN(r.ctx, n); werr != nil && err == nil {
+		err = werr
+	}
+	return n, err
+}
+
+// NewReader limits the reading of r by the limiters in ctx
+func NewReader(ctx context.Context, r io.Reader) io.Reader {
+	if len(FromContext(ctx)) == 0 {
+		return r
+	}
+	return &reader{ctx: ctx, Reader: r}
+}
+
+type readCloser struct {
+	io.Reader
+	io.Closer
+}
+
+// NewReadCloser limits the reading of rc by the limiters in ctx
+func NewReadCloser(ctx context.Context, rc io.ReadCloser) io.ReadCloser {
+	if len(FromContext(ctx)) == 0 {
+		return rc
+	}
+	return readCloser{Reader: NewReader(ctx, rc), Closer: rc}
+}
+
+type writer struct {
+	ctx context.Context
+	io.Writer
+}
+
+func (w *writer) Write(p []byte) (int, error) {
+	var written int
+	for len(p) > 0 {
+		c := min(len(p), chunkSize)
+		if err := WaitN(w.ctx, c); err != nil {
+			return written, err
+		}
+		n, err := w.Writer.Write(p[:c])
+		written += n
+		if err != nil {
+			return written, err
+		}
+		p = p[c:]
+	}
+	return written, nil
+}
+
+// NewWriter limits the writing to w by the limiters in ctx
+func NewWriter(ctx context.Context, w io.Writer) io.Writer {
+	if len(FromContext(ctx)) == 0 {
+		return w
+	}
+	return &writer{ctx: ctx, Writer: w}
+}
+
+type responseWriter struct {
+	http.ResponseWriter
+	w io.Writer
+}
+
+func (w *responseWriter) Write(p []byte) (int, error) {
+	return w.w.Write(p)
+}
+
+// NewResponseWriter limits the writing of body to w by the limiters in ctx
+func NewResponseWriter(ctx context.Context, w http.ResponseWriter) http.ResponseWriter {
+	if len(FromContext(ctx)) == 0 {
+		return w
+	}
+	return &responseWriter{ResponseWriter: w, w: &writer{ctx: ctx, Writer: w}}
+}
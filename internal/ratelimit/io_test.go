@@ -0,0 +1,106 @@
+package ratelimit
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"testing"
+	"time"
+
+	"golang.org/x/time/rate"
+)
+
+func TestWaitN(t *testing.T) {
+	tests := []struct {
+		name  string
+		ls    func() []*rate.Limiter
+		n     int
+		delay time.Duration
+	}{
+		{
+			name: "no limit",
+			ls: func() []*rate.Limiter {
+				return []*rate.Limiter{newLimiter()}
+			},
+			n: 1 << 20,
+		},
+		{
+			name: "within burst",
+			ls: func() []*rate.Limiter {
+				return []*rate.Limiter{rate.NewLimiter(1<<20, 1<<20)}
+			},
+			n: 1 << 20,
+		},
+		{
+			// the remaining 1MB takes 1s at 1MB/s
+			name: "exceed burst",
+			ls: func() []*rate.Limiter {
+				return []*rate.Limiter{rate.NewLimiter(1<<20, 1<<20)}
+			},
+			n:     2 << 20,
+			delay: time.Second,
+		},
+		{
+			// the burst is smaller than a chunk, all bytes are waited
+			name: "small burst",
+			ls: func() []*rate.Limiter {
+				return []*rate.Limiter{rate.NewLimiter(64<<10, 1<<10)}
+			},
+			n:     33 << 10,
+			delay: 500 * time.Millisecond,
+		},
+		{
+			// the limiters are waited at the same time, so the delay is the max rather than the sum
+			name: "multiple limiters",
+			ls: func() []*rate.Limiter {
+				return []*rate.Limiter{
+					rate.NewLimiter(1<<20, 512<<10),
+					rate.NewLimiter(2<<20, 512<<10),
+					newLimiter(),
+				}
+			},
+			n:     1 << 20,
+			delay: 500 * time.Millisecond,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := WithLimiters(context.Background(), tt.ls()...)
+			start := time.Now()
+			if err := WaitN(ctx, tt.n); err != nil {
+				t.Fatalf("WaitN() error = %v", err)
+			}
+			// the limiters may refill a bit before reserved
+			elapsed := time.Since(start)
+			if elapsed < tt.delay*9/10 || elapsed > tt.delay*12/10+50*time.Millisecond {
+				t.Errorf("WaitN() waited %v, want %v", elapsed, tt.delay)
+			}
+		})
+	}
+}
+
+func TestWaitNCanceled(t *testing.T) {
+	l := rate.NewLimiter(1<<10, 1<<10)
+	ctx, cancel := context.WithTimeout(WithLimiters(context.Background(), l), 100*time.Millisecond)
+	defer cancel()
+	if err := WaitN(ctx, 64<<10); err == nil {
+		t.Errorf("WaitN() beyond the deadline succeeded")
+	}
+	// the tokens reserved by the failed wait are returned
+	if tokens := l.Tokens(); tokens < 1<<10-1 {
+		t.Errorf("the limiter has %v tokens after the failed wait, want %d", tokens, 1<<10)
+	}
+}
+
+func TestReader(t *testing.T) {
+	data := bytes.Repeat([]byte("0123456789"), 10<<10)
+	ctx := WithLimiters(context.Background(), rate.NewLimiter(rate.Limit(len(data)), 1<<10))
+	start := time.Now()
+	got, err := io.ReadAll(NewReader(ctx, bytes.NewReader(data)))
+	if err != nil || !bytes.Equal(got, data) {
+		t.Fatalf("read %d bytes, %v, want %d bytes", len(got), err, len(data))
+	}
+	if elapsed := time.Since(start); elapsed < 900*time.Millisecond {
+		t.Errorf("read %d bytes at %d B/s in %v", len(data), len(data), elapsed)
+	}
+}
@@ -0,0 +1,92 @@
+package ratelimit
+
+import (
+	"sync"
+
+	"github.com/alist-org/alist/v3/internal/model"
+	"golang.org/x/time/rate"
+)
+
+// the max bytes waited at once, larger reads and writes are split
+const chunkSize = 32 * 1024
+
+var (
+	globalDownload = newLimiter()
+	globalUpload   = newLimiter()
+	userDownload   sync.Map // map[uint]*rate.Limiter
+	userUpload     sync.Map
+	storageDown    sync.Map
+	storageUp      sync.Map
+)
+
+func newLimiter() *rate.Limiter {
+	return rate.NewLimiter(rate.Inf, chunkSize)
+}
+
+// setLimit sets the limit of l to kbps KB/s, no limit if kbps <= 0
+func setLimit(l *rate.Limiter, kbps int64) {
+	if kbps <= 0 {
+		if l.Limit() != rate.Inf {
+			l.SetLimit(rate.Inf)
+		}
+		return
+	}
+	bps := kbps * 1024
+	if l.Limit() != rate.Limit(bps) {
+		l.SetLimit(rate.Limit(bps))
+		l.SetBurst(int(max(bps, chunkSize)))
+	}
+}
+
+func SetGlobalDownload(kbps int64) {
+	setLimit(globalDownload, kbps)
+}
+
+func SetGlobalUpload(kbps int64) {
+	setLimit(globalUpload, kbps)
+}
+
+// get returns the limiter of id shared by all requests, its limit is updated to kbps
+func get(m *sync.Map, id uint, kbps int64) *rate.Limiter {
+	v, ok := m.Load(id)
+	if !ok {
+		if kbps <= 0 {
+			return nil
+		}
+		v, _ = m.LoadOrStore(id, newLimiter())
+	}
+	l := v.(*rate.Limiter)
+	setLimit(l, kbps)
+	return l
+}
+
+// Download returns the limiters of downloading by the user from the storage,
+// both of them can be nil
+func Download(user *model.User, storage *model.Storage) []*rate.Limiter {
+	ls := []*rate.Limiter{globalDownload}
+	if user != nil {
+		ls = append(ls, get(&userDownload, user.ID, user.DownloadLimit))
+	}
+	if storage != nil {
+		ls = append(ls, get(&storageDown, storage.ID, storage.DownloadLimit))
+	}
+	return ls
+}
+
+// Upload returns the limiters of uploading by the user to the storage,
+// both of them can be nil
+func Upload(user *model.User, storage *model.Storage) []*rate.Limiter {
+	ls := []*rate.Limiter{globalUpload}
+	if user != nil {
+		ls = append(ls, get(&userUpload, user.ID, user.UploadLimit))
+	}
+	if storage != nil {
+		ls = append(ls, get(&storageUp, storage.ID, storage.UploadLimit))
+	}
+	return ls
+}
+
+// Transfer returns the limiters of transferring from the src storage to the dst one
+func Transfer(src, dst *model.Storage) []*rate.Limiter {
+	return append(Download(nil, src), Upload(nil, dst)...)
+}
@@ -4,11 +4,14 @@ import (
 	"github.com/alist-org/alist/v3/internal/conf"
 	"github.com/alist-org/alist/v3/internal/search/searcher"
 	"github.com/blevesearch/bleve/v2"
+	"github.com/blevesearch/bleve/v2/analysis/analyzer/keyword"
+	"github.com/blevesearch/bleve/v2/mapping"
 	log "github.com/sirupsen/logrus"
 )
 
 var config = searcher.Config{
-	Name: "bleve",
+	Name:    "bleve",
+	Content: true,
 }
 
 func Init(indexPath *string) (bleve.Index, error) {
@@ -26,6 +29,11 @@ func Init(indexPath *string) (bleve.Index, error) {
 		nameFieldMapping := bleve.NewKeywordFieldMapping()
 		searchNodeMapping.AddFieldMappingsAt("name", nameFieldMapping)
 		indexMapping.AddDocumentMapping("SearchNode", searchNodeMapping)
+		// the nodes are indexed by value and mapped by the default mapping,
+		// the term fields should not be analyzed
+		for _, field := range []string{"parent", "storage", "hash"} {
+			indexMapping.DefaultMapping.AddFieldMappingsAt(field, bleve.NewKeywordFieldMapping())
+		}
 		fileIndex, err = bleve.New(*indexPath, indexMapping)
 		if err != nil {
 			return nil, err
@@ -42,6 +50,24 @@ func init() {
 		if err != nil {
 			return nil, err
 		}
-		return &Bleve{BIndex: b}, nil
+		return &Bleve{BIndex: b, parentIndexed: isParentIndexed(b)}, nil
 	})
 }
+
+// isParentIndexed reports whether the parent is indexed as keyword,
+// the parent of the indexes created by the old versions is text
+func isParentIndexed(index bleve.Index) bool {
+	return isKeyword(index, "parent")
+}
+
+func isKeyword(index bleve.Index, field string) bool {
+	m, ok := index.Mapping().(*mapping.IndexMappingImpl)
+	if !ok {
+		return false
+	}
+	property, ok := m.DefaultMapping.Properties[field]
+	if !ok || len(property.Fields) == 0 {
+		return false
+	}
+	return property.Fields[0].Analyzer == keyword.Name
+}
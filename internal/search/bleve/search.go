@@ -3,6 +3,8 @@ package bleve
 import (
 	"context"
 	"os"
+	"strings"
+	"time"
 
 	query2 "github.com/blevesearch/bleve/v2/search/query"
 
@@ -13,12 +15,15 @@ import (
 	"github.com/alist-org/alist/v3/pkg/utils"
 	"github.com/blevesearch/bleve/v2"
 	search2 "github.com/blevesearch/bleve/v2/search"
+	"github.com/blevesearch/bleve/v2/search/highlight/highlighter/html"
 	"github.com/google/uuid"
 	log "github.com/sirupsen/logrus"
 )
 
 type Bleve struct {
 	BIndex bleve.Index
+	// the old indexes can't be searched by parent until rebuilt
+	parentIndexed bool
 }
 
 func (b *Bleve) Config() searcher.Config {
@@ -27,36 +32,133 @@ func (b *Bleve) Config() searcher.Config {
 
 func (b *Bleve) Search(ctx context.Context, req model.SearchReq) ([]model.SearchNode, int64, error) {
 	var queries []query2.Query
-	query := bleve.NewMatchQuery(req.Keywords)
-	query.SetField("name")
-	queries = append(queries, query)
+	if req.Keywords != "" {
+		query := bleve.NewMatchQuery(req.Keywords)
+		query.SetField("name")
+		if req.Content {
+			contentQuery := bleve.NewMatchQuery(req.Keywords)
+			contentQuery.SetField("content")
+			queries = append(queries, bleve.NewDisjunctionQuery(query, contentQuery))
+		} else {
+			queries = append(queries, query)
+		}
+	} else {
+		queries = append(queries, bleve.NewMatchAllQuery())
+	}
 	if req.Scope != 0 {
 		isDir := req.Scope == 1
 		isDirQuery := bleve.NewBoolFieldQuery(isDir)
+		isDirQuery.SetField("is_dir")
 		queries = append(queries, isDirQuery)
 	}
+	if b.parentIndexed && req.Parent != "" && req.Parent != "/" {
+		parentQuery := bleve.NewTermQuery(req.Parent)
+		parentQuery.SetField("parent")
+		subQuery := bleve.NewPrefixQuery(req.Parent + "/")
+		subQuery.SetField("parent")
+		queries = append(queries, bleve.NewDisjunctionQuery(parentQuery, subQuery))
+	}
+	if len(req.Types) != 0 {
+		inclusive := true
+		var typeQueries []query2.Query
+		for _, t := range req.Types {
+			v := float64(t)
+			typeQuery := bleve.NewNumericRangeInclusiveQuery(&v, &v, &inclusive, &inclusive)
+			typeQuery.SetField("file_type")
+			typeQueries = append(typeQueries, typeQuery)
+		}
+		queries = append(queries, bleve.NewDisjunctionQuery(typeQueries...))
+	}
+	for field, term := range map[string]string{"storage": req.Storage, "hash": strings.ToLower(req.Hash)} {
+		if term != "" {
+			termQuery := bleve.NewTermQuery(term)
+			termQuery.SetField(field)
+			queries = append(queries, termQuery)
+		}
+	}
+	if req.MinSize != 0 || req.MaxSize != 0 {
+		var min, max *float64
+		if req.MinSize != 0 {
+			v := float64(req.MinSize)
+			min = &v
+		}
+		if req.MaxSize != 0 {
+			v := float64(req.MaxSize)
+			max = &v
+		}
+		inclusive := true
+		sizeQuery := bleve.NewNumericRangeInclusiveQuery(min, max, &inclusive, &inclusive)
+		sizeQuery.SetField("size")
+		queries = append(queries, sizeQuery)
+	}
+	if req.ModifiedAfter != nil || req.ModifiedBefore != nil {
+		// the zero time means unbounded
+		var start, end time.Time
+		if req.ModifiedAfter != nil {
+			start = *req.ModifiedAfter
+		}
+		if req.ModifiedBefore != nil {
+			end = *req.ModifiedBefore
+		}
+		modifiedQuery := bleve.NewDateRangeQuery(start, end)
+		modifiedQuery.SetField("modified")
+		queries = append(queries, modifiedQuery)
+	}
 	reqQuery := bleve.NewConjunctionQuery(queries...)
 	search := bleve.NewSearchRequest(reqQuery)
-	search.SortBy([]string{"name"})
+	orderBy, desc := req.Order()
+	if desc {
+		orderBy = "-" + orderBy
+	}
+	search.SortBy([]string{orderBy})
 	search.From = (req.Page - 1) * req.PerPage
 	search.Size = req.PerPage
-	search.Fields = []string{"*"}
+	// the content is too large to be returned
+	search.Fields = []string{"parent", "name", "is_dir", "size", "modified", "file_type", "storage", "hash"}
+	if req.Content && req.Keywords != "" {
+		search.Highlight = bleve.NewHighlightWithStyle(html.Name)
+		search.Highlight.AddField("content")
+	}
 	searchResults, err := b.BIndex.Search(search)
 	if err != nil {
 		log.Errorf("search error: %+v", err)
 		return nil, 0, err
 	}
 	res, err := utils.SliceConvert(searchResults.Hits, func(src *search2.DocumentMatch) (model.SearchNode, error) {
-		return model.SearchNode{
+		// the fields are missing in the indexes created by the old versions
+		node := model.SearchNode{
 			Parent: src.Fields["parent"].(string),
 			Name:   src.Fields["name"].(string),
 			IsDir:  src.Fields["is_dir"].(bool),
 			Size:   int64(src.Fields["size"].(float64)),
-		}, nil
+		}
+		if modified, ok := src.Fields["modified"].(string); ok {
+			node.Modified, _ = time.Parse(time.RFC3339, modified)
+		}
+		if fileType, ok := src.Fields["file_type"].(float64); ok {
+			node.FileType = int(fileType)
+		}
+		node.Storage, _ = src.Fields["storage"].(string)
+		node.Hash, _ = src.Fields["hash"].(string)
+		if fragments := src.Fragments["content"]; len(fragments) != 0 {
+			node.Snippet = fragments[0]
+		}
+		return node, nil
 	})
 	return res, int64(searchResults.Total), nil
 }
 
+// Outdated reports whether the term fields are missing in the mapping,
+// the documents of the old versions lack the fields of filters as well
+func (b *Bleve) Outdated(ctx context.Context) (bool, error) {
+	for _, field := range []string{"parent", "storage", "hash"} {
+		if !isKeyword(b.BIndex, field) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
 func (b *Bleve) Index(ctx context.Context, node model.SearchNode) error {
 	return b.BIndex.Index(uuid.NewString(), node)
 }
@@ -99,7 +201,9 @@ func (b *Bleve) Clear(ctx context.Context) error {
 		return err
 	}
 	b.BIndex = bIndex
+	b.parentIndexed = isParentIndexed(bIndex)
 	return nil
 }
 
 var _ searcher.Searcher = (*Bleve)(nil)
+var _ searcher.Outdated = (*Bleve)(nil)
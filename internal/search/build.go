@@ -10,6 +10,7 @@ import (
 	"time"
 
 	"github.com/alist-org/alist/v3/internal/conf"
+	"github.com/alist-org/alist/v3/internal/db"
 	"github.com/alist-org/alist/v3/internal/errs"
 	"github.com/alist-org/alist/v3/internal/fs"
 	"github.com/alist-org/alist/v3/internal/model"
@@ -183,10 +184,17 @@ func BuildIndex(ctx context.Context, indexPaths, ignorePaths []string, maxDepth
 }
 
 func Del(ctx context.Context, prefix string) error {
+	if err := db.DeleteIndexedDirs(prefix); err != nil {
+		log.Errorf("failed delete indexed dirs of %s: %+v", prefix, err)
+	}
 	return instance.Del(ctx, prefix)
 }
 
 func Clear(ctx context.Context) error {
+	contentMQ.Clear()
+	if err := db.ClearIndexedDirs(); err != nil {
+		log.Errorf("failed clear indexed dirs: %+v", err)
+	}
 	return instance.Clear(ctx)
 }
 
@@ -194,23 +202,25 @@ func Config(ctx context.Context) searcher.Config {
 	return instance.Config()
 }
 
-func Update(parent string, objs []model.Obj) {
+// canAutoUpdate reports whether the index can be updated without rebuilding
+func canAutoUpdate() bool {
 	if instance == nil || !instance.Config().AutoUpdate || !setting.GetBool(conf.AutoUpdateIndex) || Running() {
-		return
+		return false
 	}
-	if isIgnorePath(parent) {
-		return
-	}
-	ctx := context.Background()
 	// only update when index have built
 	progress, err := Progress()
 	if err != nil {
 		log.Errorf("update search index error while get progress: %+v", err)
-		return
+		return false
 	}
-	if !progress.IsDone {
+	return progress.IsDone
+}
+
+func Update(parent string, objs []model.Obj) {
+	if isIgnorePath(parent) || !canAutoUpdate() {
 		return
 	}
+	ctx := context.Background()
 	nodes, err := instance.Get(ctx, parent)
 	if err != nil {
 		log.Errorf("update search index error while get nodes: %+v", err)
@@ -261,7 +271,3 @@ func Update(parent string, objs []model.Obj) {
 		}
 	}
 }
-
-func init() {
-	op.RegisterObjsUpdateHook(Update)
-}
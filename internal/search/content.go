@@ -0,0 +1,142 @@
+package search
+
+import (
+	"context"
+	stderrors "errors"
+	"io"
+	"path"
+	"sync"
+
+	"github.com/alist-org/alist/v3/internal/conf"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/internal/op"
+	"github.com/alist-org/alist/v3/internal/search/extract"
+	"github.com/alist-org/alist/v3/internal/setting"
+	"github.com/alist-org/alist/v3/internal/stream"
+	"github.com/alist-org/alist/v3/pkg/http_range"
+	"github.com/alist-org/alist/v3/pkg/mq"
+	"github.com/alist-org/alist/v3/pkg/utils"
+	log "github.com/sirupsen/logrus"
+)
+
+// shouldIndexContent reports whether the content of obj should be indexed
+func shouldIndexContent(obj model.Obj) bool {
+	if instance == nil || !instance.Config().Content || !setting.GetBool(conf.IndexContent) {
+		return false
+	}
+	if obj.IsDir() || obj.GetSize() == 0 || !extract.Supported(obj.GetName()) {
+		return false
+	}
+	// the other files can't be parsed if truncated
+	return extract.IsText(obj.GetName()) || obj.GetSize() <= contentMaxSize()
+}
+
+func contentMaxSize() int64 {
+	return int64(setting.GetInt(conf.IndexContentMaxSize, 10)) << 20
+}
+
+var (
+	contentMQ     = mq.NewInMemoryMQ[ObjWithParent]()
+	contentSignal = make(chan struct{}, 1)
+	contentOnce   sync.Once
+)
+
+// enqueueContent indexes the file with its content in background, since the
+// extraction reads the file from the storage, which may take a long time
+func enqueueContent(obj ObjWithParent) {
+	contentOnce.Do(func() {
+		go indexContents()
+	})
+	contentMQ.Publish(mq.Message[ObjWithParent]{Content: obj})
+	select {
+	case contentSignal <- struct{}{}:
+	default:
+	}
+}
+
+func indexContents() {
+	ctx := context.Background()
+	for range contentSignal {
+		var objs []ObjWithParent
+		contentMQ.ConsumeAll(func(messages []mq.Message[ObjWithParent]) {
+			objs = utils.MustSliceConvert(messages, func(src mq.Message[ObjWithParent]) ObjWithParent {
+				return src.Content
+			})
+		})
+		for _, obj := range objs {
+			// the file may be changed or removed while waiting
+			if !isCurrent(ctx, obj) {
+				continue
+			}
+			node := toSearchNode(obj.Parent, obj.Obj)
+			node.Content = extractContent(ctx, obj.Parent, obj.Obj)
+			s := instance
+			if s == nil {
+				break
+			}
+			if err := s.Index(ctx, node); err != nil {
+				log.Errorf("failed index content of %s: %+v", path.Join(obj.Parent, obj.GetName()), err)
+			}
+		}
+	}
+}
+
+// isCurrent reports whether the file is the same as the one in storage
+func isCurrent(ctx context.Context, obj ObjWithParent) bool {
+	storage, actualPath, err := op.GetStorageAndActualPath(path.Join(obj.Parent, obj.GetName()))
+	if err != nil {
+		return false
+	}
+	cur, err := op.Get(ctx, storage, actualPath)
+	return err == nil && cur.GetSize() == obj.GetSize() && cur.ModTime().Equal(obj.ModTime())
+}
+
+// extractContent returns the text of obj, or empty if failed
+func extractContent(ctx context.Context, parent string, obj model.Obj) string {
+	p := path.Join(parent, obj.GetName())
+	data, err := readContent(ctx, p, obj, contentMaxSize())
+	if err != nil {
+		log.Warnf("failed read content of %s: %+v", p, err)
+		return ""
+	}
+	text, err := extract.Text(obj.GetName(), data)
+	if err != nil {
+		log.Warnf("failed extract content of %s: %+v", p, err)
+		return ""
+	}
+	return text
+}
+
+// readContent reads at most limit bytes from the beginning of obj with path
+func readContent(ctx context.Context, p string, obj model.Obj, limit int64) ([]byte, error) {
+	storage, actualPath, err := op.GetStorageAndActualPath(p)
+	if err != nil {
+		return nil, err
+	}
+	link, _, err := op.Link(ctx, storage, actualPath, model.LinkArgs{})
+	if err != nil {
+		return nil, err
+	}
+	length := obj.GetSize()
+	if length > limit {
+		length = limit
+	}
+	if link.MFile != nil {
+		defer link.MFile.Close()
+		return io.ReadAll(io.LimitReader(link.MFile, length))
+	}
+	rrc := link.RangeReadCloser
+	if rrc == nil {
+		rrc, err = stream.GetRangeReadCloserFromLink(obj.GetSize(), link)
+		if err != nil {
+			return nil, err
+		}
+	}
+	rc, err := rrc.RangeRead(ctx, http_range.Range{Start: 0, Length: length})
+	if err != nil {
+		_ = rrc.Close()
+		return nil, err
+	}
+	data, err := io.ReadAll(io.LimitReader(rc, length))
+	return data, stderrors.Join(err, rc.Close(), rrc.Close())
+}
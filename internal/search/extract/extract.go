@@ -0,0 +1,78 @@
+package extract
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/alist-org/alist/v3/internal/conf"
+	"github.com/alist-org/alist/v3/pkg/utils"
+)
+
+// MaxTextLength is the max length of text extracted from a file
+const MaxTextLength = 1 << 20
+
+type extractor func(data []byte) (string, error)
+
+var extractors = map[string]extractor{
+	"pdf":  pdfText,
+	"docx": officeText("word/document.xml", "word/header", "word/footer"),
+	"xlsx": officeText("xl/sharedStrings.xml"),
+	"pptx": officeText("ppt/slides/slide"),
+	"odt":  officeText("content.xml"),
+	"ods":  officeText("content.xml"),
+	"odp":  officeText("content.xml"),
+}
+
+// IsText reports whether the file is a text file, which can be extracted from its beginning
+func IsText(name string) bool {
+	return utils.SliceContains(conf.SlicesMap[conf.TextTypes], utils.Ext(name))
+}
+
+// Supported reports whether the text of the file can be extracted
+func Supported(name string) bool {
+	if IsText(name) {
+		return true
+	}
+	_, ok := extractors[utils.Ext(name)]
+	return ok
+}
+
+// Text extracts the text of the file with name from its data, the data of
+// text files may be truncated, but the others must be complete
+func Text(name string, data []byte) (text string, err error) {
+	if IsText(name) {
+		text = string(data)
+	} else if e, ok := extractors[utils.Ext(name)]; ok {
+		// the parsers may panic on malformed files
+		defer func() {
+			if r := recover(); r != nil {
+				err = fmt.Errorf("failed extract text of %s: %v", name, r)
+			}
+		}()
+		text, err = e(data)
+		if err != nil {
+			return "", err
+		}
+	} else {
+		return "", fmt.Errorf("not support extract text of %s", name)
+	}
+	return clean(text), nil
+}
+
+// clean removes the invalid and control characters, and truncates the text to MaxTextLength
+func clean(text string) string {
+	if len(text) > MaxTextLength {
+		text = text[:MaxTextLength]
+	}
+	var buf bytes.Buffer
+	buf.Grow(len(text))
+	for _, r := range strings.ToValidUTF8(text, "") {
+		if r == utf8.RuneError || r < ' ' && r != '\n' && r != '\t' && r != '\r' {
+			continue
+		}
+		buf.WriteRune(r)
+	}
+	return strings.TrimSpace(buf.String())
+}
@@ -0,0 +1,42 @@
+package extract
+
+import (
+	"archive/zip"
+	"bytes"
+	"testing"
+
+	"github.com/alist-org/alist/v3/internal/conf"
+)
+
+func TestText(t *testing.T) {
+	conf.SlicesMap[conf.TextTypes] = []string{"txt"}
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	w, err := zw.Create("word/document.xml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, _ = w.Write([]byte(`<w:document xmlns:w="w"><w:body><w:p><w:r><w:t>Hello</w:t></w:r><w:r><w:t xml:space="preserve"> world</w:t></w:r></w:p><w:p><w:r><w:t>Bye</w:t></w:r></w:p></w:body></w:document>`))
+	if err = zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name string
+		data []byte
+		want string
+	}{
+		{"a.txt", []byte("  hello\x00 world\xff\n"), "hello world"},
+		{"a.docx", buf.Bytes(), "Hello world\nBye"},
+	}
+	for _, tt := range tests {
+		got, err := Text(tt.name, tt.data)
+		if err != nil {
+			t.Errorf("Text(%s) error: %v", tt.name, err)
+		} else if got != tt.want {
+			t.Errorf("Text(%s) = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+	if _, err = Text("a.pdf", []byte("not a pdf")); err == nil {
+		t.Errorf("Text(a.pdf) should fail on malformed file")
+	}
+}
@@ -0,0 +1,81 @@
+package extract
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"io"
+	"sort"
+	"strings"
+)
+
+// officeText extracts the text of the entries with the prefixes in the zip
+// based office documents, such as docx, xlsx, pptx and the OpenDocument formats
+func officeText(prefixes ...string) extractor {
+	return func(data []byte) (string, error) {
+		zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+		if err != nil {
+			return "", err
+		}
+		var files []*zip.File
+		for _, f := range zr.File {
+			if !strings.HasSuffix(f.Name, ".xml") {
+				continue
+			}
+			for _, prefix := range prefixes {
+				if strings.HasPrefix(f.Name, prefix) {
+					files = append(files, f)
+					break
+				}
+			}
+		}
+		// keep the order of slides and sheets
+		sort.SliceStable(files, func(i, j int) bool {
+			if len(files[i].Name) != len(files[j].Name) {
+				return len(files[i].Name) < len(files[j].Name)
+			}
+			return files[i].Name < files[j].Name
+		})
+		var sb strings.Builder
+		for _, f := range files {
+			if sb.Len() >= MaxTextLength {
+				break
+			}
+			if err = xmlText(f, &sb); err != nil {
+				return "", err
+			}
+		}
+		return sb.String(), nil
+	}
+}
+
+// xmlText writes the char data of xml to sb, with a new line after each paragraph or row
+func xmlText(f *zip.File, sb *strings.Builder) error {
+	rc, err := f.Open()
+	if err != nil {
+		return err
+	}
+	defer rc.Close()
+	decoder := xml.NewDecoder(rc)
+	for sb.Len() < MaxTextLength {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		switch t := token.(type) {
+		case xml.CharData:
+			sb.Write(t)
+		case xml.EndElement:
+			switch t.Name.Local {
+			case "p", "row", "h", "table-row":
+				sb.WriteByte('\n')
+			case "c", "tab", "table-cell":
+				sb.WriteByte('\t')
+			}
+		}
+	}
+	return nil
+}
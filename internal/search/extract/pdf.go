@@ -0,0 +1,38 @@
+package extract
+
+import (
+	"bytes"
+	"strings"
+
+	"github.com/ledongthuc/pdf"
+)
+
+func pdfText(data []byte) (string, error) {
+	r, err := pdf.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return "", err
+	}
+	var sb strings.Builder
+	// cache the fonts shared by pages
+	fonts := make(map[string]*pdf.Font)
+	for i := 1; i <= r.NumPage() && sb.Len() < MaxTextLength; i++ {
+		page := r.Page(i)
+		if page.V.IsNull() {
+			continue
+		}
+		for _, name := range page.Fonts() {
+			if _, ok := fonts[name]; !ok {
+				f := page.Font(name)
+				fonts[name] = &f
+			}
+		}
+		text, err := page.GetPlainText(fonts)
+		if err != nil {
+			// skip the pages which can't be parsed
+			continue
+		}
+		sb.WriteString(text)
+		sb.WriteByte('\n')
+	}
+	return sb.String(), nil
+}
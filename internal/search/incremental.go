@@ -0,0 +1,309 @@
+package search
+
+import (
+	"context"
+	"crypto/md5"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/alist-org/alist/v3/internal/conf"
+	"github.com/alist-org/alist/v3/internal/db"
+	"github.com/alist-org/alist/v3/internal/errs"
+	"github.com/alist-org/alist/v3/internal/fs"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/internal/op"
+	"github.com/alist-org/alist/v3/internal/setting"
+	log "github.com/sirupsen/logrus"
+)
+
+// indexChange is either a listing of dir or a change of obj
+type indexChange struct {
+	parent string
+	objs   []model.Obj
+	change *op.ObjChange
+}
+
+var (
+	changeOnce  sync.Once
+	changeQueue = make(chan indexChange, 1024)
+)
+
+// enqueueChange queues the listings and the changes made by alist, they are
+// applied to the index in order, so a stale listing won't undo a later change
+func enqueueChange(c indexChange) {
+	if !canAutoUpdate() {
+		return
+	}
+	changeOnce.Do(func() {
+		go applyChanges()
+	})
+	select {
+	case changeQueue <- c:
+	default:
+		// the incremental update will fix it
+		log.Warnf("too many changes to index, drop the change of %s", c.path())
+	}
+}
+
+func (c indexChange) path() string {
+	if c.change != nil {
+		return c.change.Path
+	}
+	return c.parent
+}
+
+func onObjsUpdate(parent string, objs []model.Obj) {
+	enqueueChange(indexChange{parent: parent, objs: objs})
+}
+
+func onObjChange(change op.ObjChange) {
+	enqueueChange(indexChange{change: &change})
+}
+
+func applyChanges() {
+	ctx := context.Background()
+	// the dirs to be reindexed after the running build
+	pending := make(map[string]struct{})
+	ticker := time.NewTicker(10 * time.Second)
+	defer ticker.Stop()
+	for {
+		select {
+		case c := <-changeQueue:
+			if c.change == nil {
+				Update(c.parent, c.objs)
+				continue
+			}
+			if isIgnorePath(c.change.Path) || isInTrash(c.change.Path) || !canAutoUpdate() {
+				continue
+			}
+			err := applyChange(ctx, *c.change)
+			if errors.Is(err, errs.BuildIndexIsRunning) {
+				pending[c.change.Path] = struct{}{}
+			} else if err != nil {
+				log.Errorf("failed update index of %s: %+v", c.change.Path, err)
+			}
+		case <-ticker.C:
+			for p := range pending {
+				if Running() {
+					break
+				}
+				delete(pending, p)
+				if !canAutoUpdate() {
+					continue
+				}
+				err := reindexDir(ctx, p)
+				if errors.Is(err, errs.BuildIndexIsRunning) {
+					pending[p] = struct{}{}
+				} else if err != nil {
+					log.Errorf("failed update index of %s: %+v", p, err)
+				}
+			}
+		}
+	}
+}
+
+func applyChange(ctx context.Context, change op.ObjChange) error {
+	if change.Obj == nil {
+		if op.HasStorage(change.Path) {
+			return nil
+		}
+		log.Debugf("delete index: %s", change.Path)
+		return Del(ctx, change.Path)
+	}
+	log.Debugf("add index: %s", change.Path)
+	if change.Obj.IsDir() {
+		// the dir may be moved with its children, so build the index of it
+		return reindexDir(ctx, change.Path)
+	}
+	// the obj may be overwritten
+	if err := Del(ctx, change.Path); err != nil {
+		return err
+	}
+	return Index(ctx, path.Dir(change.Path), change.Obj)
+}
+
+// reindexDir replaces the index of dir and its children, it returns
+// errs.BuildIndexIsRunning if another build is running
+func reindexDir(ctx context.Context, dir string) error {
+	if Running() {
+		return errs.BuildIndexIsRunning
+	}
+	if err := Del(ctx, dir); err != nil {
+		return err
+	}
+	return BuildIndex(ctx, []string{dir}, conf.SlicesMap[conf.IgnorePaths],
+		setting.GetInt(conf.MaxIndexDepth, 20)-strings.Count(dir, "/"), false)
+}
+
+// isInTrash reports whether the path is in the recycle bin of storage
+func isInTrash(p string) bool {
+	storage, actualPath, err := op.GetStorageAndActualPath(p)
+	return err == nil && storage != nil && op.IsInTrash(actualPath)
+}
+
+// isMountDir reports whether the path is the root of storage or a virtual dir
+// above it, their modified time in listing never changes
+func isMountDir(p string) bool {
+	_, actualPath, err := op.GetStorageAndActualPath(p)
+	return err != nil || actualPath == "/"
+}
+
+// fingerprint identifies the listing of a dir, it changes if any child is
+// added, removed or modified
+func fingerprint(objs []model.Obj) string {
+	lines := make([]string, 0, len(objs))
+	for _, obj := range objs {
+		lines = append(lines, fmt.Sprintf("%s|%v|%d|%d", obj.GetName(), obj.IsDir(), obj.GetSize(), obj.ModTime().Unix()))
+	}
+	sort.Strings(lines)
+	sum := md5.Sum([]byte(strings.Join(lines, "\n")))
+	return hex.EncodeToString(sum[:])
+}
+
+var minutesSinceUpdate atomic.Int64
+
+// RunIncrementalUpdate should be called every minute, it runs UpdateIncrementally
+// once the interval in minutes has elapsed since the last run
+func RunIncrementalUpdate() {
+	interval := int64(setting.GetInt(conf.IndexUpdateInterval, 0))
+	if minutes := minutesSinceUpdate.Add(1); interval <= 0 || minutes < interval {
+		return
+	}
+	minutesSinceUpdate.Store(0)
+	if err := UpdateIncrementally(context.Background()); err != nil && err != errs.BuildIndexIsRunning {
+		log.Errorf("incremental update index error: %+v", err)
+	}
+}
+
+// UpdateIncrementally walks the tree and only reindexes the dirs whose listings
+// have changed since the last time they were indexed. The sub dirs are walked if
+// their modified time in the listing of parent changed, or they haven't been
+// listed for the max age, because the modified time of dir doesn't change with
+// its descendants in most storages.
+func UpdateIncrementally(ctx context.Context) error {
+	if !canAutoUpdate() {
+		return nil
+	}
+	quit := make(chan struct{}, 1)
+	if !Quit.CompareAndSwap(nil, &quit) {
+		return errs.BuildIndexIsRunning
+	}
+	defer Quit.Store(nil)
+	admin, err := op.GetAdmin()
+	if err != nil {
+		return err
+	}
+	ctx = context.WithValue(ctx, "user", admin)
+	records, err := db.GetIndexedDirs()
+	if err != nil {
+		return err
+	}
+	indexed := make(map[string]model.IndexedDir, len(records))
+	for _, r := range records {
+		indexed[r.Path] = r
+	}
+	maxAge := time.Duration(setting.GetInt(conf.IndexMaxAge, 24)) * time.Hour
+	maxDepth := setting.GetInt(conf.MaxIndexDepth, 20)
+	var walked, updated int
+	queue := []model.IndexedDir{{Path: "/"}}
+	for len(queue) > 0 {
+		select {
+		case <-quit:
+			log.Infof("incremental update index stopped")
+			return nil
+		default:
+		}
+		dir := queue[0]
+		queue = queue[1:]
+		objs, err := fs.List(ctx, dir.Path, &fs.ListArgs{Refresh: true, NoLog: true})
+		if err != nil {
+			log.Warnf("incremental update index: failed list %s: %+v", dir.Path, err)
+			continue
+		}
+		walked++
+		dir.Fingerprint = fingerprint(objs)
+		dir.Indexed = time.Now()
+		if r, ok := indexed[dir.Path]; !ok || r.Fingerprint != dir.Fingerprint {
+			if err = updateDir(ctx, dir.Path, objs); err != nil {
+				log.Errorf("incremental update index: failed update %s: %+v", dir.Path, err)
+				continue
+			}
+			updated++
+		}
+		if err = db.SaveIndexedDir(&dir); err != nil {
+			return err
+		}
+		if strings.Count(dir.Path, "/") >= maxDepth {
+			continue
+		}
+		for _, obj := range objs {
+			if !obj.IsDir() {
+				continue
+			}
+			sub := model.IndexedDir{Path: path.Join(dir.Path, obj.GetName()), Modified: obj.ModTime()}
+			if isIgnorePath(sub.Path) || isInTrash(sub.Path) {
+				continue
+			}
+			r, ok := indexed[sub.Path]
+			if !ok || !r.Modified.Equal(sub.Modified) || time.Since(r.Indexed) > maxAge || isMountDir(sub.Path) {
+				queue = append(queue, sub)
+			}
+		}
+	}
+	log.Infof("incremental update index: %d dirs walked, %d dirs updated", walked, updated)
+	return nil
+}
+
+// updateDir updates the nodes of parent to the objs
+func updateDir(ctx context.Context, parent string, objs []model.Obj) error {
+	nodes, err := instance.Get(ctx, parent)
+	if err != nil {
+		return err
+	}
+	old := make(map[string]model.SearchNode, len(nodes))
+	for _, node := range nodes {
+		old[node.Name] = node
+	}
+	var toIndex []ObjWithParent
+	for _, obj := range objs {
+		node, ok := old[obj.GetName()]
+		delete(old, obj.GetName())
+		if isIgnorePath(path.Join(parent, obj.GetName())) {
+			continue
+		}
+		if ok && node.IsDir == obj.IsDir() && (node.IsDir ||
+			node.Size == obj.GetSize() && node.Modified.Unix() == obj.ModTime().Unix()) {
+			continue
+		}
+		if ok {
+			// the changed file, or the file replaced by dir or vice versa
+			if err = instance.Del(ctx, path.Join(parent, obj.GetName())); err != nil {
+				return err
+			}
+		}
+		toIndex = append(toIndex, ObjWithParent{Parent: parent, Obj: obj})
+	}
+	for name := range old {
+		p := path.Join(parent, name)
+		if op.HasStorage(p) {
+			continue
+		}
+		log.Debugf("delete index: %s", p)
+		if err = Del(ctx, p); err != nil {
+			return err
+		}
+	}
+	return BatchIndex(ctx, toIndex)
+}
+
+func init() {
+	op.RegisterObjsUpdateHook(onObjsUpdate)
+	op.RegisterObjChangeHook(onObjChange)
+}
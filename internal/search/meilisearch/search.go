@@ -8,7 +8,9 @@ import (
 	"github.com/alist-org/alist/v3/pkg/utils"
 	"github.com/google/uuid"
 	"github.com/meilisearch/meilisearch-go"
+	"html"
 	"path"
+	"strconv"
 	"strings"
 	"time"
 )
@@ -16,6 +18,59 @@ import (
 type searchDocument struct {
 	ID string `json:"id"`
 	model.SearchNode
+	// the unix time of modified, the filters and sorting only support numbers
+	ModifiedTs int64 `json:"modified_ts"`
+	// the parent and its ancestors, for filtering by parent
+	Ancestors []string `json:"ancestors"`
+}
+
+func newSearchDocument(node model.SearchNode) *searchDocument {
+	ancestors := []string{"/"}
+	for p := node.Parent; p != "/" && p != "" && p != "."; p = path.Dir(p) {
+		ancestors = append(ancestors, p)
+	}
+	return &searchDocument{
+		ID:         uuid.NewString(),
+		SearchNode: node,
+		ModifiedTs: node.Modified.Unix(),
+		Ancestors:  ancestors,
+	}
+}
+
+// toSearchNode converts the document, the fields are missing in the documents created by the old versions
+func toSearchNode(src map[string]any) model.SearchNode {
+	node := model.SearchNode{
+		Parent: src["parent"].(string),
+		Name:   src["name"].(string),
+		IsDir:  src["is_dir"].(bool),
+		Size:   int64(src["size"].(float64)),
+	}
+	if modified, ok := src["modified"].(string); ok {
+		node.Modified, _ = time.Parse(time.RFC3339, modified)
+	}
+	if fileType, ok := src["file_type"].(float64); ok {
+		node.FileType = int(fileType)
+	}
+	node.Storage, _ = src["storage"].(string)
+	node.Hash, _ = src["hash"].(string)
+	return node
+}
+
+// the tags of highlighting are replaced after the content is escaped,
+// they are in the private use area so won't be in the content of files
+const (
+	highlightPreTag  = "\ue000"
+	highlightPostTag = "\ue001"
+)
+
+// toSnippet escapes the highlighted content and marks the highlighted words
+func toSnippet(formatted string) string {
+	return strings.NewReplacer(highlightPreTag, "<mark>", highlightPostTag, "</mark>").
+		Replace(html.EscapeString(formatted))
+}
+
+func quote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", "\\'") + "'"
 }
 
 type Meilisearch struct {
@@ -23,6 +78,7 @@ type Meilisearch struct {
 	IndexUid             string
 	FilterableAttributes []string
 	SearchableAttributes []string
+	SortableAttributes   []string
 }
 
 func (m *Meilisearch) Config() searcher.Config {
@@ -31,25 +87,75 @@ func (m *Meilisearch) Config() searcher.Config {
 
 func (m *Meilisearch) Search(ctx context.Context, req model.SearchReq) ([]model.SearchNode, int64, error) {
 	mReq := &meilisearch.SearchRequest{
-		AttributesToSearchOn: m.SearchableAttributes,
+		AttributesToSearchOn: []string{"name"},
+		// the content is too large to be returned
+		AttributesToRetrieve: []string{"parent", "name", "is_dir", "size", "modified", "file_type", "storage", "hash"},
 		Page:                 int64(req.Page),
 		HitsPerPage:          int64(req.PerPage),
 	}
+	if req.Content {
+		mReq.AttributesToSearchOn = m.SearchableAttributes
+		mReq.AttributesToCrop = []string{"content"}
+		mReq.AttributesToHighlight = []string{"content"}
+		mReq.CropLength = 30
+		mReq.HighlightPreTag = highlightPreTag
+		mReq.HighlightPostTag = highlightPostTag
+	}
+	var filters []string
 	if req.Scope != 0 {
-		mReq.Filter = fmt.Sprintf("is_dir = %v", req.Scope == 1)
+		filters = append(filters, fmt.Sprintf("is_dir = %v", req.Scope == 1))
+	}
+	if req.Parent != "" && req.Parent != "/" {
+		filters = append(filters, "ancestors = "+quote(req.Parent))
+	}
+	if len(req.Types) != 0 {
+		types := utils.MustSliceConvert(req.Types, func(t int) string {
+			return strconv.Itoa(t)
+		})
+		filters = append(filters, fmt.Sprintf("file_type IN [%s]", strings.Join(types, ",")))
+	}
+	if req.Storage != "" {
+		filters = append(filters, "storage = "+quote(req.Storage))
+	}
+	if req.Hash != "" {
+		filters = append(filters, "hash = "+quote(strings.ToLower(req.Hash)))
+	}
+	if req.MinSize != 0 {
+		filters = append(filters, fmt.Sprintf("size >= %d", req.MinSize))
+	}
+	if req.MaxSize != 0 {
+		filters = append(filters, fmt.Sprintf("size <= %d", req.MaxSize))
+	}
+	if req.ModifiedAfter != nil {
+		filters = append(filters, fmt.Sprintf("modified_ts >= %d", req.ModifiedAfter.Unix()))
+	}
+	if req.ModifiedBefore != nil {
+		filters = append(filters, fmt.Sprintf("modified_ts < %d", req.ModifiedBefore.Unix()))
+	}
+	if len(filters) != 0 {
+		mReq.Filter = strings.Join(filters, " AND ")
+	}
+	orderBy, desc := req.Order()
+	if orderBy == "modified" {
+		orderBy = "modified_ts"
+	}
+	if desc {
+		mReq.Sort = []string{orderBy + ":desc"}
+	} else {
+		mReq.Sort = []string{orderBy + ":asc"}
 	}
 	search, err := m.Client.Index(m.IndexUid).Search(req.Keywords, mReq)
 	if err != nil {
 		return nil, 0, err
 	}
 	nodes, err := utils.SliceConvert(search.Hits, func(src any) (model.SearchNode, error) {
-		srcMap := src.(map[string]any)
-		return model.SearchNode{
-			Parent: srcMap["parent"].(string),
-			Name:   srcMap["name"].(string),
-			IsDir:  srcMap["is_dir"].(bool),
-			Size:   int64(srcMap["size"].(float64)),
-		}, nil
+		hit := src.(map[string]any)
+		node := toSearchNode(hit)
+		if formatted, ok := hit["_formatted"].(map[string]any); ok {
+			snippet, _ := formatted["content"].(string)
+			node.Snippet = toSnippet(snippet)
+		}
+		return node, nil
 	})
 	if err != nil {
 		return nil, 0, err
@@ -57,17 +163,26 @@ func (m *Meilisearch) Search(ctx context.Context, req model.SearchReq) ([]model.
 	return nodes, search.TotalHits, nil
 }
 
+// Outdated reports whether there are documents created by the old versions,
+// which lack the fields for filtering by parent and modified
+func (m *Meilisearch) Outdated(ctx context.Context) (bool, error) {
+	search, err := m.Client.Index(m.IndexUid).Search("", &meilisearch.SearchRequest{
+		Filter: "ancestors NOT EXISTS OR modified_ts NOT EXISTS",
+		Limit:  1,
+	})
+	if err != nil {
+		return false, err
+	}
+	return len(search.Hits) != 0, nil
+}
+
 func (m *Meilisearch) Index(ctx context.Context, node model.SearchNode) error {
 	return m.BatchIndex(ctx, []model.SearchNode{node})
 }
 
 func (m *Meilisearch) BatchIndex(ctx context.Context, nodes []model.SearchNode) error {
 	documents, _ := utils.SliceConvert(nodes, func(src model.SearchNode) (*searchDocument, error) {
-
-		return &searchDocument{
-			ID:         uuid.NewString(),
-			SearchNode: src,
-		}, nil
+		return newSearchDocument(src), nil
 	})
 
 	_, err := m.Client.Index(m.IndexUid).AddDocuments(documents)
@@ -100,13 +215,8 @@ func (m *Meilisearch) getDocumentsByParent(ctx context.Context, parent string) (
 	}
 	return utils.SliceConvert(result.Results, func(src map[string]any) (*searchDocument, error) {
 		return &searchDocument{
-			ID: src["id"].(string),
-			SearchNode: model.SearchNode{
-				Parent: src["parent"].(string),
-				Name:   src["name"].(string),
-				IsDir:  src["is_dir"].(bool),
-				Size:   int64(src["size"].(float64)),
-			},
+			ID:         src["id"].(string),
+			SearchNode: toSearchNode(src),
 		}, nil
 	})
 }
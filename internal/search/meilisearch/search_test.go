@@ -0,0 +1,20 @@
+package meilisearch
+
+import "testing"
+
+func TestToSnippet(t *testing.T) {
+	tests := []struct {
+		formatted string
+		want      string
+	}{
+		{"a " + highlightPreTag + "word" + highlightPostTag + " b", "a <mark>word</mark> b"},
+		{"<script>alert(1)</script> " + highlightPreTag + "x" + highlightPostTag,
+			"&lt;script&gt;alert(1)&lt;/script&gt; <mark>x</mark>"},
+		{"<mark>fake</mark> & more", "&lt;mark&gt;fake&lt;/mark&gt; &amp; more"},
+	}
+	for _, tt := range tests {
+		if got := toSnippet(tt.formatted); got != tt.want {
+			t.Errorf("toSnippet(%q) = %q, want %q", tt.formatted, got, tt.want)
+		}
+	}
+}
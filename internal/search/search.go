@@ -26,6 +26,7 @@ func Init(mode string) error {
 			log.Errorf("release instance err: %+v", err)
 		}
 		instance = nil
+		contentMQ.Clear()
 	}
 	if Running() {
 		return fmt.Errorf("index is running")
@@ -43,10 +44,34 @@ func Init(mode string) error {
 		log.Errorf("init searcher error: %+v", err)
 	} else {
 		instance = i
+		checkOutdated(i)
 	}
 	return err
 }
 
+// checkOutdated prompts to rebuild the index created by the old versions in the progress
+func checkOutdated(s searcher.Searcher) {
+	o, ok := s.(searcher.Outdated)
+	if !ok {
+		return
+	}
+	outdated, err := o.Outdated(context.Background())
+	if err != nil {
+		log.Warnf("failed to check the search index: %+v", err)
+		return
+	}
+	if !outdated {
+		return
+	}
+	log.Warnf("the search index is created by an old version, please rebuild it")
+	progress, err := Progress()
+	if err != nil {
+		progress = &model.IndexProgress{}
+	}
+	progress.Error = "the index is created by an old version, some filters won't work until it's rebuilt"
+	WriteProgress(progress)
+}
+
 func Search(ctx context.Context, req model.SearchReq) ([]model.SearchNode, int64, error) {
 	return instance.Search(ctx, req)
 }
@@ -55,12 +80,11 @@ func Index(ctx context.Context, parent string, obj model.Obj) error {
 	if instance == nil {
 		return errs.SearchNotAvailable
 	}
-	return instance.Index(ctx, model.SearchNode{
-		Parent: parent,
-		Name:   obj.GetName(),
-		IsDir:  obj.IsDir(),
-		Size:   obj.GetSize(),
-	})
+	if shouldIndexContent(obj) {
+		enqueueContent(ObjWithParent{Parent: parent, Obj: obj})
+		return nil
+	}
+	return instance.Index(ctx, toSearchNode(parent, obj))
 }
 
 type ObjWithParent struct {
@@ -77,12 +101,14 @@ func BatchIndex(ctx context.Context, objs []ObjWithParent) error {
 	}
 	var searchNodes []model.SearchNode
 	for i := range objs {
-		searchNodes = append(searchNodes, model.SearchNode{
-			Parent: objs[i].Parent,
-			Name:   objs[i].GetName(),
-			IsDir:  objs[i].IsDir(),
-			Size:   objs[i].GetSize(),
-		})
+		if shouldIndexContent(objs[i].Obj) {
+			enqueueContent(objs[i])
+			continue
+		}
+		searchNodes = append(searchNodes, toSearchNode(objs[i].Parent, objs[i].Obj))
+	}
+	if len(searchNodes) == 0 {
+		return nil
 	}
 	return instance.BatchIndex(ctx, searchNodes)
 }
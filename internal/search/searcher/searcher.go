@@ -9,6 +9,8 @@ import (
 type Config struct {
 	Name       string
 	AutoUpdate bool
+	// whether the content of files can be indexed and searched
+	Content bool
 }
 
 type Searcher interface {
@@ -29,3 +31,10 @@ type Searcher interface {
 	// Clear all index
 	Clear(ctx context.Context) error
 }
+
+// Outdated is implemented by the searchers whose index may be created by the
+// old versions, which lacks the fields needed by the filters
+type Outdated interface {
+	// Outdated reports whether the index should be rebuilt
+	Outdated(ctx context.Context) (bool, error)
+}
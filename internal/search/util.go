@@ -94,3 +94,30 @@ func init() {
 		}
 	})
 }
+
+// toSearchNode converts obj without the content, which is extracted in background
+func toSearchNode(parent string, obj model.Obj) model.SearchNode {
+	node := model.SearchNode{
+		Parent:   parent,
+		Name:     obj.GetName(),
+		IsDir:    obj.IsDir(),
+		Size:     obj.GetSize(),
+		Modified: obj.ModTime(),
+		FileType: utils.GetObjType(obj.GetName(), obj.IsDir()),
+		Hash:     hashString(obj.GetHash()),
+	}
+	if storage, _, err := op.GetStorageAndActualPath(parent); err == nil {
+		node.Storage = storage.GetStorage().MountPath
+	}
+	return node
+}
+
+// hashString returns the first known hash of md5, sha1 and sha256 in the form of type:hex
+func hashString(hi utils.HashInfo) string {
+	for _, ht := range []*utils.HashType{utils.MD5, utils.SHA1, utils.SHA256} {
+		if h := hi.GetHash(ht); h != "" {
+			return ht.Name + ":" + strings.ToLower(h)
+		}
+	}
+	return ""
+}
@@ -10,6 +10,7 @@ import (
 
 	"github.com/alist-org/alist/v3/internal/errs"
 	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/internal/ratelimit"
 	"github.com/alist-org/alist/v3/pkg/http_range"
 	"github.com/alist-org/alist/v3/pkg/utils"
 )
@@ -190,7 +191,7 @@ func (ss *SeekableStream) RangeRead(httpRange http_range.Range) (io.Reader, erro
 		httpRange.Length = ss.GetSize()
 	}
 	if ss.mFile != nil {
-		return io.NewSectionReader(ss.mFile, httpRange.Start, httpRange.Length), nil
+		return ratelimit.NewReader(ss.Ctx, io.NewSectionReader(ss.mFile, httpRange.Start, httpRange.Length)), nil
 	}
 	if ss.tmpFile != nil {
 		return io.NewSectionReader(ss.tmpFile, httpRange.Start, httpRange.Length), nil
@@ -200,7 +201,7 @@ func (ss *SeekableStream) RangeRead(httpRange http_range.Range) (io.Reader, erro
 		if err != nil {
 			return nil, err
 		}
-		return rc, nil
+		return ratelimit.NewReader(ss.Ctx, rc), nil
 	}
 	return nil, fmt.Errorf("can't find mFile or rangeReadCloser")
 }
@@ -227,7 +228,15 @@ func (ss *SeekableStream) Read(p []byte) (n int, err error) {
 		ss.Closers.Add(rc)
 
 	}
-	return ss.Reader.Read(p)
+	n, err = ss.Reader.Read(p)
+	if ss.tmpFile != nil {
+		return n, err
+	}
+	// the reading is limited if the limiters are set in ctx, except the cached one
+	if werr := ratelimit.WaitN(ss.Ctx, n); werr != nil && err == nil {
+		err = werr
+	}
+	return n, err
 }
 
 func (ss *SeekableStream) CacheFullInTempFile() (model.File, error) {
@@ -10,6 +10,7 @@ import (
 	"github.com/alist-org/alist/v3/internal/model"
 	"github.com/alist-org/alist/v3/internal/net"
 	"github.com/alist-org/alist/v3/pkg/http_range"
+	"github.com/alist-org/alist/v3/pkg/utils"
 	log "github.com/sirupsen/logrus"
 )
 
@@ -68,6 +69,33 @@ func GetRangeReadCloserFromLink(size int64, link *model.Link) (model.RangeReadCl
 	return &resultRangeReadCloser, nil
 }
 
+// GetRangeReaderFromLink opens the range of the file of size from the link, which
+// has one of MFile, RangeReadCloser and URL. the link is closed with the reader
+func GetRangeReaderFromLink(ctx context.Context, size int64, link *model.Link, r http_range.Range) (io.ReadCloser, error) {
+	if r.Length < 0 || r.Start+r.Length > size {
+		r.Length = size - r.Start
+	}
+	if link.MFile != nil {
+		return utils.NewReadCloser(io.NewSectionReader(link.MFile, r.Start, r.Length), link.MFile.Close), nil
+	}
+	rrc := link.RangeReadCloser
+	if rrc == nil {
+		var err error
+		if rrc, err = GetRangeReadCloserFromLink(size, link); err != nil {
+			return nil, err
+		}
+	}
+	rc, err := rrc.RangeRead(ctx, r)
+	if err != nil {
+		_ = rrc.Close()
+		return nil, err
+	}
+	return utils.NewReadCloser(rc, func() error {
+		_ = rc.Close()
+		return rrc.Close()
+	}), nil
+}
+
 func RequestRangedHttp(ctx context.Context, link *model.Link, offset, length int64) (*http.Response, error) {
 	header := net.ProcessHeader(http.Header{}, link.Header)
 	header = http_range.ApplyRangeToHttpHeader(http_range.Range{Start: offset, Length: length}, header)
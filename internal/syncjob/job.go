@@ -0,0 +1,94 @@
+package syncjob
+
+import (
+	"sync"
+	"time"
+
+	"github.com/alist-org/alist/v3/internal/db"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/pkg/cron"
+	"github.com/alist-org/alist/v3/pkg/utils"
+	log "github.com/sirupsen/logrus"
+)
+
+var (
+	crons   = make(map[uint]*cron.Cron)
+	cronsMu sync.Mutex
+)
+
+// schedule runs the job every interval, or stops the scheduled runs if it's disabled
+func schedule(job model.SyncJob) {
+	cronsMu.Lock()
+	defer cronsMu.Unlock()
+	if c, ok := crons[job.ID]; ok {
+		c.Stop()
+		delete(crons, job.ID)
+	}
+	if job.Disabled || job.Interval <= 0 {
+		return
+	}
+	c := cron.NewCron(time.Duration(job.Interval) * time.Minute)
+	c.Do(func() {
+		j, err := db.GetSyncJobById(job.ID)
+		if err != nil {
+			log.Errorf("failed get sync job %d: %+v", job.ID, err)
+			return
+		}
+		if _, err = Run(j, false); err != nil {
+			log.Warnf("failed run sync job %s: %+v", j.Name, err)
+		}
+	})
+	crons[job.ID] = c
+}
+
+// Init schedules the jobs
+func Init() {
+	jobs, err := db.GetSyncJobs()
+	if err != nil {
+		utils.Log.Errorf("failed get sync jobs: %+v", err)
+		return
+	}
+	for _, job := range jobs {
+		schedule(job)
+	}
+}
+
+func CreateJob(job *model.SyncJob) error {
+	if err := job.Validate(); err != nil {
+		return err
+	}
+	job.ID = 0
+	job.SrcPath = utils.FixAndCleanPath(job.SrcPath)
+	job.DstPath = utils.FixAndCleanPath(job.DstPath)
+	if err := db.CreateSyncJob(job); err != nil {
+		return err
+	}
+	schedule(*job)
+	return nil
+}
+
+func UpdateJob(job *model.SyncJob) error {
+	if err := job.Validate(); err != nil {
+		return err
+	}
+	old, err := db.GetSyncJobById(job.ID)
+	if err != nil {
+		return err
+	}
+	job.LastRun = old.LastRun
+	job.SrcPath = utils.FixAndCleanPath(job.SrcPath)
+	job.DstPath = utils.FixAndCleanPath(job.DstPath)
+	if err = db.UpdateSyncJob(job); err != nil {
+		return err
+	}
+	schedule(*job)
+	return nil
+}
+
+func DeleteJob(id uint) error {
+	if err := db.DeleteSyncJobById(id); err != nil {
+		return err
+	}
+	schedule(model.SyncJob{ID: id, Disabled: true})
+	return nil
+}
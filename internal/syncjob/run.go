@@ -0,0 +1,345 @@
+package syncjob
+
+import (
+	"context"
+	stderrors "errors"
+	stdpath "path"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/alist-org/alist/v3/internal/conf"
+	"github.com/alist-org/alist/v3/internal/db"
+	"github.com/alist-org/alist/v3/internal/errs"
+	"github.com/alist-org/alist/v3/internal/fs"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/internal/op"
+	"github.com/alist-org/alist/v3/pkg/utils"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+	"github.com/xhofe/tache"
+)
+
+var running sync.Map
+
+// Run starts a run of job in background and returns its record, the copies between
+// storages are added to the copy tasks. The dry run only reports the actions.
+func Run(job *model.SyncJob, dryRun bool) (*model.SyncRun, error) {
+	if _, ok := running.LoadOrStore(job.ID, struct{}{}); ok {
+		return nil, errors.New("the job is running")
+	}
+	run := &model.SyncRun{
+		JobID:  job.ID,
+		DryRun: dryRun,
+		Start:  time.Now(),
+		State:  model.SyncRunRunning,
+	}
+	if err := db.CreateSyncRun(run); err != nil {
+		running.Delete(job.ID)
+		return nil, err
+	}
+	r := *run
+	go func() {
+		defer running.Delete(job.ID)
+		runJob(job, &r)
+	}()
+	return run, nil
+}
+
+func runJob(job *model.SyncJob, run *model.SyncRun) {
+	err := func() error {
+		admin, err := op.GetAdmin()
+		if err != nil {
+			return err
+		}
+		s := &syncer{
+			ctx: context.WithValue(context.Background(), "user", admin),
+			job: job,
+			run: run,
+		}
+		return s.sync()
+	}()
+	now := time.Now()
+	run.End = &now
+	run.State = model.SyncRunSucceeded
+	if err != nil {
+		log.Errorf("failed run sync job %s: %+v", job.Name, err)
+		run.State = model.SyncRunFailed
+		run.Error = err.Error()
+	}
+	if err = db.UpdateSyncRun(run); err != nil {
+		log.Errorf("failed save sync run: %+v", err)
+	}
+	if run.DryRun {
+		return
+	}
+	// the job may be updated while running
+	if j, err := db.GetSyncJobById(job.ID); err == nil {
+		j.LastRun = &run.Start
+		if err = db.UpdateSyncJob(j); err != nil {
+			log.Errorf("failed save sync job: %+v", err)
+		}
+	}
+}
+
+type syncer struct {
+	ctx context.Context
+	job *model.SyncJob
+	run *model.SyncRun
+	// whether the source and destination are in the same storage
+	sameStorage bool
+	// the copies which are recorded after finished
+	copying []*copying
+}
+
+// copying is a copy or update in progress, the obj it replaces is renamed to
+// backup, which is removed after the copy succeeded or restored if it failed
+type copying struct {
+	action  model.SyncAction
+	dstPath string
+	backup  string
+	// nil if copied in place
+	task tache.TaskWithInfo
+}
+
+// backupSuffix is appended to the name of obj replaced by the copy
+const backupSuffix = ".alist_sync_old"
+
+func (s *syncer) sync() error {
+	s.job.SrcPath = utils.FixAndCleanPath(s.job.SrcPath)
+	s.job.DstPath = utils.FixAndCleanPath(s.job.DstPath)
+	if utils.IsSubPath(s.job.SrcPath, s.job.DstPath) || utils.IsSubPath(s.job.DstPath, s.job.SrcPath) {
+		return errors.New("the source and destination can't contain each other")
+	}
+	srcStorage, _, err := op.GetStorageAndActualPath(s.job.SrcPath)
+	if err != nil {
+		return errors.WithMessage(err, "failed get src storage")
+	}
+	dstStorage, _, err := op.GetStorageAndActualPath(s.job.DstPath)
+	if err != nil {
+		return errors.WithMessage(err, "failed get dst storage")
+	}
+	s.sameStorage = srcStorage.GetStorage() == dstStorage.GetStorage()
+	if _, err = fs.Get(s.ctx, s.job.DstPath, &fs.GetArgs{NoLog: true}); err != nil {
+		if !errs.IsObjectNotFound(err) {
+			return errors.WithMessage(err, "failed get dst dir")
+		}
+		if !s.run.DryRun {
+			if err = fs.MakeDir(s.ctx, s.job.DstPath); err != nil {
+				return errors.WithMessage(err, "failed make dst dir")
+			}
+		}
+	}
+	err = s.syncDir("/")
+	// the tasks are shown in the run before they finish
+	if len(s.copying) != 0 {
+		if e := db.UpdateSyncRun(s.run); e != nil {
+			log.Errorf("failed save sync run: %+v", e)
+		}
+	}
+	s.finish()
+	return err
+}
+
+// syncDir syncs the dir with the path relative to the source and destination
+func (s *syncer) syncDir(rel string) error {
+	if utils.IsCanceled(s.ctx) {
+		return s.ctx.Err()
+	}
+	srcObjs, err := fs.List(s.ctx, stdpath.Join(s.job.SrcPath, rel), &fs.ListArgs{Refresh: true, NoLog: true})
+	if err != nil {
+		return errors.WithMessagef(err, "failed list src dir %s", rel)
+	}
+	dstObjs, err := fs.List(s.ctx, stdpath.Join(s.job.DstPath, rel), &fs.ListArgs{Refresh: true, NoLog: true})
+	if err != nil && !(s.run.DryRun && errs.IsObjectNotFound(err)) {
+		return errors.WithMessagef(err, "failed list dst dir %s", rel)
+	}
+	dst := make(map[string]model.Obj, len(dstObjs))
+	for _, obj := range dstObjs {
+		dst[obj.GetName()] = obj
+	}
+	for _, obj := range srcObjs {
+		p := stdpath.Join(rel, obj.GetName())
+		d, ok := dst[obj.GetName()]
+		delete(dst, obj.GetName())
+		switch {
+		case !ok:
+			s.do(model.SyncActionCopy, p, obj, false)
+		case obj.IsDir() && d.IsDir():
+			if err = s.syncDir(p); err != nil {
+				if utils.IsCanceled(s.ctx) {
+					return err
+				}
+				s.record(model.SyncAction{Action: model.SyncActionUpdate, Path: p, IsDir: true, Error: err.Error()})
+			}
+		case s.job.Mode != model.SyncModeCopy && s.changed(obj, d):
+			// the copy between storages overwrites the existing file, but the drivers
+			// may refuse to copy to an existing one, and a dir can't be overwritten
+			s.do(model.SyncActionUpdate, p, obj, s.sameStorage || obj.IsDir() != d.IsDir())
+		}
+	}
+	if s.job.Mode != model.SyncModeMirror {
+		return nil
+	}
+	// some storages list an empty dir if failed, which shouldn't clear the destination
+	if len(srcObjs) == 0 && len(dstObjs) != 0 {
+		return errors.Errorf("src dir %s is empty, refuse to delete the objs in dst dir", rel)
+	}
+	for _, obj := range dstObjs {
+		if _, ok := dst[obj.GetName()]; ok {
+			s.do(model.SyncActionDelete, stdpath.Join(rel, obj.GetName()), obj, true)
+		}
+	}
+	return nil
+}
+
+// changed reports whether the file in destination should be replaced by the one in source
+func (s *syncer) changed(src, dst model.Obj) bool {
+	if src.IsDir() != dst.IsDir() || src.GetSize() != dst.GetSize() {
+		return true
+	}
+	switch s.job.Compare {
+	case model.SyncCompareModified:
+		return src.ModTime().Truncate(time.Second).After(dst.ModTime().Truncate(time.Second))
+	case model.SyncCompareHash:
+		for _, ht := range []*utils.HashType{utils.MD5, utils.SHA1, utils.SHA256} {
+			srcHash, dstHash := src.GetHash().GetHash(ht), dst.GetHash().GetHash(ht)
+			if srcHash != "" && dstHash != "" {
+				return !strings.EqualFold(srcHash, dstHash)
+			}
+		}
+	}
+	return false
+}
+
+// do does the action on the obj with the relative path and records it,
+// the obj in destination is replaced if replace
+func (s *syncer) do(action string, rel string, obj model.Obj, replace bool) {
+	a := model.SyncAction{Action: action, Path: rel, IsDir: obj.IsDir(), Size: obj.GetSize()}
+	if s.run.DryRun {
+		s.record(a)
+		return
+	}
+	if action == model.SyncActionDelete {
+		if err := fs.Remove(s.ctx, stdpath.Join(s.job.DstPath, rel)); err != nil {
+			a.Error = err.Error()
+		}
+		s.record(a)
+		return
+	}
+	c, err := s.copy(a, replace)
+	if err != nil {
+		a.Error = err.Error()
+		s.record(a)
+		return
+	}
+	s.copying = append(s.copying, c)
+}
+
+// copy starts the copy of the action, the obj in destination is renamed to backup first if replace
+func (s *syncer) copy(a model.SyncAction, replace bool) (*copying, error) {
+	srcPath := stdpath.Join(s.job.SrcPath, a.Path)
+	c := &copying{action: a, dstPath: stdpath.Join(s.job.DstPath, a.Path)}
+	if replace {
+		name := stdpath.Base(c.dstPath) + backupSuffix
+		if err := fs.Rename(s.ctx, c.dstPath, name); err != nil {
+			return nil, errors.WithMessage(err, "failed rename the dst obj to backup")
+		}
+		c.backup = stdpath.Join(stdpath.Dir(c.dstPath), name)
+	}
+	t, err := fs.Copy(context.WithValue(s.ctx, conf.SingleTaskKey, struct{}{}), srcPath, stdpath.Dir(c.dstPath))
+	if err != nil {
+		return nil, stderrors.Join(err, s.restore(c))
+	}
+	if t != nil {
+		c.task = t
+		s.run.TaskIDs = append(s.run.TaskIDs, t.GetID())
+	}
+	return c, nil
+}
+
+// finish waits for the copies and records them
+func (s *syncer) finish() {
+	for _, c := range s.copying {
+		err := waitTask(c.task)
+		if err == nil && c.backup != "" {
+			if e := removeBackup(s.ctx, c.backup); e != nil {
+				log.Warnf("failed remove the backup %s: %+v", c.backup, e)
+			}
+		}
+		if err != nil {
+			if e := s.restore(c); e != nil {
+				err = stderrors.Join(err, e)
+			}
+			c.action.Error = err.Error()
+		}
+		s.record(c.action)
+	}
+	s.copying = nil
+}
+
+// removeBackup removes the backup permanently, it's internal so it isn't moved to the recycle bin
+func removeBackup(ctx context.Context, path string) error {
+	storage, actualPath, err := op.GetStorageAndActualPath(path)
+	if err != nil {
+		return err
+	}
+	return op.RemovePermanently(ctx, storage, actualPath)
+}
+
+// restore removes what the failed copy left and renames the backup back
+func (s *syncer) restore(c *copying) error {
+	if c.backup == "" {
+		return nil
+	}
+	storage, actualPath, err := op.GetStorageAndActualPath(c.dstPath)
+	if err != nil {
+		return err
+	}
+	if _, err = op.Get(s.ctx, storage, actualPath); err == nil {
+		if err = op.RemovePermanently(s.ctx, storage, actualPath); err != nil {
+			return errors.WithMessage(err, "failed remove the partial copy")
+		}
+	}
+	if err = fs.Rename(s.ctx, c.backup, stdpath.Base(c.dstPath)); err != nil {
+		return errors.WithMessagef(err, "failed restore the backup %s", c.backup)
+	}
+	return nil
+}
+
+// waitTask waits for the task to finish and returns its error
+func waitTask(t tache.TaskWithInfo) error {
+	if t == nil {
+		return nil
+	}
+	for {
+		switch t.GetState() {
+		case tache.StateSucceeded:
+			return nil
+		case tache.StateFailed:
+			if err := t.GetErr(); err != nil {
+				return err
+			}
+			return errors.New("the copy task is failed")
+		case tache.StateCanceled:
+			return errors.New("the copy task is canceled")
+		}
+		time.Sleep(time.Second)
+	}
+}
+
+func (s *syncer) record(a model.SyncAction) {
+	switch {
+	case a.Error != "":
+		s.run.Failed++
+	case a.Action == model.SyncActionCopy:
+		s.run.Copied++
+	case a.Action == model.SyncActionUpdate:
+		s.run.Updated++
+	case a.Action == model.SyncActionDelete:
+		s.run.Deleted++
+	}
+	if len(s.run.Actions) < model.MaxSyncActions {
+		s.run.Actions = append(s.run.Actions, a)
+	}
+}
@@ -0,0 +1,229 @@
+package syncjob
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	_ "github.com/alist-org/alist/v3/drivers/local"
+	"github.com/alist-org/alist/v3/internal/conf"
+	"github.com/alist-org/alist/v3/internal/db"
+	"github.com/alist-org/alist/v3/internal/fs"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/internal/op"
+	"github.com/alist-org/alist/v3/pkg/utils"
+	"github.com/xhofe/tache"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func init() {
+	dB, err := gorm.Open(sqlite.Open("file::memory:?cache=shared"), &gorm.Config{})
+	if err != nil {
+		panic("failed to connect database")
+	}
+	conf.Conf = conf.DefaultConfig()
+	db.Init(dB)
+	fs.CopyTaskManager = tache.NewManager[*fs.CopyTask](tache.WithWorks(1))
+}
+
+// mount mounts a Local storage of a temp dir with the files, the names of dirs end with /
+func mount(t *testing.T, mountPath string, files map[string]string) string {
+	root := t.TempDir()
+	for name, content := range files {
+		p := filepath.Join(root, filepath.FromSlash(name))
+		if strings.HasSuffix(name, "/") {
+			if err := os.MkdirAll(p, 0777); err != nil {
+				t.Fatal(err)
+			}
+			continue
+		}
+		if err := os.MkdirAll(filepath.Dir(p), 0777); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(p, []byte(content), 0666); err != nil {
+			t.Fatal(err)
+		}
+	}
+	id, err := op.CreateStorage(context.Background(), model.Storage{
+		Driver:    "Local",
+		MountPath: mountPath,
+		Addition:  `{"root_folder_path":"` + filepath.ToSlash(root) + `"}`,
+	})
+	if err != nil {
+		t.Fatalf("failed to create storage: %+v", err)
+	}
+	t.Cleanup(func() {
+		_ = op.DeleteStorageById(context.Background(), id)
+	})
+	return root
+}
+
+func newSyncer(job *model.SyncJob) *syncer {
+	admin := &model.User{Username: "admin", Role: model.ADMIN, BasePath: "/"}
+	return &syncer{
+		ctx: context.WithValue(context.Background(), "user", admin),
+		job: job,
+		run: &model.SyncRun{},
+	}
+}
+
+// files returns the files in root with their content, the dirs end with /
+func files(t *testing.T, root string) map[string]string {
+	res := make(map[string]string)
+	err := filepath.Walk(root, func(p string, info os.FileInfo, err error) error {
+		if err != nil || p == root {
+			return err
+		}
+		rel := filepath.ToSlash(strings.TrimPrefix(p, root+string(filepath.Separator)))
+		if info.IsDir() {
+			res[rel+"/"] = ""
+			return nil
+		}
+		content, err := os.ReadFile(p)
+		res[rel] = string(content)
+		return err
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return res
+}
+
+func equal(a, b map[string]string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for k, v := range a {
+		if w, ok := b[k]; !ok || w != v {
+			return false
+		}
+	}
+	return true
+}
+
+func TestChanged(t *testing.T) {
+	now := time.Now()
+	md5 := func(h string) utils.HashInfo {
+		return utils.NewHashInfo(utils.MD5, h)
+	}
+	tests := []struct {
+		compare  string
+		src, dst model.Object
+		changed  bool
+	}{
+		{compare: model.SyncCompareSize, src: model.Object{Size: 1}, dst: model.Object{Size: 2}, changed: true},
+		{compare: model.SyncCompareSize, src: model.Object{Size: 1, Modified: now}, dst: model.Object{Size: 1}, changed: false},
+		{compare: model.SyncCompareSize, src: model.Object{IsFolder: true}, dst: model.Object{}, changed: true},
+		{compare: model.SyncCompareModified, src: model.Object{Size: 1, Modified: now}, dst: model.Object{Size: 1, Modified: now.Add(-time.Minute)}, changed: true},
+		{compare: model.SyncCompareModified, src: model.Object{Size: 1, Modified: now.Add(-time.Minute)}, dst: model.Object{Size: 1, Modified: now}, changed: false},
+		{compare: model.SyncCompareHash, src: model.Object{Size: 1, HashInfo: md5("aa")}, dst: model.Object{Size: 1, HashInfo: md5("AA")}, changed: false},
+		{compare: model.SyncCompareHash, src: model.Object{Size: 1, HashInfo: md5("aa")}, dst: model.Object{Size: 1, HashInfo: md5("bb")}, changed: true},
+		{compare: model.SyncCompareHash, src: model.Object{Size: 1, HashInfo: md5("aa")}, dst: model.Object{Size: 1}, changed: false},
+	}
+	for i, tt := range tests {
+		s := newSyncer(&model.SyncJob{Compare: tt.compare})
+		if got := s.changed(&tt.src, &tt.dst); got != tt.changed {
+			t.Errorf("%d: changed() = %v, want %v", i, got, tt.changed)
+		}
+	}
+}
+
+func TestSync(t *testing.T) {
+	// the name of backup is too long, so the file can't be replaced
+	long := strings.Repeat("l", 245)
+	tests := []struct {
+		name     string
+		mode     string
+		srcPath  string
+		src, dst map[string]string
+		want     map[string]string
+		isErr    bool
+		failed   int
+	}{
+		{
+			name: "between storages",
+			mode: model.SyncModeMirror,
+			src:  map[string]string{"a.txt": "new", "b.txt": "same", "dir/c.txt": "c", "d": "file"},
+			dst:  map[string]string{"a.txt": "old!", "b.txt": "same", "extra.txt": "extra", "d/e.txt": "dir"},
+			want: map[string]string{"a.txt": "new", "b.txt": "same", "dir/": "", "dir/c.txt": "c", "d": "file"},
+		},
+		{
+			name: "copy only",
+			mode: model.SyncModeCopy,
+			src:  map[string]string{"a.txt": "new", "b.txt": "b"},
+			dst:  map[string]string{"a.txt": "old!"},
+			want: map[string]string{"a.txt": "old!", "b.txt": "b"},
+		},
+		{
+			name:    "empty source",
+			mode:    model.SyncModeMirror,
+			srcPath: "/src/empty",
+			src:     map[string]string{"empty/": ""},
+			dst:     map[string]string{"a.txt": "a"},
+			want:    map[string]string{"a.txt": "a"},
+			isErr:   true,
+		},
+		{
+			name:   "failed to replace",
+			mode:   model.SyncModeUpdate,
+			src:    map[string]string{long: "new"},
+			dst:    map[string]string{long: "old!"},
+			want:   map[string]string{long: "old!"},
+			failed: 1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			src := "/src"
+			if tt.srcPath != "" {
+				src = tt.srcPath
+			}
+			mount(t, "/src", tt.src)
+			dst := mount(t, "/dst", tt.dst)
+			s := newSyncer(&model.SyncJob{SrcPath: src, DstPath: "/dst", Mode: tt.mode, Compare: model.SyncCompareSize})
+			err := s.sync()
+			if (err != nil) != tt.isErr {
+				t.Errorf("sync() error = %v, want error: %v", err, tt.isErr)
+			}
+			if s.run.Failed != tt.failed {
+				t.Errorf("sync() failed %d actions: %+v, want %d", s.run.Failed, s.run.Actions, tt.failed)
+			}
+			if got := files(t, dst); !equal(got, tt.want) {
+				t.Errorf("sync() got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSyncSameStorage(t *testing.T) {
+	// the backups of replaced files aren't moved to the recycle bin
+	if err := op.SaveSettingItem(&model.SettingItem{Key: conf.RecycleBinEnabled, Value: "true", Type: conf.TypeBool}); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		_ = op.SaveSettingItem(&model.SettingItem{Key: conf.RecycleBinEnabled, Value: "false", Type: conf.TypeBool})
+	})
+	root := mount(t, "/local", map[string]string{
+		"src/a.txt": "new",
+		"src/b.txt": "b",
+		"dst/a.txt": "old!",
+	})
+	s := newSyncer(&model.SyncJob{SrcPath: "/local/src", DstPath: "/local/dst", Mode: model.SyncModeUpdate, Compare: model.SyncCompareSize})
+	if err := s.sync(); err != nil {
+		t.Fatalf("sync() error = %v", err)
+	}
+	want := map[string]string{"a.txt": "new", "b.txt": "b"}
+	if got := files(t, filepath.Join(root, "dst")); !equal(got, want) {
+		t.Errorf("sync() got %v, want %v", got, want)
+	}
+	if s.run.Updated != 1 || s.run.Copied != 1 || len(s.run.TaskIDs) != 0 {
+		t.Errorf("sync() run = %+v, want 1 updated and 1 copied without tasks", s.run)
+	}
+	if _, err := os.Stat(filepath.Join(root, ".alist_recycle_bin")); !os.IsNotExist(err) {
+		t.Errorf("the backup is moved to the recycle bin: %v", err)
+	}
+}
@@ -219,6 +219,7 @@ func FromString(str string) HashInfo {
 
 	return hi
 }
+
 func (hi HashInfo) GetHash(ht *HashType) string {
 	return hi.h[ht]
 }
@@ -6,6 +6,7 @@ import (
 	"github.com/Xhofe/go-cache"
 	"github.com/alist-org/alist/v3/internal/conf"
 	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/internal/op"
 	"github.com/golang-jwt/jwt/v4"
 	"github.com/pkg/errors"
 )
@@ -64,6 +65,27 @@ func ParseToken(tokenString string) (*UserClaims, error) {
 	return nil, errors.New("couldn't handle this token")
 }
 
+// ParseUser returns the user of the login token, which must not be
+// disabled or issued before the password changed
+func ParseUser(token string) (*model.User, error) {
+	userClaims, err := ParseToken(token)
+	if err != nil {
+		return nil, err
+	}
+	user, err := op.GetUserByName(userClaims.Username)
+	if err != nil {
+		return nil, err
+	}
+	// validate password timestamp
+	if userClaims.PwdTS != user.PwdTS {
+		return nil, errors.New("Password has been changed, login please")
+	}
+	if user.Disabled {
+		return nil, errors.New("Current user is disabled, replace please")
+	}
+	return user, nil
+}
+
 func InvalidateToken(tokenString string) error {
 	if tokenString == "" {
 		return nil // don't invalidate empty guest token
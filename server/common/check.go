@@ -10,6 +10,7 @@ import (
 	"github.com/alist-org/alist/v3/internal/op"
 	"github.com/alist-org/alist/v3/pkg/utils"
 	"github.com/dlclark/regexp2"
+	log "github.com/sirupsen/logrus"
 )
 
 func IsStorageSignEnabled(rawPath string) bool {
@@ -42,6 +43,9 @@ func CanAccess(user *model.User, meta *model.Meta, reqPath string, password stri
 			}
 		}
 	}
+	if !CanOperate(user, reqPath, model.ACLRead, true) {
+		return false
+	}
 	// if is not guest and can access without password
 	if user.CanAccessWithoutPassword() {
 		return true
@@ -58,6 +62,41 @@ func CanAccess(user *model.User, meta *model.Meta, reqPath string, password stri
 	return meta.Password == password
 }
 
+// CanOperate checks the acl rules of user on reqPath, global is the result of
+// the permission bits and metas. The rules only narrow the permissions, so
+// the operation is never allowed if global is false
+func CanOperate(user *model.User, reqPath string, operation int32, global bool) bool {
+	if user.IsAdmin() || !global {
+		return global
+	}
+	operations, ok, err := op.GetACLOperations(user, reqPath)
+	if err != nil {
+		log.Errorf("failed get acl rules of [%s]: %+v", user.Username, err)
+		return false
+	}
+	if !ok {
+		return global
+	}
+	return operations&operation == operation
+}
+
+// FilterReadable removes the objs in dir that can't be read by user
+func FilterReadable(user *model.User, dir string, objs []model.Obj) []model.Obj {
+	if user.IsAdmin() {
+		return objs
+	}
+	if ok, err := op.HasACLs(user); err != nil || !ok {
+		return objs
+	}
+	res := make([]model.Obj, 0, len(objs))
+	for _, obj := range objs {
+		if CanOperate(user, path.Join(dir, obj.GetName()), model.ACLRead, true) {
+			res = append(res, obj)
+		}
+	}
+	return res
+}
+
 // ShouldProxy TODO need optimize
 // when should be proxy?
 // 1. config.MustProxy()
@@ -1,6 +1,24 @@
 package common
 
-import "testing"
+import (
+	"testing"
+
+	"github.com/alist-org/alist/v3/internal/conf"
+	"github.com/alist-org/alist/v3/internal/db"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/internal/op"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func init() {
+	dB, err := gorm.Open(sqlite.Open("file::memory:?cache=shared"), &gorm.Config{})
+	if err != nil {
+		panic("failed to connect database")
+	}
+	conf.Conf = conf.DefaultConfig()
+	db.Init(dB)
+}
 
 func TestIsApply(t *testing.T) {
 	datas := []struct {
@@ -22,3 +40,43 @@ func TestIsApply(t *testing.T) {
 		}
 	}
 }
+
+func TestCanOperate(t *testing.T) {
+	user := &model.User{ID: 100, Username: "acl", Role: model.GENERAL}
+	acls := []model.ACL{
+		{Path: "/a", Operations: model.ACLRead | model.ACLWrite | model.ACLRemove, Inherit: true},
+		{Path: "/a/b", Operations: model.ACLRead},
+	}
+	for i := range acls {
+		acls[i].SubjectType = model.ACLSubjectUser
+		acls[i].SubjectID = user.ID
+		if err := op.CreateACL(&acls[i]); err != nil {
+			t.Fatalf("failed to create acl: %+v", err)
+		}
+	}
+	datas := []struct {
+		path      string
+		operation int32
+		global    bool
+		result    bool
+	}{
+		// no rule applies
+		{path: "/c", operation: model.ACLRemove, global: true, result: true},
+		{path: "/c", operation: model.ACLRemove, global: false, result: false},
+		// the rule narrows the permissions
+		{path: "/a/c", operation: model.ACLWrite, global: true, result: true},
+		{path: "/a/c", operation: model.ACLRename, global: true, result: false},
+		// the rule can't allow what is denied by the permission bits
+		{path: "/a/c", operation: model.ACLRemove, global: false, result: false},
+		// the nearest rule wins
+		{path: "/a/b", operation: model.ACLRead, global: true, result: true},
+		{path: "/a/b", operation: model.ACLWrite, global: true, result: false},
+		// the rule of /a/b isn't inherited
+		{path: "/a/b/c", operation: model.ACLWrite, global: true, result: true},
+	}
+	for i, data := range datas {
+		if CanOperate(user, data.path, data.operation, data.global) != data.result {
+			t.Errorf("TestCanOperate %d failed", i)
+		}
+	}
+}
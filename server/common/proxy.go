@@ -6,9 +6,11 @@ import (
 	"io"
 	"net/http"
 	"net/url"
+	"strings"
 
 	"github.com/alist-org/alist/v3/internal/model"
 	"github.com/alist-org/alist/v3/internal/net"
+	"github.com/alist-org/alist/v3/internal/ratelimit"
 	"github.com/alist-org/alist/v3/internal/stream"
 	"github.com/alist-org/alist/v3/pkg/http_range"
 	"github.com/alist-org/alist/v3/pkg/utils"
@@ -23,7 +25,7 @@ func Proxy(w http.ResponseWriter, r *http.Request, link *model.Link, file model.
 		if contentType != "" {
 			w.Header().Set("Content-Type", contentType)
 		}
-		http.ServeContent(w, r, file.GetName(), file.ModTime(), link.MFile)
+		http.ServeContent(ratelimit.NewResponseWriter(r.Context(), w), r, file.GetName(), file.ModTime(), link.MFile)
 		return nil
 	} else if link.RangeReadCloser != nil {
 		attachFileName(w, file)
@@ -72,7 +74,7 @@ func Proxy(w http.ResponseWriter, r *http.Request, link *model.Link, file model.
 		if r.Method == http.MethodHead {
 			return nil
 		}
-		_, err = io.Copy(w, res.Body)
+		_, err = io.Copy(ratelimit.NewWriter(r.Context(), w), res.Body)
 		if err != nil {
 			return err
 		}
@@ -87,6 +89,17 @@ func attachFileName(w http.ResponseWriter, file model.Obj) {
 
 var NoProxyRange = &model.RangeReadCloser{}
 
+// IsDownloadStart reports whether the request starts a download, the range
+// requests continuing a download and the thumbnails are not counted. It's only
+// for the handlers passing the type to fs.Link, which may return a thumbnail
+func IsDownloadStart(r *http.Request) bool {
+	if r.URL.Query().Get("type") == "thumb" {
+		return false
+	}
+	rng := r.Header.Get("Range")
+	return rng == "" || strings.HasPrefix(rng, "bytes=0-")
+}
+
 func ProxyRange(link *model.Link, size int64) {
 	if link.MFile != nil {
 		return
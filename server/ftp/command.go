@@ -0,0 +1,662 @@
+package ftp
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"net"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/alist-org/alist/v3/internal/audit"
+	"github.com/alist-org/alist/v3/internal/errs"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/internal/op"
+	"github.com/alist-org/alist/v3/pkg/utils"
+	log "github.com/sirupsen/logrus"
+)
+
+type command struct {
+	// handler returns true if the connection should be closed
+	handler func(s *session, param string) bool
+	// open commands can be used before login
+	open bool
+	// param is required
+	param bool
+}
+
+var commands map[string]command
+
+func init() {
+	commands = map[string]command{
+		"USER": {handler: cmdUser, open: true, param: true},
+		"PASS": {handler: cmdPass, open: true},
+		"AUTH": {handler: cmdAuth, open: true, param: true},
+		"PBSZ": {handler: cmdPbsz, open: true, param: true},
+		"PROT": {handler: cmdProt, open: true, param: true},
+		"FEAT": {handler: cmdFeat, open: true},
+		"SYST": {handler: cmdSyst, open: true},
+		"NOOP": {handler: cmdNoop, open: true},
+		"QUIT": {handler: cmdQuit, open: true},
+		"OPTS": {handler: cmdOpts, open: true, param: true},
+		"TYPE": {handler: cmdType, param: true},
+		"MODE": {handler: cmdMode, param: true},
+		"STRU": {handler: cmdStru, param: true},
+		"ALLO": {handler: cmdNoop},
+		"PWD":  {handler: cmdPwd},
+		"XPWD": {handler: cmdPwd},
+		"CWD":  {handler: cmdCwd, param: true},
+		"XCWD": {handler: cmdCwd, param: true},
+		"CDUP": {handler: cmdCdup},
+		"XCUP": {handler: cmdCdup},
+		"PASV": {handler: cmdPasv},
+		"EPSV": {handler: cmdEpsv},
+		"PORT": {handler: cmdPort, param: true},
+		"EPRT": {handler: cmdEprt, param: true},
+		"LIST": {handler: cmdList},
+		"NLST": {handler: cmdNlst},
+		"MLSD": {handler: cmdMlsd},
+		"MLST": {handler: cmdMlst},
+		"SIZE": {handler: cmdSize, param: true},
+		"MDTM": {handler: cmdMdtm, param: true},
+		"REST": {handler: cmdRest, param: true},
+		"RETR": {handler: cmdRetr, param: true},
+		"STOR": {handler: cmdStor, param: true},
+		"APPE": {handler: cmdAppe, param: true},
+		"DELE": {handler: cmdDele, param: true},
+		"MKD":  {handler: cmdMkd, param: true},
+		"XMKD": {handler: cmdMkd, param: true},
+		"RMD":  {handler: cmdRmd, param: true},
+		"XRMD": {handler: cmdRmd, param: true},
+		"RNFR": {handler: cmdRnfr, param: true},
+		"RNTO": {handler: cmdRnto, param: true},
+		"ABOR": {handler: cmdAbor},
+	}
+}
+
+// replyErr replies the error of fs operations
+func (s *session) replyErr(err error) {
+	switch {
+	case errs.IsNotFoundError(err):
+		s.reply(550, "No such file or directory")
+	case errors.Is(err, errs.PermissionDenied):
+		s.reply(550, "Permission denied")
+	case errors.Is(err, errs.RelativePath):
+		s.reply(553, "Relative path is not allowed")
+	case errors.Is(err, errs.NotFolder):
+		s.reply(550, "Not a directory")
+	case errors.Is(err, ErrDirNotEmpty):
+		s.reply(550, "Directory not empty")
+	default:
+		s.reply(550, err.Error())
+	}
+}
+
+func cmdUser(s *session, param string) bool {
+	if s.user != nil {
+		s.reply(530, "Already logged in")
+		return false
+	}
+	s.username = param
+	s.reply(331, "Password required for "+param)
+	return false
+}
+
+func cmdPass(s *session, param string) bool {
+	if s.user != nil {
+		s.reply(230, "Already logged in")
+		return false
+	}
+	if s.username == "" {
+		s.reply(503, "Login with USER first")
+		return false
+	}
+	username := s.username
+	s.username = ""
+	user, err := op.GetUserByName(username)
+	if err != nil || user.ValidateRawPassword(param) != nil {
+		log.Infof("[ftp] failed login of [%s] from %s", username, s.conn.RemoteAddr())
+		s.reply(530, "Login incorrect")
+		return false
+	}
+	if user.Disabled || !user.CanFTPAccess() {
+		s.reply(530, "Permission denied")
+		return false
+	}
+	s.user = user
+	s.reply(230, "Login successful")
+	return false
+}
+
+func cmdAuth(s *session, param string) bool {
+	if s.server.TLSConfig == nil {
+		s.reply(534, "TLS is not configured")
+		return false
+	}
+	if mech := strings.ToUpper(param); mech != "TLS" && mech != "TLS-C" && mech != "SSL" {
+		s.reply(504, "Unsupported AUTH mechanism")
+		return false
+	}
+	if s.isTLS() {
+		s.reply(503, "Already using TLS")
+		return false
+	}
+	s.reply(234, "AUTH TLS successful")
+	if err := s.upgradeTLS(); err != nil {
+		log.Debugf("[ftp] tls handshake failed: %+v", err)
+		return true
+	}
+	return false
+}
+
+func cmdPbsz(s *session, param string) bool {
+	if !s.isTLS() {
+		s.reply(503, "Use AUTH TLS first")
+		return false
+	}
+	s.reply(200, "PBSZ=0")
+	return false
+}
+
+func cmdProt(s *session, param string) bool {
+	if !s.isTLS() {
+		s.reply(503, "Use AUTH TLS first")
+		return false
+	}
+	switch strings.ToUpper(param) {
+	case "P":
+		s.protData = true
+		s.reply(200, "Protection level set to Private")
+	case "C":
+		s.protData = false
+		s.reply(200, "Protection level set to Clear")
+	default:
+		s.reply(504, "Unsupported protection level")
+	}
+	return false
+}
+
+func cmdFeat(s *session, param string) bool {
+	feats := []string{"Features:", "EPRT", "EPSV", "PASV", "MDTM", "MLST type*;size*;modify*;", "REST STREAM", "SIZE", "UTF8"}
+	if s.server.TLSConfig != nil {
+		feats = append(feats, "AUTH TLS", "PBSZ", "PROT")
+	}
+	feats = append(feats, "End")
+	s.reply(211, strings.Join(feats, "\n"))
+	return false
+}
+
+func cmdSyst(s *session, param string) bool {
+	s.reply(215, "UNIX Type: L8")
+	return false
+}
+
+func cmdNoop(s *session, param string) bool {
+	s.reply(200, "OK")
+	return false
+}
+
+func cmdQuit(s *session, param string) bool {
+	s.reply(221, "Goodbye")
+	return true
+}
+
+func cmdOpts(s *session, param string) bool {
+	if strings.ToUpper(param) == "UTF8 ON" {
+		s.reply(200, "UTF8 enabled")
+		return false
+	}
+	s.reply(501, "Unsupported option")
+	return false
+}
+
+func cmdType(s *session, param string) bool {
+	switch strings.ToUpper(param) {
+	case "I", "L 8":
+		s.binary = true
+		s.reply(200, "Type set to I")
+	case "A", "A N":
+		// transfer in binary anyway, as most of the servers do
+		s.binary = false
+		s.reply(200, "Type set to A")
+	default:
+		s.reply(504, "Unsupported type")
+	}
+	return false
+}
+
+func cmdMode(s *session, param string) bool {
+	if strings.ToUpper(param) == "S" {
+		s.reply(200, "Mode set to S")
+	} else {
+		s.reply(504, "Only stream mode is supported")
+	}
+	return false
+}
+
+func cmdStru(s *session, param string) bool {
+	if strings.ToUpper(param) == "F" {
+		s.reply(200, "Structure set to F")
+	} else {
+		s.reply(504, "Only file structure is supported")
+	}
+	return false
+}
+
+func cmdPwd(s *session, param string) bool {
+	s.reply(257, fmt.Sprintf("\"%s\" is the current directory", strings.ReplaceAll(s.cwd, "\"", "\"\"")))
+	return false
+}
+
+func cmdCwd(s *session, param string) bool {
+	p, full, err := s.resolve(param)
+	if err != nil {
+		s.replyErr(err)
+		return false
+	}
+	obj, err := Get(s.userCtx(), full)
+	if err != nil {
+		s.replyErr(err)
+		return false
+	}
+	if !obj.IsDir() {
+		s.reply(550, "Not a directory")
+		return false
+	}
+	s.cwd = p
+	s.reply(250, "Directory changed to "+p)
+	return false
+}
+
+func cmdCdup(s *session, param string) bool {
+	return cmdCwd(s, "..")
+}
+
+func cmdPasv(s *session, param string) bool {
+	ip, port, err := s.openPasv()
+	if err != nil {
+		s.reply(425, "Can't open passive connection")
+		return false
+	}
+	ip4 := ip.To4()
+	if ip4 == nil {
+		s.reply(425, "PASV requires IPv4, use EPSV")
+		s.closePasv()
+		return false
+	}
+	s.reply(227, fmt.Sprintf("Entering Passive Mode (%d,%d,%d,%d,%d,%d)",
+		ip4[0], ip4[1], ip4[2], ip4[3], port>>8, port&0xff))
+	return false
+}
+
+func cmdEpsv(s *session, param string) bool {
+	if strings.ToUpper(param) == "ALL" {
+		s.mu.Lock()
+		s.epsvAll = true
+		s.mu.Unlock()
+		s.reply(200, "EPSV ALL accepted")
+		return false
+	}
+	_, port, err := s.openPasv()
+	if err != nil {
+		s.reply(425, "Can't open passive connection")
+		return false
+	}
+	s.reply(229, fmt.Sprintf("Entering Extended Passive Mode (|||%d|)", port))
+	return false
+}
+
+func cmdPort(s *session, param string) bool {
+	addr, err := parsePort(param)
+	if err != nil {
+		s.reply(501, err.Error())
+		return false
+	}
+	return enterActive(s, addr)
+}
+
+func cmdEprt(s *session, param string) bool {
+	addr, err := parseEprt(param)
+	if err != nil {
+		s.reply(501, err.Error())
+		return false
+	}
+	return enterActive(s, addr)
+}
+
+func enterActive(s *session, addr *net.TCPAddr) bool {
+	s.mu.Lock()
+	epsvAll := s.epsvAll
+	s.mu.Unlock()
+	if epsvAll {
+		s.reply(503, "Only EPSV is allowed after EPSV ALL")
+		return false
+	}
+	if err := s.setActive(addr); err != nil {
+		s.reply(504, "Active mode refused: "+err.Error())
+		return false
+	}
+	s.reply(200, "Active mode entered")
+	return false
+}
+
+// parsePort parses the h1,h2,h3,h4,p1,p2 of PORT
+func parsePort(param string) (*net.TCPAddr, error) {
+	fields := strings.Split(param, ",")
+	if len(fields) != 6 {
+		return nil, errors.New("invalid PORT parameter")
+	}
+	var b [6]byte
+	for i, f := range fields {
+		n, err := strconv.ParseUint(strings.TrimSpace(f), 10, 8)
+		if err != nil {
+			return nil, errors.New("invalid PORT parameter")
+		}
+		b[i] = byte(n)
+	}
+	return &net.TCPAddr{
+		IP:   net.IPv4(b[0], b[1], b[2], b[3]),
+		Port: int(b[4])<<8 | int(b[5]),
+	}, nil
+}
+
+// parseEprt parses the <d><proto><d><addr><d><port><d> of EPRT, the delimiter is usually |
+func parseEprt(param string) (*net.TCPAddr, error) {
+	if len(param) < 2 {
+		return nil, errors.New("invalid EPRT parameter")
+	}
+	fields := strings.Split(param[1:], param[:1])
+	if len(fields) != 4 || fields[3] != "" {
+		return nil, errors.New("invalid EPRT parameter")
+	}
+	ip := net.ParseIP(fields[1])
+	if ip == nil || (fields[0] == "1") != (ip.To4() != nil) || (fields[0] != "1" && fields[0] != "2") {
+		return nil, errors.New("invalid EPRT address")
+	}
+	port, err := strconv.Atoi(fields[2])
+	if err != nil || port <= 0 || port > 65535 {
+		return nil, errors.New("invalid EPRT port")
+	}
+	return &net.TCPAddr{IP: ip, Port: port}, nil
+}
+
+// listArg strips the options like -a -l of LIST and NLST
+func listArg(param string) string {
+	fields := strings.Fields(param)
+	var args []string
+	for _, f := range fields {
+		if strings.HasPrefix(f, "-") {
+			continue
+		}
+		args = append(args, f)
+	}
+	return strings.Join(args, " ")
+}
+
+// listObjs returns the objs of dir, or the obj itself if it's a file
+func (s *session) listObjs(param string) ([]model.Obj, error) {
+	_, full, err := s.resolve(param)
+	if err != nil {
+		return nil, err
+	}
+	obj, err := Get(s.userCtx(), full)
+	if err != nil {
+		return nil, err
+	}
+	if !obj.IsDir() {
+		return []model.Obj{obj}, nil
+	}
+	objs, err := List(s.userCtx(), full)
+	if err != nil {
+		return nil, err
+	}
+	sort.SliceStable(objs, func(i, j int) bool {
+		return objs[i].GetName() < objs[j].GetName()
+	})
+	return objs, nil
+}
+
+func (s *session) sendList(param string, format func(obj model.Obj) string) {
+	objs, err := s.listObjs(param)
+	if err != nil {
+		s.closePasv()
+		s.replyErr(err)
+		return
+	}
+	s.transfer(func(conn net.Conn) error {
+		w := bufio.NewWriter(conn)
+		for _, obj := range objs {
+			if _, err := w.WriteString(format(obj)); err != nil {
+				return err
+			}
+		}
+		return w.Flush()
+	})
+}
+
+func cmdList(s *session, param string) bool {
+	s.sendList(listArg(param), formatList)
+	return false
+}
+
+func cmdNlst(s *session, param string) bool {
+	s.sendList(listArg(param), func(obj model.Obj) string {
+		return obj.GetName() + "\r\n"
+	})
+	return false
+}
+
+func cmdMlsd(s *session, param string) bool {
+	s.sendList(param, func(obj model.Obj) string {
+		return formatFacts(obj, obj.GetName()) + "\r\n"
+	})
+	return false
+}
+
+func cmdMlst(s *session, param string) bool {
+	p, full, err := s.resolve(param)
+	if err != nil {
+		s.replyErr(err)
+		return false
+	}
+	obj, err := Get(s.userCtx(), full)
+	if err != nil {
+		s.replyErr(err)
+		return false
+	}
+	s.reply(250, fmt.Sprintf("Listing %s\n%s\nEnd", p, formatFacts(obj, p)))
+	return false
+}
+
+// getFile returns the file of param, replies the error if it's not a file
+func (s *session) getFile(param string) (string, model.Obj, bool) {
+	_, full, err := s.resolve(param)
+	if err != nil {
+		s.replyErr(err)
+		return "", nil, false
+	}
+	obj, err := Get(s.userCtx(), full)
+	if err != nil {
+		s.replyErr(err)
+		return "", nil, false
+	}
+	if obj.IsDir() {
+		s.reply(550, "Not a regular file")
+		return "", nil, false
+	}
+	return full, obj, true
+}
+
+func cmdSize(s *session, param string) bool {
+	if _, obj, ok := s.getFile(param); ok {
+		s.reply(213, strconv.FormatInt(obj.GetSize(), 10))
+	}
+	return false
+}
+
+func cmdMdtm(s *session, param string) bool {
+	if _, obj, ok := s.getFile(param); ok {
+		s.reply(213, obj.ModTime().UTC().Format("20060102150405"))
+	}
+	return false
+}
+
+func cmdRest(s *session, param string) bool {
+	offset, err := strconv.ParseInt(param, 10, 64)
+	if err != nil || offset < 0 {
+		s.reply(501, "Invalid offset")
+		return false
+	}
+	s.restOffset = offset
+	s.reply(350, fmt.Sprintf("Restarting at %d", offset))
+	return false
+}
+
+func cmdRetr(s *session, param string) bool {
+	full, obj, ok := s.getFile(param)
+	if !ok {
+		s.closePasv()
+		return false
+	}
+	offset := s.restOffset
+	if offset > obj.GetSize() {
+		s.closePasv()
+		s.reply(554, "Invalid REST offset")
+		return false
+	}
+	ctx := s.userCtx()
+	reader, err := OpenReader(ctx, full, obj, offset)
+	if offset == 0 {
+		audit.Record(ctx, model.AuditDownload, full, "", err)
+	}
+	if err != nil {
+		s.closePasv()
+		s.reply(550, err.Error())
+		return false
+	}
+	defer reader.Close()
+	s.transfer(func(conn net.Conn) error {
+		_, err := utils.CopyWithBuffer(conn, reader)
+		return err
+	})
+	return false
+}
+
+func (s *session) store(param string, appendMode bool) {
+	_, full, err := s.resolve(param)
+	if err != nil {
+		s.closePasv()
+		s.replyErr(err)
+		return
+	}
+	offset := s.restOffset
+	if appendMode {
+		offset = 0
+		if obj, err := Get(s.userCtx(), full); err == nil && !obj.IsDir() {
+			offset = obj.GetSize()
+		}
+	}
+	s.transfer(func(conn net.Conn) error {
+		return Upload(s.userCtx(), full, conn, offset)
+	})
+}
+
+func cmdStor(s *session, param string) bool {
+	s.store(param, false)
+	return false
+}
+
+func cmdAppe(s *session, param string) bool {
+	s.store(param, true)
+	return false
+}
+
+func cmdDele(s *session, param string) bool {
+	full, _, ok := s.getFile(param)
+	if !ok {
+		return false
+	}
+	if err := Remove(s.userCtx(), full); err != nil {
+		s.replyErr(err)
+		return false
+	}
+	s.reply(250, "File deleted")
+	return false
+}
+
+func cmdMkd(s *session, param string) bool {
+	p, full, err := s.resolve(param)
+	if err == nil {
+		err = MakeDir(s.userCtx(), full)
+	}
+	if err != nil {
+		s.replyErr(err)
+		return false
+	}
+	s.reply(257, fmt.Sprintf("\"%s\" created", strings.ReplaceAll(p, "\"", "\"\"")))
+	return false
+}
+
+func cmdRmd(s *session, param string) bool {
+	p, full, err := s.resolve(param)
+	if err != nil {
+		s.replyErr(err)
+		return false
+	}
+	if p == "/" {
+		s.reply(550, "Can't remove the root directory")
+		return false
+	}
+	if _, err = Get(s.userCtx(), full); err != nil {
+		s.replyErr(err)
+		return false
+	}
+	if err = RemoveDir(s.userCtx(), full); err != nil {
+		s.replyErr(err)
+		return false
+	}
+	s.reply(250, "Directory removed")
+	return false
+}
+
+func cmdRnfr(s *session, param string) bool {
+	_, full, err := s.resolve(param)
+	if err == nil {
+		_, err = Get(s.userCtx(), full)
+	}
+	if err != nil {
+		s.renameFrom = ""
+		s.replyErr(err)
+		return false
+	}
+	s.renameFrom = full
+	s.reply(350, "Ready for RNTO")
+	return false
+}
+
+func cmdRnto(s *session, param string) bool {
+	src := s.renameFrom
+	if src == "" {
+		s.reply(503, "Use RNFR first")
+		return false
+	}
+	_, dst, err := s.resolve(param)
+	if err != nil {
+		s.replyErr(err)
+		return false
+	}
+	if err = Rename(s.userCtx(), src, dst); err != nil {
+		s.replyErr(err)
+		return false
+	}
+	s.reply(250, "Rename successful")
+	return false
+}
+
+func cmdAbor(s *session, param string) bool {
+	// transfers are synchronous, there's nothing in progress to abort
+	s.closePasv()
+	s.reply(226, "ABOR successful")
+	return false
+}
@@ -0,0 +1,297 @@
+package ftp
+
+import (
+	"context"
+	stderrors "errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	stdpath "path"
+	"strings"
+	"time"
+
+	"github.com/alist-org/alist/v3/internal/conf"
+	"github.com/alist-org/alist/v3/internal/errs"
+	"github.com/alist-org/alist/v3/internal/fs"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/internal/op"
+	"github.com/alist-org/alist/v3/internal/stream"
+	"github.com/alist-org/alist/v3/pkg/http_range"
+	"github.com/alist-org/alist/v3/pkg/utils"
+	"github.com/alist-org/alist/v3/server/common"
+	"github.com/pkg/errors"
+)
+
+// resolve returns the user relative path and the full alist path of p
+func (s *session) resolve(p string) (string, string, error) {
+	if !strings.HasPrefix(p, "/") {
+		p = stdpath.Join(s.cwd, p)
+	}
+	p = utils.FixAndCleanPath(p)
+	full, err := s.user.JoinPath(p)
+	return p, full, err
+}
+
+func (s *session) userCtx() context.Context {
+	ctx := context.WithValue(s.ctx, "user", s.user)
+	ctx = context.WithValue(ctx, conf.FrontendKey, model.FrontendFTP)
+	return context.WithValue(ctx, conf.ClientIPKey, remoteIP(s.conn))
+}
+
+func remoteIP(conn net.Conn) string {
+	host, _, err := net.SplitHostPort(conn.RemoteAddr().String())
+	if err != nil {
+		return conn.RemoteAddr().String()
+	}
+	return host
+}
+
+// the functions below operate on the full alist path with the user in ctx,
+// they are shared by the ftp and sftp servers
+
+func getMeta(user *model.User, path string) (*model.Meta, error) {
+	meta, err := op.GetNearestUserMeta(user, path)
+	if err != nil && !errors.Is(errors.Cause(err), errs.MetaNotFound) {
+		return nil, err
+	}
+	return meta, nil
+}
+
+// checkRead checks whether the user can access the path, the clients
+// can't provide the password of meta, so only the users who can access
+// without password are allowed to enter the protected folders
+func checkRead(ctx context.Context, path string) (*model.Meta, error) {
+	user := ctx.Value("user").(*model.User)
+	meta, err := getMeta(user, path)
+	if err != nil {
+		return nil, err
+	}
+	if !common.CanAccess(user, meta, path, "") {
+		return nil, errs.PermissionDenied
+	}
+	return meta, nil
+}
+
+// CheckWrite checks whether the user can mkdir or upload in dir
+func CheckWrite(ctx context.Context, dir string) error {
+	user := ctx.Value("user").(*model.User)
+	if !user.CanFTPManage() {
+		return errs.PermissionDenied
+	}
+	canWrite := user.CanWrite()
+	if !canWrite {
+		meta, err := getMeta(user, dir)
+		if err != nil {
+			return err
+		}
+		canWrite = common.CanWrite(meta, dir)
+	}
+	if !common.CanOperate(user, dir, model.ACLWrite, canWrite) {
+		return errs.PermissionDenied
+	}
+	return nil
+}
+
+func Get(ctx context.Context, path string) (model.Obj, error) {
+	if _, err := checkRead(ctx, path); err != nil {
+		return nil, err
+	}
+	return fs.Get(ctx, path, &fs.GetArgs{NoLog: true})
+}
+
+func List(ctx context.Context, path string) ([]model.Obj, error) {
+	meta, err := checkRead(ctx, path)
+	if err != nil {
+		return nil, err
+	}
+	objs, err := fs.List(context.WithValue(ctx, "meta", meta), path, &fs.ListArgs{NoLog: true})
+	if err != nil {
+		return nil, err
+	}
+	return common.FilterReadable(ctx.Value("user").(*model.User), path, objs), nil
+}
+
+// OpenReader returns a reader of obj which starts at offset
+func OpenReader(ctx context.Context, path string, obj model.Obj, offset int64) (io.ReadCloser, error) {
+	link, _, err := fs.Link(ctx, path, model.LinkArgs{})
+	if err != nil {
+		return nil, err
+	}
+	size := obj.GetSize()
+	if offset > size {
+		offset = size
+	}
+	if link.MFile != nil {
+		if _, err = link.MFile.Seek(offset, io.SeekStart); err != nil {
+			_ = link.MFile.Close()
+			return nil, err
+		}
+		return link.MFile, nil
+	}
+	rrc := link.RangeReadCloser
+	if rrc == nil {
+		rrc, err = stream.GetRangeReadCloserFromLink(size, link)
+		if err != nil {
+			return nil, err
+		}
+	}
+	rc, err := rrc.RangeRead(ctx, http_range.Range{Start: offset, Length: size - offset})
+	if err != nil {
+		_ = rrc.Close()
+		return nil, err
+	}
+	return utils.NewReadCloser(rc, func() error {
+		return stderrors.Join(rc.Close(), rrc.Close())
+	}), nil
+}
+
+// Upload caches r to a temp file since the size is unknown,
+// then puts it to path. If offset > 0, the first offset bytes of the
+// existing file are kept to resume the upload.
+func Upload(ctx context.Context, path string, r io.Reader, offset int64) error {
+	if err := CheckWrite(ctx, stdpath.Dir(path)); err != nil {
+		return err
+	}
+	if offset > 0 {
+		obj, err := Get(ctx, path)
+		if err != nil {
+			return err
+		}
+		if offset > obj.GetSize() {
+			return errors.Errorf("resume offset %d is larger than the file size %d", offset, obj.GetSize())
+		}
+		head, err := OpenReader(ctx, path, obj, 0)
+		if err != nil {
+			return err
+		}
+		defer head.Close()
+		r = io.MultiReader(io.LimitReader(head, offset), r)
+	}
+	tmpFile, err := utils.CreateTempFile(r, 0)
+	if err != nil {
+		return err
+	}
+	return PutTempFile(ctx, path, tmpFile)
+}
+
+// PutTempFile puts the content of tmpFile to path, tmpFile is removed after that
+func PutTempFile(ctx context.Context, path string, tmpFile *os.File) error {
+	info, err := tmpFile.Stat()
+	if err != nil {
+		_ = tmpFile.Close()
+		_ = os.Remove(tmpFile.Name())
+		return errors.WithStack(err)
+	}
+	fileStream := &stream.FileStream{
+		Obj: &model.Object{
+			Name:     stdpath.Base(path),
+			Size:     info.Size(),
+			Modified: time.Now(),
+		},
+		Reader:   tmpFile,
+		Mimetype: utils.GetMimeType(path),
+	}
+	fileStream.Closers.Add(tmpFile)
+	fileStream.SetTmpFile(tmpFile)
+	defer fileStream.Close()
+	return fs.PutDirectly(ctx, stdpath.Dir(path), fileStream)
+}
+
+func MakeDir(ctx context.Context, path string) error {
+	if err := CheckWrite(ctx, stdpath.Dir(path)); err != nil {
+		return err
+	}
+	return fs.MakeDir(ctx, path)
+}
+
+// ErrDirNotEmpty is returned when removing a dir which is not empty, as RMD of RFC 959 requires
+var ErrDirNotEmpty = stderrors.New("directory not empty")
+
+func Remove(ctx context.Context, path string) error {
+	user := ctx.Value("user").(*model.User)
+	if !user.CanFTPManage() || !common.CanOperate(user, path, model.ACLRemove, user.CanRemove()) {
+		return errs.PermissionDenied
+	}
+	return fs.Remove(ctx, path)
+}
+
+// RemoveDir removes the dir at path only if it's empty
+func RemoveDir(ctx context.Context, path string) error {
+	user := ctx.Value("user").(*model.User)
+	if !user.CanFTPManage() || !common.CanOperate(user, path, model.ACLRemove, user.CanRemove()) {
+		return errs.PermissionDenied
+	}
+	obj, err := fs.Get(ctx, path, &fs.GetArgs{NoLog: true})
+	if err != nil {
+		return err
+	}
+	if !obj.IsDir() {
+		return errs.NotFolder
+	}
+	// the hidden objs are counted too
+	objs, err := fs.List(ctx, path, &fs.ListArgs{NoLog: true, Refresh: true})
+	if err != nil {
+		return err
+	}
+	if len(objs) > 0 {
+		return ErrDirNotEmpty
+	}
+	return fs.Remove(ctx, path)
+}
+
+// Rename renames src to dst, the file is moved first if the dirs are different.
+// all permissions are checked before anything is changed
+func Rename(ctx context.Context, src, dst string) error {
+	user := ctx.Value("user").(*model.User)
+	if !user.CanFTPManage() {
+		return errs.PermissionDenied
+	}
+	srcDir, srcName := stdpath.Split(src)
+	dstDir, dstName := stdpath.Split(dst)
+	moving := !utils.PathEqual(srcDir, dstDir)
+	if moving {
+		if !common.CanOperate(user, src, model.ACLMove, user.CanMove()) {
+			return errs.PermissionDenied
+		}
+		if err := CheckWrite(ctx, dstDir); err != nil {
+			return err
+		}
+	}
+	if srcName != dstName && !common.CanOperate(user, src, model.ACLRename, user.CanRename()) {
+		return errs.PermissionDenied
+	}
+	if moving {
+		if _, err := fs.Move(context.WithValue(ctx, conf.NoTaskKey, struct{}{}), src, dstDir); err != nil {
+			return err
+		}
+	}
+	if srcName == dstName {
+		return nil
+	}
+	return fs.Rename(ctx, stdpath.Join(dstDir, srcName), dstName)
+}
+
+// formatList formats obj like the output of `ls -l`
+func formatList(obj model.Obj) string {
+	mode := "-rw-r--r--"
+	if obj.IsDir() {
+		mode = "drwxr-xr-x"
+	}
+	modified := obj.ModTime()
+	timeStr := modified.Format("Jan _2 15:04")
+	if time.Since(modified) > 180*24*time.Hour || modified.After(time.Now()) {
+		timeStr = modified.Format("Jan _2  2006")
+	}
+	return fmt.Sprintf("%s 1 alist alist %12d %s %s\r\n", mode, obj.GetSize(), timeStr, obj.GetName())
+}
+
+// formatFacts formats obj in the machine readable format of MLSD/MLST
+func formatFacts(obj model.Obj, name string) string {
+	typ := "file"
+	if obj.IsDir() {
+		typ = "dir"
+	}
+	return fmt.Sprintf("type=%s;size=%d;modify=%s; %s",
+		typ, obj.GetSize(), obj.ModTime().UTC().Format("20060102150405"), name)
+}
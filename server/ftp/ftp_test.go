@@ -0,0 +1,245 @@
+package ftp
+
+import (
+	"context"
+	"io"
+	"net"
+	"net/textproto"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+
+	_ "github.com/alist-org/alist/v3/drivers/local"
+	"github.com/alist-org/alist/v3/internal/conf"
+	"github.com/alist-org/alist/v3/internal/db"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/internal/op"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+func init() {
+	dB, err := gorm.Open(sqlite.Open("file::memory:?cache=shared"), &gorm.Config{})
+	if err != nil {
+		panic("failed to connect database")
+	}
+	conf.Conf = conf.DefaultConfig()
+	db.Init(dB)
+}
+
+func TestParseLine(t *testing.T) {
+	tests := []struct {
+		line, cmd, param string
+	}{
+		{"USER alice\r\n", "USER", "alice"},
+		{"stor a file.txt\r\n", "STOR", "a file.txt"},
+		{"PASV\n", "PASV", ""},
+		{"\r\n", "", ""},
+	}
+	for _, tt := range tests {
+		cmd, param := parseLine(tt.line)
+		if cmd != tt.cmd || param != tt.param {
+			t.Errorf("parseLine(%q) = %q, %q, want %q, %q", tt.line, cmd, param, tt.cmd, tt.param)
+		}
+	}
+}
+
+func TestParsePortRange(t *testing.T) {
+	tests := []struct {
+		r        string
+		min, max int
+		isErr    bool
+	}{
+		{r: "30000-30010", min: 30000, max: 30010},
+		{r: " 2121 ", min: 2121, max: 2121},
+		{r: "30010-30000", isErr: true},
+		{r: "0-10", isErr: true},
+		{r: "1-65536", isErr: true},
+		{r: "a-b", isErr: true},
+	}
+	for _, tt := range tests {
+		min, max, err := parsePortRange(tt.r)
+		if (err != nil) != tt.isErr {
+			t.Errorf("parsePortRange(%q) error = %v, want error: %v", tt.r, err, tt.isErr)
+			continue
+		}
+		if !tt.isErr && (min != tt.min || max != tt.max) {
+			t.Errorf("parsePortRange(%q) = %d, %d, want %d, %d", tt.r, min, max, tt.min, tt.max)
+		}
+	}
+}
+
+func TestParsePort(t *testing.T) {
+	tests := []struct {
+		param string
+		addr  string
+		isErr bool
+	}{
+		{param: "127,0,0,1,4,1", addr: "127.0.0.1:1025"},
+		{param: "192,168,1,2,117,48", addr: "192.168.1.2:30000"},
+		{param: "127,0,0,1,4", isErr: true},
+		{param: "127,0,0,256,4,1", isErr: true},
+		{param: "127,0,0,1,-1,1", isErr: true},
+	}
+	for _, tt := range tests {
+		addr, err := parsePort(tt.param)
+		if (err != nil) != tt.isErr {
+			t.Errorf("parsePort(%q) error = %v, want error: %v", tt.param, err, tt.isErr)
+			continue
+		}
+		if !tt.isErr && addr.String() != tt.addr {
+			t.Errorf("parsePort(%q) = %s, want %s", tt.param, addr, tt.addr)
+		}
+	}
+}
+
+func TestParseEprt(t *testing.T) {
+	tests := []struct {
+		param string
+		addr  string
+		isErr bool
+	}{
+		{param: "|1|127.0.0.1|1025|", addr: "127.0.0.1:1025"},
+		{param: "|2|::1|30000|", addr: "[::1]:30000"},
+		{param: "!1!10.0.0.1!2000!", addr: "10.0.0.1:2000"},
+		{param: "|2|127.0.0.1|1025|", isErr: true},
+		{param: "|1|::1|1025|", isErr: true},
+		{param: "|3|127.0.0.1|1025|", isErr: true},
+		{param: "|1|127.0.0.1|0|", isErr: true},
+		{param: "|1|127.0.0.1|1025", isErr: true},
+		{param: "|", isErr: true},
+	}
+	for _, tt := range tests {
+		addr, err := parseEprt(tt.param)
+		if (err != nil) != tt.isErr {
+			t.Errorf("parseEprt(%q) error = %v, want error: %v", tt.param, err, tt.isErr)
+			continue
+		}
+		if !tt.isErr && addr.String() != tt.addr {
+			t.Errorf("parseEprt(%q) = %s, want %s", tt.param, addr, tt.addr)
+		}
+	}
+}
+
+// setupServer serves a Local storage in a temp dir at /local and returns the address
+func setupServer(t *testing.T) (string, string) {
+	root := t.TempDir()
+	id, err := op.CreateStorage(context.Background(), model.Storage{
+		Driver:    "Local",
+		MountPath: "/local",
+		Addition:  `{"root_folder_path":"` + filepath.ToSlash(root) + `"}`,
+	})
+	if err != nil {
+		t.Fatalf("failed to create storage: %+v", err)
+	}
+	user := &model.User{
+		Username:   "ftp",
+		BasePath:   "/",
+		Role:       model.GENERAL,
+		Permission: 1<<3 | 1<<4 | 1<<5 | 1<<7 | 1<<10 | 1<<11,
+	}
+	if err = op.CreateUser(user.SetPassword("ftp")); err != nil {
+		t.Fatalf("failed to create user: %+v", err)
+	}
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := &Server{sessions: make(map[*session]struct{})}
+	go func() { _ = s.Serve(l) }()
+	t.Cleanup(func() {
+		_ = s.Close()
+		_ = op.DeleteStorageById(context.Background(), id)
+		_ = op.DeleteUserById(user.ID)
+	})
+	return l.Addr().String(), root
+}
+
+func dial(t *testing.T, addr string) *textproto.Conn {
+	c, err := textproto.Dial("tcp", addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = c.Close() })
+	expect(t, c, "", 220)
+	expect(t, c, "USER ftp", 331)
+	expect(t, c, "PASS ftp", 230)
+	return c
+}
+
+// expect sends the command if it's not empty and checks the code of reply
+func expect(t *testing.T, c *textproto.Conn, cmd string, code int) string {
+	t.Helper()
+	if cmd != "" {
+		if err := c.PrintfLine("%s", cmd); err != nil {
+			t.Fatal(err)
+		}
+	}
+	got, msg, err := c.ReadResponse(0)
+	if got != code {
+		t.Fatalf("%s: got %d %s (%v), want %d", cmd, got, msg, err, code)
+	}
+	return msg
+}
+
+func TestActiveMode(t *testing.T) {
+	addr, root := setupServer(t)
+	if err := os.WriteFile(filepath.Join(root, "a.txt"), []byte("hello"), 0666); err != nil {
+		t.Fatal(err)
+	}
+	c := dial(t, addr)
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+	port := l.Addr().(*net.TCPAddr).Port
+	tests := []struct {
+		cmd  string
+		code int
+	}{
+		{"PORT 10,0,0,1,4,1", 504},
+		{"PORT 127,0,0,1,0,21", 504},
+		{"EPRT |1|10.0.0.1|2000|", 504},
+		{"EPRT |1|127.0.0.1|" + strconv.Itoa(port) + "|", 200},
+	}
+	for _, tt := range tests {
+		expect(t, c, tt.cmd, tt.code)
+	}
+	expect(t, c, "RETR /local/a.txt", 150)
+	conn, err := l.Accept()
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := io.ReadAll(conn)
+	_ = conn.Close()
+	if err != nil || string(data) != "hello" {
+		t.Errorf("RETR got %q, %v, want hello", data, err)
+	}
+	expect(t, c, "", 226)
+	// the active address is only used once
+	expect(t, c, "RETR /local/a.txt", 150)
+	expect(t, c, "", 425)
+}
+
+func TestRmdNotEmpty(t *testing.T) {
+	addr, root := setupServer(t)
+	if err := os.MkdirAll(filepath.Join(root, "full"), 0777); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(root, "full", "a.txt"), []byte("hello"), 0666); err != nil {
+		t.Fatal(err)
+	}
+	c := dial(t, addr)
+	msg := expect(t, c, "RMD /local/full", 550)
+	if !strings.Contains(msg, "not empty") {
+		t.Errorf("RMD of non-empty dir replied %q", msg)
+	}
+	if _, err := os.Stat(filepath.Join(root, "full", "a.txt")); err != nil {
+		t.Errorf("the file in the dir is removed: %v", err)
+	}
+	expect(t, c, "DELE /local/full/a.txt", 250)
+	expect(t, c, "RMD /local/full", 250)
+}
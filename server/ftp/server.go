@@ -0,0 +1,156 @@
+package ftp
+
+import (
+	"crypto/tls"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/alist-org/alist/v3/internal/conf"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+)
+
+// Server is a ftp server that serves the alist virtual file system,
+// it supports passive and active mode, explicit TLS (AUTH TLS) and REST resume
+type Server struct {
+	Addr        string
+	PublicHost  string
+	IdleTimeout time.Duration
+	TLSConfig   *tls.Config
+
+	pasvMin, pasvMax int
+
+	mu       sync.Mutex
+	listener net.Listener
+	sessions map[*session]struct{}
+	closed   bool
+}
+
+func NewServer() (*Server, error) {
+	c := conf.Conf.FTP
+	s := &Server{
+		Addr:        fmt.Sprintf("%s:%d", conf.Conf.Scheme.Address, c.Port),
+		PublicHost:  c.PublicHost,
+		IdleTimeout: time.Duration(c.IdleTimeout) * time.Second,
+		sessions:    make(map[*session]struct{}),
+	}
+	if c.PasvPortRange != "" {
+		min, max, err := parsePortRange(c.PasvPortRange)
+		if err != nil {
+			return nil, err
+		}
+		s.pasvMin, s.pasvMax = min, max
+	}
+	if c.TLS {
+		cert, err := tls.LoadX509KeyPair(conf.Conf.Scheme.CertFile, conf.Conf.Scheme.KeyFile)
+		if err != nil {
+			return nil, errors.WithMessage(err, "failed load tls certificate")
+		}
+		s.TLSConfig = &tls.Config{
+			Certificates: []tls.Certificate{cert},
+		}
+	}
+	return s, nil
+}
+
+func parsePortRange(r string) (int, int, error) {
+	parts := strings.SplitN(r, "-", 2)
+	min, err := strconv.Atoi(strings.TrimSpace(parts[0]))
+	if err != nil {
+		return 0, 0, errors.Errorf("invalid pasv port range: %s", r)
+	}
+	max := min
+	if len(parts) == 2 {
+		max, err = strconv.Atoi(strings.TrimSpace(parts[1]))
+		if err != nil {
+			return 0, 0, errors.Errorf("invalid pasv port range: %s", r)
+		}
+	}
+	if min <= 0 || max > 65535 || min > max {
+		return 0, 0, errors.Errorf("invalid pasv port range: %s", r)
+	}
+	return min, max, nil
+}
+
+func (s *Server) ListenAndServe() error {
+	l, err := net.Listen("tcp", s.Addr)
+	if err != nil {
+		return err
+	}
+	return s.Serve(l)
+}
+
+func (s *Server) Serve(l net.Listener) error {
+	s.mu.Lock()
+	if s.closed {
+		s.mu.Unlock()
+		return net.ErrClosed
+	}
+	s.listener = l
+	s.mu.Unlock()
+	for {
+		conn, err := l.Accept()
+		if err != nil {
+			s.mu.Lock()
+			closed := s.closed
+			s.mu.Unlock()
+			if closed {
+				return nil
+			}
+			var ne net.Error
+			if errors.As(err, &ne) && ne.Timeout() {
+				time.Sleep(50 * time.Millisecond)
+				continue
+			}
+			return err
+		}
+		sess := newSession(s, conn)
+		s.mu.Lock()
+		s.sessions[sess] = struct{}{}
+		s.mu.Unlock()
+		go func() {
+			sess.serve()
+			s.mu.Lock()
+			delete(s.sessions, sess)
+			s.mu.Unlock()
+		}()
+	}
+}
+
+// Close stops accepting new connections and closes all the sessions
+func (s *Server) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.closed = true
+	for sess := range s.sessions {
+		sess.close()
+	}
+	if s.listener != nil {
+		return s.listener.Close()
+	}
+	return nil
+}
+
+// listenPasv listens on a port for the passive data connection
+func (s *Server) listenPasv(ip string) (net.Listener, error) {
+	if s.pasvMax == 0 {
+		return net.Listen("tcp", net.JoinHostPort(ip, "0"))
+	}
+	var err error
+	n := s.pasvMax - s.pasvMin + 1
+	start := int(time.Now().UnixNano() % int64(n))
+	for i := 0; i < n; i++ {
+		port := s.pasvMin + (start+i)%n
+		var l net.Listener
+		l, err = net.Listen("tcp", net.JoinHostPort(ip, strconv.Itoa(port)))
+		if err == nil {
+			return l, nil
+		}
+	}
+	log.Warnf("[ftp] no available pasv port in %d-%d: %+v", s.pasvMin, s.pasvMax, err)
+	return nil, err
+}
@@ -0,0 +1,294 @@
+package ftp
+
+import (
+	"bufio"
+	"context"
+	"crypto/tls"
+	"fmt"
+	"io"
+	"net"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+)
+
+// session is a ftp control connection
+type session struct {
+	server *Server
+	conn   net.Conn
+	reader *bufio.Reader
+	writer *bufio.Writer
+
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	// pending username of USER
+	username string
+	user     *model.User
+	// current working directory, relative to the base path of user
+	cwd        string
+	binary     bool
+	restOffset int64
+	renameFrom string
+	// PROT P is set, data connections should be secured
+	protData bool
+
+	// mu guards conn, which is replaced by AUTH TLS and closed by Server.Close,
+	// and the pending data connection
+	mu   sync.Mutex
+	pasv net.Listener
+	// the address of client to connect for the active mode, set by PORT or EPRT
+	active *net.TCPAddr
+	// EPSV ALL is sent, PORT and EPRT are refused
+	epsvAll bool
+}
+
+func newSession(server *Server, conn net.Conn) *session {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &session{
+		server: server,
+		conn:   conn,
+		reader: bufio.NewReader(conn),
+		writer: bufio.NewWriter(conn),
+		ctx:    ctx,
+		cancel: cancel,
+		cwd:    "/",
+	}
+}
+
+func (s *session) serve() {
+	defer s.close()
+	s.reply(220, "Welcome to AList FTP server")
+	for {
+		if s.server.IdleTimeout > 0 {
+			_ = s.conn.SetReadDeadline(time.Now().Add(s.server.IdleTimeout))
+		}
+		line, err := s.reader.ReadString('\n')
+		if err != nil {
+			var ne net.Error
+			if errors.As(err, &ne) && ne.Timeout() {
+				s.reply(421, "Idle timeout, closing control connection")
+			} else if !errors.Is(err, io.EOF) && !errors.Is(err, net.ErrClosed) {
+				log.Debugf("[ftp] read command error: %+v", err)
+			}
+			return
+		}
+		cmd, param := parseLine(line)
+		if cmd == "" {
+			continue
+		}
+		if cmd == "PASS" {
+			log.Debugf("[ftp] %s PASS ***", s.conn.RemoteAddr())
+		} else {
+			log.Debugf("[ftp] %s %s %s", s.conn.RemoteAddr(), cmd, param)
+		}
+		c, ok := commands[cmd]
+		if !ok {
+			s.reply(502, fmt.Sprintf("Command %s not implemented", cmd))
+			continue
+		}
+		if !c.open && s.user == nil {
+			s.reply(530, "Please login with USER and PASS")
+			continue
+		}
+		if c.param && param == "" {
+			s.reply(501, "Syntax error in parameters or arguments")
+			continue
+		}
+		if c.handler(s, param) {
+			return
+		}
+		if cmd != "REST" && cmd != "RNFR" {
+			s.resetPending()
+		}
+	}
+}
+
+// resetPending clears the state that is only valid for the next command
+func (s *session) resetPending() {
+	s.restOffset = 0
+	s.renameFrom = ""
+}
+
+func parseLine(line string) (string, string) {
+	line = strings.TrimRight(line, "\r\n")
+	cmd, param, _ := strings.Cut(line, " ")
+	return strings.ToUpper(cmd), param
+}
+
+func (s *session) reply(code int, msg string) {
+	lines := strings.Split(msg, "\n")
+	for i, l := range lines {
+		if i == len(lines)-1 {
+			_, _ = fmt.Fprintf(s.writer, "%d %s\r\n", code, l)
+		} else if i == 0 {
+			_, _ = fmt.Fprintf(s.writer, "%d-%s\r\n", code, l)
+		} else {
+			_, _ = fmt.Fprintf(s.writer, " %s\r\n", l)
+		}
+	}
+	if err := s.writer.Flush(); err != nil {
+		log.Debugf("[ftp] write reply error: %+v", err)
+	}
+}
+
+// upgradeTLS secures the control connection after AUTH TLS
+func (s *session) upgradeTLS() error {
+	tlsConn := tls.Server(s.conn, s.server.TLSConfig)
+	if err := tlsConn.HandshakeContext(s.ctx); err != nil {
+		return err
+	}
+	s.mu.Lock()
+	s.conn = tlsConn
+	s.mu.Unlock()
+	s.reader = bufio.NewReader(tlsConn)
+	s.writer = bufio.NewWriter(tlsConn)
+	return nil
+}
+
+func (s *session) isTLS() bool {
+	_, ok := s.conn.(*tls.Conn)
+	return ok
+}
+
+// openPasv starts listening for a passive data connection and returns the port
+func (s *session) openPasv() (net.IP, int, error) {
+	s.closePasv()
+	host, _, err := net.SplitHostPort(s.conn.LocalAddr().String())
+	if err != nil {
+		return nil, 0, err
+	}
+	l, err := s.server.listenPasv(host)
+	if err != nil {
+		return nil, 0, err
+	}
+	s.mu.Lock()
+	s.pasv = l
+	s.mu.Unlock()
+	ip := net.ParseIP(host)
+	if s.server.PublicHost != "" {
+		if pub := net.ParseIP(s.server.PublicHost); pub != nil {
+			ip = pub
+		} else if addrs, err := net.LookupIP(s.server.PublicHost); err == nil && len(addrs) > 0 {
+			ip = addrs[0]
+		}
+	}
+	return ip, l.Addr().(*net.TCPAddr).Port, nil
+}
+
+// closePasv closes the passive listener and forgets the active address
+func (s *session) closePasv() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.pasv != nil {
+		_ = s.pasv.Close()
+		s.pasv = nil
+	}
+	s.active = nil
+}
+
+// setActive switches to the active mode, the server connects to addr for the next transfer.
+// Only the client of control connection with an unprivileged port is allowed,
+// so the server can't be used to connect to other hosts (FTP bounce)
+func (s *session) setActive(addr *net.TCPAddr) error {
+	remote, ok := s.conn.RemoteAddr().(*net.TCPAddr)
+	if !ok || !remote.IP.Equal(addr.IP) {
+		return errors.Errorf("the address %s doesn't match the client", addr.IP)
+	}
+	if addr.Port < 1024 {
+		return errors.Errorf("the port %d is privileged", addr.Port)
+	}
+	s.closePasv()
+	s.mu.Lock()
+	s.active = addr
+	s.mu.Unlock()
+	return nil
+}
+
+// dataConn accepts the passive data connection or connects to the client in
+// the active mode, the listener is closed after that since each transfer uses
+// a new PASV
+func (s *session) dataConn() (net.Conn, error) {
+	s.mu.Lock()
+	l, active := s.pasv, s.active
+	s.pasv, s.active = nil, nil
+	s.mu.Unlock()
+	var conn net.Conn
+	var err error
+	switch {
+	case active != nil:
+		d := net.Dialer{Timeout: 30 * time.Second}
+		conn, err = d.DialContext(s.ctx, "tcp", active.String())
+		if err != nil {
+			return nil, err
+		}
+	case l != nil:
+		if conn, err = s.accept(l); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, errors.New("use PORT, EPRT, PASV or EPSV first")
+	}
+	if s.protData {
+		tlsConn := tls.Server(conn, s.server.TLSConfig)
+		if err = tlsConn.HandshakeContext(s.ctx); err != nil {
+			_ = conn.Close()
+			return nil, err
+		}
+		return tlsConn, nil
+	}
+	return conn, nil
+}
+
+func (s *session) accept(l net.Listener) (net.Conn, error) {
+	defer l.Close()
+	if tl, ok := l.(*net.TCPListener); ok {
+		_ = tl.SetDeadline(time.Now().Add(30 * time.Second))
+	}
+	conn, err := l.Accept()
+	if err != nil {
+		return nil, err
+	}
+	// only the client of control connection can connect
+	remote, _, _ := net.SplitHostPort(s.conn.RemoteAddr().String())
+	dataRemote, _, _ := net.SplitHostPort(conn.RemoteAddr().String())
+	if remote != dataRemote {
+		_ = conn.Close()
+		return nil, errors.Errorf("data connection from %s doesn't match %s", dataRemote, remote)
+	}
+	return conn, nil
+}
+
+// transfer opens the data connection and calls f with it,
+// the replies before and after the transfer are handled here
+func (s *session) transfer(f func(conn net.Conn) error) {
+	s.reply(150, "Opening data connection")
+	conn, err := s.dataConn()
+	if err != nil {
+		s.reply(425, "Can't open data connection: "+err.Error())
+		return
+	}
+	err = f(conn)
+	if cerr := conn.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		log.Errorf("[ftp] transfer error: %+v", err)
+		s.reply(426, "Connection closed; transfer aborted: "+err.Error())
+		return
+	}
+	s.reply(226, "Transfer complete")
+}
+
+func (s *session) close() {
+	s.cancel()
+	s.closePasv()
+	s.mu.Lock()
+	conn := s.conn
+	s.mu.Unlock()
+	_ = conn.Close()
+}
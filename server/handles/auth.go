@@ -100,6 +100,9 @@ func CurrentUser(c *gin.Context) {
 		User: *user,
 	}
 	userResp.Password = ""
+	// the frontend gets the permission and base path inherited from the groups
+	userResp.BasePath = user.GetBasePath()
+	userResp.Permission = user.EffectivePermission()
 	if userResp.OtpSecret != "" {
 		userResp.Otp = true
 	}
@@ -0,0 +1,70 @@
+package handles
+
+import (
+	"github.com/alist-org/alist/v3/internal/dedup"
+	"github.com/alist-org/alist/v3/server/common"
+	"github.com/gin-gonic/gin"
+)
+
+type FindDuplicatesReq struct {
+	Paths   []string `json:"paths" binding:"required"`
+	MinSize int64    `json:"min_size"`
+	// -1 for unlimited
+	MaxDepth int `json:"max_depth"`
+}
+
+func FindDuplicates(c *gin.Context) {
+	var req FindDuplicatesReq
+	if err := c.ShouldBind(&req); err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	if req.MaxDepth == 0 {
+		req.MaxDepth = -1
+	}
+	t, err := dedup.Find(req.Paths, req.MinSize, req.MaxDepth)
+	if err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	common.SuccessResp(c, gin.H{
+		"task": getTaskInfo(t),
+	})
+}
+
+func GetDuplicateGroups(c *gin.Context) {
+	groups, err := dedup.GetGroups(c.Query("tid"))
+	if err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	common.SuccessResp(c, groups)
+}
+
+type ResolveDuplicatesReq struct {
+	TaskID string   `json:"tid" binding:"required"`
+	Keep   string   `json:"keep" binding:"required"`
+	Paths  []string `json:"paths" binding:"required"`
+	// delete or link
+	Action string `json:"action" binding:"required"`
+}
+
+func ResolveDuplicates(c *gin.Context) {
+	var req ResolveDuplicatesReq
+	if err := c.ShouldBind(&req); err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	err := dedup.Resolve(c, dedup.ResolveArgs{
+		TaskID: req.TaskID,
+		Keep:   req.Keep,
+		Paths:  req.Paths,
+		Action: req.Action,
+		ApiUrl: common.GetApiUrl(c.Request),
+	})
+	if err != nil {
+		common.ErrorResp(c, err, 500)
+		return
+	}
+	common.SuccessResp(c)
+}
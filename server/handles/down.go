@@ -6,10 +6,13 @@ import (
 	stdpath "path"
 	"strings"
 
+	"github.com/alist-org/alist/v3/internal/audit"
 	"github.com/alist-org/alist/v3/internal/conf"
 	"github.com/alist-org/alist/v3/internal/driver"
 	"github.com/alist-org/alist/v3/internal/fs"
 	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/internal/op"
+	"github.com/alist-org/alist/v3/internal/ratelimit"
 	"github.com/alist-org/alist/v3/internal/setting"
 	"github.com/alist-org/alist/v3/internal/sign"
 	"github.com/alist-org/alist/v3/pkg/utils"
@@ -26,7 +29,7 @@ func Down(c *gin.Context) {
 		common.ErrorResp(c, err, 500)
 		return
 	}
-	if common.ShouldProxy(storage, filename) {
+	if _, ok := c.GetQuery("inner"); ok || common.ShouldProxy(storage, filename) {
 		Proxy(c)
 		return
 	} else {
@@ -36,6 +39,9 @@ func Down(c *gin.Context) {
 			Type:    c.Query("type"),
 			HttpReq: c.Request,
 		})
+		if common.IsDownloadStart(c.Request) {
+			audit.Record(c, model.AuditDownload, rawPath, "", err)
+		}
 		if err != nil {
 			common.ErrorResp(c, err, 500)
 			return
@@ -73,6 +79,10 @@ func Proxy(c *gin.Context) {
 		common.ErrorResp(c, err, 500)
 		return
 	}
+	if inner, ok := c.GetQuery("inner"); ok {
+		archiveProxy(c, storage, rawPath, inner)
+		return
+	}
 	if canProxy(storage, filename) {
 		downProxyUrl := storage.GetStorage().DownProxyUrl
 		if downProxyUrl != "" {
@@ -91,6 +101,9 @@ func Proxy(c *gin.Context) {
 			Type:    c.Query("type"),
 			HttpReq: c.Request,
 		})
+		if common.IsDownloadStart(c.Request) {
+			audit.Record(c, model.AuditDownload, rawPath, "", err)
+		}
 		if err != nil {
 			common.ErrorResp(c, err, 500)
 			return
@@ -109,6 +122,8 @@ func Proxy(c *gin.Context) {
 		if storage.GetStorage().ProxyRange {
 			common.ProxyRange(link, file.GetSize())
 		}
+		limiters := ratelimit.Download(downloadUser(c), storage.GetStorage())
+		c.Request = c.Request.WithContext(ratelimit.WithLimiters(c.Request.Context(), limiters...))
 		err = common.Proxy(c.Writer, c.Request, link, file)
 		if err != nil {
 			common.ErrorResp(c, err, 500, true)
@@ -120,6 +135,24 @@ func Proxy(c *gin.Context) {
 	}
 }
 
+// downloadUser returns the user whose download limit is applied,
+// the links are usually requested without token, so it's the guest then
+func downloadUser(c *gin.Context) *model.User {
+	if user, ok := c.Value("user").(*model.User); ok {
+		return user
+	}
+	if token := c.GetHeader("Authorization"); token != "" {
+		if user, err := common.ParseUser(token); err == nil {
+			return user
+		}
+	}
+	guest, err := op.GetGuest()
+	if err != nil {
+		return nil
+	}
+	return guest
+}
+
 // TODO need optimize
 // when can be proxy?
 // 1. text file
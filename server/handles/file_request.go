@@ -0,0 +1,355 @@
+package handles
+
+import (
+	"fmt"
+	"net/url"
+	stdpath "path"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/alist-org/alist/v3/internal/errs"
+	"github.com/alist-org/alist/v3/internal/fs"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/internal/op"
+	"github.com/alist-org/alist/v3/internal/stream"
+	"github.com/alist-org/alist/v3/pkg/utils/random"
+	"github.com/alist-org/alist/v3/server/common"
+	"github.com/gin-gonic/gin"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+)
+
+type FileRequestCreateReq struct {
+	Path        string `json:"path" binding:"required"`
+	Description string `json:"description"`
+	Password    string `json:"password"`
+	// the password of the meta of path
+	MetaPassword string     `json:"meta_password"`
+	Expires      *time.Time `json:"expires"`
+	MaxSize      int64      `json:"max_size"`
+	MaxFiles     int64      `json:"max_files"`
+}
+
+// canWriteFileRequest checks whether user can upload to the dir of file request
+func canWriteFileRequest(user *model.User, meta *model.Meta, dir string) bool {
+	return common.CanOperate(user, dir, model.ACLWrite, user.CanWrite() || common.CanWrite(meta, dir))
+}
+
+func CreateMyFileRequest(c *gin.Context) {
+	user := c.MustGet("user").(*model.User)
+	if user.IsGuest() || !user.CanFileRequest() {
+		common.ErrorResp(c, errs.PermissionDenied, 403)
+		return
+	}
+	var req FileRequestCreateReq
+	if err := c.ShouldBind(&req); err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	if req.Expires != nil && req.Expires.Before(time.Now()) {
+		common.ErrorStrResp(c, "expires should be in the future", 400)
+		return
+	}
+	if req.MaxSize < 0 || req.MaxFiles < 0 {
+		common.ErrorStrResp(c, "quotas should not be negative", 400)
+		return
+	}
+	reqPath, err := user.JoinPath(req.Path)
+	if err != nil {
+		common.ErrorResp(c, err, 403)
+		return
+	}
+	meta, err := op.GetNearestUserMeta(user, reqPath)
+	if err != nil {
+		if !errors.Is(errors.Cause(err), errs.MetaNotFound) {
+			common.ErrorResp(c, err, 500, true)
+			return
+		}
+	}
+	if !common.CanAccess(user, meta, reqPath, req.MetaPassword) || !canWriteFileRequest(user, meta, reqPath) {
+		common.ErrorStrResp(c, "password is incorrect or you have no permission", 403)
+		return
+	}
+	obj, err := fs.Get(c, reqPath, &fs.GetArgs{})
+	if err != nil {
+		common.ErrorResp(c, err, 500)
+		return
+	}
+	if !obj.IsDir() {
+		common.ErrorResp(c, errs.NotFolder, 400)
+		return
+	}
+	r := &model.FileRequest{
+		UserID:      user.ID,
+		Path:        reqPath,
+		Description: req.Description,
+		Password:    req.Password,
+		Expires:     req.Expires,
+		MaxSize:     req.MaxSize,
+		MaxFiles:    req.MaxFiles,
+	}
+	if err = op.CreateFileRequest(r); err != nil {
+		common.ErrorResp(c, err, 500, true)
+		return
+	}
+	common.SuccessResp(c, r)
+}
+
+func ListMyFileRequests(c *gin.Context) {
+	user := c.MustGet("user").(*model.User)
+	if user.IsGuest() {
+		common.ErrorStrResp(c, "Guest user can not list file requests", 403)
+		return
+	}
+	var req model.PageReq
+	if err := c.ShouldBind(&req); err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	req.Validate()
+	requests, total, err := op.GetFileRequestsByUserId(user.ID, req.Page, req.PerPage)
+	if err != nil {
+		common.ErrorResp(c, err, 500, true)
+		return
+	}
+	common.SuccessResp(c, common.PageResp{
+		Content: requests,
+		Total:   total,
+	})
+}
+
+func DeleteMyFileRequest(c *gin.Context) {
+	user := c.MustGet("user").(*model.User)
+	r, err := op.GetFileRequestByIdAndUserId(c.Query("id"), user.ID)
+	if err != nil {
+		common.ErrorStrResp(c, "failed to get file request", 404)
+		return
+	}
+	if err = op.DeleteFileRequestById(r.ID); err != nil {
+		common.ErrorResp(c, err, 500, true)
+		return
+	}
+	common.SuccessResp(c)
+}
+
+func ListMyFileRequestUploads(c *gin.Context) {
+	user := c.MustGet("user").(*model.User)
+	r, err := op.GetFileRequestByIdAndUserId(c.Query("id"), user.ID)
+	if err != nil {
+		common.ErrorStrResp(c, "failed to get file request", 404)
+		return
+	}
+	listFileRequestUploads(c, r.ID)
+}
+
+func ListFileRequests(c *gin.Context) {
+	var req model.PageReq
+	if err := c.ShouldBind(&req); err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	req.Validate()
+	requests, total, err := op.GetFileRequests(req.Page, req.PerPage)
+	if err != nil {
+		common.ErrorResp(c, err, 500, true)
+		return
+	}
+	common.SuccessResp(c, common.PageResp{
+		Content: requests,
+		Total:   total,
+	})
+}
+
+func DeleteFileRequest(c *gin.Context) {
+	if err := op.DeleteFileRequestById(c.Query("id")); err != nil {
+		common.ErrorResp(c, err, 500, true)
+		return
+	}
+	common.SuccessResp(c)
+}
+
+func ListFileRequestUploads(c *gin.Context) {
+	listFileRequestUploads(c, c.Query("id"))
+}
+
+func listFileRequestUploads(c *gin.Context, requestId string) {
+	var req model.PageReq
+	if err := c.ShouldBind(&req); err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	req.Validate()
+	uploads, total, err := op.GetFileRequestUploads(requestId, req.Page, req.PerPage)
+	if err != nil {
+		common.ErrorResp(c, err, 500, true)
+		return
+	}
+	common.SuccessResp(c, common.PageResp{
+		Content: uploads,
+		Total:   total,
+	})
+}
+
+// getFileRequest gets the valid file request and its owner,
+// the error response is written if ok is false
+func getFileRequest(c *gin.Context, id string) (r *model.FileRequest, owner *model.User, ok bool) {
+	r, owner, err := op.GetValidFileRequest(id)
+	if err != nil {
+		switch {
+		case errors.Is(err, errs.FileRequestExpired):
+			common.ErrorResp(c, err, 410)
+		case errors.Is(err, errs.PermissionDenied):
+			common.ErrorResp(c, err, 403)
+		default:
+			common.ErrorStrResp(c, "file request not found", 404)
+		}
+		return nil, nil, false
+	}
+	return r, owner, true
+}
+
+type FileRequestGetReq struct {
+	ID string `json:"id" form:"id" binding:"required"`
+}
+
+// FileRequestResp is the info of file request for the uploaders, the path is not included
+type FileRequestResp struct {
+	ID            string     `json:"id"`
+	Description   string     `json:"description"`
+	NeedPassword  bool       `json:"need_password"`
+	Expires       *time.Time `json:"expires"`
+	MaxSize       int64      `json:"max_size"`
+	MaxFiles      int64      `json:"max_files"`
+	UploadedSize  int64      `json:"uploaded_size"`
+	UploadedFiles int64      `json:"uploaded_files"`
+}
+
+func toFileRequestResp(r *model.FileRequest) FileRequestResp {
+	return FileRequestResp{
+		ID:            r.ID,
+		Description:   r.Description,
+		NeedPassword:  r.Password != "",
+		Expires:       r.Expires,
+		MaxSize:       r.MaxSize,
+		MaxFiles:      r.MaxFiles,
+		UploadedSize:  r.UploadedSize,
+		UploadedFiles: r.UploadedFiles,
+	}
+}
+
+func FileRequestGet(c *gin.Context) {
+	var req FileRequestGetReq
+	if err := c.ShouldBind(&req); err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	r, _, ok := getFileRequest(c, req.ID)
+	if !ok {
+		return
+	}
+	common.SuccessResp(c, toFileRequestResp(r))
+}
+
+const maxUploaderLength = 64
+
+// validFileName reports whether name can be used as a file name in the dir
+func validFileName(name string) bool {
+	return name != "" && name != "." && name != ".." && !strings.ContainsAny(name, "/\\")
+}
+
+// uniqueFileName appends the upload time and a random suffix to name, the
+// uploaders should never overwrite the files or learn the existing names
+func uniqueFileName(name string) string {
+	ext := stdpath.Ext(name)
+	base := strings.TrimSuffix(name, ext)
+	return fmt.Sprintf("%s_%s_%s%s", base, time.Now().Format("20060102150405"), random.String(8), ext)
+}
+
+// FileRequestPut uploads a file to the file request, it's like FsStream
+// with the File-Request-Id and File-Name headers instead of File-Path
+func FileRequestPut(c *gin.Context) {
+	defer c.Request.Body.Close()
+	r, owner, ok := getFileRequest(c, c.GetHeader("File-Request-Id"))
+	if !ok {
+		return
+	}
+	password, _ := url.PathUnescape(c.GetHeader("Password"))
+	if r.Password != "" && r.Password != password {
+		common.ErrorStrResp(c, "password is incorrect", 403)
+		return
+	}
+	name, err := url.PathUnescape(c.GetHeader("File-Name"))
+	if err != nil || !validFileName(name) {
+		common.ErrorStrResp(c, "invalid file name", 400)
+		return
+	}
+	uploader, _ := url.PathUnescape(c.GetHeader("Uploader"))
+	uploader = strings.TrimSpace(uploader)
+	if runes := []rune(uploader); len(runes) > maxUploaderLength {
+		uploader = string(runes[:maxUploaderLength])
+	}
+	size, err := strconv.ParseInt(c.GetHeader("Content-Length"), 10, 64)
+	if err != nil || size < 0 {
+		common.ErrorStrResp(c, "invalid content length", 400)
+		return
+	}
+	meta, err := op.GetNearestMeta(r.Path)
+	if err != nil {
+		if !errors.Is(errors.Cause(err), errs.MetaNotFound) {
+			common.ErrorResp(c, err, 500, true)
+			return
+		}
+	}
+	if !canWriteFileRequest(owner, meta, r.Path) {
+		common.ErrorResp(c, errs.PermissionDenied, 403)
+		return
+	}
+	c.Set("user", owner)
+	c.Set("meta", meta)
+	if err = op.ReserveFileRequestUpload(r, size); err != nil {
+		if errors.Is(err, errs.FileRequestQuotaExceeded) {
+			common.ErrorResp(c, err, 413)
+		} else {
+			common.ErrorResp(c, err, 500, true)
+		}
+		return
+	}
+	name = uniqueFileName(name)
+	asTask := c.GetHeader("As-Task") == "true"
+	s := &stream.FileStream{
+		Obj: &model.Object{
+			Name:     name,
+			Size:     size,
+			Modified: getLastModified(c),
+		},
+		Reader:       c.Request.Body,
+		Mimetype:     c.GetHeader("Content-Type"),
+		WebPutAsTask: asTask,
+	}
+	if asTask {
+		_, err = fs.PutAsTask(c, r.Path, s)
+	} else {
+		err = fs.PutDirectly(c, r.Path, s, true)
+	}
+	if err != nil {
+		if err := op.ReleaseFileRequestUpload(r, size); err != nil {
+			log.Errorf("failed release the quotas of file request %s: %+v", r.ID, err)
+		}
+		// the error may contain the path which should be hidden from the uploaders
+		log.Errorf("failed upload to file request %s: %+v", r.ID, err)
+		common.ErrorStrResp(c, "failed to upload", 500)
+		return
+	}
+	err = op.CreateFileRequestUpload(&model.FileRequestUpload{
+		RequestID: r.ID,
+		Name:      name,
+		Size:      size,
+		Uploader:  uploader,
+		IP:        c.ClientIP(),
+	})
+	if err != nil {
+		log.Errorf("failed record the upload of file request %s: %+v", r.ID, err)
+	}
+	common.SuccessResp(c)
+}
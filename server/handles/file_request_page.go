@@ -0,0 +1,89 @@
+package handles
+
+import (
+	"html/template"
+
+	"github.com/alist-org/alist/v3/internal/conf"
+	"github.com/alist-org/alist/v3/internal/setting"
+	"github.com/alist-org/alist/v3/server/common"
+	"github.com/gin-gonic/gin"
+	log "github.com/sirupsen/logrus"
+)
+
+var fileRequestPage = template.Must(template.New("file_request").Funcs(template.FuncMap{
+	"size": formatSize,
+}).Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<meta name="referrer" content="no-referrer">
+<title>{{.Title}}</title>
+<style>
+body{font-family:sans-serif;max-width:640px;margin:2em auto;padding:0 1em;color:#333}
+input,button{display:block;margin:.5em 0;width:100%;box-sizing:border-box;padding:.4em}
+#log p{margin:.2em 0}
+</style>
+</head>
+<body>
+<h2>{{.Title}}</h2>
+{{with .Request}}
+{{if .Description}}<p style="white-space:pre-wrap">{{.Description}}</p>{{end}}
+{{if .Expires}}<p>expires at {{.Expires.Format "2006-01-02 15:04:05"}}</p>{{end}}
+{{if .MaxFiles}}<p>max files: {{.MaxFiles}}</p>{{end}}
+{{if .MaxSize}}<p>max size: {{size .MaxSize}}</p>{{end}}
+<input id="uploader" placeholder="your name">
+{{if .NeedPassword}}<input id="password" type="password" placeholder="password">{{end}}
+<input id="files" type="file" multiple>
+<button id="upload">Upload</button>
+<div id="log"></div>
+{{end}}
+<script>
+const api = {{.Api}}, id = {{.Request.ID}};
+const log = (msg) => { const p = document.createElement("p"); p.textContent = msg; document.getElementById("log").appendChild(p); };
+document.getElementById("upload").onclick = async () => {
+  const password = document.getElementById("password");
+  for (const file of document.getElementById("files").files) {
+    const resp = await fetch(api + "/api/public/file_request/put", {
+      method: "PUT",
+      headers: {
+        "File-Request-Id": id,
+        "File-Name": encodeURIComponent(file.name),
+        "Uploader": encodeURIComponent(document.getElementById("uploader").value),
+        "Password": password ? encodeURIComponent(password.value) : "",
+        "Last-Modified": String(file.lastModified),
+        "Content-Type": file.type || "application/octet-stream",
+      },
+      body: file,
+    }).then((r) => r.json()).catch((e) => ({ message: String(e) }));
+    log(file.name + ": " + (resp.code === 200 ? "uploaded" : resp.message));
+  }
+};
+</script>
+</body>
+</html>`))
+
+type fileRequestPageData struct {
+	Title   string
+	Api     string
+	Request FileRequestResp
+}
+
+// FileRequestPage serves the upload page of file request
+func FileRequestPage(c *gin.Context) {
+	r, _, ok := getFileRequest(c, c.Param("id"))
+	if !ok {
+		return
+	}
+	c.Header("Content-Type", "text/html; charset=utf-8")
+	c.Header("Referrer-Policy", "no-referrer")
+	c.Status(200)
+	err := fileRequestPage.Execute(c.Writer, fileRequestPageData{
+		Title:   setting.GetStr(conf.SiteTitle),
+		Api:     common.GetApiUrl(c.Request),
+		Request: toFileRequestResp(r),
+	})
+	if err != nil {
+		log.Errorf("failed render file request page: %+v", err)
+	}
+}
@@ -0,0 +1,149 @@
+package handles
+
+import (
+	stdpath "path"
+
+	"github.com/alist-org/alist/v3/internal/conf"
+	"github.com/alist-org/alist/v3/internal/driver"
+	"github.com/alist-org/alist/v3/internal/errs"
+	"github.com/alist-org/alist/v3/internal/fs"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/internal/op"
+	"github.com/alist-org/alist/v3/internal/ratelimit"
+	"github.com/alist-org/alist/v3/internal/setting"
+	"github.com/alist-org/alist/v3/internal/sign"
+	"github.com/alist-org/alist/v3/pkg/utils"
+	"github.com/alist-org/alist/v3/server/common"
+	"github.com/gin-gonic/gin"
+	"github.com/pkg/errors"
+	"github.com/xhofe/tache"
+)
+
+type ArchiveListReq struct {
+	ListReq
+	// the dir in the archive, empty for the root
+	InnerPath string `json:"inner_path" form:"inner_path"`
+}
+
+type ArchiveListResp struct {
+	Content []ObjResp `json:"content"`
+	Total   int64     `json:"total"`
+	// the sign of the archive, the files in it are downloaded by /d/path?sign=sign&inner=inner_path
+	Sign string `json:"sign"`
+}
+
+func FsArchiveList(c *gin.Context) {
+	var req ArchiveListReq
+	if err := c.ShouldBind(&req); err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	req.Validate()
+	user := c.MustGet("user").(*model.User)
+	reqPath, err := user.JoinPath(req.Path)
+	if err != nil {
+		common.ErrorResp(c, err, 403)
+		return
+	}
+	meta, err := op.GetNearestUserMeta(user, reqPath)
+	if err != nil {
+		if !errors.Is(errors.Cause(err), errs.MetaNotFound) {
+			common.ErrorResp(c, err, 500, true)
+			return
+		}
+	}
+	c.Set("meta", meta)
+	if !common.CanAccess(user, meta, reqPath, req.Password) {
+		common.ErrorStrResp(c, "password is incorrect or you have no permission", 403)
+		return
+	}
+	if req.Refresh && !common.CanOperate(user, reqPath, model.ACLWrite, user.CanWrite() || common.CanWrite(meta, reqPath)) {
+		common.ErrorStrResp(c, "Refresh without permission", 403)
+		return
+	}
+	objs, err := fs.ArchiveList(c, reqPath, req.InnerPath, &fs.ListArgs{Refresh: req.Refresh})
+	if err != nil {
+		common.ErrorResp(c, err, 500)
+		return
+	}
+	total, objs := pagination(objs, &req.PageReq)
+	var s string
+	if isEncrypt(meta, reqPath) || setting.GetBool(conf.SignAll) {
+		s = sign.Sign(reqPath)
+	}
+	common.SuccessResp(c, ArchiveListResp{
+		Content: toArchiveObjsResp(objs),
+		Total:   int64(total),
+		Sign:    s,
+	})
+}
+
+func toArchiveObjsResp(objs []model.Obj) []ObjResp {
+	var resp []ObjResp
+	for _, obj := range objs {
+		resp = append(resp, ObjResp{
+			Name:     obj.GetName(),
+			Size:     obj.GetSize(),
+			IsDir:    obj.IsDir(),
+			Modified: obj.ModTime(),
+			Created:  obj.CreateTime(),
+			Type:     utils.GetObjType(obj.GetName(), obj.IsDir()),
+		})
+	}
+	return resp
+}
+
+type ArchiveExtractReq struct {
+	Path string `json:"path"`
+	// the file or dir in the archive to be extracted, empty for all
+	InnerPath string `json:"inner_path"`
+	DstDir    string `json:"dst_dir"`
+}
+
+func FsArchiveExtract(c *gin.Context) {
+	var req ArchiveExtractReq
+	if err := c.ShouldBind(&req); err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	user := c.MustGet("user").(*model.User)
+	reqPath, err := user.JoinPath(req.Path)
+	if err != nil {
+		common.ErrorResp(c, err, 403)
+		return
+	}
+	dstDir, err := user.JoinPath(req.DstDir)
+	if err != nil {
+		common.ErrorResp(c, err, 403)
+		return
+	}
+	// extracting is copying the files in the archive
+	if !checkMoveOrCopy(user, stdpath.Dir(reqPath), dstDir, []string{stdpath.Base(reqPath)}, model.ACLCopy, user.CanCopy()) {
+		common.ErrorResp(c, errs.PermissionDenied, 403)
+		return
+	}
+	t, err := fs.ArchiveExtract(c, reqPath, req.InnerPath, dstDir)
+	if err != nil {
+		common.ErrorResp(c, err, 500)
+		return
+	}
+	common.SuccessResp(c, gin.H{
+		"tasks": getTaskInfos([]tache.TaskWithInfo{t}),
+	})
+}
+
+// archiveProxy proxies the file at inner in the archive at rawPath,
+// the files in archives are always proxied since they are decompressed by alist
+func archiveProxy(c *gin.Context, storage driver.Driver, rawPath, inner string) {
+	link, file, err := fs.ArchiveLink(c, rawPath, inner)
+	if err != nil {
+		common.ErrorResp(c, err, 500)
+		return
+	}
+	limiters := ratelimit.Download(downloadUser(c), storage.GetStorage())
+	c.Request = c.Request.WithContext(ratelimit.WithLimiters(c.Request.Context(), limiters...))
+	err = common.Proxy(c.Writer, c.Request, link, file)
+	if err != nil {
+		common.ErrorResp(c, err, 500, true)
+	}
+}
@@ -29,18 +29,13 @@ func FsBatchRename(c *gin.Context) {
 		return
 	}
 	user := c.MustGet("user").(*model.User)
-	if !user.CanRename() {
-		common.ErrorResp(c, errs.PermissionDenied, 403)
-		return
-	}
-
 	reqPath, err := user.JoinPath(req.SrcDir)
 	if err != nil {
 		common.ErrorResp(c, err, 403)
 		return
 	}
 
-	meta, err := op.GetNearestMeta(reqPath)
+	meta, err := op.GetNearestUserMeta(user, reqPath)
 	if err != nil {
 		if !errors.Is(errors.Cause(err), errs.MetaNotFound) {
 			common.ErrorResp(c, err, 500, true)
@@ -53,6 +48,10 @@ func FsBatchRename(c *gin.Context) {
 			continue
 		}
 		filePath := fmt.Sprintf("%s/%s", reqPath, renameObject.SrcName)
+		if !common.CanOperate(user, filePath, model.ACLRename, user.CanRename()) {
+			common.ErrorResp(c, errs.PermissionDenied, 403)
+			return
+		}
 		if err := fs.Rename(c, filePath, renameObject.NewName); err != nil {
 			common.ErrorResp(c, err, 500)
 			return
@@ -74,10 +73,6 @@ func FsRecursiveMove(c *gin.Context) {
 	}
 
 	user := c.MustGet("user").(*model.User)
-	if !user.CanMove() {
-		common.ErrorResp(c, errs.PermissionDenied, 403)
-		return
-	}
 	srcDir, err := user.JoinPath(req.SrcDir)
 	if err != nil {
 		common.ErrorResp(c, err, 403)
@@ -88,8 +83,13 @@ func FsRecursiveMove(c *gin.Context) {
 		common.ErrorResp(c, err, 403)
 		return
 	}
+	if !common.CanOperate(user, srcDir, model.ACLMove, user.CanMove()) ||
+		!common.CanOperate(user, dstDir, model.ACLWrite, true) {
+		common.ErrorResp(c, errs.PermissionDenied, 403)
+		return
+	}
 
-	meta, err := op.GetNearestMeta(srcDir)
+	meta, err := op.GetNearestUserMeta(user, srcDir)
 	if err != nil {
 		if !errors.Is(errors.Cause(err), errs.MetaNotFound) {
 			common.ErrorResp(c, err, 500, true)
@@ -137,7 +137,7 @@ func FsRecursiveMove(c *gin.Context) {
 			}
 
 			// move
-			err := fs.Move(c, movingFileName, dstDir, movingFiles.IsEmpty())
+			_, err := fs.Move(c, movingFileName, dstDir, movingFiles.IsEmpty())
 			if err != nil {
 				common.ErrorResp(c, err, 500)
 				return
@@ -162,18 +162,13 @@ func FsRegexRename(c *gin.Context) {
 		return
 	}
 	user := c.MustGet("user").(*model.User)
-	if !user.CanRename() {
-		common.ErrorResp(c, errs.PermissionDenied, 403)
-		return
-	}
-
 	reqPath, err := user.JoinPath(req.SrcDir)
 	if err != nil {
 		common.ErrorResp(c, err, 403)
 		return
 	}
 
-	meta, err := op.GetNearestMeta(reqPath)
+	meta, err := op.GetNearestUserMeta(user, reqPath)
 	if err != nil {
 		if !errors.Is(errors.Cause(err), errs.MetaNotFound) {
 			common.ErrorResp(c, err, 500, true)
@@ -198,6 +193,10 @@ func FsRegexRename(c *gin.Context) {
 
 		if srcRegexp.MatchString(file.GetName()) {
 			filePath := fmt.Sprintf("%s/%s", reqPath, file.GetName())
+			if !common.CanOperate(user, filePath, model.ACLRename, user.CanRename()) {
+				common.ErrorResp(c, errs.PermissionDenied, 403)
+				return
+			}
 			newFileName := srcRegexp.ReplaceAllString(file.GetName(), req.NewNameRegex)
 			if err := fs.Rename(c, filePath, newFileName); err != nil {
 				common.ErrorResp(c, err, 500)
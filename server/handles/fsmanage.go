@@ -35,18 +35,20 @@ func FsMkdir(c *gin.Context) {
 		common.ErrorResp(c, err, 403)
 		return
 	}
-	if !user.CanWrite() {
-		meta, err := op.GetNearestMeta(stdpath.Dir(reqPath))
+	canWrite := user.CanWrite()
+	if !canWrite {
+		meta, err := op.GetNearestUserMeta(user, stdpath.Dir(reqPath))
 		if err != nil {
 			if !errors.Is(errors.Cause(err), errs.MetaNotFound) {
 				common.ErrorResp(c, err, 500, true)
 				return
 			}
 		}
-		if !common.CanWrite(meta, reqPath) {
-			common.ErrorResp(c, errs.PermissionDenied, 403)
-			return
-		}
+		canWrite = common.CanWrite(meta, reqPath)
+	}
+	if !common.CanOperate(user, stdpath.Dir(reqPath), model.ACLWrite, canWrite) {
+		common.ErrorResp(c, errs.PermissionDenied, 403)
+		return
 	}
 	if err := fs.MakeDir(c, reqPath); err != nil {
 		common.ErrorResp(c, err, 500)
@@ -72,10 +74,6 @@ func FsMove(c *gin.Context) {
 		return
 	}
 	user := c.MustGet("user").(*model.User)
-	if !user.CanMove() {
-		common.ErrorResp(c, errs.PermissionDenied, 403)
-		return
-	}
 	srcDir, err := user.JoinPath(req.SrcDir)
 	if err != nil {
 		common.ErrorResp(c, err, 403)
@@ -86,14 +84,24 @@ func FsMove(c *gin.Context) {
 		common.ErrorResp(c, err, 403)
 		return
 	}
+	if !checkMoveOrCopy(user, srcDir, dstDir, req.Names, model.ACLMove, user.CanMove()) {
+		common.ErrorResp(c, errs.PermissionDenied, 403)
+		return
+	}
+	var addedTasks []tache.TaskWithInfo
 	for i, name := range req.Names {
-		err := fs.Move(c, stdpath.Join(srcDir, name), dstDir, len(req.Names) > i+1)
+		t, err := fs.Move(c, stdpath.Join(srcDir, name), dstDir, len(req.Names) > i+1)
+		if t != nil {
+			addedTasks = append(addedTasks, t)
+		}
 		if err != nil {
 			common.ErrorResp(c, err, 500)
 			return
 		}
 	}
-	common.SuccessResp(c)
+	common.SuccessResp(c, gin.H{
+		"tasks": getTaskInfos(addedTasks),
+	})
 }
 
 func FsCopy(c *gin.Context) {
@@ -107,10 +115,6 @@ func FsCopy(c *gin.Context) {
 		return
 	}
 	user := c.MustGet("user").(*model.User)
-	if !user.CanCopy() {
-		common.ErrorResp(c, errs.PermissionDenied, 403)
-		return
-	}
 	srcDir, err := user.JoinPath(req.SrcDir)
 	if err != nil {
 		common.ErrorResp(c, err, 403)
@@ -121,6 +125,10 @@ func FsCopy(c *gin.Context) {
 		common.ErrorResp(c, err, 403)
 		return
 	}
+	if !checkMoveOrCopy(user, srcDir, dstDir, req.Names, model.ACLCopy, user.CanCopy()) {
+		common.ErrorResp(c, errs.PermissionDenied, 403)
+		return
+	}
 	var addedTasks []tache.TaskWithInfo
 	for i, name := range req.Names {
 		t, err := fs.Copy(c, stdpath.Join(srcDir, name), dstDir, len(req.Names) > i+1)
@@ -137,6 +145,16 @@ func FsCopy(c *gin.Context) {
 	})
 }
 
+// checkMoveOrCopy checks the operation on the src objs and the write on dstDir
+func checkMoveOrCopy(user *model.User, srcDir, dstDir string, names []string, operation int32, global bool) bool {
+	for _, name := range names {
+		if !common.CanOperate(user, stdpath.Join(srcDir, name), operation, global) {
+			return false
+		}
+	}
+	return common.CanOperate(user, dstDir, model.ACLWrite, true)
+}
+
 type RenameReq struct {
 	Path string `json:"path"`
 	Name string `json:"name"`
@@ -149,15 +167,15 @@ func FsRename(c *gin.Context) {
 		return
 	}
 	user := c.MustGet("user").(*model.User)
-	if !user.CanRename() {
-		common.ErrorResp(c, errs.PermissionDenied, 403)
-		return
-	}
 	reqPath, err := user.JoinPath(req.Path)
 	if err != nil {
 		common.ErrorResp(c, err, 403)
 		return
 	}
+	if !common.CanOperate(user, reqPath, model.ACLRename, user.CanRename()) {
+		common.ErrorResp(c, errs.PermissionDenied, 403)
+		return
+	}
 	if err := fs.Rename(c, reqPath, req.Name); err != nil {
 		common.ErrorResp(c, err, 500)
 		return
@@ -181,15 +199,17 @@ func FsRemove(c *gin.Context) {
 		return
 	}
 	user := c.MustGet("user").(*model.User)
-	if !user.CanRemove() {
-		common.ErrorResp(c, errs.PermissionDenied, 403)
-		return
-	}
 	reqDir, err := user.JoinPath(req.Dir)
 	if err != nil {
 		common.ErrorResp(c, err, 403)
 		return
 	}
+	for _, name := range req.Names {
+		if !common.CanOperate(user, stdpath.Join(reqDir, name), model.ACLRemove, user.CanRemove()) {
+			common.ErrorResp(c, errs.PermissionDenied, 403)
+			return
+		}
+	}
 	for _, name := range req.Names {
 		err := fs.Remove(c, stdpath.Join(reqDir, name))
 		if err != nil {
@@ -213,17 +233,17 @@ func FsRemoveEmptyDirectory(c *gin.Context) {
 	}
 
 	user := c.MustGet("user").(*model.User)
-	if !user.CanRemove() {
-		common.ErrorResp(c, errs.PermissionDenied, 403)
-		return
-	}
 	srcDir, err := user.JoinPath(req.SrcDir)
 	if err != nil {
 		common.ErrorResp(c, err, 403)
 		return
 	}
+	if !common.CanOperate(user, srcDir, model.ACLRemove, user.CanRemove()) {
+		common.ErrorResp(c, errs.PermissionDenied, 403)
+		return
+	}
 
-	meta, err := op.GetNearestMeta(srcDir)
+	meta, err := op.GetNearestUserMeta(user, srcDir)
 	if err != nil {
 		if !errors.Is(errors.Cause(err), errs.MetaNotFound) {
 			common.ErrorResp(c, err, 500, true)
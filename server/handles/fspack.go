@@ -0,0 +1,157 @@
+package handles
+
+import (
+	"fmt"
+	"net/url"
+	stdpath "path"
+
+	"github.com/alist-org/alist/v3/internal/archive"
+	"github.com/alist-org/alist/v3/internal/errs"
+	"github.com/alist-org/alist/v3/internal/fs"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/internal/op"
+	"github.com/alist-org/alist/v3/internal/ratelimit"
+	"github.com/alist-org/alist/v3/pkg/utils"
+	"github.com/alist-org/alist/v3/server/common"
+	"github.com/gin-gonic/gin"
+	"github.com/pkg/errors"
+	"github.com/xhofe/tache"
+)
+
+type PackReq struct {
+	Dir      string   `json:"dir" form:"dir"`
+	Names    []string `json:"names" form:"names"`
+	Password string   `json:"password" form:"password"`
+	// zip or tar.gz, default to zip
+	Format string `json:"format" form:"format"`
+	// the name of archive without extension, default to the name of the only obj or dir
+	Name string `json:"name" form:"name"`
+}
+
+// check validates the req and returns the full path of dir and the name of archive
+func (req *PackReq) check(c *gin.Context, user *model.User) (string, string, bool) {
+	if len(req.Names) == 0 {
+		common.ErrorStrResp(c, "Empty file names", 400)
+		return "", "", false
+	}
+	if req.Format == "" {
+		req.Format = "zip"
+	}
+	if !utils.SliceContains(archive.WriteFormats, req.Format) {
+		common.ErrorStrResp(c, "Unsupported format: "+req.Format, 400)
+		return "", "", false
+	}
+	dir, err := user.JoinPath(req.Dir)
+	if err != nil {
+		common.ErrorResp(c, err, 403)
+		return "", "", false
+	}
+	for _, name := range req.Names {
+		// the objs out of dir may be out of the base path of user
+		if p := stdpath.Join(dir, name); utils.PathEqual(p, dir) || !utils.IsSubPath(dir, p) {
+			common.ErrorStrResp(c, "Invalid file name: "+name, 400)
+			return "", "", false
+		}
+	}
+	meta, err := op.GetNearestUserMeta(user, dir)
+	if err != nil {
+		if !errors.Is(errors.Cause(err), errs.MetaNotFound) {
+			common.ErrorResp(c, err, 500, true)
+			return "", "", false
+		}
+	}
+	if !common.CanAccess(user, meta, dir, req.Password) {
+		common.ErrorStrResp(c, "password is incorrect or you have no permission", 403)
+		return "", "", false
+	}
+	name := req.Name
+	if name == "" {
+		if len(req.Names) == 1 {
+			name = req.Names[0]
+		} else if name = stdpath.Base(dir); name == "/" {
+			name = "archive"
+		}
+	}
+	return dir, name + "." + req.Format, true
+}
+
+// packFilter skips the objs which can't be accessed by user with password,
+// like the hidden ones and the ones protected by other passwords
+func packFilter(user *model.User, password string) func(path string, obj model.Obj) bool {
+	return func(path string, obj model.Obj) bool {
+		meta, err := op.GetNearestUserMeta(user, path)
+		if err != nil && !errors.Is(errors.Cause(err), errs.MetaNotFound) {
+			return false
+		}
+		return common.CanAccess(user, meta, path, password)
+	}
+}
+
+// FsPack streams the archive of the objs
+func FsPack(c *gin.Context) {
+	var req PackReq
+	if err := c.ShouldBind(&req); err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	user := c.MustGet("user").(*model.User)
+	dir, name, ok := req.check(c, user)
+	if !ok {
+		return
+	}
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"; filename*=UTF-8''%s`, name, url.PathEscape(name)))
+	c.Header("Content-Type", utils.GetMimeType(name))
+	c.Status(200)
+	ctx := ratelimit.WithLimiters(c, ratelimit.Download(user, nil)...)
+	// the error can't be responded after the archive is written partially
+	_ = fs.Pack(ctx, c.Writer, dir, req.Names, &fs.PackArgs{
+		Format: req.Format,
+		Filter: packFilter(user, req.Password),
+	})
+}
+
+type PackTaskReq struct {
+	PackReq
+	DstDir string `json:"dst_dir" form:"dst_dir"`
+}
+
+// FsPackTask adds the task to pack the objs into dst_dir
+func FsPackTask(c *gin.Context) {
+	var req PackTaskReq
+	if err := c.ShouldBind(&req); err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	user := c.MustGet("user").(*model.User)
+	dir, name, ok := req.check(c, user)
+	if !ok {
+		return
+	}
+	dstDir, err := user.JoinPath(req.DstDir)
+	if err != nil {
+		common.ErrorResp(c, err, 403)
+		return
+	}
+	meta, err := op.GetNearestUserMeta(user, dstDir)
+	if err != nil {
+		if !errors.Is(errors.Cause(err), errs.MetaNotFound) {
+			common.ErrorResp(c, err, 500, true)
+			return
+		}
+	}
+	if !common.CanOperate(user, dstDir, model.ACLWrite, user.CanWrite() || common.CanWrite(meta, dstDir)) {
+		common.ErrorResp(c, errs.PermissionDenied, 403)
+		return
+	}
+	t, err := fs.PackAsTask(c, dir, req.Names, dstDir, name, &fs.PackArgs{
+		Format: req.Format,
+		Filter: packFilter(user, req.Password),
+	})
+	if err != nil {
+		common.ErrorResp(c, err, 500)
+		return
+	}
+	common.SuccessResp(c, gin.H{
+		"tasks": getTaskInfos([]tache.TaskWithInfo{t}),
+	})
+}
@@ -12,6 +12,7 @@ import (
 
 	"github.com/alist-org/alist/v3/internal/fs"
 	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/internal/ratelimit"
 	"github.com/alist-org/alist/v3/server/common"
 	"github.com/gin-gonic/gin"
 )
@@ -27,6 +28,16 @@ func getLastModified(c *gin.Context) time.Time {
 	return lastModified
 }
 
+// limitUpload limits the reading of request body by the upload limiters of user and storage
+func limitUpload(c *gin.Context, user *model.User, path string) {
+	var storage *model.Storage
+	if s, err := fs.GetStorage(path, &fs.GetStoragesArgs{}); err == nil {
+		storage = s.GetStorage()
+	}
+	ctx := ratelimit.WithLimiters(c.Request.Context(), ratelimit.Upload(user, storage)...)
+	c.Request.Body = ratelimit.NewReadCloser(ctx, c.Request.Body)
+}
+
 func FsStream(c *gin.Context) {
 	path := c.GetHeader("File-Path")
 	path, err := url.PathUnescape(path)
@@ -48,6 +59,7 @@ func FsStream(c *gin.Context) {
 		common.ErrorResp(c, err, 400)
 		return
 	}
+	limitUpload(c, user, path)
 	s := &stream.FileStream{
 		Obj: &model.Object{
 			Name:     name,
@@ -60,7 +72,7 @@ func FsStream(c *gin.Context) {
 	}
 	var t tache.TaskWithInfo
 	if asTask {
-		t, err = fs.PutAsTask(dir, s)
+		t, err = fs.PutAsTask(c, dir, s)
 	} else {
 		err = fs.PutDirectly(c, dir, s, true)
 	}
@@ -101,6 +113,7 @@ func FsForm(c *gin.Context) {
 		common.ErrorStrResp(c, "Current storage doesn't support upload", 405)
 		return
 	}
+	limitUpload(c, user, path)
 	file, err := c.FormFile("file")
 	if err != nil {
 		common.ErrorResp(c, err, 500)
@@ -128,7 +141,7 @@ func FsForm(c *gin.Context) {
 		s.Reader = struct {
 			io.Reader
 		}{f}
-		t, err = fs.PutAsTask(dir, &s)
+		t, err = fs.PutAsTask(c, dir, &s)
 	} else {
 		ss, err := stream.NewSeekableStream(s, nil)
 		if err != nil {
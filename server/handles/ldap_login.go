@@ -49,6 +49,7 @@ func loginLdap(c *gin.Context, req *LoginReq) {
 	ldapManagerPassword := setting.GetStr(conf.LdapManagerPassword)
 	ldapUserSearchBase := setting.GetStr(conf.LdapUserSearchBase)
 	ldapUserSearchFilter := setting.GetStr(conf.LdapUserSearchFilter) // (uid=%s)
+	ldapGroupAttribute := setting.GetStr(conf.LdapGroupAttribute)     // memberOf
 
 	// Connect to LdapServer
 	l, err := dial(ldapServer)
@@ -69,11 +70,15 @@ func loginLdap(c *gin.Context, req *LoginReq) {
 	}
 
 	// Search for the given username
+	attributes := []string{"dn"}
+	if ldapGroupAttribute != "" {
+		attributes = append(attributes, ldapGroupAttribute)
+	}
 	searchRequest := ldap.NewSearchRequest(
 		ldapUserSearchBase,
 		ldap.ScopeWholeSubtree, ldap.NeverDerefAliases, 0, 0, false,
 		fmt.Sprintf(ldapUserSearchFilter, req.Username),
-		[]string{"dn"},
+		attributes,
 		nil,
 	)
 	sr, err := l.Search(searchRequest)
@@ -110,6 +115,12 @@ func loginLdap(c *gin.Context, req *LoginReq) {
 			return
 		}
 	}
+	if ldapGroupAttribute != "" {
+		groups := sr.Entries[0].GetAttributeValues(ldapGroupAttribute)
+		if err = op.SyncExternalGroups(user, groups); err != nil {
+			utils.Log.Errorf("failed sync ldap groups of user %s: %+v", user.Username, err)
+		}
+	}
 
 	// generate token
 	token, err := common.GenerateToken(user)
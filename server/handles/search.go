@@ -50,10 +50,10 @@ func Search(c *gin.Context) {
 	}
 	var filteredNodes []model.SearchNode
 	for _, node := range nodes {
-		if !strings.HasPrefix(node.Parent, user.BasePath) {
+		if !strings.HasPrefix(node.Parent, user.GetBasePath()) {
 			continue
 		}
-		meta, err := op.GetNearestMeta(node.Parent)
+		meta, err := op.GetNearestUserMeta(user, node.Parent)
 		if err != nil && !errors.Is(errors.Cause(err), errs.MetaNotFound) {
 			continue
 		}
@@ -0,0 +1,354 @@
+package handles
+
+import (
+	stdpath "path"
+	"time"
+
+	"github.com/alist-org/alist/v3/internal/errs"
+	"github.com/alist-org/alist/v3/internal/fs"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/internal/op"
+	"github.com/alist-org/alist/v3/internal/sign"
+	"github.com/alist-org/alist/v3/pkg/utils"
+	"github.com/alist-org/alist/v3/server/common"
+	"github.com/gin-gonic/gin"
+	"github.com/pkg/errors"
+)
+
+type ShareCreateReq struct {
+	Path     string `json:"path" binding:"required"`
+	Password string `json:"password"`
+	// the password of the meta of path
+	MetaPassword string     `json:"meta_password"`
+	Expires      *time.Time `json:"expires"`
+	MaxDownloads int64      `json:"max_downloads"`
+}
+
+func CreateMyShare(c *gin.Context) {
+	user := c.MustGet("user").(*model.User)
+	if user.IsGuest() || !user.CanShare() {
+		common.ErrorResp(c, errs.PermissionDenied, 403)
+		return
+	}
+	var req ShareCreateReq
+	if err := c.ShouldBind(&req); err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	if req.Expires != nil && req.Expires.Before(time.Now()) {
+		common.ErrorStrResp(c, "expires should be in the future", 400)
+		return
+	}
+	if req.MaxDownloads < 0 {
+		common.ErrorStrResp(c, "max downloads should not be negative", 400)
+		return
+	}
+	reqPath, err := user.JoinPath(req.Path)
+	if err != nil {
+		common.ErrorResp(c, err, 403)
+		return
+	}
+	meta, err := op.GetNearestUserMeta(user, reqPath)
+	if err != nil {
+		if !errors.Is(errors.Cause(err), errs.MetaNotFound) {
+			common.ErrorResp(c, err, 500, true)
+			return
+		}
+	}
+	if !common.CanAccess(user, meta, reqPath, req.MetaPassword) {
+		common.ErrorStrResp(c, "password is incorrect or you have no permission", 403)
+		return
+	}
+	if _, err = fs.Get(c, reqPath, &fs.GetArgs{}); err != nil {
+		common.ErrorResp(c, err, 500)
+		return
+	}
+	s := &model.Share{
+		UserID:       user.ID,
+		Path:         reqPath,
+		Password:     req.Password,
+		Expires:      req.Expires,
+		MaxDownloads: req.MaxDownloads,
+	}
+	if err = op.CreateShare(s); err != nil {
+		common.ErrorResp(c, err, 500, true)
+		return
+	}
+	common.SuccessResp(c, s)
+}
+
+func ListMyShares(c *gin.Context) {
+	user := c.MustGet("user").(*model.User)
+	if user.IsGuest() {
+		common.ErrorStrResp(c, "Guest user can not list shares", 403)
+		return
+	}
+	var req model.PageReq
+	if err := c.ShouldBind(&req); err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	req.Validate()
+	shares, total, err := op.GetSharesByUserId(user.ID, req.Page, req.PerPage)
+	if err != nil {
+		common.ErrorResp(c, err, 500, true)
+		return
+	}
+	common.SuccessResp(c, common.PageResp{
+		Content: shares,
+		Total:   total,
+	})
+}
+
+func DeleteMyShare(c *gin.Context) {
+	user := c.MustGet("user").(*model.User)
+	s, err := op.GetShareByIdAndUserId(c.Query("id"), user.ID)
+	if err != nil {
+		common.ErrorStrResp(c, "failed to get share", 404)
+		return
+	}
+	if err = op.DeleteShareById(s.ID); err != nil {
+		common.ErrorResp(c, err, 500, true)
+		return
+	}
+	common.SuccessResp(c)
+}
+
+func ListShares(c *gin.Context) {
+	var req model.PageReq
+	if err := c.ShouldBind(&req); err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	req.Validate()
+	shares, total, err := op.GetShares(req.Page, req.PerPage)
+	if err != nil {
+		common.ErrorResp(c, err, 500, true)
+		return
+	}
+	common.SuccessResp(c, common.PageResp{
+		Content: shares,
+		Total:   total,
+	})
+}
+
+func DeleteShare(c *gin.Context) {
+	if err := op.DeleteShareById(c.Query("id")); err != nil {
+		common.ErrorResp(c, err, 500, true)
+		return
+	}
+	common.SuccessResp(c)
+}
+
+// sharedPath is the resolved path of a request to a share
+type sharedPath struct {
+	share *model.Share
+	owner *model.User
+	meta  *model.Meta
+	// the path relative to the share
+	subPath string
+	// the full path in alist
+	path string
+}
+
+// getShare gets the valid share and its owner,
+// the error response is written if ok is false
+func getShare(c *gin.Context, id string) (s *model.Share, owner *model.User, ok bool) {
+	s, owner, err := op.GetValidShare(id)
+	if err != nil {
+		switch {
+		case errors.Is(err, errs.ShareExpired):
+			common.ErrorResp(c, err, 410)
+		case errors.Is(err, errs.PermissionDenied):
+			common.ErrorResp(c, err, 403)
+		default:
+			common.ErrorStrResp(c, "share not found", 404)
+		}
+		return nil, nil, false
+	}
+	return s, owner, true
+}
+
+// checkSharePassword checks the password of share, or the sign of the file url
+func checkSharePassword(s *model.Share, subPath, password, signStr string) bool {
+	if s.Password == "" || s.Password == password {
+		return true
+	}
+	return signStr != "" && sign.Verify(shareSignData(s, subPath), signStr) == nil
+}
+
+// resolveSharePath joins subPath to the share and checks the permission of the owner,
+// the error response is written if ok is false
+func resolveSharePath(c *gin.Context, s *model.Share, owner *model.User, subPath string) (sp *sharedPath, ok bool) {
+	reqPath, err := utils.JoinBasePath(s.Path, subPath)
+	if err != nil {
+		common.ErrorResp(c, err, 403)
+		return nil, false
+	}
+	meta, err := op.GetNearestUserMeta(owner, reqPath)
+	if err != nil {
+		if !errors.Is(errors.Cause(err), errs.MetaNotFound) {
+			common.ErrorResp(c, err, 500, true)
+			return nil, false
+		}
+	}
+	// the owner has passed the metas above the share on creating,
+	// the passwords of the metas inside the share are still required
+	metaPassword := ""
+	if meta != nil && (meta.Path == s.Path || !utils.IsSubPath(s.Path, meta.Path)) {
+		metaPassword = meta.Password
+	}
+	if !common.CanAccess(owner, meta, reqPath, metaPassword) {
+		common.ErrorStrResp(c, "you have no permission", 403)
+		return nil, false
+	}
+	// the hidden files of the owner are hidden
+	c.Set("user", owner)
+	c.Set("meta", meta)
+	return &sharedPath{share: s, owner: owner, meta: meta, subPath: subPath, path: reqPath}, true
+}
+
+// resolveShare resolves the request of the share api
+func resolveShare(c *gin.Context, id, subPath, password string) (sp *sharedPath, ok bool) {
+	s, owner, ok := getShare(c, id)
+	if !ok {
+		return nil, false
+	}
+	subPath = utils.FixAndCleanPath(subPath)
+	if !checkSharePassword(s, subPath, password, "") {
+		common.ErrorStrResp(c, "password is incorrect", 403)
+		return nil, false
+	}
+	return resolveSharePath(c, s, owner, subPath)
+}
+
+// shareSignData includes the password, so the signs are invalid after it's changed
+func shareSignData(s *model.Share, subPath string) string {
+	return "/s/" + s.ID + "#" + s.Password + subPath
+}
+
+// shareSign signs the download url of a file in share, the sign is short-lived
+// and the share is still checked on every download with it
+func shareSign(s *model.Share, subPath string) string {
+	expiration := shareCookieExpiration
+	if s.Expires != nil && time.Until(*s.Expires) < expiration {
+		expiration = time.Until(*s.Expires)
+	}
+	return sign.WithDuration(shareSignData(s, subPath), expiration)
+}
+
+type ShareListReq struct {
+	model.PageReq
+	ID       string `json:"id" form:"id" binding:"required"`
+	Path     string `json:"path" form:"path"`
+	Password string `json:"password" form:"password"`
+}
+
+type ShareObjResp struct {
+	ObjResp
+	// the download url of file
+	URL string `json:"url"`
+}
+
+type ShareListResp struct {
+	Content []ShareObjResp `json:"content"`
+	Total   int64          `json:"total"`
+	Readme  string         `json:"readme"`
+	Header  string         `json:"header"`
+}
+
+func toShareObjsResp(c *gin.Context, sp *sharedPath, objs []model.Obj) []ShareObjResp {
+	resp := make([]ShareObjResp, 0, len(objs))
+	for _, obj := range objs {
+		resp = append(resp, toShareObjResp(c, sp.share, stdpath.Join(sp.subPath, obj.GetName()), obj))
+	}
+	return resp
+}
+
+func toShareObjResp(c *gin.Context, s *model.Share, subPath string, obj model.Obj) ShareObjResp {
+	thumb, _ := model.GetThumb(obj)
+	resp := ShareObjResp{
+		ObjResp: ObjResp{
+			Name:        obj.GetName(),
+			Size:        obj.GetSize(),
+			IsDir:       obj.IsDir(),
+			Modified:    obj.ModTime(),
+			Created:     obj.CreateTime(),
+			HashInfoStr: obj.GetHash().String(),
+			HashInfo:    obj.GetHash().Export(),
+			Thumb:       thumb,
+			Type:        utils.GetObjType(obj.GetName(), obj.IsDir()),
+		},
+	}
+	if !obj.IsDir() {
+		resp.Sign = shareSign(s, subPath)
+		resp.URL = shareURL(c, s, subPath) + "?sign=" + resp.Sign
+	}
+	return resp
+}
+
+func shareURL(c *gin.Context, s *model.Share, subPath string) string {
+	return common.GetApiUrl(c.Request) + "/s/" + s.ID + utils.EncodePath(subPath, true)
+}
+
+func ShareList(c *gin.Context) {
+	var req ShareListReq
+	if err := c.ShouldBind(&req); err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	req.Validate()
+	sp, ok := resolveShare(c, req.ID, req.Path, req.Password)
+	if !ok {
+		return
+	}
+	objs, err := fs.List(c, sp.path, &fs.ListArgs{})
+	if err != nil {
+		common.ErrorResp(c, err, 500)
+		return
+	}
+	objs = common.FilterReadable(sp.owner, sp.path, objs)
+	total, objs := pagination(objs, &req.PageReq)
+	common.SuccessResp(c, ShareListResp{
+		Content: toShareObjsResp(c, sp, objs),
+		Total:   int64(total),
+		Readme:  getReadme(sp.meta, sp.path),
+		Header:  getHeader(sp.meta, sp.path),
+	})
+}
+
+type ShareGetReq struct {
+	ID       string `json:"id" form:"id" binding:"required"`
+	Path     string `json:"path" form:"path"`
+	Password string `json:"password" form:"password"`
+}
+
+type ShareGetResp struct {
+	ShareObjResp
+	Expires      *time.Time `json:"expires"`
+	MaxDownloads int64      `json:"max_downloads"`
+	Downloads    int64      `json:"downloads"`
+}
+
+func ShareGet(c *gin.Context) {
+	var req ShareGetReq
+	if err := c.ShouldBind(&req); err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	sp, ok := resolveShare(c, req.ID, req.Path, req.Password)
+	if !ok {
+		return
+	}
+	obj, err := fs.Get(c, sp.path, &fs.GetArgs{})
+	if err != nil {
+		common.ErrorResp(c, err, 500)
+		return
+	}
+	common.SuccessResp(c, ShareGetResp{
+		ShareObjResp: toShareObjResp(c, sp.share, sp.subPath, obj),
+		Expires:      sp.share.Expires,
+		MaxDownloads: sp.share.MaxDownloads,
+		Downloads:    sp.share.Downloads,
+	})
+}
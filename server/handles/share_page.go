@@ -0,0 +1,290 @@
+package handles
+
+import (
+	"fmt"
+	"html/template"
+	"net/http"
+	stdpath "path"
+	"strings"
+	"time"
+
+	"github.com/alist-org/alist/v3/internal/audit"
+	"github.com/alist-org/alist/v3/internal/conf"
+	"github.com/alist-org/alist/v3/internal/errs"
+	"github.com/alist-org/alist/v3/internal/fs"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/internal/op"
+	"github.com/alist-org/alist/v3/internal/ratelimit"
+	"github.com/alist-org/alist/v3/internal/setting"
+	"github.com/alist-org/alist/v3/internal/sign"
+	"github.com/alist-org/alist/v3/pkg/utils"
+	"github.com/alist-org/alist/v3/server/common"
+	"github.com/gin-gonic/gin"
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+)
+
+// the max lifetime of the cookie set after the password of share is entered
+const shareCookieExpiration = 24 * time.Hour
+
+const (
+	shareDownloadCookieName = "alist_share_download"
+	// the lifetime of the cookie which lets the range requests continue a counted download
+	shareDownloadExpiration = time.Hour
+)
+
+var sharePage = template.Must(template.New("share").Funcs(template.FuncMap{
+	"size": formatSize,
+	"time": func(t time.Time) string {
+		return t.Format("2006-01-02 15:04:05")
+	},
+}).Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<meta name="referrer" content="no-referrer">
+<title>{{.Name}} - {{.Title}}</title>
+<style>
+body{font-family:sans-serif;max-width:960px;margin:2em auto;padding:0 1em;color:#333}
+table{width:100%;border-collapse:collapse}
+td,th{padding:.4em;text-align:left;border-bottom:1px solid #eee}
+td.r,th.r{text-align:right;white-space:nowrap}
+a{color:#1890ff;text-decoration:none}
+</style>
+</head>
+<body>
+<h2>{{.Name}}</h2>
+{{if .NeedPassword}}
+<form method="post">
+<input type="password" name="pwd" placeholder="password" autofocus>
+<button type="submit">OK</button>
+{{if .WrongPassword}}<p>password is incorrect</p>{{end}}
+</form>
+{{else}}
+{{if .Expires}}<p>expires at {{time .Expires}}</p>{{end}}
+<table>
+<tr><th>Name</th><th class="r">Size</th><th class="r">Modified</th></tr>
+{{if .Parent}}<tr><td><a href="{{.Parent}}">../</a></td><td></td><td></td></tr>{{end}}
+{{range .Entries}}
+<tr><td><a href="{{.URL}}">{{.Name}}{{if .IsDir}}/{{end}}</a></td><td class="r">{{if not .IsDir}}{{size .Size}}{{end}}</td><td class="r">{{time .Modified}}</td></tr>
+{{end}}
+</table>
+{{end}}
+</body>
+</html>`))
+
+func formatSize(size int64) string {
+	units := []string{"B", "KB", "MB", "GB", "TB", "PB"}
+	f := float64(size)
+	i := 0
+	for f >= 1024 && i < len(units)-1 {
+		f /= 1024
+		i++
+	}
+	if i == 0 {
+		return fmt.Sprintf("%d B", size)
+	}
+	return fmt.Sprintf("%.2f %s", f, units[i])
+}
+
+type sharePageEntry struct {
+	Name     string
+	URL      string
+	IsDir    bool
+	Size     int64
+	Modified time.Time
+}
+
+type sharePageData struct {
+	Title         string
+	Name          string
+	NeedPassword  bool
+	WrongPassword bool
+	Expires       *time.Time
+	Parent        string
+	Entries       []sharePageEntry
+}
+
+// SharePage serves the read only browse page of the shared folders, the
+// shared files are counted and streamed through here, so the share is
+// checked on every download. The password entered is kept in a cookie
+func SharePage(c *gin.Context) {
+	id := c.Param("id")
+	subPath := utils.FixAndCleanPath(c.Param("path"))
+	s, owner, ok := getShare(c, id)
+	if !ok {
+		return
+	}
+	pwd := c.PostForm("pwd")
+	if pwd == "" {
+		// the links of old versions carry the password in query
+		pwd = c.Query("pwd")
+	}
+	if s.Password != "" && pwd == s.Password {
+		setShareCookie(c, s)
+		c.Redirect(http.StatusSeeOther, c.Request.URL.Path)
+		return
+	}
+	if !checkSharePassword(s, subPath, "", c.Query("sign")) && !checkShareCookie(c, s) {
+		renderSharePage(c, sharePageData{
+			Name:          stdpath.Base(s.Path),
+			NeedPassword:  true,
+			WrongPassword: pwd != "",
+		})
+		return
+	}
+	sp, ok := resolveSharePath(c, s, owner, subPath)
+	if !ok {
+		return
+	}
+	obj, err := fs.Get(c, sp.path, &fs.GetArgs{})
+	if err != nil {
+		common.ErrorResp(c, err, 404)
+		return
+	}
+	if !obj.IsDir() {
+		shareDown(c, sp)
+		return
+	}
+	objs, err := fs.List(c, sp.path, &fs.ListArgs{})
+	if err != nil {
+		common.ErrorResp(c, err, 500)
+		return
+	}
+	objs = common.FilterReadable(sp.owner, sp.path, objs)
+	data := sharePageData{
+		Name:    obj.GetName(),
+		Expires: s.Expires,
+	}
+	if sp.subPath != "/" {
+		data.Parent = shareURL(c, s, stdpath.Dir(sp.subPath))
+	} else {
+		data.Name = stdpath.Base(s.Path)
+	}
+	for _, o := range objs {
+		entry := sharePageEntry{
+			Name:     o.GetName(),
+			IsDir:    o.IsDir(),
+			Size:     o.GetSize(),
+			Modified: o.ModTime(),
+		}
+		// the files are authorized by the cookie or the share without password
+		entry.URL = shareURL(c, s, stdpath.Join(sp.subPath, o.GetName()))
+		data.Entries = append(data.Entries, entry)
+	}
+	renderSharePage(c, data)
+}
+
+func shareCookieName(s *model.Share) string {
+	return "alist_share_" + s.ID
+}
+
+// shareCookieData includes the password, so the cookies are invalid after it's changed
+func shareCookieData(s *model.Share) string {
+	return "/s/" + s.ID + "#" + s.Password
+}
+
+func setShareCookie(c *gin.Context, s *model.Share) {
+	expiration := shareCookieExpiration
+	if s.Expires != nil && time.Until(*s.Expires) < expiration {
+		expiration = time.Until(*s.Expires)
+	}
+	secure := c.Request.TLS != nil || strings.EqualFold(c.GetHeader("X-Forwarded-Proto"), "https")
+	c.SetSameSite(http.SameSiteLaxMode)
+	c.SetCookie(shareCookieName(s), sign.WithDuration(shareCookieData(s), expiration),
+		int(expiration.Seconds()), stdpath.Join(conf.URL.Path, "/s", s.ID), "", secure, true)
+}
+
+func checkShareCookie(c *gin.Context, s *model.Share) bool {
+	cookie, err := c.Cookie(shareCookieName(s))
+	return err == nil && sign.Verify(shareCookieData(s), cookie) == nil
+}
+
+func renderSharePage(c *gin.Context, data sharePageData) {
+	data.Title = setting.GetStr(conf.SiteTitle)
+	c.Header("Content-Type", "text/html; charset=utf-8")
+	c.Header("Referrer-Policy", "no-referrer")
+	c.Status(200)
+	if err := sharePage.Execute(c.Writer, data); err != nil {
+		log.Errorf("failed render share page: %+v", err)
+	}
+}
+
+// shareDown counts the download and streams the file. Every request is counted
+// except the range requests continuing a counted download, which carry the
+// cookie set on it, and all requests are refused once the downloads are exhausted
+func shareDown(c *gin.Context, sp *sharedPath) {
+	// the thumbnails are not served by share, the file would be served instead
+	if c.Query("type") == "thumb" {
+		common.ErrorStrResp(c, "thumbnails are not supported by share", 400)
+		return
+	}
+	if sp.share.IsDownloadsExhausted() {
+		common.ErrorResp(c, errs.ShareDownloadsExhausted, 403)
+		return
+	}
+	counted := !isDownloadContinued(c, sp)
+	if counted {
+		if err := op.ConsumeShareDownload(sp.share); err != nil {
+			if errors.Is(err, errs.ShareDownloadsExhausted) {
+				common.ErrorResp(c, err, 403)
+			} else {
+				common.ErrorResp(c, err, 500, true)
+			}
+			return
+		}
+		setShareDownloadCookie(c, sp)
+	}
+	storage, err := fs.GetStorage(sp.path, &fs.GetStoragesArgs{})
+	if err != nil {
+		common.ErrorResp(c, err, 500)
+		return
+	}
+	link, file, err := fs.Link(c, sp.path, model.LinkArgs{
+		Header:  c.Request.Header,
+		HttpReq: c.Request,
+	})
+	if counted {
+		audit.Record(c, model.AuditDownload, sp.path, "", err)
+	}
+	if err != nil {
+		common.ErrorResp(c, err, 500)
+		return
+	}
+	if storage.GetStorage().ProxyRange {
+		common.ProxyRange(link, file.GetSize())
+	}
+	c.Header("Referrer-Policy", "no-referrer")
+	c.Header("Cache-Control", "max-age=0, no-cache, no-store, must-revalidate")
+	limiters := ratelimit.Download(sp.owner, storage.GetStorage())
+	c.Request = c.Request.WithContext(ratelimit.WithLimiters(c.Request.Context(), limiters...))
+	if err = common.Proxy(c.Writer, c.Request, link, file); err != nil {
+		common.ErrorResp(c, err, 500, true)
+	}
+}
+
+// shareDownloadData differs from shareSignData, so the cookie can't be used as a sign of share
+func shareDownloadData(sp *sharedPath) string {
+	return "/s/" + sp.share.ID + "#" + sp.share.Password + "#download" + sp.subPath
+}
+
+// setShareDownloadCookie sets the short-lived cookie of the counted download to the url of file
+func setShareDownloadCookie(c *gin.Context, sp *sharedPath) {
+	secure := c.Request.TLS != nil || strings.EqualFold(c.GetHeader("X-Forwarded-Proto"), "https")
+	c.SetSameSite(http.SameSiteLaxMode)
+	c.SetCookie(shareDownloadCookieName, sign.WithDuration(shareDownloadData(sp), shareDownloadExpiration),
+		int(shareDownloadExpiration.Seconds()), stdpath.Join(conf.URL.Path, "/s", sp.share.ID, utils.EncodePath(sp.subPath, true)),
+		"", secure, true)
+}
+
+// isDownloadContinued reports whether the request is a range request
+// continuing a download counted before
+func isDownloadContinued(c *gin.Context, sp *sharedPath) bool {
+	rng := c.GetHeader("Range")
+	if rng == "" || strings.HasPrefix(rng, "bytes=0-") {
+		return false
+	}
+	cookie, err := c.Cookie(shareDownloadCookieName)
+	return err == nil && sign.Verify(shareDownloadData(sp), cookie) == nil
+}
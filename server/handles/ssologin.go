@@ -14,6 +14,7 @@ import (
 	"github.com/alist-org/alist/v3/internal/conf"
 	"github.com/alist-org/alist/v3/internal/db"
 	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/internal/op"
 	"github.com/alist-org/alist/v3/internal/setting"
 	"github.com/alist-org/alist/v3/pkg/utils"
 	"github.com/alist-org/alist/v3/pkg/utils/random"
@@ -21,6 +22,7 @@ import (
 	"github.com/coreos/go-oidc"
 	"github.com/gin-gonic/gin"
 	"github.com/go-resty/resty/v2"
+	jsoniter "github.com/json-iterator/go"
 	"github.com/pquerna/otp"
 	"github.com/pquerna/otp/totp"
 	"golang.org/x/oauth2"
@@ -179,6 +181,21 @@ func parseJWT(p string) ([]byte, error) {
 	return payload, nil
 }
 
+// oidcGroups gets the groups claim of the id token, which is an array or a string
+func oidcGroups(payload []byte, key string) []string {
+	claim := utils.Json.Get(payload, key)
+	switch claim.ValueType() {
+	case jsoniter.ArrayValue:
+		var groups []string
+		claim.ToVal(&groups)
+		return groups
+	case jsoniter.StringValue:
+		return strings.Fields(strings.ReplaceAll(claim.ToString(), ",", " "))
+	default:
+		return nil
+	}
+}
+
 func OIDCLoginCallback(c *gin.Context) {
 	useCompatibility := setting.GetBool(conf.SSOCompatibilityMode)
 	argument := c.Query("method")
@@ -258,11 +275,18 @@ func OIDCLoginCallback(c *gin.Context) {
 			user, err = autoRegister(userID, userID, err)
 			if err != nil {
 				common.ErrorResp(c, err, 400)
+				return
+			}
+		}
+		if groupsKey := setting.GetStr(conf.SSOOIDCGroupsKey); groupsKey != "" {
+			if err = op.SyncExternalGroups(user, oidcGroups(payload, groupsKey)); err != nil {
+				utils.Log.Errorf("failed sync oidc groups of user %s: %+v", user.Username, err)
 			}
 		}
 		token, err := common.GenerateToken(user)
 		if err != nil {
 			common.ErrorResp(c, err, 400)
+			return
 		}
 		if useCompatibility {
 			c.Redirect(302, common.GetApiUrl(c.Request)+"/@login?token="+token)
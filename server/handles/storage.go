@@ -3,9 +3,11 @@ package handles
 import (
 	"context"
 	"strconv"
+	"time"
 
 	"github.com/alist-org/alist/v3/internal/conf"
 	"github.com/alist-org/alist/v3/internal/db"
+	"github.com/alist-org/alist/v3/internal/driver"
 	"github.com/alist-org/alist/v3/internal/model"
 	"github.com/alist-org/alist/v3/internal/op"
 	"github.com/alist-org/alist/v3/server/common"
@@ -27,11 +29,57 @@ func ListStorages(c *gin.Context) {
 		return
 	}
 	common.SuccessResp(c, common.PageResp{
-		Content: storages,
+		Content: withDetails(c.Request.Context(), storages),
 		Total:   total,
 	})
 }
 
+type StorageResp struct {
+	model.Storage
+	Details *model.StorageDetails `json:"details,omitempty"`
+}
+
+// withDetails gets the usage of storages concurrently, the ones
+// failed or not reported in time are returned without details
+func withDetails(ctx context.Context, storages []model.Storage) []StorageResp {
+	type result struct {
+		i       int
+		details *model.StorageDetails
+	}
+	resp := make([]StorageResp, len(storages))
+	// buffered, so the late ones won't block after returned
+	results := make(chan result, len(storages))
+	n := 0
+	for i := range storages {
+		resp[i].Storage = storages[i]
+		storage, err := op.GetStorageByMountPath(storages[i].MountPath)
+		if err != nil {
+			continue
+		}
+		if _, ok := storage.(driver.WithDetails); !ok {
+			continue
+		}
+		n++
+		go func(i int) {
+			details, err := op.GetStorageDetails(ctx, storage)
+			if err != nil {
+				log.Warnf("failed get details of storage [%s]: %+v", storages[i].MountPath, err)
+			}
+			results <- result{i: i, details: details}
+		}(i)
+	}
+	timeout := time.After(5 * time.Second)
+	for ; n > 0; n-- {
+		select {
+		case r := <-results:
+			resp[r.i].Details = r.details
+		case <-timeout:
+			return resp
+		}
+	}
+	return resp
+}
+
 func CreateStorage(c *gin.Context) {
 	var req model.Storage
 	if err := c.ShouldBind(&req); err != nil {
@@ -3,6 +3,7 @@ package handles
 import (
 	"math"
 
+	"github.com/alist-org/alist/v3/internal/dedup"
 	"github.com/alist-org/alist/v3/internal/fs"
 	"github.com/alist-org/alist/v3/internal/offline_download/tool"
 	"github.com/alist-org/alist/v3/pkg/utils"
@@ -93,6 +94,10 @@ func taskRoute[T tache.TaskWithInfo](g *gin.RouterGroup, manager *tache.Manager[
 func SetupTaskRoute(g *gin.RouterGroup) {
 	taskRoute(g.Group("/upload"), fs.UploadTaskManager)
 	taskRoute(g.Group("/copy"), fs.CopyTaskManager)
+	taskRoute(g.Group("/move"), fs.MoveTaskManager)
 	taskRoute(g.Group("/offline_download"), tool.DownloadTaskManager)
 	taskRoute(g.Group("/offline_download_transfer"), tool.TransferTaskManager)
+	taskRoute(g.Group("/dedup"), dedup.TaskManager)
+	taskRoute(g.Group("/extract"), fs.ExtractTaskManager)
+	taskRoute(g.Group("/warm_up"), fs.WarmUpTaskManager)
 }
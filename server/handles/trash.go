@@ -0,0 +1,83 @@
+package handles
+
+import (
+	stdpath "path"
+	"strconv"
+
+	"github.com/alist-org/alist/v3/internal/db"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/internal/op"
+	"github.com/alist-org/alist/v3/server/common"
+	"github.com/gin-gonic/gin"
+)
+
+type ListTrashReq struct {
+	model.PageReq
+	StorageID uint `json:"storage_id" form:"storage_id"`
+}
+
+type TrashItemResp struct {
+	model.TrashItem
+	// the original path in alist, empty if the storage is deleted
+	FullPath string `json:"full_path"`
+}
+
+func ListTrash(c *gin.Context) {
+	var req ListTrashReq
+	if err := c.ShouldBind(&req); err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	req.Validate()
+	items, total, err := op.GetTrashItems(req.StorageID, req.Page, req.PerPage)
+	if err != nil {
+		common.ErrorResp(c, err, 500, true)
+		return
+	}
+	mountPaths := make(map[uint]string)
+	resp := make([]TrashItemResp, 0, len(items))
+	for _, item := range items {
+		mountPath, ok := mountPaths[item.StorageID]
+		if !ok {
+			if storage, err := db.GetStorageById(item.StorageID); err == nil {
+				mountPath = storage.MountPath
+			}
+			mountPaths[item.StorageID] = mountPath
+		}
+		r := TrashItemResp{TrashItem: item}
+		if mountPath != "" {
+			r.FullPath = stdpath.Join(mountPath, item.Path)
+		}
+		resp = append(resp, r)
+	}
+	common.SuccessResp(c, common.PageResp{
+		Content: resp,
+		Total:   total,
+	})
+}
+
+func RestoreTrash(c *gin.Context) {
+	id, err := strconv.Atoi(c.Query("id"))
+	if err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	if err = op.RestoreTrashItem(c, uint(id)); err != nil {
+		common.ErrorResp(c, err, 500, true)
+		return
+	}
+	common.SuccessResp(c)
+}
+
+func PurgeTrash(c *gin.Context) {
+	id, err := strconv.Atoi(c.Query("id"))
+	if err != nil {
+		common.ErrorResp(c, err, 400)
+		return
+	}
+	if err = op.PurgeTrashItem(c, uint(id)); err != nil {
+		common.ErrorResp(c, err, 500, true)
+		return
+	}
+	common.SuccessResp(c)
+}
@@ -0,0 +1,15 @@
+package middlewares
+
+import (
+	"github.com/alist-org/alist/v3/internal/conf"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/gin-gonic/gin"
+)
+
+// WebClient puts the client info of the web requests into the context for audit log,
+// the context of webdav and s3 is set by themselves
+func WebClient(c *gin.Context) {
+	c.Set(conf.FrontendKey, model.FrontendWeb)
+	c.Set(conf.ClientIPKey, c.ClientIP())
+	c.Next()
+}
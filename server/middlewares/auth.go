@@ -45,29 +45,12 @@ func Auth(c *gin.Context) {
 		c.Next()
 		return
 	}
-	userClaims, err := common.ParseToken(token)
+	user, err := common.ParseUser(token)
 	if err != nil {
 		common.ErrorResp(c, err, 401)
 		c.Abort()
 		return
 	}
-	user, err := op.GetUserByName(userClaims.Username)
-	if err != nil {
-		common.ErrorResp(c, err, 401)
-		c.Abort()
-		return
-	}
-	// validate password timestamp
-	if userClaims.PwdTS != user.PwdTS {
-		common.ErrorStrResp(c, "Password has been changed, login please", 401)
-		c.Abort()
-		return
-	}
-	if user.Disabled {
-		common.ErrorStrResp(c, "Current user is disabled, replace please", 401)
-		c.Abort()
-		return
-	}
 	c.Set("user", user)
 	log.Debugf("use login token: %+v", user)
 	c.Next()
@@ -99,29 +82,12 @@ func Authn(c *gin.Context) {
 		c.Next()
 		return
 	}
-	userClaims, err := common.ParseToken(token)
+	user, err := common.ParseUser(token)
 	if err != nil {
 		common.ErrorResp(c, err, 401)
 		c.Abort()
 		return
 	}
-	user, err := op.GetUserByName(userClaims.Username)
-	if err != nil {
-		common.ErrorResp(c, err, 401)
-		c.Abort()
-		return
-	}
-	// validate password timestamp
-	if userClaims.PwdTS != user.PwdTS {
-		common.ErrorStrResp(c, "Password has been changed, login please", 401)
-		c.Abort()
-		return
-	}
-	if user.Disabled {
-		common.ErrorStrResp(c, "Current user is disabled, replace please", 401)
-		c.Abort()
-		return
-	}
 	c.Set("user", user)
 	log.Debugf("use login token: %+v", user)
 	c.Next()
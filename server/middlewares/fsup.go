@@ -27,7 +27,7 @@ func FsUp(c *gin.Context) {
 		common.ErrorResp(c, err, 403)
 		return
 	}
-	meta, err := op.GetNearestMeta(stdpath.Dir(path))
+	meta, err := op.GetNearestUserMeta(user, stdpath.Dir(path))
 	if err != nil {
 		if !errors.Is(errors.Cause(err), errs.MetaNotFound) {
 			common.ErrorResp(c, err, 500, true)
@@ -35,7 +35,8 @@ func FsUp(c *gin.Context) {
 			return
 		}
 	}
-	if !(common.CanAccess(user, meta, path, password) && (user.CanWrite() || common.CanWrite(meta, stdpath.Dir(path)))) {
+	canWrite := user.CanWrite() || common.CanWrite(meta, stdpath.Dir(path))
+	if !(common.CanAccess(user, meta, path, password) && common.CanOperate(user, stdpath.Dir(path), model.ACLWrite, canWrite)) {
 		common.ErrorResp(c, errs.PermissionDenied, 403)
 		c.Abort()
 		return
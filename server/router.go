@@ -37,11 +37,15 @@ func Init(e *gin.Engine) {
 	}
 	WebDav(g.Group("/dav"))
 	S3(g.Group("/s3"))
+	g.Use(middlewares.WebClient)
 
 	g.GET("/d/*path", middlewares.Down, handles.Down)
 	g.GET("/p/*path", middlewares.Down, handles.Proxy)
 	g.HEAD("/d/*path", middlewares.Down, handles.Down)
 	g.HEAD("/p/*path", middlewares.Down, handles.Proxy)
+	g.GET("/s/:id/*path", handles.SharePage)
+	g.POST("/s/:id/*path", handles.SharePage)
+	g.GET("/r/:id", handles.FileRequestPage)
 
 	api := g.Group("/api")
 	auth := api.Group("", middlewares.Auth)
@@ -52,6 +56,16 @@ func Init(e *gin.Engine) {
 	api.POST("/auth/login/ldap", handles.LoginLdap)
 	auth.GET("/me", handles.CurrentUser)
 	auth.POST("/me/update", handles.UpdateCurrent)
+	auth.GET("/me/sshkey/list", handles.ListMyPublicKey)
+	auth.POST("/me/sshkey/add", handles.AddMyPublicKey)
+	auth.POST("/me/sshkey/delete", handles.DeleteMyPublicKey)
+	auth.GET("/me/share/list", handles.ListMyShares)
+	auth.POST("/me/share/create", handles.CreateMyShare)
+	auth.POST("/me/share/delete", handles.DeleteMyShare)
+	auth.GET("/me/file_request/list", handles.ListMyFileRequests)
+	auth.POST("/me/file_request/create", handles.CreateMyFileRequest)
+	auth.POST("/me/file_request/delete", handles.DeleteMyFileRequest)
+	auth.GET("/me/file_request/uploads", handles.ListMyFileRequestUploads)
 	auth.POST("/auth/2fa/generate", handles.Generate2FA)
 	auth.POST("/auth/2fa/verify", handles.Verify2FA)
 	auth.GET("/auth/logout", handles.LogOut)
@@ -74,6 +88,10 @@ func Init(e *gin.Engine) {
 	public := api.Group("/public")
 	public.Any("/settings", handles.PublicSettings)
 	public.Any("/offline_download_tools", handles.OfflineDownloadTools)
+	public.POST("/share/get", handles.ShareGet)
+	public.POST("/share/list", handles.ShareList)
+	public.POST("/file_request/get", handles.FileRequestGet)
+	public.PUT("/file_request/put", handles.FileRequestPut)
 
 	_fs(auth.Group("/fs"))
 	admin(auth.Group("/admin", middlewares.AuthAdmin))
@@ -93,6 +111,64 @@ func admin(g *gin.RouterGroup) {
 	meta.POST("/update", handles.UpdateMeta)
 	meta.POST("/delete", handles.DeleteMeta)
 
+	acl := g.Group("/acl")
+	acl.GET("/list", handles.ListACLs)
+	acl.GET("/get", handles.GetACL)
+	acl.POST("/create", handles.CreateACL)
+	acl.POST("/update", handles.UpdateACL)
+	acl.POST("/delete", handles.DeleteACL)
+
+	share := g.Group("/share")
+	share.GET("/list", handles.ListShares)
+	share.POST("/delete", handles.DeleteShare)
+
+	fileRequest := g.Group("/file_request")
+	fileRequest.GET("/list", handles.ListFileRequests)
+	fileRequest.POST("/delete", handles.DeleteFileRequest)
+	fileRequest.GET("/uploads", handles.ListFileRequestUploads)
+
+	audit := g.Group("/audit")
+	audit.GET("/list", handles.ListAuditLogs)
+	audit.POST("/clear", handles.ClearAuditLogs)
+
+	trash := g.Group("/trash")
+	trash.GET("/list", handles.ListTrash)
+	trash.POST("/restore", handles.RestoreTrash)
+	trash.POST("/delete", handles.PurgeTrash)
+
+	dedup := g.Group("/dedup")
+	dedup.POST("/find", handles.FindDuplicates)
+	dedup.GET("/groups", handles.GetDuplicateGroups)
+	dedup.POST("/resolve", handles.ResolveDuplicates)
+
+	syncJob := g.Group("/sync")
+	syncJob.GET("/list", handles.ListSyncJobs)
+	syncJob.POST("/create", handles.CreateSyncJob)
+	syncJob.POST("/update", handles.UpdateSyncJob)
+	syncJob.POST("/delete", handles.DeleteSyncJob)
+	syncJob.POST("/run", handles.RunSyncJob)
+	syncJob.GET("/runs", handles.ListSyncRuns)
+
+	quota := g.Group("/quota")
+	quota.GET("/list", handles.ListQuotas)
+	quota.POST("/create", handles.CreateQuota)
+	quota.POST("/update", handles.UpdateQuota)
+	quota.POST("/delete", handles.DeleteQuota)
+	quota.POST("/reset", handles.ResetQuota)
+
+	group := g.Group("/group")
+	group.GET("/list", handles.ListGroups)
+	group.GET("/get", handles.GetGroup)
+	group.POST("/create", handles.CreateGroup)
+	group.POST("/update", handles.UpdateGroup)
+	group.POST("/delete", handles.DeleteGroup)
+	group.GET("/members", handles.GetGroupMembers)
+	group.POST("/set_members", handles.SetGroupMembers)
+	group.GET("/meta/list", handles.ListGroupMetas)
+	group.POST("/meta/create", handles.CreateGroupMeta)
+	group.POST("/meta/update", handles.UpdateGroupMeta)
+	group.POST("/meta/delete", handles.DeleteGroupMeta)
+
 	user := g.Group("/user")
 	user.GET("/list", handles.ListUsers)
 	user.GET("/get", handles.GetUser)
@@ -101,6 +177,8 @@ func admin(g *gin.RouterGroup) {
 	user.POST("/cancel_2fa", handles.Cancel2FAById)
 	user.POST("/delete", handles.DeleteUser)
 	user.POST("/del_cache", handles.DelUserCache)
+	user.GET("/sshkey/list", handles.ListPublicKeys)
+	user.POST("/sshkey/delete", handles.DeletePublicKey)
 
 	storage := g.Group("/storage")
 	storage.GET("/list", handles.ListStorages)
@@ -164,6 +242,10 @@ func _fs(g *gin.RouterGroup) {
 	// g.POST("/add_qbit", handles.AddQbittorrent)
 	// g.POST("/add_transmission", handles.SetTransmission)
 	g.POST("/add_offline_download", handles.AddOfflineDownload)
+	g.Any("/archive/list", handles.FsArchiveList)
+	g.POST("/archive/extract", handles.FsArchiveExtract)
+	g.Any("/pack", handles.FsPack)
+	g.POST("/pack/task", handles.FsPackTask)
 }
 
 func Cors(r *gin.Engine) {
@@ -2,10 +2,12 @@ package server
 
 import (
 	"context"
+	"net/http"
 	"path"
 	"strings"
 
 	"github.com/alist-org/alist/v3/internal/conf"
+	"github.com/alist-org/alist/v3/internal/model"
 	"github.com/alist-org/alist/v3/server/common"
 	"github.com/alist-org/alist/v3/server/s3"
 	"github.com/gin-gonic/gin"
@@ -29,11 +31,25 @@ func S3(g *gin.RouterGroup) {
 	g.Any("/*path", func(c *gin.Context) {
 		adjustedPath := strings.TrimPrefix(c.Request.URL.Path, path.Join(conf.URL.Path, "/s3"))
 		c.Request.URL.Path = adjustedPath
-		gin.WrapH(h)(c)
+		s3Handler(h)(c)
 	})
 }
 
 func S3Server(g *gin.RouterGroup) {
 	h, _ := s3.NewServer(context.Background())
-	g.Any("/*path", gin.WrapH(h))
+	g.Any("/*path", s3Handler(h))
+}
+
+// s3Handler puts the client info into the request context for audit log,
+// the s3 user is the user of the logs as the token user of the web api
+func s3Handler(h http.Handler) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx := context.WithValue(c.Request.Context(), conf.FrontendKey, model.FrontendS3)
+		ctx = context.WithValue(ctx, conf.ClientIPKey, c.ClientIP())
+		if user, _ := s3.GetUser(); user != nil {
+			ctx = context.WithValue(ctx, "user", user)
+		}
+		c.Request = c.Request.WithContext(ctx)
+		gin.WrapH(h)(c)
+	}
 }
@@ -13,10 +13,13 @@ import (
 	"sync"
 	"time"
 
+	"github.com/alist-org/alist/v3/internal/audit"
+	"github.com/alist-org/alist/v3/internal/conf"
 	"github.com/alist-org/alist/v3/internal/errs"
 	"github.com/alist-org/alist/v3/internal/fs"
 	"github.com/alist-org/alist/v3/internal/model"
 	"github.com/alist-org/alist/v3/internal/op"
+	"github.com/alist-org/alist/v3/internal/ratelimit"
 	"github.com/alist-org/alist/v3/internal/stream"
 	"github.com/alist-org/alist/v3/pkg/http_range"
 	"github.com/alist-org/alist/v3/pkg/utils"
@@ -83,8 +86,11 @@ func (b *s3Backend) ListBucket(ctx context.Context, bucketName string, prefix *g
 
 	response := gofakes3.NewObjectList()
 	path, remaining := prefixParser(prefix)
+	if !canOperate(bucketPath, model.ACLRead) {
+		return response, nil
+	}
 
-	err = b.entryListR(bucketPath, path, remaining, prefix.HasDelimiter, response)
+	err = b.entryListR(ctx, bucketPath, path, remaining, prefix.HasDelimiter, response)
 	if err == gofakes3.ErrNoSuchKey {
 		// AWS just returns an empty list
 		response = gofakes3.NewObjectList()
@@ -106,6 +112,9 @@ func (b *s3Backend) HeadObject(ctx context.Context, bucketName, objectName strin
 	bucketPath := bucket.Path
 
 	fp := path.Join(bucketPath, objectName)
+	if !canOperate(fp, model.ACLRead) {
+		return nil, gofakes3.KeyNotFound(objectName)
+	}
 	fmeta, _ := op.GetNearestMeta(fp)
 	node, err := fs.Get(context.WithValue(ctx, "meta", fmeta), fp, &fs.GetArgs{})
 	if err != nil {
@@ -149,6 +158,9 @@ func (b *s3Backend) GetObject(ctx context.Context, bucketName, objectName string
 	bucketPath := bucket.Path
 
 	fp := path.Join(bucketPath, objectName)
+	if !canOperate(fp, model.ACLRead) {
+		return nil, gofakes3.KeyNotFound(objectName)
+	}
 	fmeta, _ := op.GetNearestMeta(fp)
 	node, err := fs.Get(context.WithValue(ctx, "meta", fmeta), fp, &fs.GetArgs{})
 	if err != nil {
@@ -160,6 +172,9 @@ func (b *s3Backend) GetObject(ctx context.Context, bucketName, objectName string
 	}
 
 	link, file, err := fs.Link(ctx, fp, model.LinkArgs{})
+	if rangeRequest == nil || (rangeRequest.Start == 0 && !rangeRequest.FromEnd) {
+		audit.Record(ctx, model.AuditDownload, fp, "", err)
+	}
 	if err != nil {
 		return nil, err
 	}
@@ -226,6 +241,11 @@ func (b *s3Backend) GetObject(ctx context.Context, bucketName, objectName string
 		}
 	}
 
+	user, _ := ctx.Value("user").(*model.User)
+	if storage, err := fs.GetStorage(fp, &fs.GetStoragesArgs{}); err == nil {
+		rdr = ratelimit.NewReadCloser(ratelimit.WithLimiters(ctx, ratelimit.Download(user, storage.GetStorage())...), rdr)
+	}
+
 	meta := map[string]string{
 		"Last-Modified": node.ModTime().Format(timeFormat),
 		"Content-Type":  utils.GetMimeType(fp),
@@ -280,6 +300,9 @@ func (b *s3Backend) PutObject(
 		reqPath = path.Dir(fp)
 	}
 	log.Debugf("reqPath: %s", reqPath)
+	if !canOperate(path.Dir(fp), model.ACLWrite) {
+		return result, errAccessDenied
+	}
 	fmeta, _ := op.GetNearestMeta(fp)
 	ctx = context.WithValue(ctx, "meta", fmeta)
 
@@ -372,6 +395,9 @@ func (b *s3Backend) deleteObject(ctx context.Context, bucketName, objectName str
 	bucketPath := bucket.Path
 
 	fp := path.Join(bucketPath, objectName)
+	if !canOperate(fp, model.ACLRemove) {
+		return errAccessDenied
+	}
 	fmeta, _ := op.GetNearestMeta(fp)
 	// S3 does not report an error when attemping to delete a key that does not exist, so
 	// we need to skip IsNotExist errors.
@@ -421,8 +447,53 @@ func (b *s3Backend) CopyObject(ctx context.Context, srcBucket, srcKey, dstBucket
 	srcBucketPath := srcB.Path
 
 	srcFp := path.Join(srcBucketPath, srcKey)
+	if !canOperate(srcFp, model.ACLCopy) {
+		return result, errAccessDenied
+	}
 	fmeta, _ := op.GetNearestMeta(srcFp)
 	srcNode, err := fs.Get(context.WithValue(ctx, "meta", fmeta), srcFp, &fs.GetArgs{})
+	if err != nil {
+		return result, gofakes3.KeyNotFound(srcKey)
+	}
+
+	// copy to another dir with the same name like the copy of web, it's done by the storage
+	// if in the same one, so the CopyObject and DeleteObject of clients work like a move
+	dstB, err := getBucketByName(dstBucket)
+	if err != nil {
+		return result, err
+	}
+	dstFp := path.Join(dstB.Path, dstKey)
+	// the existing object is overwritten by PutObject, since the storages may refuse to copy to it
+	if _, err = fs.Get(ctx, dstFp, &fs.GetArgs{}); path.Base(srcFp) == path.Base(dstFp) && !srcNode.IsDir() && errs.IsObjectNotFound(err) {
+		if !canOperate(path.Dir(dstFp), model.ACLWrite) {
+			return result, errAccessDenied
+		}
+		if _, err = fs.Get(ctx, path.Dir(dstFp), &fs.GetArgs{}); errs.IsObjectNotFound(err) {
+			err = fs.MakeDir(ctx, path.Dir(dstFp), true)
+		}
+		if err != nil {
+			return result, err
+		}
+		if _, err = fs.Copy(context.WithValue(ctx, conf.NoTaskKey, struct{}{}), srcFp, path.Dir(dstFp)); err != nil {
+			return result, err
+		}
+		if val, ok := b.meta.Load(srcFp); ok {
+			for k, v := range val.(map[string]string) {
+				if _, found := meta[k]; !found && k != "X-Amz-Acl" {
+					meta[k] = v
+				}
+			}
+		}
+		b.meta.Store(dstFp, meta)
+		hash, err := b.copiedHash(ctx, dstBucket, dstKey, srcNode)
+		if err != nil {
+			return result, err
+		}
+		return gofakes3.CopyObjectResult{
+			ETag:         `"` + hash + `"`,
+			LastModified: gofakes3.NewContentTime(srcNode.ModTime()),
+		}, nil
+	}
 
 	c, err := b.GetObject(ctx, srcBucket, srcKey, nil)
 	if err != nil {
@@ -451,3 +522,26 @@ func (b *s3Backend) CopyObject(ctx context.Context, srcBucket, srcKey, dstBucket
 		LastModified: gofakes3.NewContentTime(srcNode.ModTime()),
 	}, nil
 }
+
+// copiedHash returns the md5 of the copied object for its ETag, which is
+// computed by reading the object if neither the copy nor the src has
+func (b *s3Backend) copiedHash(ctx context.Context, bucketName, objectName string, src model.Obj) (string, error) {
+	bucket, err := getBucketByName(bucketName)
+	if err != nil {
+		return "", err
+	}
+	if node, err := fs.Get(ctx, path.Join(bucket.Path, objectName), &fs.GetArgs{}); err == nil {
+		if h := node.GetHash().GetHash(utils.MD5); h != "" {
+			return strings.ToLower(h), nil
+		}
+	}
+	if h := src.GetHash().GetHash(utils.MD5); h != "" {
+		return strings.ToLower(h), nil
+	}
+	obj, err := b.GetObject(ctx, bucketName, objectName, nil)
+	if err != nil {
+		return "", err
+	}
+	defer obj.Contents.Close()
+	return utils.HashReader(utils.MD5, obj.Contents)
+}
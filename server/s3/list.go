@@ -3,16 +3,17 @@
 package s3
 
 import (
+	"context"
 	"path"
 	"strings"
 
 	"github.com/alist-org/gofakes3"
 )
 
-func (b *s3Backend) entryListR(bucket, fdPath, name string, addPrefix bool, response *gofakes3.ObjectList) error {
+func (b *s3Backend) entryListR(ctx context.Context, bucket, fdPath, name string, addPrefix bool, response *gofakes3.ObjectList) error {
 	fp := path.Join(bucket, fdPath)
 
-	dirEntries, err := getDirEntries(fp)
+	dirEntries, err := getDirEntries(ctx, fp)
 	if err != nil {
 		return err
 	}
@@ -33,7 +34,7 @@ func (b *s3Backend) entryListR(bucket, fdPath, name string, addPrefix bool, resp
 				response.AddPrefix(objectPath)
 				continue
 			}
-			err := b.entryListR(bucket, path.Join(fdPath, object), "", false, response)
+			err := b.entryListR(ctx, bucket, path.Join(fdPath, object), "", false, response)
 			if err != nil {
 				return err
 			}
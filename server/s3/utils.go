@@ -13,6 +13,7 @@ import (
 	"github.com/alist-org/alist/v3/internal/model"
 	"github.com/alist-org/alist/v3/internal/op"
 	"github.com/alist-org/alist/v3/internal/setting"
+	"github.com/alist-org/alist/v3/server/common"
 	"github.com/alist-org/gofakes3"
 )
 
@@ -40,8 +41,28 @@ func getBucketByName(name string) (Bucket, error) {
 	return Bucket{}, gofakes3.BucketNotFound(name)
 }
 
-func getDirEntries(path string) ([]model.Obj, error) {
-	ctx := context.Background()
+var errAccessDenied = gofakes3.ErrorMessage("AccessDenied", "Access Denied")
+
+// canOperate checks the acl rules of the s3 user,
+// all the operations are allowed if the s3 user is not set
+func canOperate(path string, operation int32) bool {
+	user, err := GetUser()
+	if err != nil {
+		return false
+	}
+	return user == nil || common.CanOperate(user, path, operation, true)
+}
+
+// GetUser returns the user whose permissions are applied to s3, it's nil if not set
+func GetUser() (*model.User, error) {
+	name := setting.GetStr(conf.S3User)
+	if name == "" {
+		return nil, nil
+	}
+	return op.GetUserByName(name)
+}
+
+func getDirEntries(ctx context.Context, path string) ([]model.Obj, error) {
 	meta, _ := op.GetNearestMeta(path)
 	fi, err := fs.Get(context.WithValue(ctx, "meta", meta), path, &fs.GetArgs{})
 	if errs.IsNotFoundError(err) {
@@ -58,6 +79,13 @@ func getDirEntries(path string) ([]model.Obj, error) {
 	if err != nil {
 		return nil, err
 	}
+	user, err := GetUser()
+	if err != nil {
+		return nil, err
+	}
+	if user != nil {
+		dirEntries = common.FilterReadable(user, path, dirEntries)
+	}
 
 	return dirEntries, nil
 }
@@ -0,0 +1,314 @@
+package sftp
+
+import (
+	"context"
+	"errors"
+	"io"
+	"os"
+	stdpath "path"
+	"sync"
+
+	"github.com/alist-org/alist/v3/internal/audit"
+	"github.com/alist-org/alist/v3/internal/conf"
+	"github.com/alist-org/alist/v3/internal/errs"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/pkg/utils"
+	"github.com/alist-org/alist/v3/server/ftp"
+	"github.com/pkg/sftp"
+)
+
+// handler maps the sftp requests to the alist file system,
+// the permissions are checked by the functions of ftp package
+type handler struct {
+	user *model.User
+	ip   string
+}
+
+func newHandlers(user *model.User, ip string) sftp.Handlers {
+	h := &handler{user: user, ip: ip}
+	return sftp.Handlers{FileGet: h, FilePut: h, FileCmd: h, FileList: h}
+}
+
+func (h *handler) ctx(r *sftp.Request) context.Context {
+	ctx := context.WithValue(r.Context(), "user", h.user)
+	ctx = context.WithValue(ctx, conf.FrontendKey, model.FrontendSFTP)
+	return context.WithValue(ctx, conf.ClientIPKey, h.ip)
+}
+
+func (h *handler) fullPath(p string) (string, error) {
+	return h.user.JoinPath(p)
+}
+
+// toSftpErr converts the errors so that the client gets the right status code
+func toSftpErr(err error) error {
+	switch {
+	case err == nil:
+		return nil
+	case errs.IsNotFoundError(err):
+		return os.ErrNotExist
+	case errors.Is(err, errs.PermissionDenied), errors.Is(err, errs.RelativePath):
+		return os.ErrPermission
+	case errs.IsNotImplement(err), errs.IsNotSupportError(err):
+		return sftp.ErrSSHFxOpUnsupported
+	default:
+		return err
+	}
+}
+
+func (h *handler) Fileread(r *sftp.Request) (io.ReaderAt, error) {
+	path, err := h.fullPath(r.Filepath)
+	if err != nil {
+		return nil, toSftpErr(err)
+	}
+	ctx := h.ctx(r)
+	obj, err := ftp.Get(ctx, path)
+	if err != nil {
+		return nil, toSftpErr(err)
+	}
+	if obj.IsDir() {
+		return nil, sftp.ErrSSHFxFailure
+	}
+	// the reader is opened on the first read, so the download is recorded here
+	audit.Record(ctx, model.AuditDownload, path, "", nil)
+	return &reader{ctx: ctx, path: path, obj: obj}, nil
+}
+
+func (h *handler) Filewrite(r *sftp.Request) (io.WriterAt, error) {
+	path, err := h.fullPath(r.Filepath)
+	if err != nil {
+		return nil, toSftpErr(err)
+	}
+	ctx := h.ctx(r)
+	if err = ftp.CheckWrite(ctx, stdpath.Dir(path)); err != nil {
+		return nil, toSftpErr(err)
+	}
+	tmpFile, err := os.CreateTemp(conf.Conf.TempDir, "sftp-*")
+	if err != nil {
+		return nil, err
+	}
+	w := &writer{ctx: ctx, path: path, tmpFile: tmpFile}
+	flags := r.Pflags()
+	// keep the existing content so that the client can resume the upload
+	if !flags.Trunc {
+		if obj, err := ftp.Get(ctx, path); err == nil && !obj.IsDir() && obj.GetSize() > 0 {
+			if flags.Excl {
+				w.discard()
+				return nil, os.ErrExist
+			}
+			rc, err := ftp.OpenReader(ctx, path, obj, 0)
+			if err == nil {
+				_, err = io.Copy(tmpFile, rc)
+				_ = rc.Close()
+			}
+			if err != nil {
+				w.discard()
+				return nil, err
+			}
+		}
+	}
+	return w, nil
+}
+
+func (h *handler) Filecmd(r *sftp.Request) error {
+	path, err := h.fullPath(r.Filepath)
+	if err != nil {
+		return toSftpErr(err)
+	}
+	ctx := h.ctx(r)
+	switch r.Method {
+	case "Setstat":
+		// the storages don't support the attributes
+		return nil
+	case "Rename", "PosixRename":
+		target, err := h.fullPath(r.Target)
+		if err != nil {
+			return toSftpErr(err)
+		}
+		return toSftpErr(ftp.Rename(ctx, path, target))
+	case "Rmdir":
+		return toSftpErr(ftp.RemoveDir(ctx, path))
+	case "Remove":
+		return toSftpErr(ftp.Remove(ctx, path))
+	case "Mkdir":
+		return toSftpErr(ftp.MakeDir(ctx, path))
+	default:
+		return sftp.ErrSSHFxOpUnsupported
+	}
+}
+
+func (h *handler) PosixRename(r *sftp.Request) error {
+	return h.Filecmd(r)
+}
+
+func (h *handler) Filelist(r *sftp.Request) (sftp.ListerAt, error) {
+	path, err := h.fullPath(r.Filepath)
+	if err != nil {
+		return nil, toSftpErr(err)
+	}
+	ctx := h.ctx(r)
+	switch r.Method {
+	case "List":
+		objs, err := ftp.List(ctx, path)
+		if err != nil {
+			return nil, toSftpErr(err)
+		}
+		infos := make(listerAt, 0, len(objs))
+		for _, obj := range objs {
+			infos = append(infos, &fileInfo{obj})
+		}
+		return infos, nil
+	case "Stat":
+		obj, err := ftp.Get(ctx, path)
+		if err != nil {
+			return nil, toSftpErr(err)
+		}
+		return listerAt{&fileInfo{obj}}, nil
+	default:
+		return nil, sftp.ErrSSHFxOpUnsupported
+	}
+}
+
+type listerAt []os.FileInfo
+
+func (l listerAt) ListAt(f []os.FileInfo, offset int64) (int, error) {
+	if offset >= int64(len(l)) {
+		return 0, io.EOF
+	}
+	n := copy(f, l[offset:])
+	if n < len(f) {
+		return n, io.EOF
+	}
+	return n, nil
+}
+
+type fileInfo struct {
+	model.Obj
+}
+
+func (f *fileInfo) Name() string {
+	return f.GetName()
+}
+
+func (f *fileInfo) Size() int64 {
+	return f.GetSize()
+}
+
+func (f *fileInfo) Mode() os.FileMode {
+	if f.IsDir() {
+		return os.ModeDir | 0755
+	}
+	return 0644
+}
+
+func (f *fileInfo) Sys() any {
+	return nil
+}
+
+// readWindow is the size of the data kept after read, the clients send many read
+// requests at once, which may be handled out of order
+const readWindow = 4 * utils.MB
+
+var openReader = ftp.OpenReader
+
+// reader reads the file sequentially with one stream and keeps the recent data,
+// the stream is reopened only if the client seeks out of the window
+type reader struct {
+	ctx  context.Context
+	path string
+	obj  model.Obj
+
+	mu sync.Mutex
+	rc io.ReadCloser
+	// the data read from rc recently, which ends at the offset of rc
+	buf      []byte
+	bufStart int64
+}
+
+func (r *reader) ReadAt(p []byte, off int64) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	size := r.obj.GetSize()
+	if off >= size {
+		return 0, io.EOF
+	}
+	offset := r.bufStart + int64(len(r.buf))
+	if r.rc == nil || off < r.bufStart || off > offset+readWindow {
+		if err := r.open(off); err != nil {
+			return 0, err
+		}
+		offset = off
+	}
+	if end := min(size, off+int64(len(p))); end > offset {
+		l := len(r.buf)
+		r.buf = append(r.buf, make([]byte, end-offset)...)
+		n, err := io.ReadFull(r.rc, r.buf[l:])
+		r.buf = r.buf[:l+n]
+		if err != nil && !errors.Is(err, io.EOF) && !errors.Is(err, io.ErrUnexpectedEOF) {
+			_ = r.close()
+			return 0, err
+		}
+	}
+	var n int
+	if i := off - r.bufStart; i < int64(len(r.buf)) {
+		n = copy(p, r.buf[i:])
+	}
+	if cut := len(r.buf) - readWindow; cut > 0 {
+		r.buf = append(r.buf[:0], r.buf[cut:]...)
+		r.bufStart += int64(cut)
+	}
+	if n < len(p) {
+		return n, io.EOF
+	}
+	return n, nil
+}
+
+func (r *reader) open(off int64) error {
+	_ = r.close()
+	rc, err := openReader(r.ctx, r.path, r.obj, off)
+	if err != nil {
+		return err
+	}
+	r.rc, r.buf, r.bufStart = rc, r.buf[:0], off
+	return nil
+}
+
+func (r *reader) close() error {
+	if r.rc == nil {
+		return nil
+	}
+	err := r.rc.Close()
+	r.rc = nil
+	return err
+}
+
+func (r *reader) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.close()
+}
+
+// writer writes to a temp file which is uploaded on close
+type writer struct {
+	ctx     context.Context
+	path    string
+	tmpFile *os.File
+}
+
+func (w *writer) WriteAt(p []byte, off int64) (int, error) {
+	return w.tmpFile.WriteAt(p, off)
+}
+
+func (w *writer) Close() error {
+	if _, err := w.tmpFile.Seek(0, io.SeekStart); err != nil {
+		w.discard()
+		return err
+	}
+	return toSftpErr(ftp.PutTempFile(w.ctx, w.path, w.tmpFile))
+}
+
+func (w *writer) discard() {
+	_ = w.tmpFile.Close()
+	_ = os.Remove(w.tmpFile.Name())
+}
+
+var _ os.FileInfo = (*fileInfo)(nil)
@@ -0,0 +1,62 @@
+package sftp
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"testing"
+
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/pkg/utils"
+	"github.com/alist-org/alist/v3/server/ftp"
+)
+
+func TestReaderReadAt(t *testing.T) {
+	data := make([]byte, 10*utils.MB)
+	for i := range data {
+		data[i] = byte(i % 251)
+	}
+	var opened []int64
+	openReader = func(ctx context.Context, path string, obj model.Obj, offset int64) (io.ReadCloser, error) {
+		opened = append(opened, offset)
+		return io.NopCloser(bytes.NewReader(data[offset:])), nil
+	}
+	t.Cleanup(func() {
+		openReader = ftp.OpenReader
+	})
+	const packet = 32 * utils.KB
+	tests := []struct {
+		name string
+		offs []int64
+		// the offsets the stream is opened at
+		opened []int64
+	}{
+		{name: "sequential", offs: []int64{0, packet, 2 * packet}, opened: []int64{0}},
+		{name: "out of order", offs: []int64{0, 2 * packet, packet, 4 * packet, 3 * packet}, opened: []int64{0}},
+		{name: "seek back out of the window", offs: []int64{9 * utils.MB, 0}, opened: []int64{9 * utils.MB, 0}},
+		{name: "seek ahead out of the window", offs: []int64{0, 5 * utils.MB}, opened: []int64{0, 5 * utils.MB}},
+		{name: "the end", offs: []int64{10*utils.MB - 100}, opened: []int64{10*utils.MB - 100}},
+	}
+	for _, tt := range tests {
+		opened = nil
+		r := &reader{ctx: context.Background(), obj: &model.Object{Size: int64(len(data))}}
+		for _, off := range tt.offs {
+			p := make([]byte, packet)
+			n, err := r.ReadAt(p, off)
+			end := min(int64(len(data)), off+packet)
+			if int64(n) != end-off || !bytes.Equal(p[:n], data[off:end]) || (err != nil) != (end-off < packet) {
+				t.Errorf("%s: ReadAt(%d) = %d, %v, want %d bytes", tt.name, off, n, err, end-off)
+			}
+		}
+		_ = r.Close()
+		if len(opened) != len(tt.opened) {
+			t.Errorf("%s: opened at %v, want %v", tt.name, opened, tt.opened)
+			continue
+		}
+		for i := range opened {
+			if opened[i] != tt.opened[i] {
+				t.Errorf("%s: opened at %v, want %v", tt.name, opened, tt.opened)
+			}
+		}
+	}
+}
@@ -0,0 +1,270 @@
+package sftp
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/pem"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/alist-org/alist/v3/cmd/flags"
+	"github.com/alist-org/alist/v3/internal/conf"
+	"github.com/alist-org/alist/v3/internal/db"
+	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/internal/op"
+	"github.com/pkg/errors"
+	"github.com/pkg/sftp"
+	log "github.com/sirupsen/logrus"
+	"golang.org/x/crypto/ssh"
+)
+
+// keyIDExtension is the extension of ssh.Permissions recording the id of public key authenticated with
+const keyIDExtension = "alist-key-id"
+
+// Server serves the alist virtual file system over sftp
+type Server struct {
+	Addr   string
+	config *ssh.ServerConfig
+
+	mu       sync.Mutex
+	listener net.Listener
+	conns    map[net.Conn]struct{}
+	closed   bool
+}
+
+func NewServer() (*Server, error) {
+	s := &Server{
+		Addr:  fmt.Sprintf("%s:%d", conf.Conf.Scheme.Address, conf.Conf.SFTP.Port),
+		conns: make(map[net.Conn]struct{}),
+	}
+	s.config = &ssh.ServerConfig{
+		PasswordCallback:  passwordCallback,
+		PublicKeyCallback: publicKeyCallback,
+		ServerVersion:     "SSH-2.0-AList",
+	}
+	signers, err := loadHostKeys(filepath.Join(flags.DataDir, "ssh"))
+	if err != nil {
+		return nil, err
+	}
+	for _, signer := range signers {
+		s.config.AddHostKey(signer)
+	}
+	return s, nil
+}
+
+// loadHostKeys loads the host keys from dir, the missing ones are generated,
+// so that the fingerprints of the server won't change after restart
+func loadHostKeys(dir string) ([]ssh.Signer, error) {
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, errors.WithStack(err)
+	}
+	generators := map[string]func() (any, error){
+		"ssh_host_ed25519_key": func() (any, error) {
+			_, key, err := ed25519.GenerateKey(rand.Reader)
+			return key, err
+		},
+		"ssh_host_rsa_key": func() (any, error) {
+			return rsa.GenerateKey(rand.Reader, 3072)
+		},
+	}
+	var signers []ssh.Signer
+	for name, generate := range generators {
+		keyPath := filepath.Join(dir, name)
+		data, err := os.ReadFile(keyPath)
+		if os.IsNotExist(err) {
+			log.Infof("generating sftp host key %s", keyPath)
+			key, err := generate()
+			if err != nil {
+				return nil, errors.WithStack(err)
+			}
+			block, err := ssh.MarshalPrivateKey(key, "")
+			if err != nil {
+				return nil, errors.WithStack(err)
+			}
+			data = pem.EncodeToMemory(block)
+			if err = os.WriteFile(keyPath, data, 0600); err != nil {
+				return nil, errors.WithStack(err)
+			}
+		} else if err != nil {
+			return nil, errors.WithStack(err)
+		}
+		signer, err := ssh.ParsePrivateKey(data)
+		if err != nil {
+			return nil, errors.WithMessagef(err, "failed parse host key %s", keyPath)
+		}
+		signers = append(signers, signer)
+	}
+	return signers, nil
+}
+
+func getUser(username string) (*model.User, error) {
+	user, err := op.GetUserByName(username)
+	if err != nil {
+		return nil, err
+	}
+	if user.Disabled || !user.CanFTPAccess() {
+		return nil, errors.New("permission denied")
+	}
+	return user, nil
+}
+
+func passwordCallback(meta ssh.ConnMetadata, password []byte) (*ssh.Permissions, error) {
+	user, err := getUser(meta.User())
+	if err != nil {
+		return nil, err
+	}
+	if err = user.ValidateRawPassword(string(password)); err != nil {
+		log.Infof("[sftp] failed login of [%s] from %s", meta.User(), meta.RemoteAddr())
+		return nil, err
+	}
+	return nil, nil
+}
+
+func publicKeyCallback(meta ssh.ConnMetadata, key ssh.PublicKey) (*ssh.Permissions, error) {
+	user, err := getUser(meta.User())
+	if err != nil {
+		return nil, err
+	}
+	k, err := db.GetSSHPublicKeyByUserIdAndFingerprint(user.ID, ssh.FingerprintSHA256(key))
+	if err != nil {
+		return nil, errors.New("public key not found")
+	}
+	pubKey, err := k.GetKey()
+	if err != nil || !bytes.Equal(pubKey.Marshal(), key.Marshal()) {
+		return nil, errors.New("public key mismatch")
+	}
+	// the callback is also called for the queries of unsigned keys,
+	// so the use is recorded by updateKeyLastUsed after the handshake
+	return &ssh.Permissions{Extensions: map[string]string{keyIDExtension: strconv.FormatUint(uint64(k.ID), 10)}}, nil
+}
+
+// updateKeyLastUsed records the use of the public key which the user authenticated with
+func updateKeyLastUsed(perms *ssh.Permissions) {
+	if perms == nil || perms.Extensions[keyIDExtension] == "" {
+		return
+	}
+	id, err := strconv.ParseUint(perms.Extensions[keyIDExtension], 10, 64)
+	if err != nil {
+		return
+	}
+	k, err := db.GetSSHPublicKeyById(uint(id))
+	if err != nil {
+		log.Warnf("[sftp] failed get key %d: %+v", id, err)
+		return
+	}
+	k.UpdateLastUsedTime()
+	if err = op.UpdateSSHPublicKey(k); err != nil {
+		log.Warnf("[sftp] failed update last used time of key %d: %+v", k.ID, err)
+	}
+}
+
+func (s *Server) ListenAndServe() error {
+	l, err := net.Listen("tcp", s.Addr)
+	if err != nil {
+		return err
+	}
+	return s.Serve(l)
+}
+
+func (s *Server) Serve(l net.Listener) error {
+	s.mu.Lock()
+	if s.closed {
+		s.mu.Unlock()
+		return net.ErrClosed
+	}
+	s.listener = l
+	s.mu.Unlock()
+	for {
+		conn, err := l.Accept()
+		if err != nil {
+			s.mu.Lock()
+			closed := s.closed
+			s.mu.Unlock()
+			if closed {
+				return nil
+			}
+			var ne net.Error
+			if errors.As(err, &ne) && ne.Timeout() {
+				time.Sleep(50 * time.Millisecond)
+				continue
+			}
+			return err
+		}
+		s.mu.Lock()
+		s.conns[conn] = struct{}{}
+		s.mu.Unlock()
+		go func() {
+			s.handleConn(conn)
+			s.mu.Lock()
+			delete(s.conns, conn)
+			s.mu.Unlock()
+		}()
+	}
+}
+
+// Close stops accepting new connections and closes all the connections
+func (s *Server) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.closed = true
+	for conn := range s.conns {
+		_ = conn.Close()
+	}
+	if s.listener != nil {
+		return s.listener.Close()
+	}
+	return nil
+}
+
+func (s *Server) handleConn(conn net.Conn) {
+	defer conn.Close()
+	sconn, chans, reqs, err := ssh.NewServerConn(conn, s.config)
+	if err != nil {
+		log.Debugf("[sftp] handshake with %s failed: %+v", conn.RemoteAddr(), err)
+		return
+	}
+	defer sconn.Close()
+	go ssh.DiscardRequests(reqs)
+	updateKeyLastUsed(sconn.Permissions)
+	user, err := getUser(sconn.User())
+	if err != nil {
+		return
+	}
+	ip, _, _ := net.SplitHostPort(conn.RemoteAddr().String())
+	for newChannel := range chans {
+		if newChannel.ChannelType() != "session" {
+			_ = newChannel.Reject(ssh.UnknownChannelType, "unknown channel type")
+			continue
+		}
+		channel, requests, err := newChannel.Accept()
+		if err != nil {
+			log.Errorf("[sftp] failed accept channel: %+v", err)
+			continue
+		}
+		go func() {
+			defer channel.Close()
+			for req := range requests {
+				// only the sftp subsystem is supported, no shell or exec
+				ok := req.Type == "subsystem" && len(req.Payload) > 4 && string(req.Payload[4:]) == "sftp"
+				_ = req.Reply(ok, nil)
+				if !ok {
+					continue
+				}
+				server := sftp.NewRequestServer(channel, newHandlers(user, ip))
+				if err := server.Serve(); err != nil && !errors.Is(err, io.EOF) {
+					log.Debugf("[sftp] session of [%s] ended: %+v", user.Username, err)
+				}
+				_ = server.Close()
+				return
+			}
+		}()
+	}
+}
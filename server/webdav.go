@@ -43,6 +43,8 @@ func WebDav(dav *gin.RouterGroup) {
 func ServeWebDAV(c *gin.Context) {
 	user := c.MustGet("user").(*model.User)
 	ctx := context.WithValue(c.Request.Context(), "user", user)
+	ctx = context.WithValue(ctx, conf.FrontendKey, model.FrontendWebDAV)
+	ctx = context.WithValue(ctx, conf.ClientIPKey, c.ClientIP())
 	handler.ServeHTTP(c.Writer, c.Request.WithContext(ctx))
 }
 
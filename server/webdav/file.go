@@ -14,6 +14,7 @@ import (
 	"github.com/alist-org/alist/v3/internal/fs"
 	"github.com/alist-org/alist/v3/internal/model"
 	"github.com/alist-org/alist/v3/internal/op"
+	"github.com/alist-org/alist/v3/server/common"
 )
 
 // slashClean is equivalent to but slightly more efficient than
@@ -29,26 +30,20 @@ func slashClean(name string) string {
 //
 // See section 9.9.4 for when various HTTP status codes apply.
 func moveFiles(ctx context.Context, src, dst string, overwrite bool) (status int, err error) {
-	srcDir := path.Dir(src)
-	dstDir := path.Dir(dst)
-	srcName := path.Base(src)
-	dstName := path.Base(dst)
-	if srcDir == dstDir {
-		err = fs.Rename(ctx, src, dstName)
+	status = http.StatusCreated
+	if _, err = fs.Get(ctx, dst, &fs.GetArgs{NoLog: true}); err == nil {
+		status = http.StatusNoContent
+	}
+	if path.Dir(src) == path.Dir(dst) {
+		err = fs.Rename(ctx, src, path.Base(dst))
 	} else {
-		err = fs.Move(ctx, src, dstDir)
-		if err != nil {
-			return http.StatusInternalServerError, err
-		}
-		if srcName != dstName {
-			err = fs.Rename(ctx, path.Join(dstDir, srcName), dstName)
-		}
+		// moved without task like COPY, so the result is known when responding
+		_, err = fs.MoveAs(context.WithValue(ctx, conf.NoTaskKey, struct{}{}), src, dst)
 	}
 	if err != nil {
 		return http.StatusInternalServerError, err
 	}
-	// TODO if there are no files copy, should return 204
-	return http.StatusCreated, nil
+	return status, nil
 }
 
 // copyFiles copies files and/or directories from src to dst.
@@ -84,7 +79,7 @@ func walkFS(ctx context.Context, depth int, name string, info model.Obj, walkFn
 	if depth == 1 {
 		depth = 0
 	}
-	meta, _ := op.GetNearestMeta(name)
+	meta, _ := op.GetNearestUserMeta(ctx.Value("user").(*model.User), name)
 	// Read directory names.
 	objs, err := fs.List(context.WithValue(ctx, "meta", meta), name, &fs.ListArgs{})
 	//f, err := fs.OpenFile(ctx, name, os.O_RDONLY, 0)
@@ -96,6 +91,7 @@ func walkFS(ctx context.Context, depth int, name string, info model.Obj, walkFn
 	if err != nil {
 		return walkFn(name, info, err)
 	}
+	objs = common.FilterReadable(ctx.Value("user").(*model.User), name, objs)
 
 	for _, fileInfo := range objs {
 		filename := path.Join(name, fileInfo.GetName())
@@ -17,7 +17,11 @@ import (
 	"strings"
 	"time"
 
+	"github.com/alist-org/alist/v3/internal/errs"
+	"github.com/alist-org/alist/v3/internal/fs"
 	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/internal/op"
+	log "github.com/sirupsen/logrus"
 )
 
 // Proppatch describes a property update instruction as defined in RFC 4918.
@@ -108,6 +112,9 @@ var liveProps = map[xml.Name]struct {
 	findFn func(context.Context, LockSystem, string, model.Obj) (string, error)
 	// dir is true if the property applies to directories.
 	dir bool
+	// named is true if the property is only returned when it's named,
+	// it's not returned for allprop.
+	named bool
 }{
 	{Space: "DAV:", Local: "resourcetype"}: {
 		findFn: findResourceType,
@@ -160,6 +167,18 @@ var liveProps = map[xml.Name]struct {
 		findFn: findSupportedLock,
 		dir:    true,
 	},
+
+	// http://www.webdav.org/specs/rfc4331.html
+	{Space: "DAV:", Local: "quota-available-bytes"}: {
+		findFn: findQuotaAvailableBytes,
+		dir:    true,
+		named:  true,
+	},
+	{Space: "DAV:", Local: "quota-used-bytes"}: {
+		findFn: findQuotaUsedBytes,
+		dir:    true,
+		named:  true,
+	},
 }
 
 // TODO(nigeltao) merge props and allprop?
@@ -168,7 +187,7 @@ var liveProps = map[xml.Name]struct {
 //
 // Each Propstat has a unique status and each property name will only be part
 // of one Propstat element.
-func props(ctx context.Context, ls LockSystem, fi model.Obj, pnames []xml.Name) ([]Propstat, error) {
+func props(ctx context.Context, ls LockSystem, name string, fi model.Obj, pnames []xml.Name) ([]Propstat, error) {
 	//f, err := fs.OpenFile(ctx, name, os.O_RDONLY, 0)
 	//if err != nil {
 	//	return nil, err
@@ -199,7 +218,13 @@ func props(ctx context.Context, ls LockSystem, fi model.Obj, pnames []xml.Name)
 		}
 		// Otherwise, it must either be a live property or we don't know it.
 		if prop := liveProps[pn]; prop.findFn != nil && (prop.dir || !isDir) {
-			innerXML, err := prop.findFn(ctx, ls, fi.GetName(), fi)
+			innerXML, err := prop.findFn(ctx, ls, name, fi)
+			if errors.Is(err, ErrNotImplemented) {
+				pstatNotFound.Props = append(pstatNotFound.Props, Property{
+					XMLName: pn,
+				})
+				continue
+			}
 			if err != nil {
 				return nil, err
 			}
@@ -258,14 +283,19 @@ func propnames(ctx context.Context, ls LockSystem, fi model.Obj) ([]xml.Name, er
 // returned if they are named in 'include'.
 //
 // See http://www.webdav.org/specs/rfc4918.html#METHOD_PROPFIND
-func allprop(ctx context.Context, ls LockSystem, fi model.Obj, include []xml.Name) ([]Propstat, error) {
-	pnames, err := propnames(ctx, ls, fi)
+func allprop(ctx context.Context, ls LockSystem, name string, fi model.Obj, include []xml.Name) ([]Propstat, error) {
+	names, err := propnames(ctx, ls, fi)
 	if err != nil {
 		return nil, err
 	}
 	// Add names from include if they are not already covered in pnames.
+	pnames := make([]xml.Name, 0, len(names))
 	nameset := make(map[xml.Name]bool)
-	for _, pn := range pnames {
+	for _, pn := range names {
+		if liveProps[pn].named {
+			continue
+		}
+		pnames = append(pnames, pn)
 		nameset[pn] = true
 	}
 	for _, pn := range include {
@@ -273,7 +303,7 @@ func allprop(ctx context.Context, ls LockSystem, fi model.Obj, include []xml.Nam
 			pnames = append(pnames, pn)
 		}
 	}
-	return props(ctx, ls, fi, pnames)
+	return props(ctx, ls, name, fi, pnames)
 }
 
 // Patch patches the properties of resource name. The return values are
@@ -371,7 +401,7 @@ func findResourceType(ctx context.Context, ls LockSystem, name string, fi model.
 }
 
 func findDisplayName(ctx context.Context, ls LockSystem, name string, fi model.Obj) (string, error) {
-	if slashClean(name) == "/" {
+	if slashClean(fi.GetName()) == "/" {
 		// Hide the real name of a possibly prefixed root directory.
 		return "", nil
 	}
@@ -393,6 +423,39 @@ func findCreationDate(ctx context.Context, ls LockSystem, name string, fi model.
 	return fi.CreateTime().UTC().Format(time.RFC3339), nil
 }
 
+func findQuotaAvailableBytes(ctx context.Context, ls LockSystem, name string, fi model.Obj) (string, error) {
+	details, err := getStorageDetails(ctx, name)
+	if err != nil {
+		return "", err
+	}
+	return strconv.FormatInt(details.FreeSpace, 10), nil
+}
+
+func findQuotaUsedBytes(ctx context.Context, ls LockSystem, name string, fi model.Obj) (string, error) {
+	details, err := getStorageDetails(ctx, name)
+	if err != nil {
+		return "", err
+	}
+	return strconv.FormatInt(details.UsedSpace, 10), nil
+}
+
+// getStorageDetails returns ErrNotImplemented if the usage of storage of name can't be got,
+// so the quota properties are reported as not found
+func getStorageDetails(ctx context.Context, name string) (*model.StorageDetails, error) {
+	storage, err := fs.GetStorage(name, &fs.GetStoragesArgs{})
+	if err != nil {
+		return nil, ErrNotImplemented
+	}
+	details, err := op.GetStorageDetails(ctx, storage)
+	if err != nil {
+		if !errs.IsNotImplement(err) {
+			log.Warnf("failed get details of storage [%s]: %+v", storage.GetStorage().MountPath, err)
+		}
+		return nil, ErrNotImplemented
+	}
+	return details, nil
+}
+
 // ErrNotImplemented should be returned by optional interfaces if they
 // want the original implementation to be used.
 var ErrNotImplemented = errors.New("not implemented")
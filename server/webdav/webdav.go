@@ -18,9 +18,11 @@ import (
 
 	"github.com/alist-org/alist/v3/internal/stream"
 
+	"github.com/alist-org/alist/v3/internal/audit"
 	"github.com/alist-org/alist/v3/internal/errs"
 	"github.com/alist-org/alist/v3/internal/fs"
 	"github.com/alist-org/alist/v3/internal/model"
+	"github.com/alist-org/alist/v3/internal/ratelimit"
 	"github.com/alist-org/alist/v3/internal/sign"
 	"github.com/alist-org/alist/v3/pkg/utils"
 	"github.com/alist-org/alist/v3/server/common"
@@ -223,6 +225,9 @@ func (h *Handler) handleGetHeadPost(w http.ResponseWriter, r *http.Request) (sta
 	if err != nil {
 		return http.StatusForbidden, err
 	}
+	if !common.CanOperate(user, reqPath, model.ACLRead, true) {
+		return http.StatusForbidden, errs.PermissionDenied
+	}
 	fi, err := fs.Get(ctx, reqPath, &fs.GetArgs{})
 	if err != nil {
 		return http.StatusNotFound, err
@@ -244,12 +249,17 @@ func (h *Handler) handleGetHeadPost(w http.ResponseWriter, r *http.Request) (sta
 	downProxyUrl := storage.GetStorage().DownProxyUrl
 	if storage.GetStorage().WebdavNative() || (storage.GetStorage().WebdavProxy() && downProxyUrl == "") {
 		link, _, err := fs.Link(ctx, reqPath, model.LinkArgs{Header: r.Header, HttpReq: r})
+		if common.IsDownloadStart(r) {
+			audit.Record(ctx, model.AuditDownload, reqPath, "", err)
+		}
 		if err != nil {
 			return http.StatusInternalServerError, err
 		}
 		if storage.GetStorage().ProxyRange {
 			common.ProxyRange(link, fi.GetSize())
 		}
+		user, _ := ctx.Value("user").(*model.User)
+		r = r.WithContext(ratelimit.WithLimiters(r.Context(), ratelimit.Download(user, storage.GetStorage())...))
 		err = common.Proxy(w, r, link, fi)
 		if err != nil {
 			log.Errorf("webdav proxy error: %+v", err)
@@ -264,6 +274,9 @@ func (h *Handler) handleGetHeadPost(w http.ResponseWriter, r *http.Request) (sta
 		http.Redirect(w, r, u, http.StatusFound)
 	} else {
 		link, _, err := fs.Link(ctx, reqPath, model.LinkArgs{IP: utils.ClientIP(r), Header: r.Header, HttpReq: r})
+		if common.IsDownloadStart(r) {
+			audit.Record(ctx, model.AuditDownload, reqPath, "", err)
+		}
 		if err != nil {
 			return http.StatusInternalServerError, err
 		}
@@ -289,6 +302,9 @@ func (h *Handler) handleDelete(w http.ResponseWriter, r *http.Request) (status i
 	if err != nil {
 		return 403, err
 	}
+	if !common.CanOperate(user, reqPath, model.ACLRemove, true) {
+		return http.StatusForbidden, errs.PermissionDenied
+	}
 	// TODO: return MultiStatus where appropriate.
 
 	// "godoc os RemoveAll" says that "If the path does not exist, RemoveAll
@@ -328,6 +344,9 @@ func (h *Handler) handlePut(w http.ResponseWriter, r *http.Request) (status int,
 	if err != nil {
 		return http.StatusForbidden, err
 	}
+	if !common.CanOperate(user, path.Dir(reqPath), model.ACLWrite, true) {
+		return http.StatusForbidden, errs.PermissionDenied
+	}
 	obj := model.Object{
 		Name:     path.Base(reqPath),
 		Size:     r.ContentLength,
@@ -346,6 +365,9 @@ func (h *Handler) handlePut(w http.ResponseWriter, r *http.Request) (status int,
 	if errs.IsNotFoundError(err) {
 		return http.StatusNotFound, err
 	}
+	if errors.Is(err, errs.QuotaExceeded) {
+		return StatusInsufficientStorage, err
+	}
 
 	_ = r.Body.Close()
 	_ = fsStream.Close()
@@ -382,6 +404,9 @@ func (h *Handler) handleMkcol(w http.ResponseWriter, r *http.Request) (status in
 	if err != nil {
 		return 403, err
 	}
+	if !common.CanOperate(user, path.Dir(reqPath), model.ACLWrite, true) {
+		return http.StatusForbidden, errs.PermissionDenied
+	}
 
 	if r.ContentLength > 0 {
 		return http.StatusUnsupportedMediaType, nil
@@ -450,6 +475,17 @@ func (h *Handler) handleCopyMove(w http.ResponseWriter, r *http.Request) (status
 	if err != nil {
 		return 403, err
 	}
+	operation := model.ACLCopy
+	if r.Method == "MOVE" {
+		operation = model.ACLMove
+		if path.Dir(src) == path.Dir(dst) {
+			operation = model.ACLRename
+		}
+	}
+	if !common.CanOperate(user, src, operation, true) ||
+		(operation != model.ACLRename && !common.CanOperate(user, path.Dir(dst), model.ACLWrite, true)) {
+		return http.StatusForbidden, errs.PermissionDenied
+	}
 
 	if r.Method == "COPY" {
 		// Section 7.5.1 says that a COPY only needs to lock the destination,
@@ -630,6 +666,9 @@ func (h *Handler) handlePropfind(w http.ResponseWriter, r *http.Request) (status
 	if err != nil {
 		return 403, err
 	}
+	if !common.CanOperate(user, reqPath, model.ACLRead, true) {
+		return http.StatusForbidden, errs.PermissionDenied
+	}
 	fi, err := fs.Get(ctx, reqPath, &fs.GetArgs{})
 	if err != nil {
 		if errs.IsNotFoundError(err) {
@@ -667,14 +706,14 @@ func (h *Handler) handlePropfind(w http.ResponseWriter, r *http.Request) (status
 			}
 			pstats = append(pstats, pstat)
 		} else if pf.Allprop != nil {
-			pstats, err = allprop(ctx, h.LockSystem, info, pf.Prop)
+			pstats, err = allprop(ctx, h.LockSystem, reqPath, info, pf.Prop)
 		} else {
-			pstats, err = props(ctx, h.LockSystem, info, pf.Prop)
+			pstats, err = props(ctx, h.LockSystem, reqPath, info, pf.Prop)
 		}
 		if err != nil {
 			return err
 		}
-		href := path.Join(h.Prefix, strings.TrimPrefix(reqPath, user.BasePath))
+		href := path.Join(h.Prefix, strings.TrimPrefix(reqPath, user.GetBasePath()))
 		if href != "/" && info.IsDir() {
 			href += "/"
 		}